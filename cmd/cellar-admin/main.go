@@ -0,0 +1,169 @@
+// Command cellar-admin manages the API keys and the datastore of a Cellar server. It reads the same configuration
+// as the server, so it manages the datastore the server uses.
+//
+//	cellar-admin keys create -name <name> -scopes <scope>[,<scope>...] [-tenant <tenant>]
+//	cellar-admin keys list
+//	cellar-admin keys revoke <id>
+//	cellar-admin datastore migrate-keys
+package main
+
+import (
+	"cellar/pkg/apikeys"
+	"cellar/pkg/datastore/redis"
+	"cellar/pkg/keyid"
+	"cellar/pkg/models"
+	"cellar/pkg/settings"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+const usage = `usage:
+  cellar-admin keys create -name <name> -scopes <scope>[,<scope>...] [-tenant <tenant>]
+  cellar-admin keys list
+  cellar-admin keys revoke <id>
+  cellar-admin datastore migrate-keys
+
+scopes: %s
+`
+
+var errUsage = errors.New("invalid arguments")
+
+func main() {
+	cfg := settings.NewConfiguration()
+	keyIds, err := keyid.NewHasher(cfg.Datastore().IDPepper())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: datastore id pepper: %v\n", err)
+		os.Exit(1)
+	}
+	dataStore := redis.NewDataStore(cfg.Datastore().Redis(), keyIds)
+	defer func() { _ = dataStore.Close() }()
+
+	err = run(context.Background(), dataStore, os.Args[1:], os.Stdout)
+	if errors.Is(err, errUsage) {
+		fmt.Fprintf(os.Stderr, usage, scopeNames())
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, dataStore *redis.DataStore, args []string, out io.Writer) error {
+	if len(args) == 2 && args[0] == "datastore" && args[1] == "migrate-keys" {
+		return migrateKeys(ctx, dataStore, out)
+	}
+	if len(args) < 2 || args[0] != "keys" {
+		return errUsage
+	}
+
+	store := apikeys.NewRedisStore(dataStore.Client())
+	switch args[1] {
+	case "create":
+		return createKey(ctx, store, args[2:], out)
+	case "list":
+		return listKeys(ctx, store, out)
+	case "revoke":
+		if len(args) != 3 {
+			return errUsage
+		}
+		return revokeKey(ctx, store, args[2], out)
+	default:
+		return errUsage
+	}
+}
+
+func createKey(ctx context.Context, store apikeys.Store, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("create", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	name := flags.String("name", "", "name of the key")
+	scopeList := flags.String("scopes", "", "comma separated scopes of the key")
+	tenant := flags.String("tenant", "", "tenant the key is bound to")
+	if err := flags.Parse(args); err != nil || *name == "" || *scopeList == "" {
+		return errUsage
+	}
+
+	var names []string
+	for _, scope := range strings.Split(*scopeList, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			names = append(names, scope)
+		}
+	}
+	scopes, err := models.ParseScopes(names)
+	if err != nil {
+		return err
+	}
+
+	key, token, err := apikeys.Generate(*name, scopes)
+	if err != nil {
+		return err
+	}
+	key.Tenant = *tenant
+	if err = store.Write(ctx, key); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Created API key %s. It is shown only once:\n%s\n", key.ID, token)
+	return nil
+}
+
+func listKeys(ctx context.Context, store apikeys.Store, out io.Writer) error {
+	keys, err := store.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "ID\tNAME\tSCOPES\tTENANT\tCREATED")
+	for _, key := range keys {
+		scopes := make([]string, len(key.Scopes))
+		for i, scope := range key.Scopes {
+			scopes[i] = string(scope)
+		}
+		tenant := key.Tenant
+		if tenant == "" {
+			tenant = "-"
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n", key.ID, key.Name, strings.Join(scopes, ","), tenant, key.Created().Format())
+	}
+	return writer.Flush()
+}
+
+func revokeKey(ctx context.Context, store apikeys.Store, id string, out io.Writer) error {
+	deleted, err := store.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return fmt.Errorf("API key %s not found", id)
+	}
+
+	fmt.Fprintf(out, "Revoked API key %s\n", id)
+	return nil
+}
+
+// migrateKeys moves the records stored under their IDs, before key IDs were introduced, to their key IDs.
+// Records that have not been moved cannot be found by the server, so it is run once when upgrading.
+func migrateKeys(ctx context.Context, dataStore *redis.DataStore, out io.Writer) error {
+	migrated, err := dataStore.MigrateKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Migrated %d datastore records to key ids\n", migrated)
+	return nil
+}
+
+func scopeNames() string {
+	names := make([]string, len(models.Scopes))
+	for i, scope := range models.Scopes {
+		names[i] = string(scope)
+	}
+	return strings.Join(names, ", ")
+}
@@ -2,11 +2,13 @@ package main
 
 import (
 	"cellar/pkg/controllers"
+	"cellar/pkg/controllers/admin"
 	v1 "cellar/pkg/controllers/v1"
 	v2 "cellar/pkg/controllers/v2"
 	"cellar/pkg/middleware"
 	"cellar/pkg/settings"
 	"context"
+	"encoding/json"
 	"net/http"
 	"os"
 
@@ -21,6 +23,8 @@ import (
 
 var ginLambda *ginadapter.GinLambda
 
+var dependencies *middleware.Dependencies
+
 var version = "0.0.0"
 
 func init() {
@@ -28,7 +32,7 @@ func init() {
 	settings.SetAppVersion(version)
 	cfg := settings.NewConfiguration()
 	setMultipartMemoryLimit(router, cfg)
-	middleware.Setup(router, cfg)
+	dependencies = middleware.Setup(router, cfg)
 	addRoutes(router)
 
 	ginLambda = ginadapter.New(router)
@@ -54,6 +58,8 @@ func addRoutes(router *gin.Engine) {
 
 	v2.Register(router)
 
+	admin.Register(router)
+
 }
 
 // DisablingWrapHandler turn handler off
@@ -71,10 +77,26 @@ func DisablingWrapHandler(handler *webdav.Handler, envName string, options ...fu
 }
 
 // Handler proxies API Gateway requests to the Gin application.
-func Handler(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+// Scheduled EventBridge invocations run an expiry reconciliation sweep instead,
+// since a Lambda function cannot hold a subscription to keyspace expiry events.
+func Handler(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+	var scheduledEvent events.CloudWatchEvent
+	if err := json.Unmarshal(payload, &scheduledEvent); err == nil && scheduledEvent.DetailType == "Scheduled Event" {
+		expired, err := middleware.SweepExpiredSecrets(ctx, dependencies)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]int{"expired": expired}, nil
+	}
+
+	var req events.APIGatewayProxyRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return nil, err
+	}
 	return ginLambda.ProxyWithContext(ctx, req)
 }
 
 func main() {
-	lambda.Start(Handler)
+	// Lambda sends SIGTERM before shutting the execution environment down, giving queued notifications a chance to be sent.
+	lambda.StartWithOptions(Handler, lambda.WithEnableSIGTERM(dependencies.Close))
 }
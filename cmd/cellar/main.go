@@ -2,31 +2,65 @@ package main
 
 import (
 	"cellar/pkg/controllers"
+	"cellar/pkg/controllers/admin"
 	v1 "cellar/pkg/controllers/v1"
 	v2 "cellar/pkg/controllers/v2"
 	"cellar/pkg/middleware"
 	"cellar/pkg/ratelimit"
 	"cellar/pkg/settings"
+	"context"
+	"errors"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"golang.org/x/net/webdav"
 
 	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
 var version = "0.0.0"
 
+// shutdownTimeout bounds how long requests in progress are given to finish once the server is asked to stop.
+const shutdownTimeout = 30 * time.Second
+
 func main() {
 	router := gin.New()
 	settings.SetAppVersion(version)
 	cfg := settings.NewConfiguration()
 	setMultipartMemoryLimit(router, cfg)
-	middleware.Setup(router, cfg)
+	dependencies := middleware.Setup(router, cfg)
+	middleware.StartExpiryListener(context.Background(), dependencies)
+	middleware.StartGeoIPReloader(context.Background(), dependencies)
 	addRoutes(router)
-	middleware.HandleError("error while starting the server", router.Run(cfg.App().BindAddress()))
+	serve(router, cfg, dependencies)
+}
+
+// serve runs the server until it is interrupted or terminated, then stops taking requests,
+// waits for those in progress and closes the dependencies, so queued notifications are still sent.
+func serve(router *gin.Engine, cfg settings.IConfiguration, dependencies *middleware.Dependencies) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	server := &http.Server{Addr: cfg.App().BindAddress(), Handler: router}
+	go func() {
+		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			middleware.HandleError("error while starting the server", err)
+		}
+	}()
+
+	<-ctx.Done()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.WithError(err).Error("error while shutting down the server")
+	}
+	dependencies.Close()
 }
 
 func setMultipartMemoryLimit(router *gin.Engine, cfg settings.IConfiguration) {
@@ -49,6 +83,8 @@ func addRoutes(router *gin.Engine) {
 
 	v2.Register(router)
 
+	admin.Register(router)
+
 }
 
 // DisablingWrapHandler turn handler off
@@ -19,6 +19,10 @@ if [ -z "$DATASTORE_REDIS_PASSWORD" ]; then
   [ -z "$DATASTORE_REDIS_PASSWORD_FILE" ] || export DATASTORE_REDIS_PASSWORD=$(cat "$DATASTORE_REDIS_PASSWORD_FILE")
 fi
 
+if [ -z "$DATASTORE_ID_PEPPER" ]; then
+  [ -z "$DATASTORE_ID_PEPPER_FILE" ] || export DATASTORE_ID_PEPPER=$(cat "$DATASTORE_ID_PEPPER_FILE")
+fi
+
 if [ -z "$CRYPTOGRAPHY_VAULT_AUTH_APPROLE_ROLE_ID" ]; then
   [ -z "$CRYPTOGRAPHY_VAULT_AUTH_APPROLE_ROLE_ID_FILE" ] || export CRYPTOGRAPHY_VAULT_AUTH_APPROLE_ROLE_ID=$(cat "$CRYPTOGRAPHY_VAULT_AUTH_APPROLE_ROLE_ID_FILE")
 fi
@@ -52,6 +56,7 @@ if [ -z "$CRYPTOGRAPHY_AWS_KMS_KEY_ID" ]; then
 fi
 
 verify_present "DATASTORE_REDIS_HOST" "$DATASTORE_REDIS_HOST"
+verify_present "DATASTORE_ID_PEPPER" "$DATASTORE_ID_PEPPER"
 
 exec /app/cellar $@
 
@@ -0,0 +1,69 @@
+// Package allowlist restricts access to a secret to clients from a list of IP ranges.
+package allowlist
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// Parse validates and normalizes a list of CIDR ranges. Bare IP addresses are taken as single-host ranges,
+// and host bits are masked so equal ranges are stored the same way.
+func Parse(values []string) ([]string, error) {
+	cidrs := make([]string, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		prefix, err := parsePrefix(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR range %q", value)
+		}
+		cidrs = append(cidrs, prefix.String())
+	}
+	return cidrs, nil
+}
+
+// Allows reports whether the client IP falls within one of the ranges. An empty list allows every client,
+// while a client IP that cannot be parsed is never allowed by a non-empty list.
+func Allows(cidrs []string, clientIP string) bool {
+	if len(cidrs) == 0 {
+		return true
+	}
+
+	addr, err := netip.ParseAddr(clientIP)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, cidr := range cidrs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err == nil && prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func parsePrefix(value string) (netip.Prefix, error) {
+	if !strings.Contains(value, "/") {
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		addr = addr.Unmap()
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+
+	prefix, err := netip.ParsePrefix(value)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+		prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+	}
+	return prefix.Masked(), nil
+}
@@ -0,0 +1,60 @@
+package allowlist
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("when the ranges are valid", func(t *testing.T) {
+		cidrs, err := Parse([]string{"10.0.0.0/8", " 192.168.1.17/24 ", "203.0.113.9", "2001:db8::/32", ""})
+		require.NoError(t, err)
+
+		t.Run("it should normalize the ranges", func(t *testing.T) {
+			assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.0/24", "203.0.113.9/32", "2001:db8::/32"}, cidrs)
+		})
+	})
+
+	t.Run("when a range is invalid", func(t *testing.T) {
+		_, err := Parse([]string{"10.0.0.0/8", "10.0.0.0/33"})
+
+		t.Run("it should return an error", func(t *testing.T) {
+			assert.Error(t, err)
+		})
+	})
+
+	t.Run("when a value is not an address", func(t *testing.T) {
+		_, err := Parse([]string{"vpn.example.com"})
+
+		t.Run("it should return an error", func(t *testing.T) {
+			assert.Error(t, err)
+		})
+	})
+}
+
+func TestAllows(t *testing.T) {
+	cidrs := []string{"10.0.0.0/8", "2001:db8::/32"}
+
+	t.Run("it should allow clients within a range", func(t *testing.T) {
+		assert.True(t, Allows(cidrs, "10.1.2.3"))
+		assert.True(t, Allows(cidrs, "2001:db8::1"))
+	})
+
+	t.Run("it should allow IPv4-mapped IPv6 clients within a range", func(t *testing.T) {
+		assert.True(t, Allows(cidrs, "::ffff:10.1.2.3"))
+	})
+
+	t.Run("it should reject clients outside every range", func(t *testing.T) {
+		assert.False(t, Allows(cidrs, "192.168.1.1"))
+	})
+
+	t.Run("it should reject clients without a valid address", func(t *testing.T) {
+		assert.False(t, Allows(cidrs, ""))
+	})
+
+	t.Run("it should allow every client when there are no ranges", func(t *testing.T) {
+		assert.True(t, Allows(nil, "192.168.1.1"))
+	})
+}
@@ -0,0 +1,111 @@
+// Package apikeys issues and checks the API keys that authenticate clients of restricted routes.
+// A key is given as "cellar_<id>_<secret>". The ID finds the key's record, which holds only the SHA-256
+// of the secret, so a copy of the datastore does not reveal any usable key.
+package apikeys
+
+import (
+	"cellar/pkg/models"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Key holds the store of API keys.
+var Key = "API_KEYS"
+
+// AuthenticatedKey holds the API key a request was authenticated with, if any.
+var AuthenticatedKey = "API_KEY"
+
+const (
+	prefix      = "cellar_"
+	idBytes     = 8
+	secretBytes = 32
+)
+
+// ErrInvalidKey is returned when a key is malformed, unknown or does not match its record.
+var ErrInvalidKey = errors.New("invalid API key")
+
+//go:generate mockgen -destination=../mocks/mock_apikey_store.go -package=mocks . Store
+type Store interface {
+	// Write stores a key, replacing any key with the same ID.
+	Write(ctx context.Context, key models.APIKey) error
+	// Read returns the key with the given ID, or nil if there is none.
+	Read(ctx context.Context, id string) (*models.APIKey, error)
+	// List returns every key, ordered by ID.
+	List(ctx context.Context) ([]models.APIKey, error)
+	// Delete removes the key with the given ID. Returns false if there was none.
+	Delete(ctx context.Context, id string) (bool, error)
+}
+
+// Generate creates a key with the given name and scopes. It returns the record to store
+// and the key to hand to the client, which is not stored anywhere.
+func Generate(name string, scopes []models.Scope) (models.APIKey, string, error) {
+	id, err := randomHex(idBytes)
+	if err != nil {
+		return models.APIKey{}, "", err
+	}
+	secret, err := randomHex(secretBytes)
+	if err != nil {
+		return models.APIKey{}, "", err
+	}
+
+	return models.APIKey{
+		ID:           id,
+		Name:         name,
+		SecretHash:   hashSecret(secret),
+		Scopes:       scopes,
+		CreatedEpoch: time.Now().Unix(),
+	}, prefix + id + "_" + secret, nil
+}
+
+// Authenticate returns the stored record of a key. Returns ErrInvalidKey if the key does not match a stored record.
+func Authenticate(ctx context.Context, store Store, token string) (*models.APIKey, error) {
+	id, secret, ok := parse(token)
+	if !ok {
+		return nil, ErrInvalidKey
+	}
+
+	key, err := store.Read(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil || subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(key.SecretHash)) != 1 {
+		return nil, ErrInvalidKey
+	}
+	return key, nil
+}
+
+// IsKey reports whether a bearer token is given in the format of an API key, rather than being a token of another kind.
+func IsKey(token string) bool {
+	return strings.HasPrefix(token, prefix)
+}
+
+func parse(token string) (id string, secret string, ok bool) {
+	rest, found := strings.CutPrefix(token, prefix)
+	if !found {
+		return "", "", false
+	}
+	id, secret, found = strings.Cut(rest, "_")
+	if !found || len(id) != 2*idBytes || len(secret) != 2*secretBytes {
+		return "", "", false
+	}
+	return id, secret, true
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(size int) (string, error) {
+	bytes := make([]byte, size)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
@@ -0,0 +1,119 @@
+package apikeys_test
+
+import (
+	"cellar/pkg/apikeys"
+	"cellar/pkg/mocks"
+	"cellar/pkg/models"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGenerate(t *testing.T) {
+	key, token, err := apikeys.Generate("deploy", []models.Scope{models.ScopeCreate})
+	require.NoError(t, err)
+
+	t.Run("it should return a key that names its record", func(t *testing.T) {
+		assert.True(t, strings.HasPrefix(token, "cellar_"+key.ID+"_"))
+	})
+
+	t.Run("it should not store the secret of the key", func(t *testing.T) {
+		secret := strings.TrimPrefix(token, "cellar_"+key.ID+"_")
+		assert.NotEmpty(t, key.SecretHash)
+		assert.NotContains(t, key.SecretHash, secret)
+	})
+
+	t.Run("it should keep the name and scopes", func(t *testing.T) {
+		assert.Equal(t, "deploy", key.Name)
+		assert.Equal(t, []models.Scope{models.ScopeCreate}, key.Scopes)
+		assert.NotZero(t, key.CreatedEpoch)
+	})
+}
+
+func TestAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	key, token, err := apikeys.Generate("deploy", []models.Scope{models.ScopeCreate})
+	require.NoError(t, err)
+
+	sut := func(t *testing.T, token string, stored *models.APIKey, storeErr error) (*models.APIKey, error) {
+		ctrl := gomock.NewController(t)
+		store := mocks.NewMockStore(ctrl)
+		store.EXPECT().Read(gomock.Any(), key.ID).Return(stored, storeErr).MaxTimes(1)
+		return apikeys.Authenticate(ctx, store, token)
+	}
+
+	t.Run("when the key matches its record", func(t *testing.T) {
+		actual, err := sut(t, token, &key, nil)
+		require.NoError(t, err)
+
+		t.Run("it should return the record", func(t *testing.T) {
+			assert.Equal(t, &key, actual)
+		})
+	})
+
+	t.Run("when the secret of the key is changed", func(t *testing.T) {
+		last := "0"
+		if strings.HasSuffix(token, last) {
+			last = "1"
+		}
+		changed := token[:len(token)-1] + last
+		_, err := sut(t, changed, &key, nil)
+
+		t.Run("it should reject the key", func(t *testing.T) {
+			assert.ErrorIs(t, err, apikeys.ErrInvalidKey)
+		})
+	})
+
+	t.Run("when the key was revoked", func(t *testing.T) {
+		_, err := sut(t, token, nil, nil)
+
+		t.Run("it should reject the key", func(t *testing.T) {
+			assert.ErrorIs(t, err, apikeys.ErrInvalidKey)
+		})
+	})
+
+	t.Run("when the key is malformed", func(t *testing.T) {
+		for _, malformed := range []string{"", "cellar_", token[len("cellar_"):], "cellar_" + key.ID, token + "0"} {
+			_, err := sut(t, malformed, &key, nil)
+
+			t.Run("it should reject the key", func(t *testing.T) {
+				assert.ErrorIs(t, err, apikeys.ErrInvalidKey)
+			})
+		}
+	})
+
+	t.Run("when the store fails", func(t *testing.T) {
+		storeErr := errors.New("datastore unavailable")
+		_, err := sut(t, token, nil, storeErr)
+
+		t.Run("it should return the error", func(t *testing.T) {
+			assert.ErrorIs(t, err, storeErr)
+		})
+	})
+}
+
+func TestHasScope(t *testing.T) {
+	t.Run("when the key was granted the scope", func(t *testing.T) {
+		key := models.APIKey{Scopes: []models.Scope{models.ScopeCreate}}
+
+		t.Run("it should have the scope and no other", func(t *testing.T) {
+			assert.True(t, key.HasScope(models.ScopeCreate))
+			assert.False(t, key.HasScope(models.ScopeDeleteAny))
+		})
+	})
+
+	t.Run("when the key was granted the admin scope", func(t *testing.T) {
+		key := models.APIKey{Scopes: []models.Scope{models.ScopeAdmin}}
+
+		t.Run("it should have every scope", func(t *testing.T) {
+			for _, scope := range models.Scopes {
+				assert.True(t, key.HasScope(scope))
+			}
+		})
+	})
+}
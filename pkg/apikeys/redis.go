@@ -0,0 +1,144 @@
+package apikeys
+
+import (
+	pkgerrors "cellar/pkg/errors"
+	"cellar/pkg/models"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	log "github.com/sirupsen/logrus"
+)
+
+// indexKey is a set of the IDs of every key, so keys can be listed without scanning the datastore.
+const indexKey = "cellar:apikeys"
+
+type RedisStore struct {
+	client *redis.Client
+	logger *log.Entry
+}
+
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{
+		client: client,
+		logger: log.WithFields(log.Fields{
+			"context": "api keys",
+			"backend": "redis",
+		}),
+	}
+}
+
+func (store *RedisStore) Write(ctx context.Context, key models.APIKey) error {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return err
+	}
+
+	scopes := make([]string, len(key.Scopes))
+	for i, scope := range key.Scopes {
+		scopes[i] = string(scope)
+	}
+
+	store.logger.WithField("apiKeyId", key.ID).Debug("writing api key to redis")
+	_, err := store.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, recordKey(key.ID))
+		pipe.HSet(ctx, recordKey(key.ID),
+			"name", key.Name,
+			"secrethash", key.SecretHash,
+			"scopes", strings.Join(scopes, ","),
+			"tenant", key.Tenant,
+			"created", strconv.FormatInt(key.CreatedEpoch, 10),
+		)
+		pipe.SAdd(ctx, indexKey, key.ID)
+		return nil
+	})
+	return err
+}
+
+func (store *RedisStore) Read(ctx context.Context, id string) (*models.APIKey, error) {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return nil, err
+	}
+
+	fields, err := store.client.HGetAll(ctx, recordKey(id)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return parseRecord(id, fields)
+}
+
+func (store *RedisStore) List(ctx context.Context) ([]models.APIKey, error) {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return nil, err
+	}
+
+	ids, err := store.client.SMembers(ctx, indexKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(ids)
+
+	keys := make([]models.APIKey, 0, len(ids))
+	for _, id := range ids {
+		key, err := store.Read(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if key != nil {
+			keys = append(keys, *key)
+		}
+	}
+	return keys, nil
+}
+
+func (store *RedisStore) Delete(ctx context.Context, id string) (bool, error) {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return false, err
+	}
+
+	store.logger.WithField("apiKeyId", id).Debug("deleting api key from redis")
+	var deleted *redis.IntCmd
+	_, err := store.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		deleted = pipe.Del(ctx, recordKey(id))
+		pipe.SRem(ctx, indexKey, id)
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return deleted.Val() > 0, nil
+}
+
+func parseRecord(id string, fields map[string]string) (*models.APIKey, error) {
+	created, err := strconv.ParseInt(fields["created"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("api key %s has an invalid creation time: %w", id, err)
+	}
+
+	var names []string
+	if fields["scopes"] != "" {
+		names = strings.Split(fields["scopes"], ",")
+	}
+	scopes, err := models.ParseScopes(names)
+	if err != nil {
+		return nil, fmt.Errorf("api key %s has invalid scopes: %w", id, err)
+	}
+
+	return &models.APIKey{
+		ID:           id,
+		Name:         fields["name"],
+		SecretHash:   fields["secrethash"],
+		Scopes:       scopes,
+		Tenant:       fields["tenant"],
+		CreatedEpoch: created,
+	}, nil
+}
+
+func recordKey(id string) string {
+	return fmt.Sprintf("cellar:apikeys:%s", id)
+}
@@ -0,0 +1,89 @@
+package apikeys_test
+
+import (
+	"cellar/pkg/apikeys"
+	"cellar/pkg/models"
+	"context"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisStore(t *testing.T) {
+	ctx := context.Background()
+	store := apikeys.NewRedisStore(setupRedisClient(t))
+
+	key, _, err := apikeys.Generate("deploy", []models.Scope{models.ScopeCreate, models.ScopeReadConfig})
+	require.NoError(t, err)
+	key.Tenant = "finance"
+	require.NoError(t, store.Write(ctx, key))
+
+	t.Run("when reading a stored key", func(t *testing.T) {
+		actual, err := store.Read(ctx, key.ID)
+		require.NoError(t, err)
+
+		t.Run("it should return the key", func(t *testing.T) {
+			assert.Equal(t, &key, actual)
+		})
+	})
+
+	t.Run("when listing keys", func(t *testing.T) {
+		keys, err := store.List(ctx)
+		require.NoError(t, err)
+
+		t.Run("it should include the key", func(t *testing.T) {
+			assert.Contains(t, keys, key)
+		})
+	})
+
+	t.Run("when the key is deleted", func(t *testing.T) {
+		deleted, err := store.Delete(ctx, key.ID)
+		require.NoError(t, err)
+
+		t.Run("it should report the deletion", func(t *testing.T) {
+			assert.True(t, deleted)
+		})
+
+		t.Run("it should no longer read the key", func(t *testing.T) {
+			actual, err := store.Read(ctx, key.ID)
+			require.NoError(t, err)
+			assert.Nil(t, actual)
+		})
+
+		t.Run("it should no longer list the key", func(t *testing.T) {
+			keys, err := store.List(ctx)
+			require.NoError(t, err)
+			assert.NotContains(t, keys, key)
+		})
+
+		t.Run("and deleted again", func(t *testing.T) {
+			deleted, err := store.Delete(ctx, key.ID)
+			require.NoError(t, err)
+
+			t.Run("it should report that there was nothing to delete", func(t *testing.T) {
+				assert.False(t, deleted)
+			})
+		})
+	})
+}
+
+func setupRedisClient(t *testing.T) *redis.Client {
+	client := redis.NewClient(&redis.Options{
+		Addr: "localhost:6379",
+		DB:   1,
+	})
+
+	ctx := context.Background()
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+
+	t.Cleanup(func() {
+		client.FlushDB(ctx)
+		_ = client.Close()
+	})
+
+	return client
+}
@@ -0,0 +1,91 @@
+package bundles
+
+import (
+	"archive/zip"
+	"bytes"
+	"cellar/pkg/models"
+	"cellar/pkg/plaintext"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// NoteFilename is the name given to the text note of a bundle.
+const NoteFilename = "note.txt"
+
+// File is a named item to pack into a bundle.
+type File struct {
+	Name    string
+	Content []byte
+}
+
+// Write packs the files into a zip archive in the given order.
+// Duplicate names are made unique so every item can be extracted on its own.
+// Returns the archive and the index of its items.
+func Write(files []File) ([]byte, []models.BundleItem, error) {
+	buf := bytes.NewBuffer(nil)
+	writer := zip.NewWriter(buf)
+
+	items := make([]models.BundleItem, 0, len(files))
+	used := make(map[string]bool, len(files))
+	for _, file := range files {
+		name := uniqueName(file.Name, used)
+		used[name] = true
+
+		entry, err := writer.Create(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, err = entry.Write(file.Content); err != nil {
+			return nil, nil, err
+		}
+
+		items = append(items, models.BundleItem{
+			Name: name,
+			Size: int64(len(file.Content)),
+		})
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	return buf.Bytes(), items, nil
+}
+
+// ReadItem returns the content of the item at the given position of the archive,
+// in a slice allocated with plaintext.Alloc.
+func ReadItem(archive []byte, index int) ([]byte, error) {
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, err
+	}
+
+	if index < 0 || index >= len(reader.File) {
+		return nil, fmt.Errorf("bundle item %d does not exist", index)
+	}
+
+	file := reader.File[index]
+	entry, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = entry.Close() }()
+
+	return plaintext.ReadFull(entry, int64(file.UncompressedSize64))
+}
+
+func uniqueName(name string, used map[string]bool) string {
+	if !used[name] {
+		return name
+	}
+
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, i, ext)
+		if !used[candidate] {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package bundles
+
+import (
+	"cellar/pkg/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrite(t *testing.T) {
+	t.Run("when writing a bundle", func(t *testing.T) {
+		archive, items, err := Write([]File{
+			{Name: "cert.pem", Content: []byte("certificate")},
+			{Name: "key.pem", Content: []byte("private key")},
+			{Name: NoteFilename, Content: []byte("rotate by friday")},
+		})
+		require.NoError(t, err)
+
+		t.Run("it should index the items in order", func(t *testing.T) {
+			assert.Equal(t, []models.BundleItem{
+				{Name: "cert.pem", Size: 11},
+				{Name: "key.pem", Size: 11},
+				{Name: NoteFilename, Size: 16},
+			}, items)
+		})
+
+		t.Run("it should read each item back by position", func(t *testing.T) {
+			content, err := ReadItem(archive, 1)
+			require.NoError(t, err)
+			assert.Equal(t, []byte("private key"), content)
+		})
+
+		t.Run("it should reject positions outside the bundle", func(t *testing.T) {
+			_, err := ReadItem(archive, 3)
+			assert.Error(t, err)
+
+			_, err = ReadItem(archive, -1)
+			assert.Error(t, err)
+		})
+	})
+
+	t.Run("when files share a name", func(t *testing.T) {
+		_, items, err := Write([]File{
+			{Name: "id.pem", Content: []byte("a")},
+			{Name: "id.pem", Content: []byte("b")},
+			{Name: "id.pem", Content: []byte("c")},
+		})
+		require.NoError(t, err)
+
+		t.Run("it should make the names unique", func(t *testing.T) {
+			assert.Equal(t, "id.pem", items[0].Name)
+			assert.Equal(t, "id (2).pem", items[1].Name)
+			assert.Equal(t, "id (3).pem", items[2].Name)
+		})
+	})
+}
@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"cellar/pkg/datastore"
+	pkgerrors "cellar/pkg/errors"
+	"cellar/pkg/models"
+	"context"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// getAuditLogger records administrative actions to the audit log, with who carried them out.
+func getAuditLogger(action string, actor models.AdminActor) *log.Entry {
+	return log.WithFields(log.Fields{
+		"context":  "audit",
+		"action":   action,
+		"actor":    actor.Credential,
+		"clientIp": actor.ClientIP,
+		"tenant":   actor.Tenant,
+	})
+}
+
+// GetSecretStats counts the live secrets of the datastore's namespace and the bytes of their encrypted content.
+// The context can be used to cancel the operation before completion.
+func GetSecretStats(ctx context.Context, dataStore datastore.AdminDataStore, actor models.AdminActor) (models.SecretStats, error) {
+	logger := getAuditLogger("secret_stats", actor)
+
+	var stats models.SecretStats
+	err := dataStore.ScanSecrets(ctx, func(record models.SecretRecord) error {
+		stats.Count++
+		stats.Bytes += record.Size
+		return nil
+	})
+	if err != nil {
+		logger.WithError(err).Error("Failed to count secrets")
+		return models.SecretStats{}, err
+	}
+
+	logger.WithFields(log.Fields{
+		"count": stats.Count,
+		"bytes": stats.Bytes,
+	}).Info("Counted secrets")
+	return stats, nil
+}
+
+// PurgeSecrets burns every secret of the datastore's namespace the filter selects, and returns how many were burned.
+// A filter that selects nothing returns a ValidationError, so a purge never burns every secret by mistake.
+// Secrets without a creation time are never purged by created_before_epoch, only by creator.
+// If the purge fails part way, the secrets burned until then stay burned.
+// The context can be used to cancel the operation before completion.
+func PurgeSecrets(ctx context.Context, dataStore datastore.AdminDataStore, actor models.AdminActor, filter models.PurgeFilter) (int64, error) {
+	if filter.CreatedBeforeEpoch < 0 {
+		return 0, pkgerrors.NewValidationError("created_before_epoch must not be negative")
+	}
+	if filter.CreatedBeforeEpoch == 0 && filter.Creator == "" {
+		return 0, pkgerrors.NewValidationError("required parameter: created_before_epoch or creator")
+	}
+
+	logger := getAuditLogger("purge_secrets", actor).WithFields(log.Fields{
+		"createdBeforeEpoch": filter.CreatedBeforeEpoch,
+		"creator":            filter.Creator,
+	})
+
+	var purged int64
+	err := dataStore.ScanSecrets(ctx, func(record models.SecretRecord) error {
+		if !filter.Matches(record) {
+			return nil
+		}
+		found, err := dataStore.BurnSecret(ctx, record.KeyID)
+		if err != nil {
+			return err
+		}
+		if found {
+			purged++
+		}
+		return nil
+	})
+	if err != nil {
+		logger.WithError(err).WithField("purged", purged).Error("Failed to purge secrets")
+		return purged, err
+	}
+
+	logger.WithField("purged", purged).Warn("Purged secrets")
+	return purged, nil
+}
+
+// BurnSecret deletes the secret stored under the key ID in the datastore's namespace. Key IDs are what the logs
+// name secrets by, so a secret can be burned without knowing its ID. Returns false if the secret is not found.
+// The context can be used to cancel the operation before completion.
+func BurnSecret(ctx context.Context, dataStore datastore.AdminDataStore, actor models.AdminActor, keyId string) (bool, error) {
+	logger := getAuditLogger("burn_secret", actor).WithField("secretId", keyId)
+
+	found, err := dataStore.BurnSecret(ctx, keyId)
+	if err != nil {
+		logger.WithError(err).Error("Failed to burn secret")
+		return false, err
+	}
+
+	logger.WithField("found", found).Warn("Burned secret")
+	return found, nil
+}
+
+// GetReadOnly reports whether the server is in read-only mode.
+// The context can be used to cancel the operation before completion.
+func GetReadOnly(ctx context.Context, dataStore datastore.AdminDataStore, actor models.AdminActor) (bool, error) {
+	logger := getAuditLogger("get_read_only", actor)
+
+	readOnly, err := dataStore.ReadOnly(ctx)
+	if err != nil {
+		logger.WithError(err).Error("Failed to read read-only mode")
+		return false, err
+	}
+
+	logger.WithField("readOnly", readOnly).Info("Read read-only mode")
+	return readOnly, nil
+}
+
+// SetReadOnly puts the whole server in or out of read-only mode. In read-only mode no secrets, splits or
+// secret requests can be created or changed, while existing secrets can still be accessed and deleted.
+// The context can be used to cancel the operation before completion.
+func SetReadOnly(ctx context.Context, dataStore datastore.AdminDataStore, actor models.AdminActor, readOnly bool) error {
+	logger := getAuditLogger("set_read_only", actor).WithField("readOnly", readOnly)
+
+	if err := dataStore.SetReadOnly(ctx, readOnly); err != nil {
+		logger.WithError(err).Error("Failed to set read-only mode")
+		return err
+	}
+
+	logger.Warn("Set read-only mode")
+	return nil
+}
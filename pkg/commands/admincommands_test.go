@@ -0,0 +1,171 @@
+package commands_test
+
+import (
+	"cellar/pkg/commands"
+	pkgerrors "cellar/pkg/errors"
+	"cellar/pkg/mocks"
+	"cellar/pkg/models"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+var (
+	adminActor   = models.AdminActor{Credential: "api-key:k1", ClientIP: "203.0.113.7"}
+	adminRecords = []models.SecretRecord{
+		{KeyID: "a", Creator: "alice", CreatedEpoch: 1000, Size: 100},
+		{KeyID: "b", Creator: "bob", CreatedEpoch: 2000, Size: 200},
+		{KeyID: "c", Creator: "alice", CreatedEpoch: 3000, Size: 300},
+		{KeyID: "d", Size: 400},
+	}
+)
+
+func scanRecords(_ context.Context, visit func(record models.SecretRecord) error) error {
+	for _, record := range adminRecords {
+		if err := visit(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestWhenGettingSecretStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dataStore := mocks.NewMockAdminDataStore(ctrl)
+	dataStore.EXPECT().ScanSecrets(gomock.Any(), gomock.Any()).DoAndReturn(scanRecords)
+
+	stats, err := commands.GetSecretStats(context.Background(), dataStore, adminActor)
+
+	t.Run("it should not return error", func(t *testing.T) {
+		assert.NoError(t, err)
+	})
+
+	t.Run("it should count every secret", func(t *testing.T) {
+		assert.Equal(t, int64(4), stats.Count)
+	})
+
+	t.Run("it should sum the size of every secret", func(t *testing.T) {
+		assert.Equal(t, int64(1000), stats.Bytes)
+	})
+}
+
+func TestWhenPurgingSecrets(t *testing.T) {
+	purge := func(t *testing.T, filter models.PurgeFilter) ([]string, int64, error) {
+		ctrl := gomock.NewController(t)
+		dataStore := mocks.NewMockAdminDataStore(ctrl)
+		dataStore.EXPECT().ScanSecrets(gomock.Any(), gomock.Any()).DoAndReturn(scanRecords).AnyTimes()
+
+		var burned []string
+		dataStore.EXPECT().
+			BurnSecret(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, keyId string) (bool, error) {
+				burned = append(burned, keyId)
+				return true, nil
+			}).
+			AnyTimes()
+
+		purged, err := commands.PurgeSecrets(context.Background(), dataStore, adminActor, filter)
+		return burned, purged, err
+	}
+
+	t.Run("by creation time", func(t *testing.T) {
+		burned, purged, err := purge(t, models.PurgeFilter{CreatedBeforeEpoch: 2500})
+
+		t.Run("it should not return error", func(t *testing.T) {
+			assert.NoError(t, err)
+		})
+
+		t.Run("it should burn the secrets created before the epoch", func(t *testing.T) {
+			assert.Equal(t, []string{"a", "b"}, burned)
+			assert.Equal(t, int64(2), purged)
+		})
+
+		t.Run("it should skip secrets without a creation time", func(t *testing.T) {
+			assert.NotContains(t, burned, "d")
+		})
+	})
+
+	t.Run("by creator", func(t *testing.T) {
+		burned, _, _ := purge(t, models.PurgeFilter{Creator: "alice"})
+
+		t.Run("it should burn the secrets of the creator", func(t *testing.T) {
+			assert.Equal(t, []string{"a", "c"}, burned)
+		})
+	})
+
+	t.Run("by creation time and creator", func(t *testing.T) {
+		burned, _, _ := purge(t, models.PurgeFilter{CreatedBeforeEpoch: 2500, Creator: "alice"})
+
+		t.Run("it should burn the secrets matching both", func(t *testing.T) {
+			assert.Equal(t, []string{"a"}, burned)
+		})
+	})
+
+	t.Run("without a filter", func(t *testing.T) {
+		burned, _, err := purge(t, models.PurgeFilter{})
+
+		t.Run("it should return validation error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsValidationError(err))
+		})
+
+		t.Run("it should not burn any secret", func(t *testing.T) {
+			assert.Empty(t, burned)
+		})
+	})
+
+	t.Run("and burning a secret fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		dataStore := mocks.NewMockAdminDataStore(ctrl)
+		dataStore.EXPECT().ScanSecrets(gomock.Any(), gomock.Any()).DoAndReturn(scanRecords)
+		dataStore.EXPECT().BurnSecret(gomock.Any(), "a").Return(true, nil)
+		dataStore.EXPECT().BurnSecret(gomock.Any(), "c").Return(false, errors.New("datastore unavailable"))
+
+		purged, err := commands.PurgeSecrets(context.Background(), dataStore, adminActor, models.PurgeFilter{Creator: "alice"})
+
+		t.Run("it should return error", func(t *testing.T) {
+			assert.Error(t, err)
+		})
+
+		t.Run("it should return the secrets burned until then", func(t *testing.T) {
+			assert.Equal(t, int64(1), purged)
+		})
+	})
+}
+
+func TestWhenBurningSecret(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dataStore := mocks.NewMockAdminDataStore(ctrl)
+	dataStore.EXPECT().BurnSecret(gomock.Any(), "a").Return(true, nil)
+
+	found, err := commands.BurnSecret(context.Background(), dataStore, adminActor, "a")
+
+	t.Run("it should not return error", func(t *testing.T) {
+		assert.NoError(t, err)
+	})
+
+	t.Run("it should report the secret was found", func(t *testing.T) {
+		assert.True(t, found)
+	})
+}
+
+func TestWhenSettingReadOnlyMode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dataStore := mocks.NewMockAdminDataStore(ctrl)
+	dataStore.EXPECT().SetReadOnly(gomock.Any(), true).Return(nil)
+	dataStore.EXPECT().ReadOnly(gomock.Any()).Return(true, nil)
+
+	err := commands.SetReadOnly(context.Background(), dataStore, adminActor, true)
+	readOnly, readErr := commands.GetReadOnly(context.Background(), dataStore, adminActor)
+
+	t.Run("it should not return error", func(t *testing.T) {
+		assert.NoError(t, err)
+		assert.NoError(t, readErr)
+	})
+
+	t.Run("it should report read-only mode", func(t *testing.T) {
+		assert.True(t, readOnly)
+	})
+}
@@ -13,5 +13,7 @@ func GetConfig(cfg settings.IConfiguration) models.LimitsConfig {
 		MaxFileSizeMB:        cfg.App().MaxFileSizeMB(),
 		MaxAccessCount:       cfg.App().MaxAccessCount(),
 		MaxExpirationSeconds: cfg.App().MaxExpirationSeconds(),
+		MaxKVFields:          cfg.App().MaxKVFields(),
+		MaxKVKeyLength:       cfg.App().MaxKVKeyLength(),
 	}
 }
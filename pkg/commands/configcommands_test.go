@@ -28,5 +28,13 @@ func TestGetConfig(t *testing.T) {
 		t.Run("it should return maxExpirationSeconds from configuration", func(t *testing.T) {
 			assert.Equal(t, cfg.App().MaxExpirationSeconds(), result.MaxExpirationSeconds)
 		})
+
+		t.Run("it should return maxKvFields from configuration", func(t *testing.T) {
+			assert.Equal(t, cfg.App().MaxKVFields(), result.MaxKVFields)
+		})
+
+		t.Run("it should return maxKvKeyLength from configuration", func(t *testing.T) {
+			assert.Equal(t, cfg.App().MaxKVKeyLength(), result.MaxKVKeyLength)
+		})
 	})
 }
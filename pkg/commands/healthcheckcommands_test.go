@@ -21,7 +21,7 @@ func TestGetHealth(t *testing.T) {
 		encryptionHealth := *models.NewHealth("test_encryption", models.Healthy, "1.0.0")
 		encryption.EXPECT().Health(gomock.Any()).Return(encryptionHealth)
 
-		dataStore := mocks.NewMockDataStore(ctrl)
+		dataStore := newMockDataStore(ctrl)
 		dataStoreHealth := *models.NewHealth("test_datastore", models.Healthy, "0.1.0")
 		dataStore.EXPECT().Health(gomock.Any()).Return(dataStoreHealth)
 
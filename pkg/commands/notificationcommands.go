@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"cellar/pkg/datastore"
+	"cellar/pkg/models"
+	"cellar/pkg/notifications"
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// NotifySecretExpired sends an expiry notification for a secret that expired without being opened.
+// The secret is identified by its key ID, as its ID is no longer known once it has expired.
+// Secrets that were opened, deleted, or created without a notification address are skipped.
+// The context can be used to cancel the operation before completion.
+func NotifySecretExpired(ctx context.Context, dataStore datastore.DataStore, notifier notifications.Notifier, keyId string) error {
+	logger := log.WithFields(log.Fields{
+		"context":  "secret commands",
+		"secretId": keyId,
+	})
+
+	notice, err := dataStore.TakeExpiryNotification(ctx, keyId)
+	if err != nil {
+		logger.WithError(err).Error("Error reading secret expiry notification")
+		return err
+	}
+
+	if notice.Recipient == "" {
+		return nil
+	}
+
+	logger.Info("Sending secret expiry notification")
+	err = notifier.Notify(ctx, models.Notification{
+		Type:       models.NotificationSecretExpired,
+		Recipient:  notice.Recipient,
+		Reference:  notice.Reference,
+		OccurredAt: time.Now().UTC(),
+	})
+	if err != nil {
+		logger.WithError(err).Error("Error sending secret expiry notification")
+	}
+
+	return err
+}
+
+// notifySecretAccessed tells the sender that their secret was opened.
+// Once a secret has been opened it can no longer expire unread, so any pending expiry notification is cancelled.
+// Notification failures are logged and never prevent the secret from being returned.
+func notifySecretAccessed(ctx context.Context, dataStore datastore.DataStore, notifier notifications.Notifier, secret *models.Secret, accessCount int64) {
+	logger := getLogger(dataStore, secret.ID)
+
+	if err := dataStore.CancelExpiryNotification(ctx, secret.ID); err != nil {
+		logger.WithError(err).Warn("Error cancelling secret expiry notification")
+	}
+
+	logger.Info("Sending secret access notification")
+	err := notifier.Notify(ctx, models.Notification{
+		Type:        models.NotificationSecretAccessed,
+		Recipient:   secret.NotifyEmail,
+		Reference:   models.SecretReference(secret.ID),
+		AccessCount: int(accessCount),
+		AccessLimit: secret.AccessLimit,
+		OccurredAt:  time.Now().UTC(),
+	})
+	if err != nil {
+		logger.WithError(err).Error("Error sending secret access notification")
+	}
+}
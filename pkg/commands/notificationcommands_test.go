@@ -0,0 +1,88 @@
+package commands_test
+
+import (
+	"cellar/pkg/commands"
+	"cellar/pkg/mocks"
+	"cellar/pkg/models"
+	"cellar/testing/testhelpers"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestWhenNotifyingSecretExpired(t *testing.T) {
+	t.Run("and secret has a pending expiry notification", func(t *testing.T) {
+		keyId := keyIds.Hash(testhelpers.RandomId(t))
+		notice := models.ExpiryNotice{Recipient: "sender@example.com", Reference: "22b6fff1"}
+
+		ctrl := gomock.NewController(t)
+		dataStore := newMockDataStore(ctrl)
+		dataStore.EXPECT().
+			TakeExpiryNotification(gomock.Any(), keyId).
+			Return(notice, nil)
+
+		var sent models.Notification
+		notifier := mocks.NewMockNotifier(ctrl)
+		notifier.EXPECT().
+			Notify(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, notification models.Notification) error {
+				sent = notification
+				return nil
+			})
+
+		err := commands.NotifySecretExpired(context.Background(), dataStore, notifier, keyId)
+
+		t.Run("it should not return error", func(t *testing.T) {
+			assert.NoError(t, err)
+		})
+
+		t.Run("it should send an expiry notification", func(t *testing.T) {
+			assert.Equal(t, models.NotificationType(models.NotificationSecretExpired), sent.Type)
+		})
+
+		t.Run("it should send to the pending recipient", func(t *testing.T) {
+			assert.Equal(t, notice.Recipient, sent.Recipient)
+		})
+
+		t.Run("it should reference the secret", func(t *testing.T) {
+			assert.Equal(t, notice.Reference, sent.Reference)
+		})
+	})
+
+	t.Run("and secret has no pending expiry notification", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		dataStore := newMockDataStore(ctrl)
+		dataStore.EXPECT().
+			TakeExpiryNotification(gomock.Any(), gomock.Any()).
+			Return(models.ExpiryNotice{}, nil)
+
+		notifier := mocks.NewMockNotifier(ctrl)
+		notifier.EXPECT().Notify(gomock.Any(), gomock.Any()).Times(0)
+
+		err := commands.NotifySecretExpired(context.Background(), dataStore, notifier, testhelpers.RandomId(t))
+
+		t.Run("it should not return error", func(t *testing.T) {
+			assert.NoError(t, err)
+		})
+	})
+
+	t.Run("and reading the expiry notification fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		dataStore := newMockDataStore(ctrl)
+		dataStore.EXPECT().
+			TakeExpiryNotification(gomock.Any(), gomock.Any()).
+			Return(models.ExpiryNotice{}, errors.New("datastore unavailable"))
+
+		notifier := mocks.NewMockNotifier(ctrl)
+		notifier.EXPECT().Notify(gomock.Any(), gomock.Any()).Times(0)
+
+		err := commands.NotifySecretExpired(context.Background(), dataStore, notifier, testhelpers.RandomId(t))
+
+		t.Run("it should return error", func(t *testing.T) {
+			assert.Error(t, err)
+		})
+	})
+}
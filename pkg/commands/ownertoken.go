@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"cellar/pkg/models"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+)
+
+// newOwnerToken generates an owner token and the hash that is stored in its place.
+// The token itself is only ever returned to the owner and is never persisted.
+func newOwnerToken() (token string, hash string, err error) {
+	token, err = randomId()
+	if err != nil {
+		return "", "", err
+	}
+	return token, hashOwnerToken(token), nil
+}
+
+func hashOwnerToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// ownerTokenMatches compares a presented owner token against a stored hash in constant time.
+func ownerTokenMatches(hash string, token string) bool {
+	if hash == "" || token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(hashOwnerToken(token)), []byte(hash)) == 1
+}
+
+// isOwner reports whether the caller presented the secret's owner token, or the subject of the identity that created it.
+func isOwner(secret *models.Secret, owner models.OwnerCredentials) bool {
+	if ownerTokenMatches(secret.OwnerTokenHash, owner.OwnerToken) {
+		return true
+	}
+	return secret.Creator != "" && owner.Subject != "" &&
+		subtle.ConstantTimeCompare([]byte(secret.Creator), []byte(owner.Subject)) == 1
+}
@@ -0,0 +1,191 @@
+package commands
+
+import (
+	"cellar/pkg/cryptography"
+	"cellar/pkg/datastore"
+	pkgerrors "cellar/pkg/errors"
+	"cellar/pkg/models"
+	"cellar/pkg/settings"
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func getRequestLogger(dataStore datastore.DataStore, requestId string) *log.Entry {
+	return log.WithFields(log.Fields{
+		"context":   "request commands",
+		"requestId": dataStore.KeyID(requestId),
+	})
+}
+
+// CreateSecretRequest stores a new request for a third party to send the requester a secret.
+// A maximum size of zero defaults to the configured maximum file size.
+// Returns the request metadata and the owner token, which is required to find and access the submitted secret.
+// Validation errors are returned as ValidationError types.
+// The context can be used to cancel the operation before completion.
+func CreateSecretRequest(ctx context.Context, appConfig settings.IAppConfiguration, dataStore datastore.DataStore, request models.SecretRequest) (*models.SecretRequestMetadata, string, error) {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return nil, "", err
+	}
+
+	switch request.ContentType {
+	case "", models.ContentTypeText, models.ContentTypeFile:
+	default:
+		return nil, "", pkgerrors.NewValidationError("content_type must be text or file")
+	}
+
+	maxSizeBytes := int64(appConfig.MaxFileSizeMB()) * 1024 * 1024
+	if request.MaxSizeBytes < 0 {
+		return nil, "", pkgerrors.NewValidationError("max_size_bytes cannot be negative")
+	}
+	if request.MaxSizeBytes == 0 {
+		request.MaxSizeBytes = maxSizeBytes
+	}
+	if request.MaxSizeBytes > maxSizeBytes {
+		return nil, "", pkgerrors.NewValidationError(fmt.Sprintf("max_size_bytes cannot exceed %d", maxSizeBytes))
+	}
+
+	if request.AccessLimit < 0 {
+		request.AccessLimit = 0
+	}
+	if err := validateAccessLimit(appConfig, request.AccessLimit); err != nil {
+		return nil, "", err
+	}
+
+	if err := validateExpiration(appConfig, request.Duration()); err != nil {
+		return nil, "", err
+	}
+
+	id, err := randomId()
+	if err != nil {
+		return nil, "", err
+	}
+
+	ownerToken, ownerTokenHash, err := newOwnerToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	request.ID = id
+	request.OwnerTokenHash = ownerTokenHash
+	request.SecretID = ""
+
+	logger := getRequestLogger(dataStore, id).WithFields(log.Fields{
+		"requestContentType":  request.ContentType,
+		"requestMaxSizeBytes": request.MaxSizeBytes,
+		"requestExpiration":   request.Expiration().Format(),
+		"requestCreator":      request.Creator,
+	})
+	logger.Info("Writing new secret request to datastore")
+	if err = dataStore.WriteSecretRequest(ctx, request); err != nil {
+		logger.WithError(err).Error("Error writing new secret request to datastore")
+		return nil, "", err
+	}
+
+	return request.Metadata(), ownerToken, nil
+}
+
+// GetSecretRequestMetadata retrieves metadata for a secret request.
+// The ID of the submitted secret is only included when the matching owner token is given.
+// Returns the metadata or nil if the request is not found.
+// The context can be used to cancel the operation before completion.
+func GetSecretRequestMetadata(ctx context.Context, dataStore datastore.DataStore, id string, ownerToken string) *models.SecretRequestMetadata {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return nil
+	}
+
+	getRequestLogger(dataStore, id).Info("Querying for secret request metadata")
+
+	request := dataStore.ReadSecretRequest(ctx, id)
+	if request == nil {
+		return nil
+	}
+
+	metadata := request.Metadata()
+	if ownerTokenMatches(request.OwnerTokenHash, ownerToken) {
+		metadata.SecretID = request.SecretID
+	}
+
+	return metadata
+}
+
+// SubmitSecretRequest fulfills a secret request with the given content.
+// The submitted secret takes its access limit and expiration from the request, is recorded as created by the
+// requester and can only be accessed with the requester's owner token. Each request accepts a single submission; later submissions
+// return a ConflictError. Content that does not match the request constraints returns a ValidationError
+// or FileTooLargeError.
+// The content is padded and encrypted the same way as the content of a new secret.
+// Returns the updated request metadata or nil if the request is not found.
+// The context can be used to cancel the operation before completion.
+func SubmitSecretRequest(ctx context.Context, appConfig settings.IAppConfiguration, dataStore datastore.DataStore, encryption cryptography.Encryption, id string, secret models.Secret) (*models.SecretRequestMetadata, error) {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return nil, err
+	}
+
+	request := dataStore.ReadSecretRequest(ctx, id)
+	if request == nil {
+		return nil, nil
+	}
+
+	logger := getRequestLogger(dataStore, id)
+
+	if request.Fulfilled() {
+		logger.Warn("Rejected submission to fulfilled secret request")
+		return nil, pkgerrors.NewConflictError("secret request has already been fulfilled")
+	}
+
+	if request.ContentType != "" && request.ContentType != secret.ContentType {
+		return nil, pkgerrors.NewValidationError(fmt.Sprintf("secret request only accepts %s content", request.ContentType))
+	}
+
+	if int64(len(secret.Content)) > request.MaxSizeBytes {
+		return nil, pkgerrors.NewFileTooLargeError(fmt.Sprintf("content size %d bytes exceeds the requested maximum of %d bytes", len(secret.Content), request.MaxSizeBytes))
+	}
+
+	secretId, err := randomId()
+	if err != nil {
+		return nil, err
+	}
+
+	secret.ID = secretId
+	secret.AccessCount = 0
+	secret.AccessLimit = request.AccessLimit
+	secret.ExpirationEpoch = request.ExpirationEpoch
+	secret.OwnerTokenHash = request.OwnerTokenHash
+	secret.OwnerOnly = true
+	secret.Creator = request.Creator
+	secret.NotifyEmail = ""
+	secret.SplitKey = false
+
+	logger = logger.WithField("secretId", dataStore.KeyID(secretId))
+	logger.Info("Encrypting secret request submission")
+
+	if _, err = sealSecret(ctx, appConfig, encryption, &secret); err != nil {
+		logger.WithError(err).Error("Error encrypting secret request submission")
+		return nil, err
+	}
+
+	if err = dataStore.WriteSecret(ctx, secret); err != nil {
+		logger.WithError(err).Error("Error writing secret request submission to datastore")
+		return nil, err
+	}
+
+	// The secret is written before the request is claimed so a failed write never consumes the request.
+	fulfilled, err := dataStore.FulfillSecretRequest(ctx, *request, secretId)
+	if err != nil || !fulfilled {
+		if _, deleteErr := dataStore.DeleteSecret(ctx, secretId); deleteErr != nil {
+			logger.WithError(deleteErr).Error("Error deleting unclaimed secret request submission")
+		}
+		if err != nil {
+			logger.WithError(err).Error("Error fulfilling secret request")
+			return nil, err
+		}
+		logger.Warn("Rejected concurrent submission to fulfilled secret request")
+		return nil, pkgerrors.NewConflictError("secret request has already been fulfilled")
+	}
+
+	logger.Info("Fulfilled secret request")
+	request.SecretID = secretId
+	return request.Metadata(), nil
+}
@@ -0,0 +1,384 @@
+package commands_test
+
+import (
+	"cellar/pkg/commands"
+	pkgerrors "cellar/pkg/errors"
+	"cellar/pkg/mocks"
+	"cellar/pkg/models"
+	"cellar/pkg/plaintext"
+	"cellar/testing/testhelpers"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateSecretRequest(t *testing.T) {
+	maxFileSizeMB := 8
+	maxSizeBytes := int64(maxFileSizeMB) * 1024 * 1024
+
+	sut := func(request models.SecretRequest, writeCallTimes int) (*models.SecretRequestMetadata, string, *models.SecretRequest, error) {
+		ctrl := gomock.NewController(t)
+
+		appConfig := mocks.NewMockIAppConfiguration(ctrl)
+		appConfig.EXPECT().MaxFileSizeMB().Return(maxFileSizeMB).AnyTimes()
+		appConfig.EXPECT().MaxAccessCount().Return(100).AnyTimes()
+		appConfig.EXPECT().MaxExpirationSeconds().Return(604800).AnyTimes()
+		appConfig.EXPECT().AllowedCIDRs().Return(nil).AnyTimes()
+		appConfig.EXPECT().PaddingBuckets().Return(nil).AnyTimes()
+
+		var written models.SecretRequest
+		dataStore := newMockDataStore(ctrl)
+		dataStore.EXPECT().
+			WriteSecretRequest(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, request models.SecretRequest) error {
+				written = request
+				return nil
+			}).
+			Times(writeCallTimes)
+
+		metadata, ownerToken, err := commands.CreateSecretRequest(context.Background(), appConfig, dataStore, request)
+		return metadata, ownerToken, &written, err
+	}
+
+	t.Run("when all parameters are valid", func(t *testing.T) {
+		metadata, ownerToken, written, err := sut(models.SecretRequest{
+			ContentType:     models.ContentTypeFile,
+			MaxSizeBytes:    1024,
+			AccessLimit:     1,
+			ExpirationEpoch: testhelpers.EpochFromNow(time.Hour),
+		}, 1)
+		require.NoError(t, err)
+
+		t.Run("it should return ID of length 64", func(t *testing.T) {
+			assert.Equal(t, 64, len(metadata.ID))
+		})
+
+		t.Run("it should return an owner token", func(t *testing.T) {
+			assert.Equal(t, 64, len(ownerToken))
+		})
+
+		t.Run("it should store the owner token hash instead of the owner token", func(t *testing.T) {
+			hash := sha256.Sum256([]byte(ownerToken))
+			assert.Equal(t, hex.EncodeToString(hash[:]), written.OwnerTokenHash)
+		})
+
+		t.Run("it should return expected constraints", func(t *testing.T) {
+			assert.Equal(t, models.ContentType(models.ContentTypeFile), metadata.ContentType)
+			assert.Equal(t, int64(1024), metadata.MaxSizeBytes)
+			assert.Equal(t, 1, metadata.AccessLimit)
+		})
+
+		t.Run("it should not be fulfilled", func(t *testing.T) {
+			assert.False(t, metadata.Fulfilled)
+		})
+	})
+
+	t.Run("when max size is not set", func(t *testing.T) {
+		metadata, _, _, err := sut(models.SecretRequest{
+			ExpirationEpoch: testhelpers.EpochFromNow(time.Hour),
+		}, 1)
+		require.NoError(t, err)
+
+		t.Run("it should default to the maximum file size", func(t *testing.T) {
+			assert.Equal(t, maxSizeBytes, metadata.MaxSizeBytes)
+		})
+	})
+
+	t.Run("when max size exceeds the maximum file size", func(t *testing.T) {
+		_, _, _, err := sut(models.SecretRequest{
+			MaxSizeBytes:    maxSizeBytes + 1,
+			ExpirationEpoch: testhelpers.EpochFromNow(time.Hour),
+		}, 0)
+
+		t.Run("it should return validation error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsValidationError(err), "expected validation error")
+		})
+	})
+
+	t.Run("when content type is unknown", func(t *testing.T) {
+		_, _, _, err := sut(models.SecretRequest{
+			ContentType:     "image",
+			ExpirationEpoch: testhelpers.EpochFromNow(time.Hour),
+		}, 0)
+
+		t.Run("it should return validation error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsValidationError(err), "expected validation error")
+		})
+	})
+
+	t.Run("when expiration is too short", func(t *testing.T) {
+		_, _, _, err := sut(models.SecretRequest{
+			ExpirationEpoch: testhelpers.EpochFromNow(time.Minute),
+		}, 0)
+
+		t.Run("it should return validation error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsValidationError(err), "expected validation error")
+		})
+	})
+
+	t.Run("when context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		ctrl := gomock.NewController(t)
+		_, _, err := commands.CreateSecretRequest(ctx, mocks.NewMockIAppConfiguration(ctrl), newMockDataStore(ctrl), models.SecretRequest{})
+
+		t.Run("it should return context error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsContextError(err), "expected context error")
+		})
+	})
+}
+
+func TestGetSecretRequestMetadata(t *testing.T) {
+	ownerToken := testhelpers.RandomId(t)
+	ownerTokenHash := sha256.Sum256([]byte(ownerToken))
+
+	request := models.SecretRequest{
+		ID:              testhelpers.RandomId(t),
+		OwnerTokenHash:  hex.EncodeToString(ownerTokenHash[:]),
+		MaxSizeBytes:    1024,
+		ExpirationEpoch: testhelpers.EpochFromNow(time.Hour),
+		SecretID:        testhelpers.RandomId(t),
+	}
+
+	sut := func(token string) *models.SecretRequestMetadata {
+		ctrl := gomock.NewController(t)
+		dataStore := newMockDataStore(ctrl)
+		dataStore.EXPECT().
+			ReadSecretRequest(gomock.Any(), request.ID).
+			Return(&request)
+
+		return commands.GetSecretRequestMetadata(context.Background(), dataStore, request.ID, token)
+	}
+
+	t.Run("when owner token matches", func(t *testing.T) {
+		metadata := sut(ownerToken)
+
+		t.Run("it should return the submitted secret ID", func(t *testing.T) {
+			assert.Equal(t, request.SecretID, metadata.SecretID)
+		})
+
+		t.Run("it should be fulfilled", func(t *testing.T) {
+			assert.True(t, metadata.Fulfilled)
+		})
+	})
+
+	t.Run("when owner token is missing", func(t *testing.T) {
+		metadata := sut("")
+
+		t.Run("it should not return the submitted secret ID", func(t *testing.T) {
+			assert.Empty(t, metadata.SecretID)
+		})
+
+		t.Run("it should be fulfilled", func(t *testing.T) {
+			assert.True(t, metadata.Fulfilled)
+		})
+	})
+}
+
+func TestSubmitSecretRequest(t *testing.T) {
+	ownerTokenHash := testhelpers.RandomId(t)
+	cipherText := testhelpers.RandomId(t)
+
+	newRequest := func() models.SecretRequest {
+		return models.SecretRequest{
+			ID:              testhelpers.RandomId(t),
+			OwnerTokenHash:  ownerTokenHash,
+			ContentType:     models.ContentTypeText,
+			MaxSizeBytes:    32,
+			AccessLimit:     1,
+			ExpirationEpoch: testhelpers.EpochFromNow(time.Hour),
+			Creator:         "alice",
+		}
+	}
+
+	newSubmission := func(content string) models.Secret {
+		return models.Secret{
+			Content:     []byte(content),
+			ContentType: models.ContentTypeText,
+		}
+	}
+
+	newAppConfig := func(ctrl *gomock.Controller, paddingBuckets []int) *mocks.MockIAppConfiguration {
+		appConfig := mocks.NewMockIAppConfiguration(ctrl)
+		appConfig.EXPECT().PaddingBuckets().Return(paddingBuckets).AnyTimes()
+		return appConfig
+	}
+
+	t.Run("when the request is open", func(t *testing.T) {
+		request := newRequest()
+
+		ctrl := gomock.NewController(t)
+		encryption := mocks.NewMockEncryption(ctrl)
+		encryption.EXPECT().Encrypt(gomock.Any(), gomock.Any()).Return(cipherText, nil)
+
+		var written models.Secret
+		dataStore := newMockDataStore(ctrl)
+		dataStore.EXPECT().ReadSecretRequest(gomock.Any(), request.ID).Return(&request)
+		dataStore.EXPECT().
+			WriteSecret(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, secret models.Secret) error {
+				written = secret
+				return nil
+			})
+		dataStore.EXPECT().FulfillSecretRequest(gomock.Any(), request, gomock.Any()).Return(true, nil)
+
+		metadata, err := commands.SubmitSecretRequest(context.Background(), newAppConfig(ctrl, nil), dataStore, encryption, request.ID, newSubmission("vendor credentials"))
+		require.NoError(t, err)
+
+		t.Run("it should return fulfilled metadata", func(t *testing.T) {
+			assert.True(t, metadata.Fulfilled)
+		})
+
+		t.Run("it should not return the secret ID", func(t *testing.T) {
+			assert.Empty(t, metadata.SecretID)
+		})
+
+		t.Run("it should write a secret owned by the requester", func(t *testing.T) {
+			assert.Equal(t, ownerTokenHash, written.OwnerTokenHash)
+		})
+
+		t.Run("it should write the encrypted content", func(t *testing.T) {
+			assert.Equal(t, cipherText, written.CipherText)
+		})
+
+		t.Run("it should take the access limit and expiration from the request", func(t *testing.T) {
+			assert.Equal(t, request.AccessLimit, written.AccessLimit)
+			assert.Equal(t, request.ExpirationEpoch, written.ExpirationEpoch)
+		})
+
+		t.Run("it should record the requester as the creator", func(t *testing.T) {
+			assert.Equal(t, request.Creator, written.Creator)
+		})
+
+		t.Run("it should record the creation time", func(t *testing.T) {
+			assert.InDelta(t, time.Now().Unix(), written.CreatedEpoch, 5)
+		})
+	})
+
+	t.Run("when padding is configured", func(t *testing.T) {
+		request := newRequest()
+
+		ctrl := gomock.NewController(t)
+		var padded, encrypted []byte
+		encryption := mocks.NewMockEncryption(ctrl)
+		encryption.EXPECT().
+			Encrypt(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, content []byte) (string, error) {
+				padded = content
+				encrypted = append([]byte{}, content...)
+				return cipherText, nil
+			})
+
+		var written models.Secret
+		dataStore := newMockDataStore(ctrl)
+		dataStore.EXPECT().ReadSecretRequest(gomock.Any(), request.ID).Return(&request)
+		dataStore.EXPECT().
+			WriteSecret(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, secret models.Secret) error {
+				written = secret
+				return nil
+			})
+		dataStore.EXPECT().FulfillSecretRequest(gomock.Any(), request, gomock.Any()).Return(true, nil)
+
+		_, err := commands.SubmitSecretRequest(context.Background(), newAppConfig(ctrl, []int{256, 1024}), dataStore, encryption, request.ID, newSubmission("vendor credentials"))
+		require.NoError(t, err)
+
+		t.Run("it should encrypt the content padded to the smallest bucket", func(t *testing.T) {
+			assert.Len(t, encrypted, 256)
+			assert.True(t, written.Padded)
+		})
+
+		t.Run("it should wipe the padded content once it is encrypted", func(t *testing.T) {
+			assert.True(t, plaintext.Wiped(padded))
+		})
+	})
+
+	t.Run("when the request is already fulfilled", func(t *testing.T) {
+		request := newRequest()
+		request.SecretID = testhelpers.RandomId(t)
+
+		ctrl := gomock.NewController(t)
+		dataStore := newMockDataStore(ctrl)
+		dataStore.EXPECT().ReadSecretRequest(gomock.Any(), request.ID).Return(&request)
+
+		_, err := commands.SubmitSecretRequest(context.Background(), newAppConfig(ctrl, nil), dataStore, mocks.NewMockEncryption(ctrl), request.ID, newSubmission("vendor credentials"))
+
+		t.Run("it should return conflict error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsConflictError(err), "expected conflict error")
+		})
+	})
+
+	t.Run("when another submission fulfills the request first", func(t *testing.T) {
+		request := newRequest()
+
+		ctrl := gomock.NewController(t)
+		encryption := mocks.NewMockEncryption(ctrl)
+		encryption.EXPECT().Encrypt(gomock.Any(), gomock.Any()).Return(cipherText, nil)
+
+		dataStore := newMockDataStore(ctrl)
+		dataStore.EXPECT().ReadSecretRequest(gomock.Any(), request.ID).Return(&request)
+		dataStore.EXPECT().WriteSecret(gomock.Any(), gomock.Any()).Return(nil)
+		dataStore.EXPECT().FulfillSecretRequest(gomock.Any(), request, gomock.Any()).Return(false, nil)
+		// the unclaimed secret should be deleted
+		dataStore.EXPECT().DeleteSecret(gomock.Any(), gomock.Any()).Return(true, nil).Times(1)
+
+		_, err := commands.SubmitSecretRequest(context.Background(), newAppConfig(ctrl, nil), dataStore, encryption, request.ID, newSubmission("vendor credentials"))
+
+		t.Run("it should return conflict error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsConflictError(err), "expected conflict error")
+		})
+	})
+
+	t.Run("when content type does not match", func(t *testing.T) {
+		request := newRequest()
+
+		ctrl := gomock.NewController(t)
+		dataStore := newMockDataStore(ctrl)
+		dataStore.EXPECT().ReadSecretRequest(gomock.Any(), request.ID).Return(&request)
+
+		submission := newSubmission("vendor credentials")
+		submission.ContentType = models.ContentTypeFile
+		_, err := commands.SubmitSecretRequest(context.Background(), newAppConfig(ctrl, nil), dataStore, mocks.NewMockEncryption(ctrl), request.ID, submission)
+
+		t.Run("it should return validation error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsValidationError(err), "expected validation error")
+		})
+	})
+
+	t.Run("when content exceeds the requested maximum size", func(t *testing.T) {
+		request := newRequest()
+
+		ctrl := gomock.NewController(t)
+		dataStore := newMockDataStore(ctrl)
+		dataStore.EXPECT().ReadSecretRequest(gomock.Any(), request.ID).Return(&request)
+
+		_, err := commands.SubmitSecretRequest(context.Background(), newAppConfig(ctrl, nil), dataStore, mocks.NewMockEncryption(ctrl), request.ID, newSubmission(testhelpers.RandomId(t)))
+
+		t.Run("it should return file too large error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsFileTooLargeError(err), "expected file too large error")
+		})
+	})
+
+	t.Run("when the request does not exist", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		dataStore := newMockDataStore(ctrl)
+		dataStore.EXPECT().ReadSecretRequest(gomock.Any(), gomock.Any()).Return(nil)
+
+		metadata, err := commands.SubmitSecretRequest(context.Background(), newAppConfig(ctrl, nil), dataStore, mocks.NewMockEncryption(ctrl), testhelpers.RandomId(t), newSubmission("vendor credentials"))
+
+		t.Run("it should not return error", func(t *testing.T) {
+			assert.NoError(t, err)
+		})
+
+		t.Run("it should return nil", func(t *testing.T) {
+			assert.Nil(t, metadata)
+		})
+	})
+}
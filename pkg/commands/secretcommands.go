@@ -1,11 +1,19 @@
 package commands
 
 import (
+	"cellar/pkg/allowlist"
+	"cellar/pkg/bundles"
 	"cellar/pkg/cryptography"
 	"cellar/pkg/datastore"
 	pkgerrors "cellar/pkg/errors"
+	"cellar/pkg/generators"
+	"cellar/pkg/geoip"
 	"cellar/pkg/models"
+	"cellar/pkg/notifications"
+	"cellar/pkg/padding"
+	"cellar/pkg/plaintext"
 	"cellar/pkg/settings"
+	"cellar/pkg/splitkey"
 	"context"
 	"crypto/rand"
 	"encoding/hex"
@@ -15,15 +23,61 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-func getLogger(secretId string) *log.Entry {
+// maxAllowedCIDRs caps the ranges in the allowlist of a single secret.
+const maxAllowedCIDRs = 32
+
+// getLogger logs the key ID of the secret, as the secret ID would give anyone reading the logs access to it.
+func getLogger(dataStore datastore.DataStore, secretId string) *log.Entry {
 	return log.WithFields(log.Fields{
 		"context":  "secret commands",
-		"secretId": secretId,
+		"secretId": dataStore.KeyID(secretId),
 	})
 }
 
+// sealSecret encrypts the content of a secret into its cipher text and stamps the secret with its creation time.
+// The content is first sealed with a new split key if the secret asks for one, then padded to the configured buckets.
+// Returns the split key, or an empty string.
+// The content sealed and padded here is wiped once it is encrypted. The caller wipes the content it passed in.
+func sealSecret(ctx context.Context, appConfig settings.IAppConfiguration, encryption cryptography.Encryption, secret *models.Secret) (string, error) {
+	var sealed [][]byte
+	defer func() {
+		for _, content := range sealed {
+			plaintext.Wipe(content)
+		}
+	}()
+
+	key := ""
+	if secret.SplitKey {
+		var err error
+		if key, err = splitkey.NewKey(); err != nil {
+			return "", err
+		}
+		if secret.Content, err = splitkey.Seal(key, secret.Content); err != nil {
+			return "", fmt.Errorf("error sealing secret content with its split key: %w", err)
+		}
+		sealed = append(sealed, secret.Content)
+		secret.SplitKeyCheck = splitkey.Check(key)
+	}
+
+	if buckets := appConfig.PaddingBuckets(); len(buckets) > 0 {
+		secret.Content = padding.Pad(secret.Content, buckets)
+		sealed = append(sealed, secret.Content)
+		secret.Padded = true
+	}
+
+	cipherText, err := encryption.Encrypt(ctx, secret.Content)
+	if err != nil {
+		return "", err
+	}
+	secret.CipherText = cipherText
+	secret.CreatedEpoch = time.Now().Unix()
+	return key, nil
+}
+
 // CreateSecret encrypts and stores a new secret with the given parameters.
-// Returns the secret metadata and any error encountered.
+// Split-key secrets are first encrypted with a new per-secret key, which is returned in the metadata and never stored.
+// Secrets without their own allowlist take the configured default allowlist.
+// Returns the secret metadata, including the owner token needed to manage the secret, and any error encountered.
 // Validation errors are returned as ValidationError types.
 // The context can be used to cancel the operation before completion.
 func CreateSecret(ctx context.Context, appConfig settings.IAppConfiguration, dataStore datastore.DataStore, encryption cryptography.Encryption, secret models.Secret) (*models.SecretMetadata, error) {
@@ -36,10 +90,11 @@ func CreateSecret(ctx context.Context, appConfig settings.IAppConfiguration, dat
 		return nil, err
 	}
 
-	logger := getLogger(id)
+	logger := getLogger(dataStore, id)
+
 	logger.Info("Encrypting new secret content")
 
-	secret.CipherText, err = encryption.Encrypt(ctx, secret.Content)
+	key, err := sealSecret(ctx, appConfig, encryption, &secret)
 	if err != nil {
 		logger.WithError(err).
 			Error("Error encrypting new secret content")
@@ -52,25 +107,59 @@ func CreateSecret(ctx context.Context, appConfig settings.IAppConfiguration, dat
 		secret.AccessLimit = 0
 	}
 
-	// Validate access limit against configured maximum
-	if secret.AccessLimit > 0 && secret.AccessLimit > appConfig.MaxAccessCount() {
-		return nil, pkgerrors.NewValidationError(fmt.Sprintf("access_limit cannot exceed %d", appConfig.MaxAccessCount()))
+	if err = validateAccessLimit(appConfig, secret.AccessLimit); err != nil {
+		return nil, err
 	}
 
-	// Validate expiration duration
-	if secret.Duration() < time.Minute*10 {
-		return nil, pkgerrors.NewValidationError("expiration must be at least 10 minutes in the future")
+	if err = validateExpiration(appConfig, secret.Duration()); err != nil {
+		return nil, err
 	}
 
-	// Validate expiration does not exceed configured maximum
-	maxExpirationDuration := time.Second * time.Duration(appConfig.MaxExpirationSeconds())
-	if secret.Duration() > maxExpirationDuration {
-		return nil, pkgerrors.NewValidationError(fmt.Sprintf("expiration cannot exceed %d seconds", appConfig.MaxExpirationSeconds()))
+	if secret.AvailableFromEpoch < 0 {
+		return nil, pkgerrors.NewValidationError("available_from_epoch cannot be negative")
+	}
+	if secret.AvailableFromEpoch > 0 {
+		if secret.AvailableFromEpoch >= secret.ExpirationEpoch {
+			return nil, pkgerrors.NewValidationError("available_from_epoch must be before expiration_epoch")
+		}
+		if time.Until(time.Unix(secret.AvailableFromEpoch, 0)) > time.Second*time.Duration(appConfig.MaxExpirationSeconds()) {
+			return nil, pkgerrors.NewValidationError(fmt.Sprintf("available_from_epoch cannot be more than %d seconds in the future", appConfig.MaxExpirationSeconds()))
+		}
+	}
+
+	if secret.ViewWindowSeconds < 0 {
+		return nil, pkgerrors.NewValidationError("view_window_seconds cannot be negative")
+	}
+	if secret.ViewWindowSeconds > appConfig.MaxExpirationSeconds() {
+		return nil, pkgerrors.NewValidationError(fmt.Sprintf("view_window_seconds cannot exceed %d", appConfig.MaxExpirationSeconds()))
 	}
 
+	if secret.AllowedCIDRs, err = allowedCIDRs(appConfig, secret.AllowedCIDRs); err != nil {
+		return nil, err
+	}
+
+	if secret.AllowedCountries, err = geoip.ParseCountries(secret.AllowedCountries); err != nil {
+		return nil, pkgerrors.NewValidationError("optional parameter: allowed_countries: " + err.Error())
+	}
+	if secret.DeniedCountries, err = geoip.ParseCountries(secret.DeniedCountries); err != nil {
+		return nil, pkgerrors.NewValidationError("optional parameter: denied_countries: " + err.Error())
+	}
+
+	ownerToken, ownerTokenHash, err := newOwnerToken()
+	if err != nil {
+		return nil, err
+	}
+	secret.OwnerTokenHash = ownerTokenHash
+
 	logger = logger.WithFields(log.Fields{
-		"secretAccessLimit": secret.AccessLimit,
-		"secretExpiration":  secret.Expiration().Format(),
+		"secretAccessLimit":       secret.AccessLimit,
+		"secretExpiration":        secret.Expiration().Format(),
+		"secretViewWindowSeconds": secret.ViewWindowSeconds,
+		"secretAvailableFrom":     secret.AvailableFromEpoch,
+		"secretAllowedCIDRs":      secret.AllowedCIDRs,
+		"secretAllowedCountries":  secret.AllowedCountries,
+		"secretDeniedCountries":   secret.DeniedCountries,
+		"secretCreator":           secret.Creator,
 	})
 	logger.Info("Writing new secret to datastore")
 	err = dataStore.WriteSecret(ctx, secret)
@@ -79,29 +168,151 @@ func CreateSecret(ctx context.Context, appConfig settings.IAppConfiguration, dat
 		return nil, err
 	}
 
-	return secret.Metadata(), nil
+	metadata := secret.Metadata()
+	metadata.OwnerToken = ownerToken
+	metadata.Key = key
+	return metadata, nil
+}
+
+// GenerateSecret creates a secret from material generated on the server, so it never passes through the client.
+// The remaining secret parameters are validated and stored as in CreateSecret.
+// Returns the secret metadata, which never includes the generated content.
+// Validation errors are returned as ValidationError types.
+// The context can be used to cancel the operation before completion.
+func GenerateSecret(ctx context.Context, appConfig settings.IAppConfiguration, dataStore datastore.DataStore, encryption cryptography.Encryption, secret models.Secret, options models.GenerateSecretOptions) (*models.SecretMetadata, error) {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := generators.Generate(options, &secret); err != nil {
+		return nil, err
+	}
+
+	log.WithFields(log.Fields{
+		"context":             "secret commands",
+		"generatedSecretKind": options.Kind,
+	}).Info("Generated new secret content")
+	defer plaintext.Wipe(secret.Content)
+
+	return CreateSecret(ctx, appConfig, dataStore, encryption, secret)
 }
 
 // AccessSecret retrieves and decrypts a secret by ID, incrementing its access count.
 // If the access limit is reached, the secret is automatically deleted.
-// Returns the decrypted secret or nil if not found.
+// Secrets accessed before their activation time return a NotYetAvailableError without consuming an access.
+// If the secret has a view window, the first access shortens its expiration to the end of the window.
+// If the sender asked to be notified, an access notification is sent once the content is decrypted.
+// Owner-only secrets can only be accessed with the matching owner token; other callers get a ForbiddenError.
+// Split-key secrets can only be accessed with their key; a missing or wrong key returns a ForbiddenError
+// without consuming an access.
+// Secrets with an allowlist or country restrictions can only be accessed by clients that pass them;
+// other clients get a ForbiddenError without consuming an access.
+// The country of the client is recorded in the history of the secret.
+// Secrets that require recipient verification can only be accessed with the code last sent to the recipient;
+// each code works once, and a missing or wrong code returns a ForbiddenError without consuming an access.
+// Returns the decrypted secret or nil if not found. The caller wipes its content once done with it.
+// The context can be used to cancel the operation before completion.
+func AccessSecret(ctx context.Context, dataStore datastore.DataStore, encryption cryptography.Encryption, notifier notifications.Notifier, request models.AccessRequest) (*models.Secret, error) {
+	return accessSecret(ctx, dataStore, encryption, notifier, request, nil)
+}
+
+// AccessSecretItem retrieves and decrypts a single item of a bundle by its position in the bundle.
+// Each item access counts against the access limit of the bundle as a whole; once the limit is reached
+// the whole bundle is deleted. Secrets that are not bundles, or positions outside the bundle,
+// return a ValidationError without consuming an access.
+// Returns the item as a file secret or nil if the secret is not found.
 // The context can be used to cancel the operation before completion.
-func AccessSecret(ctx context.Context, dataStore datastore.DataStore, encryption cryptography.Encryption, id string) (*models.Secret, error) {
+func AccessSecretItem(ctx context.Context, dataStore datastore.DataStore, encryption cryptography.Encryption, notifier notifications.Notifier, request models.AccessRequest, index int) (*models.Secret, error) {
+	id := request.ID
+	secret, err := accessSecret(ctx, dataStore, encryption, notifier, request, func(secret *models.Secret) error {
+		if secret.ContentType != models.ContentTypeBundle {
+			return pkgerrors.NewValidationError("secret is not a bundle")
+		}
+		if index < 0 || index >= len(secret.BundleItems) {
+			return pkgerrors.NewValidationError(fmt.Sprintf("bundle item %d does not exist", index))
+		}
+		return nil
+	})
+	if err != nil || secret == nil {
+		return nil, err
+	}
+
+	defer plaintext.Wipe(secret.Content)
+
+	content, err := bundles.ReadItem(secret.Content, index)
+	if err != nil {
+		getLogger(dataStore, id).WithError(err).Error("Error reading bundle item")
+		return nil, err
+	}
+
+	return &models.Secret{
+		ID:          id,
+		Content:     content,
+		ContentType: models.ContentTypeFile,
+		Filename:    secret.BundleItems[index].Name,
+	}, nil
+}
+
+// accessSecret implements AccessSecret. The optional check runs before an access is consumed.
+func accessSecret(ctx context.Context, dataStore datastore.DataStore, encryption cryptography.Encryption, notifier notifications.Notifier, request models.AccessRequest, check func(secret *models.Secret) error) (*models.Secret, error) {
 	if err := pkgerrors.CheckContext(ctx); err != nil {
 		return nil, err
 	}
 
+	id := request.ID
+
 	secret := dataStore.ReadSecret(ctx, id)
 	if secret == nil {
 		return nil, nil
 	}
 
+	if !allowlist.Allows(secret.AllowedCIDRs, request.Client.IP) {
+		getLogger(dataStore, id).WithField("clientIP", request.Client.IP).
+			Warn("Rejected access to secret from a client outside its allowlist")
+		return nil, pkgerrors.NewForbiddenError("this secret cannot be accessed from this address")
+	}
+
+	if !geoip.Allows(secret.AllowedCountries, secret.DeniedCountries, request.Client.Country) {
+		getLogger(dataStore, id).WithField("clientCountry", request.Client.Country).
+			Warn("Rejected access to secret from a country it is restricted from")
+		return nil, pkgerrors.NewForbiddenError("this secret cannot be accessed from this country")
+	}
+
+	if secret.OwnerOnly && !ownerTokenMatches(secret.OwnerTokenHash, request.OwnerToken) {
+		getLogger(dataStore, id).Warn("Rejected access to owned secret without a matching owner token")
+		return nil, pkgerrors.NewInvalidCredentialError("a valid owner token is required to access this secret")
+	}
+
+	if !secret.IsAvailable(time.Now()) {
+		availableFrom := secret.AvailableFrom()
+		getLogger(dataStore, id).WithField("secretAvailableFrom", availableFrom.Format()).
+			Info("Rejected access to secret before it is available")
+		return nil, pkgerrors.NewNotYetAvailableError(fmt.Sprintf("secret is not available until %s", availableFrom.Format()), availableFrom.Time())
+	}
+
+	if secret.SplitKey && !splitkey.Matches(secret.SplitKeyCheck, request.Key) {
+		getLogger(dataStore, id).Warn("Rejected access to split-key secret without a matching key")
+		return nil, pkgerrors.NewInvalidCredentialError("a valid key is required to access this secret")
+	}
+
+	if check != nil {
+		if err := check(secret); err != nil {
+			return nil, err
+		}
+	}
+
+	if secret.RecipientEmail != "" {
+		if err := verifyRecipient(ctx, dataStore, id, request.VerificationCode); err != nil {
+			return nil, err
+		}
+	}
+
 	accessCount, err := dataStore.IncreaseAccessCount(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	logger := getLogger(id).
+	logger := getLogger(dataStore, id).
 		WithFields(log.Fields{
 			"secretAccessCount": accessCount,
 			"secretAccessLimit": secret.AccessLimit,
@@ -116,18 +327,61 @@ func AccessSecret(ctx context.Context, dataStore datastore.DataStore, encryption
 				Error("Error while deleting secret")
 			return nil, err
 		}
+	} else {
+		if secret.ViewWindowSeconds > 0 && accessCount == 1 {
+			expirationEpoch := secret.ViewWindowExpirationEpoch(time.Now())
+			logger.WithField("secretViewWindowEnds", models.FormattedTime(time.Unix(expirationEpoch, 0).UTC()).Format()).
+				Info("Starting secret view window")
+			if err = dataStore.StartViewWindow(ctx, id, expirationEpoch); err != nil {
+				logger.WithError(err).
+					Error("Error while starting secret view window")
+				return nil, err
+			}
+		}
+
+		recordSecretHistory(ctx, dataStore, id, models.SecretHistoryEvent{
+			Type:        models.SecretHistoryAccessed,
+			OccurredAt:  time.Now().UTC(),
+			AccessCount: int(accessCount),
+			Country:     request.Client.Country,
+		})
 	}
 
-	content, err := encryption.Decrypt(ctx, secret.CipherText)
+	decrypted, err := encryption.Decrypt(ctx, secret.CipherText)
 	if err != nil {
 		return nil, err
 	}
+	defer plaintext.Wipe(decrypted)
+
+	content := decrypted
+	if secret.Padded {
+		if content, err = padding.Unpad(content); err != nil {
+			logger.WithError(err).
+				Error("Error removing padding from secret content")
+			return nil, err
+		}
+	}
+
+	if secret.SplitKey {
+		if content, err = splitkey.Open(request.Key, content); err != nil {
+			logger.WithError(err).
+				Error("Error opening secret content with its split key")
+			return nil, err
+		}
+	} else {
+		content = plaintext.Clone(content)
+	}
+
+	if secret.NotifyEmail != "" {
+		notifySecretAccessed(ctx, dataStore, notifier, secret, accessCount)
+	}
 
 	return &models.Secret{
 		ID:          id,
 		Content:     content,
 		ContentType: secret.ContentType,
 		Filename:    secret.Filename,
+		BundleItems: secret.BundleItems,
 	}, nil
 }
 
@@ -139,7 +393,7 @@ func GetSecretMetadata(ctx context.Context, dataStore datastore.DataStore, id st
 		return nil
 	}
 
-	logger := getLogger(id)
+	logger := getLogger(dataStore, id)
 	logger.Info("Querying for secret metadata")
 
 	secret := dataStore.ReadSecret(ctx, id)
@@ -158,10 +412,172 @@ func DeleteSecret(ctx context.Context, dataStore datastore.DataStore, id string)
 		return false, err
 	}
 
-	getLogger(id).Info("Deleting secret if it exists")
+	getLogger(dataStore, id).Info("Deleting secret if it exists")
 	return dataStore.DeleteSecret(ctx, id)
 }
 
+// UpdateSecret changes the expiration and access limit of an existing secret within the configured limits.
+// Only the holder of the secret's owner token, or its creator, can update it; other callers get a ForbiddenError.
+// The change is recorded in the secret's history.
+// Returns the updated metadata or nil if the secret is not found.
+// The context can be used to cancel the operation before completion.
+func UpdateSecret(ctx context.Context, appConfig settings.IAppConfiguration, dataStore datastore.DataStore, id string, owner models.OwnerCredentials, update models.SecretUpdate) (*models.SecretMetadata, error) {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return nil, err
+	}
+
+	if update.ExpirationEpoch == nil && update.AccessLimit == nil {
+		return nil, pkgerrors.NewValidationError("required parameter: expiration_epoch or access_limit")
+	}
+
+	secret := dataStore.ReadSecret(ctx, id)
+	if secret == nil {
+		return nil, nil
+	}
+
+	logger := getLogger(dataStore, id)
+
+	if !isOwner(secret, owner) {
+		logger.Warn("Rejected update to secret without a matching owner token")
+		return nil, pkgerrors.NewInvalidCredentialError("a valid owner token is required to update this secret")
+	}
+
+	if update.ExpirationEpoch != nil {
+		secret.ExpirationEpoch = *update.ExpirationEpoch
+		if err := validateExpiration(appConfig, secret.Duration()); err != nil {
+			return nil, err
+		}
+		if secret.AvailableFromEpoch >= secret.ExpirationEpoch {
+			return nil, pkgerrors.NewValidationError("expiration_epoch must be after the secret is available")
+		}
+	}
+
+	if update.AccessLimit != nil {
+		secret.AccessLimit = *update.AccessLimit
+		if secret.AccessLimit < 0 {
+			secret.AccessLimit = 0
+		}
+		if err := validateAccessLimit(appConfig, secret.AccessLimit); err != nil {
+			return nil, err
+		}
+		if secret.AccessLimit > 0 && secret.AccessLimit <= secret.AccessCount {
+			return nil, pkgerrors.NewValidationError(fmt.Sprintf("access_limit must be greater than the current access count of %d", secret.AccessCount))
+		}
+	}
+
+	logger = logger.WithFields(log.Fields{
+		"secretAccessLimit": secret.AccessLimit,
+		"secretExpiration":  secret.Expiration().Format(),
+	})
+	logger.Info("Updating secret")
+
+	found, err := dataStore.UpdateSecret(ctx, id, secret.ExpirationEpoch, secret.AccessLimit)
+	if err != nil {
+		logger.WithError(err).Error("Error updating secret")
+		return nil, err
+	}
+	if !found {
+		return nil, nil
+	}
+
+	accessLimit := secret.AccessLimit
+	recordSecretHistory(ctx, dataStore, id, models.SecretHistoryEvent{
+		Type:            models.SecretHistoryModified,
+		OccurredAt:      time.Now().UTC(),
+		AccessLimit:     &accessLimit,
+		ExpirationEpoch: secret.ExpirationEpoch,
+	})
+
+	return secret.Metadata(), nil
+}
+
+// GetSecretHistory retrieves the accesses and modifications recorded for a secret.
+// Only the holder of the secret's owner token, or its creator, can read its history; other callers get a ForbiddenError.
+// Returns nil if the secret is not found.
+// The context can be used to cancel the operation before completion.
+func GetSecretHistory(ctx context.Context, dataStore datastore.DataStore, id string, owner models.OwnerCredentials) ([]models.SecretHistoryEvent, error) {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return nil, err
+	}
+
+	secret := dataStore.ReadSecret(ctx, id)
+	if secret == nil {
+		return nil, nil
+	}
+
+	logger := getLogger(dataStore, id)
+
+	if !isOwner(secret, owner) {
+		logger.Warn("Rejected secret history request without a matching owner token")
+		return nil, pkgerrors.NewInvalidCredentialError("a valid owner token is required to read the history of this secret")
+	}
+
+	logger.Info("Querying for secret history")
+	return dataStore.ReadSecretHistory(ctx, id)
+}
+
+// recordSecretHistory appends an event to the history of a secret.
+// Failures are logged rather than returned, so history never blocks the operation being recorded.
+func recordSecretHistory(ctx context.Context, dataStore datastore.DataStore, id string, event models.SecretHistoryEvent) {
+	if err := dataStore.AppendSecretHistory(ctx, id, event); err != nil {
+		getLogger(dataStore, id).WithError(err).
+			WithField("secretHistoryEvent", event.Type).
+			Error("Error recording secret history")
+	}
+}
+
+// validateAccessLimit checks an access limit against the configured maximum.
+func validateAccessLimit(appConfig settings.IAppConfiguration, accessLimit int) error {
+	if accessLimit > 0 && accessLimit > appConfig.MaxAccessCount() {
+		return pkgerrors.NewValidationError(fmt.Sprintf("access_limit cannot exceed %d", appConfig.MaxAccessCount()))
+	}
+	return nil
+}
+
+// validateExpiration checks that an expiration is at least 10 minutes in the future
+// and does not exceed the configured maximum.
+func validateExpiration(appConfig settings.IAppConfiguration, duration time.Duration) error {
+	if duration < time.Minute*10 {
+		return pkgerrors.NewValidationError("expiration must be at least 10 minutes in the future")
+	}
+
+	maxExpirationDuration := time.Second * time.Duration(appConfig.MaxExpirationSeconds())
+	if duration > maxExpirationDuration {
+		return pkgerrors.NewValidationError(fmt.Sprintf("expiration cannot exceed %d seconds", appConfig.MaxExpirationSeconds()))
+	}
+	return nil
+}
+
+// clientAllowed reports whether the client passes the allowlist and country restrictions of the secret.
+func clientAllowed(secret *models.Secret, client models.Client) bool {
+	return allowlist.Allows(secret.AllowedCIDRs, client.IP) &&
+		geoip.Allows(secret.AllowedCountries, secret.DeniedCountries, client.Country)
+}
+
+// allowedCIDRs validates and normalizes the allowlist of a new secret, falling back to the configured default.
+func allowedCIDRs(appConfig settings.IAppConfiguration, requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		cidrs, err := allowlist.Parse(appConfig.AllowedCIDRs())
+		if err != nil {
+			log.WithField("context", "secret commands").
+				WithError(err).
+				Error("Error parsing the configured default allowlist")
+			return nil, err
+		}
+		return cidrs, nil
+	}
+
+	if len(requested) > maxAllowedCIDRs {
+		return nil, pkgerrors.NewValidationError(fmt.Sprintf("allowed_cidrs cannot exceed %d ranges", maxAllowedCIDRs))
+	}
+
+	cidrs, err := allowlist.Parse(requested)
+	if err != nil {
+		return nil, pkgerrors.NewValidationError("optional parameter: allowed_cidrs: " + err.Error())
+	}
+	return cidrs, nil
+}
+
 func randomId() (string, error) {
 	bytes := make([]byte, 32)
 	_, err := rand.Read(bytes)
@@ -1,12 +1,17 @@
 package commands_test
 
 import (
+	"cellar/pkg/bundles"
 	"cellar/pkg/commands"
 	pkgerrors "cellar/pkg/errors"
+	"cellar/pkg/keyid"
 	"cellar/pkg/mocks"
 	"cellar/pkg/models"
+	"cellar/pkg/plaintext"
 	"cellar/testing/testhelpers"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"testing"
 	"time"
 
@@ -46,7 +51,7 @@ func TestCreateSecret(t *testing.T) {
 						encryptCall.Times(encryptCallTimes)
 					}
 
-					dataStore := mocks.NewMockDataStore(ctrl)
+					dataStore := newMockDataStore(ctrl)
 					writeSecretCall := dataStore.EXPECT().
 						WriteSecret(gomock.Any(), gomock.Any()).
 						Return(nil).
@@ -59,6 +64,8 @@ func TestCreateSecret(t *testing.T) {
 					appConfig := mocks.NewMockIAppConfiguration(ctrl)
 					appConfig.EXPECT().MaxAccessCount().Return(maxAccessCount).AnyTimes()
 					appConfig.EXPECT().MaxExpirationSeconds().Return(maxExpirationSeconds).AnyTimes()
+					appConfig.EXPECT().AllowedCIDRs().Return(nil).AnyTimes()
+					appConfig.EXPECT().PaddingBuckets().Return(nil).AnyTimes()
 
 					response, err := commands.CreateSecret(context.Background(), appConfig, dataStore, encryption, expectedSecret)
 					require.NoError(t, err)
@@ -76,6 +83,10 @@ func TestCreateSecret(t *testing.T) {
 						assert.Equal(t, 64, len(response.ID))
 					})
 
+					t.Run("owner token of length 64", func(t *testing.T) {
+						assert.Equal(t, 64, len(response.OwnerToken))
+					})
+
 					t.Run("access count of zero", func(t *testing.T) {
 						assert.Equal(t, 0, response.AccessCount)
 					})
@@ -100,7 +111,7 @@ func TestCreateSecret(t *testing.T) {
 
 						ctrl := gomock.NewController(t)
 						encryption := mocks.NewMockEncryption(ctrl)
-						dataStore := mocks.NewMockDataStore(ctrl)
+						dataStore := newMockDataStore(ctrl)
 						appConfig := mocks.NewMockIAppConfiguration(ctrl)
 
 						_, err := commands.CreateSecret(ctx, appConfig, dataStore, encryption, expectedSecret)
@@ -141,7 +152,7 @@ func TestCreateSecret(t *testing.T) {
 				Return(encryptedData, nil).
 				AnyTimes()
 
-			dataStore := mocks.NewMockDataStore(ctrl)
+			dataStore := newMockDataStore(ctrl)
 			writeSecretCall := dataStore.EXPECT().
 				WriteSecret(gomock.Any(), gomock.Any()).
 				Return(nil).
@@ -154,6 +165,8 @@ func TestCreateSecret(t *testing.T) {
 			appConfig := mocks.NewMockIAppConfiguration(ctrl)
 			appConfig.EXPECT().MaxAccessCount().Return(maxAccessCount).AnyTimes()
 			appConfig.EXPECT().MaxExpirationSeconds().Return(maxExpirationSeconds).AnyTimes()
+			appConfig.EXPECT().AllowedCIDRs().Return(nil).AnyTimes()
+			appConfig.EXPECT().PaddingBuckets().Return(nil).AnyTimes()
 
 			_, err := commands.CreateSecret(context.Background(), appConfig, dataStore, encryption, secretRequest)
 			return err
@@ -199,7 +212,7 @@ func TestCreateSecret(t *testing.T) {
 				Return(encryptedData, nil).
 				AnyTimes()
 
-			dataStore := mocks.NewMockDataStore(ctrl)
+			dataStore := newMockDataStore(ctrl)
 			writeSecretCall := dataStore.EXPECT().
 				WriteSecret(gomock.Any(), gomock.Any()).
 				Return(nil).
@@ -210,6 +223,7 @@ func TestCreateSecret(t *testing.T) {
 			}
 
 			appConfig := mocks.NewMockIAppConfiguration(ctrl)
+			appConfig.EXPECT().PaddingBuckets().Return(nil).AnyTimes()
 			appConfig.EXPECT().
 				MaxAccessCount().
 				Return(maxAccessCount).
@@ -263,7 +277,7 @@ func TestCreateSecret(t *testing.T) {
 				Return(encryptedData, nil).
 				AnyTimes()
 
-			dataStore := mocks.NewMockDataStore(ctrl)
+			dataStore := newMockDataStore(ctrl)
 			writeSecretCall := dataStore.EXPECT().
 				WriteSecret(gomock.Any(), gomock.Any()).
 				Return(nil).
@@ -274,6 +288,7 @@ func TestCreateSecret(t *testing.T) {
 			}
 
 			appConfig := mocks.NewMockIAppConfiguration(ctrl)
+			appConfig.EXPECT().PaddingBuckets().Return(nil).AnyTimes()
 			appConfig.EXPECT().
 				MaxAccessCount().
 				Return(maxAccessCount).
@@ -302,6 +317,87 @@ func TestCreateSecret(t *testing.T) {
 	})
 }
 
+func TestWhenGeneratingASecret(t *testing.T) {
+	secret := models.Secret{
+		AccessLimit:     1,
+		ExpirationEpoch: testhelpers.EpochFromNow(time.Hour),
+	}
+
+	sut := func(options models.GenerateSecretOptions, callTimes int) (*models.SecretMetadata, []byte, models.Secret, error) {
+		ctrl := gomock.NewController(t)
+
+		var plaintext []byte
+		encryption := mocks.NewMockEncryption(ctrl)
+		encryption.EXPECT().
+			Encrypt(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, content []byte) (string, error) {
+				plaintext = content
+				return testhelpers.RandomId(t), nil
+			}).
+			Times(callTimes)
+
+		var written models.Secret
+		dataStore := newMockDataStore(ctrl)
+		dataStore.EXPECT().
+			WriteSecret(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, secret models.Secret) error {
+				written = secret
+				return nil
+			}).
+			Times(callTimes)
+
+		appConfig := mocks.NewMockIAppConfiguration(ctrl)
+		appConfig.EXPECT().MaxAccessCount().Return(100).AnyTimes()
+		appConfig.EXPECT().MaxExpirationSeconds().Return(604800).AnyTimes()
+		appConfig.EXPECT().AllowedCIDRs().Return(nil).AnyTimes()
+		appConfig.EXPECT().PaddingBuckets().Return(nil).AnyTimes()
+
+		metadata, err := commands.GenerateSecret(context.Background(), appConfig, dataStore, encryption, secret, options)
+		return metadata, plaintext, written, err
+	}
+
+	t.Run("and the kind is a password", func(t *testing.T) {
+		metadata, plaintext, written, err := sut(models.GenerateSecretOptions{Kind: models.GeneratedSecretKindPassword, Length: 40}, 1)
+		require.NoError(t, err)
+
+		t.Run("it should encrypt the generated password", func(t *testing.T) {
+			assert.Len(t, plaintext, 40)
+		})
+
+		t.Run("it should store a text secret with the given limits", func(t *testing.T) {
+			assert.Equal(t, models.ContentTypeText, written.ContentType)
+			assert.Equal(t, secret.AccessLimit, written.AccessLimit)
+		})
+
+		t.Run("it should record when the secret was created", func(t *testing.T) {
+			assert.InDelta(t, time.Now().Unix(), written.CreatedEpoch, 5)
+		})
+
+		t.Run("it should return the metadata with an owner token", func(t *testing.T) {
+			assert.Equal(t, written.ID, metadata.ID)
+			assert.NotEmpty(t, metadata.OwnerToken)
+		})
+	})
+
+	t.Run("and the kind is an age key pair", func(t *testing.T) {
+		metadata, _, _, err := sut(models.GenerateSecretOptions{Kind: models.GeneratedSecretKindAge}, 1)
+		require.NoError(t, err)
+
+		t.Run("it should return the bundle items", func(t *testing.T) {
+			assert.Equal(t, models.ContentType(models.ContentTypeBundle), metadata.ContentType)
+			assert.Len(t, metadata.BundleItems, 2)
+		})
+	})
+
+	t.Run("and the options are invalid", func(t *testing.T) {
+		_, _, _, err := sut(models.GenerateSecretOptions{Kind: models.GeneratedSecretKindPassword, Length: 4}, 0)
+
+		t.Run("it should return validation error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsValidationError(err), "expected validation error")
+		})
+	})
+}
+
 func TestWhenAccessingASecret(t *testing.T) {
 	test := func(expectedContentType string) func(t *testing.T) {
 		return func(t *testing.T) {
@@ -321,13 +417,17 @@ func TestWhenAccessingASecret(t *testing.T) {
 				encryption := mocks.NewMockEncryption(ctrl)
 				decryptCall := encryption.EXPECT().
 					Decrypt(gomock.Any(), secret.CipherText).
-					Return(secret.Content, nil).
+					DoAndReturn(decryptsTo(secret.Content)).
 					AnyTimes()
 				if decryptCallTimes >= 0 {
 					decryptCall.Times(decryptCallTimes)
 				}
 
-				dataStore := mocks.NewMockDataStore(ctrl)
+				dataStore := newMockDataStore(ctrl)
+				dataStore.EXPECT().
+					AppendSecretHistory(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil).
+					AnyTimes()
 				readSecretCall := dataStore.EXPECT().
 					ReadSecret(gomock.Any(), secret.ID).
 					Return(&secret).
@@ -344,7 +444,9 @@ func TestWhenAccessingASecret(t *testing.T) {
 					increaseAccessCountCall.Times(increaseAccessCountCallTimes)
 				}
 
-				response, err := commands.AccessSecret(context.Background(), dataStore, encryption, secret.ID)
+				notifier := mocks.NewMockNotifier(ctrl)
+
+				response, err := commands.AccessSecret(context.Background(), dataStore, encryption, notifier, models.AccessRequest{ID: secret.ID})
 				require.NoError(t, err)
 
 				return
@@ -372,9 +474,14 @@ func TestWhenAccessingASecret(t *testing.T) {
 
 					ctrl := gomock.NewController(t)
 					encryption := mocks.NewMockEncryption(ctrl)
-					dataStore := mocks.NewMockDataStore(ctrl)
+					dataStore := newMockDataStore(ctrl)
+					dataStore.EXPECT().
+						AppendSecretHistory(gomock.Any(), gomock.Any(), gomock.Any()).
+						Return(nil).
+						AnyTimes()
+					notifier := mocks.NewMockNotifier(ctrl)
 
-					_, err := commands.AccessSecret(ctx, dataStore, encryption, secret.ID)
+					_, err := commands.AccessSecret(ctx, dataStore, encryption, notifier, models.AccessRequest{ID: secret.ID})
 
 					assert.True(t, pkgerrors.IsContextError(err), "expected context error")
 				})
@@ -404,9 +511,13 @@ func TestWhenAccessingASecretWithFilename(t *testing.T) {
 	encryption := mocks.NewMockEncryption(ctrl)
 	encryption.EXPECT().
 		Decrypt(gomock.Any(), secret.CipherText).
-		Return(secret.Content, nil)
+		DoAndReturn(decryptsTo(secret.Content))
 
-	dataStore := mocks.NewMockDataStore(ctrl)
+	dataStore := newMockDataStore(ctrl)
+	dataStore.EXPECT().
+		AppendSecretHistory(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil).
+		AnyTimes()
 	dataStore.EXPECT().
 		ReadSecret(gomock.Any(), secret.ID).
 		Return(&secret)
@@ -414,7 +525,9 @@ func TestWhenAccessingASecretWithFilename(t *testing.T) {
 		IncreaseAccessCount(gomock.Any(), secret.ID).
 		Return(int64(1), nil)
 
-	response, err := commands.AccessSecret(context.Background(), dataStore, encryption, secret.ID)
+	notifier := mocks.NewMockNotifier(ctrl)
+
+	response, err := commands.AccessSecret(context.Background(), dataStore, encryption, notifier, models.AccessRequest{ID: secret.ID})
 	require.NoError(t, err)
 
 	t.Run("it should return filename", func(t *testing.T) {
@@ -422,6 +535,873 @@ func TestWhenAccessingASecretWithFilename(t *testing.T) {
 	})
 }
 
+func TestCreateSecretWithAvailableFrom(t *testing.T) {
+	sut := func(availableFrom time.Duration, expiration time.Duration, writeSecretCallTimes int) error {
+		ctrl := gomock.NewController(t)
+
+		encryption := mocks.NewMockEncryption(ctrl)
+		encryption.EXPECT().
+			Encrypt(gomock.Any(), gomock.Any()).
+			Return(testhelpers.RandomId(t), nil).
+			AnyTimes()
+
+		dataStore := newMockDataStore(ctrl)
+		dataStore.EXPECT().
+			AppendSecretHistory(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil).
+			AnyTimes()
+		dataStore.EXPECT().
+			WriteSecret(gomock.Any(), gomock.Any()).
+			Return(nil).
+			Times(writeSecretCallTimes)
+
+		appConfig := mocks.NewMockIAppConfiguration(ctrl)
+		appConfig.EXPECT().MaxAccessCount().Return(100).AnyTimes()
+		appConfig.EXPECT().MaxExpirationSeconds().Return(604800).AnyTimes()
+		appConfig.EXPECT().AllowedCIDRs().Return(nil).AnyTimes()
+		appConfig.EXPECT().PaddingBuckets().Return(nil).AnyTimes()
+
+		_, err := commands.CreateSecret(context.Background(), appConfig, dataStore, encryption, models.Secret{
+			Content:            []byte("Super Secret Test Content"),
+			ExpirationEpoch:    testhelpers.EpochFromNow(expiration),
+			AvailableFromEpoch: testhelpers.EpochFromNow(availableFrom),
+		})
+		return err
+	}
+
+	t.Run("when available from is before expiration", func(t *testing.T) {
+		t.Run("it should write the secret", func(t *testing.T) {
+			assert.NoError(t, sut(time.Hour, 2*time.Hour, 1))
+		})
+	})
+
+	t.Run("when available from is after expiration", func(t *testing.T) {
+		t.Run("it should return validation error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsValidationError(sut(3*time.Hour, 2*time.Hour, 0)))
+		})
+	})
+
+	t.Run("when available from exceeds the maximum expiration", func(t *testing.T) {
+		t.Run("it should return validation error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsValidationError(sut(8*24*time.Hour, time.Hour, 0)))
+		})
+	})
+}
+
+func TestCreateSecretWithAllowedCIDRs(t *testing.T) {
+	sut := func(allowedCIDRs []string, defaultCIDRs []string, callTimes int) (models.Secret, error) {
+		ctrl := gomock.NewController(t)
+
+		encryption := mocks.NewMockEncryption(ctrl)
+		encryption.EXPECT().
+			Encrypt(gomock.Any(), gomock.Any()).
+			Return(testhelpers.RandomId(t), nil).
+			AnyTimes()
+
+		var written models.Secret
+		dataStore := newMockDataStore(ctrl)
+		dataStore.EXPECT().
+			WriteSecret(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, secret models.Secret) error {
+				written = secret
+				return nil
+			}).
+			Times(callTimes)
+
+		appConfig := mocks.NewMockIAppConfiguration(ctrl)
+		appConfig.EXPECT().PaddingBuckets().Return(nil).AnyTimes()
+		appConfig.EXPECT().MaxAccessCount().Return(100).AnyTimes()
+		appConfig.EXPECT().MaxExpirationSeconds().Return(604800).AnyTimes()
+		appConfig.EXPECT().AllowedCIDRs().Return(defaultCIDRs).AnyTimes()
+
+		_, err := commands.CreateSecret(context.Background(), appConfig, dataStore, encryption, models.Secret{
+			Content:         []byte(testhelpers.RandomId(t)),
+			AllowedCIDRs:    allowedCIDRs,
+			ExpirationEpoch: testhelpers.EpochFromNow(time.Hour),
+		})
+		return written, err
+	}
+
+	t.Run("when the secret has its own allowlist", func(t *testing.T) {
+		written, err := sut([]string{"10.1.2.3/8", "203.0.113.9"}, []string{"192.168.0.0/16"}, 1)
+		require.NoError(t, err)
+
+		t.Run("it should store the normalized ranges instead of the default", func(t *testing.T) {
+			assert.Equal(t, []string{"10.0.0.0/8", "203.0.113.9/32"}, written.AllowedCIDRs)
+		})
+	})
+
+	t.Run("when the secret has no allowlist", func(t *testing.T) {
+		written, err := sut(nil, []string{"192.168.0.0/16"}, 1)
+		require.NoError(t, err)
+
+		t.Run("it should store the default allowlist", func(t *testing.T) {
+			assert.Equal(t, []string{"192.168.0.0/16"}, written.AllowedCIDRs)
+		})
+	})
+
+	t.Run("when a range is invalid", func(t *testing.T) {
+		_, err := sut([]string{"10.0.0.0/33"}, nil, 0)
+
+		t.Run("it should return validation error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsValidationError(err), "expected validation error")
+		})
+	})
+
+	t.Run("when there are too many ranges", func(t *testing.T) {
+		cidrs := make([]string, 33)
+		for i := range cidrs {
+			cidrs[i] = "10.0.0.0/8"
+		}
+		_, err := sut(cidrs, nil, 0)
+
+		t.Run("it should return validation error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsValidationError(err), "expected validation error")
+		})
+	})
+}
+
+func TestCreateSecretWithCountryRestrictions(t *testing.T) {
+	sut := func(allowedCountries []string, deniedCountries []string, callTimes int) (models.Secret, error) {
+		ctrl := gomock.NewController(t)
+
+		encryption := mocks.NewMockEncryption(ctrl)
+		encryption.EXPECT().
+			Encrypt(gomock.Any(), gomock.Any()).
+			Return(testhelpers.RandomId(t), nil).
+			AnyTimes()
+
+		var written models.Secret
+		dataStore := newMockDataStore(ctrl)
+		dataStore.EXPECT().
+			WriteSecret(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, secret models.Secret) error {
+				written = secret
+				return nil
+			}).
+			Times(callTimes)
+
+		appConfig := mocks.NewMockIAppConfiguration(ctrl)
+		appConfig.EXPECT().MaxAccessCount().Return(100).AnyTimes()
+		appConfig.EXPECT().MaxExpirationSeconds().Return(604800).AnyTimes()
+		appConfig.EXPECT().AllowedCIDRs().Return(nil).AnyTimes()
+		appConfig.EXPECT().PaddingBuckets().Return(nil).AnyTimes()
+
+		_, err := commands.CreateSecret(context.Background(), appConfig, dataStore, encryption, models.Secret{
+			Content:          []byte(testhelpers.RandomId(t)),
+			AllowedCountries: allowedCountries,
+			DeniedCountries:  deniedCountries,
+			ExpirationEpoch:  testhelpers.EpochFromNow(time.Hour),
+		})
+		return written, err
+	}
+
+	t.Run("when the country lists are valid", func(t *testing.T) {
+		written, err := sut([]string{"de", "FR", "DE"}, []string{"us"}, 1)
+		require.NoError(t, err)
+
+		t.Run("it should store the normalized country codes", func(t *testing.T) {
+			assert.Equal(t, []string{"DE", "FR"}, written.AllowedCountries)
+			assert.Equal(t, []string{"US"}, written.DeniedCountries)
+		})
+	})
+
+	t.Run("when an allowed country is invalid", func(t *testing.T) {
+		_, err := sut([]string{"Germany"}, nil, 0)
+
+		t.Run("it should return validation error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsValidationError(err), "expected validation error")
+		})
+	})
+
+	t.Run("when a denied country is invalid", func(t *testing.T) {
+		_, err := sut(nil, []string{"1A"}, 0)
+
+		t.Run("it should return validation error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsValidationError(err), "expected validation error")
+		})
+	})
+}
+
+func TestWhenAccessingASecretBeforeItIsAvailable(t *testing.T) {
+	secret := models.Secret{
+		ID:                 testhelpers.RandomId(t),
+		CipherText:         testhelpers.RandomId(t),
+		ContentType:        models.ContentTypeText,
+		AccessLimit:        1,
+		ExpirationEpoch:    testhelpers.EpochFromNow(2 * time.Hour),
+		AvailableFromEpoch: testhelpers.EpochFromNow(time.Hour),
+	}
+
+	ctrl := gomock.NewController(t)
+
+	dataStore := newMockDataStore(ctrl)
+	dataStore.EXPECT().
+		AppendSecretHistory(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil).
+		AnyTimes()
+	dataStore.EXPECT().
+		ReadSecret(gomock.Any(), secret.ID).
+		Return(&secret)
+	dataStore.EXPECT().
+		IncreaseAccessCount(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	encryption := mocks.NewMockEncryption(ctrl)
+	encryption.EXPECT().
+		Decrypt(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	response, err := commands.AccessSecret(context.Background(), dataStore, encryption, mocks.NewMockNotifier(ctrl), models.AccessRequest{ID: secret.ID})
+
+	t.Run("it should return not yet available error", func(t *testing.T) {
+		assert.True(t, pkgerrors.IsNotYetAvailableError(err), "expected not yet available error")
+	})
+
+	t.Run("it should report when the secret becomes available", func(t *testing.T) {
+		notYetAvailable := pkgerrors.GetNotYetAvailableError(err)
+		require.NotNil(t, notYetAvailable)
+		assert.Equal(t, secret.AvailableFromEpoch, notYetAvailable.AvailableFrom().Unix())
+	})
+
+	t.Run("it should not return secret", func(t *testing.T) {
+		assert.Nil(t, response)
+	})
+}
+
+func TestWhenAccessingASecretWithNotifyEmail(t *testing.T) {
+	secret := models.Secret{
+		ID:              testhelpers.RandomId(t),
+		Content:         []byte(testhelpers.RandomId(t)),
+		CipherText:      testhelpers.RandomId(t),
+		ContentType:     models.ContentTypeText,
+		NotifyEmail:     "sender@example.com",
+		AccessLimit:     10,
+		ExpirationEpoch: testhelpers.EpochFromNow(time.Hour),
+	}
+
+	ctrl := gomock.NewController(t)
+
+	encryption := mocks.NewMockEncryption(ctrl)
+	encryption.EXPECT().
+		Decrypt(gomock.Any(), secret.CipherText).
+		DoAndReturn(decryptsTo(secret.Content))
+
+	dataStore := newMockDataStore(ctrl)
+	dataStore.EXPECT().
+		AppendSecretHistory(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil).
+		AnyTimes()
+	dataStore.EXPECT().
+		ReadSecret(gomock.Any(), secret.ID).
+		Return(&secret)
+	dataStore.EXPECT().
+		IncreaseAccessCount(gomock.Any(), secret.ID).
+		Return(int64(1), nil)
+	dataStore.EXPECT().
+		CancelExpiryNotification(gomock.Any(), secret.ID).
+		Return(nil)
+
+	var sent models.Notification
+	notifier := mocks.NewMockNotifier(ctrl)
+	notifier.EXPECT().
+		Notify(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, notification models.Notification) error {
+			sent = notification
+			return nil
+		})
+
+	_, err := commands.AccessSecret(context.Background(), dataStore, encryption, notifier, models.AccessRequest{ID: secret.ID})
+	require.NoError(t, err)
+
+	t.Run("it should send an access notification", func(t *testing.T) {
+		assert.Equal(t, models.NotificationType(models.NotificationSecretAccessed), sent.Type)
+	})
+
+	t.Run("it should send the notification to the notify email", func(t *testing.T) {
+		assert.Equal(t, secret.NotifyEmail, sent.Recipient)
+	})
+
+	t.Run("it should include the access count", func(t *testing.T) {
+		assert.Equal(t, 1, sent.AccessCount)
+	})
+
+	t.Run("it should include the access limit", func(t *testing.T) {
+		assert.Equal(t, secret.AccessLimit, sent.AccessLimit)
+	})
+}
+
+func TestWhenAccessingASecretWithViewWindow(t *testing.T) {
+	newSecret := func() models.Secret {
+		return models.Secret{
+			ID:                testhelpers.RandomId(t),
+			Content:           []byte(testhelpers.RandomId(t)),
+			CipherText:        testhelpers.RandomId(t),
+			ContentType:       models.ContentTypeText,
+			AccessLimit:       10,
+			ExpirationEpoch:   testhelpers.EpochFromNow(time.Hour),
+			ViewWindowSeconds: 900,
+		}
+	}
+
+	sut := func(secret models.Secret, accessCount int64, startViewWindowCallTimes int) (startedEpoch int64) {
+		ctrl := gomock.NewController(t)
+
+		encryption := mocks.NewMockEncryption(ctrl)
+		encryption.EXPECT().
+			Decrypt(gomock.Any(), secret.CipherText).
+			DoAndReturn(decryptsTo(secret.Content))
+
+		dataStore := newMockDataStore(ctrl)
+		dataStore.EXPECT().
+			AppendSecretHistory(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil).
+			AnyTimes()
+		dataStore.EXPECT().
+			ReadSecret(gomock.Any(), secret.ID).
+			Return(&secret)
+		dataStore.EXPECT().
+			IncreaseAccessCount(gomock.Any(), secret.ID).
+			Return(accessCount, nil)
+		dataStore.EXPECT().
+			StartViewWindow(gomock.Any(), secret.ID, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, expirationEpoch int64) error {
+				startedEpoch = expirationEpoch
+				return nil
+			}).
+			Times(startViewWindowCallTimes)
+
+		notifier := mocks.NewMockNotifier(ctrl)
+		_, err := commands.AccessSecret(context.Background(), dataStore, encryption, notifier, models.AccessRequest{ID: secret.ID})
+		require.NoError(t, err)
+		return
+	}
+
+	t.Run("and it is the first access", func(t *testing.T) {
+		expected := testhelpers.EpochFromNow(15 * time.Minute)
+		actual := sut(newSecret(), 1, 1)
+
+		t.Run("it should start the view window", func(t *testing.T) {
+			assert.InDelta(t, expected, actual, 1)
+		})
+	})
+
+	t.Run("and it is a later access", func(t *testing.T) {
+		t.Run("it should not restart the view window", func(t *testing.T) {
+			sut(newSecret(), 2, 0)
+		})
+	})
+
+	t.Run("and the first access reaches the access limit", func(t *testing.T) {
+		secret := newSecret()
+		secret.AccessLimit = 1
+
+		ctrl := gomock.NewController(t)
+		encryption := mocks.NewMockEncryption(ctrl)
+		encryption.EXPECT().Decrypt(gomock.Any(), secret.CipherText).DoAndReturn(decryptsTo(secret.Content))
+
+		dataStore := newMockDataStore(ctrl)
+		dataStore.EXPECT().
+			AppendSecretHistory(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil).
+			AnyTimes()
+		dataStore.EXPECT().ReadSecret(gomock.Any(), secret.ID).Return(&secret)
+		dataStore.EXPECT().IncreaseAccessCount(gomock.Any(), secret.ID).Return(int64(1), nil)
+		dataStore.EXPECT().DeleteSecret(gomock.Any(), secret.ID).Return(true, nil)
+		dataStore.EXPECT().StartViewWindow(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		t.Run("it should delete the secret instead of starting the view window", func(t *testing.T) {
+			_, err := commands.AccessSecret(context.Background(), dataStore, encryption, mocks.NewMockNotifier(ctrl), models.AccessRequest{ID: secret.ID})
+			assert.NoError(t, err)
+		})
+	})
+}
+
+func TestWhenAccessingAnOwnedSecret(t *testing.T) {
+	ownerToken := testhelpers.RandomId(t)
+	ownerTokenHash := sha256.Sum256([]byte(ownerToken))
+
+	secret := models.Secret{
+		ID:              testhelpers.RandomId(t),
+		Content:         []byte(testhelpers.RandomId(t)),
+		CipherText:      testhelpers.RandomId(t),
+		ContentType:     models.ContentTypeText,
+		OwnerTokenHash:  hex.EncodeToString(ownerTokenHash[:]),
+		OwnerOnly:       true,
+		AccessLimit:     10,
+		ExpirationEpoch: testhelpers.EpochFromNow(time.Hour),
+	}
+
+	sut := func(token string, decryptCallTimes, increaseAccessCountCallTimes int) (*models.Secret, error) {
+		ctrl := gomock.NewController(t)
+
+		encryption := mocks.NewMockEncryption(ctrl)
+		encryption.EXPECT().
+			Decrypt(gomock.Any(), secret.CipherText).
+			DoAndReturn(decryptsTo(secret.Content)).
+			Times(decryptCallTimes)
+
+		dataStore := newMockDataStore(ctrl)
+		dataStore.EXPECT().
+			AppendSecretHistory(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil).
+			AnyTimes()
+		dataStore.EXPECT().
+			ReadSecret(gomock.Any(), secret.ID).
+			Return(&secret)
+		dataStore.EXPECT().
+			IncreaseAccessCount(gomock.Any(), secret.ID).
+			Return(int64(1), nil).
+			Times(increaseAccessCountCallTimes)
+
+		notifier := mocks.NewMockNotifier(ctrl)
+		return commands.AccessSecret(context.Background(), dataStore, encryption, notifier, models.AccessRequest{ID: secret.ID, OwnerToken: token})
+	}
+
+	t.Run("and owner token matches", func(t *testing.T) {
+		response, err := sut(ownerToken, 1, 1)
+		require.NoError(t, err)
+
+		t.Run("it should return content", func(t *testing.T) {
+			assert.Equal(t, secret.Content, response.Content)
+		})
+	})
+
+	t.Run("and owner token does not match", func(t *testing.T) {
+		response, err := sut(testhelpers.RandomId(t), 0, 0)
+
+		t.Run("it should return forbidden error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsForbiddenError(err), "expected forbidden error")
+		})
+
+		t.Run("it should report invalid credentials", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsInvalidCredentialError(err), "expected invalid credential error")
+		})
+
+		t.Run("it should not return secret", func(t *testing.T) {
+			assert.Nil(t, response)
+		})
+	})
+
+	t.Run("and owner token is missing", func(t *testing.T) {
+		_, err := sut("", 0, 0)
+
+		t.Run("it should return forbidden error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsForbiddenError(err), "expected forbidden error")
+		})
+	})
+}
+
+func TestWhenAccessingASplitKeySecret(t *testing.T) {
+	content := []byte(testhelpers.RandomId(t))
+	cipherText := testhelpers.RandomId(t)
+
+	ctrl := gomock.NewController(t)
+
+	var sealed []byte
+	encryption := mocks.NewMockEncryption(ctrl)
+	encryption.EXPECT().
+		Encrypt(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, content []byte) (string, error) {
+			sealed = append([]byte{}, content...)
+			return cipherText, nil
+		})
+
+	var written models.Secret
+	dataStore := newMockDataStore(ctrl)
+	dataStore.EXPECT().
+		WriteSecret(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, secret models.Secret) error {
+			written = secret
+			return nil
+		})
+
+	appConfig := mocks.NewMockIAppConfiguration(ctrl)
+	appConfig.EXPECT().MaxAccessCount().Return(100).AnyTimes()
+	appConfig.EXPECT().MaxExpirationSeconds().Return(604800).AnyTimes()
+	appConfig.EXPECT().AllowedCIDRs().Return(nil).AnyTimes()
+	appConfig.EXPECT().PaddingBuckets().Return(nil).AnyTimes()
+
+	metadata, err := commands.CreateSecret(context.Background(), appConfig, dataStore, encryption, models.Secret{
+		Content:         content,
+		ContentType:     models.ContentTypeText,
+		SplitKey:        true,
+		ExpirationEpoch: testhelpers.EpochFromNow(time.Hour),
+	})
+	require.NoError(t, err)
+
+	t.Run("it should return the key to the creator", func(t *testing.T) {
+		assert.NotEmpty(t, metadata.Key)
+		assert.True(t, metadata.SplitKey)
+	})
+
+	t.Run("it should not store the key or the plain content", func(t *testing.T) {
+		assert.NotEmpty(t, written.SplitKeyCheck)
+		assert.NotContains(t, written.SplitKeyCheck, metadata.Key)
+		assert.NotEqual(t, content, sealed)
+	})
+
+	stored := written
+	stored.CipherText = cipherText
+
+	sut := func(key string, callTimes int) (*models.Secret, error) {
+		ctrl := gomock.NewController(t)
+
+		encryption := mocks.NewMockEncryption(ctrl)
+		encryption.EXPECT().
+			Decrypt(gomock.Any(), cipherText).
+			DoAndReturn(decryptsTo(sealed)).
+			Times(callTimes)
+
+		dataStore := newMockDataStore(ctrl)
+		dataStore.EXPECT().
+			AppendSecretHistory(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil).
+			AnyTimes()
+		dataStore.EXPECT().
+			ReadSecret(gomock.Any(), stored.ID).
+			Return(&stored)
+		dataStore.EXPECT().
+			IncreaseAccessCount(gomock.Any(), stored.ID).
+			Return(int64(1), nil).
+			Times(callTimes)
+
+		notifier := mocks.NewMockNotifier(ctrl)
+		return commands.AccessSecret(context.Background(), dataStore, encryption, notifier, models.AccessRequest{ID: stored.ID, Key: key})
+	}
+
+	t.Run("and the key matches", func(t *testing.T) {
+		response, err := sut(metadata.Key, 1)
+		require.NoError(t, err)
+
+		t.Run("it should return the content", func(t *testing.T) {
+			assert.Equal(t, content, response.Content)
+		})
+	})
+
+	t.Run("and the key does not match", func(t *testing.T) {
+		response, err := sut(testhelpers.RandomId(t), 0)
+
+		t.Run("it should return forbidden error without consuming an access", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsForbiddenError(err), "expected forbidden error")
+			assert.Nil(t, response)
+		})
+	})
+
+	t.Run("and the key is missing", func(t *testing.T) {
+		_, err := sut("", 0)
+
+		t.Run("it should return forbidden error without consuming an access", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsForbiddenError(err), "expected forbidden error")
+		})
+	})
+}
+
+func TestWhenCreatingAndAccessingAPaddedSecret(t *testing.T) {
+	content := []byte(testhelpers.RandomId(t))
+	cipherText := testhelpers.RandomId(t)
+
+	ctrl := gomock.NewController(t)
+
+	var padded, encrypted []byte
+	encryption := mocks.NewMockEncryption(ctrl)
+	encryption.EXPECT().
+		Encrypt(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, content []byte) (string, error) {
+			padded = content
+			encrypted = append([]byte{}, content...)
+			return cipherText, nil
+		})
+
+	var written models.Secret
+	dataStore := newMockDataStore(ctrl)
+	dataStore.EXPECT().
+		WriteSecret(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, secret models.Secret) error {
+			written = secret
+			return nil
+		})
+
+	appConfig := mocks.NewMockIAppConfiguration(ctrl)
+	appConfig.EXPECT().MaxAccessCount().Return(100).AnyTimes()
+	appConfig.EXPECT().MaxExpirationSeconds().Return(604800).AnyTimes()
+	appConfig.EXPECT().AllowedCIDRs().Return(nil).AnyTimes()
+	appConfig.EXPECT().PaddingBuckets().Return([]int{256, 1024}).AnyTimes()
+
+	_, err := commands.CreateSecret(context.Background(), appConfig, dataStore, encryption, models.Secret{
+		Content:         content,
+		ContentType:     models.ContentTypeText,
+		ExpirationEpoch: testhelpers.EpochFromNow(time.Hour),
+	})
+	require.NoError(t, err)
+
+	t.Run("it should encrypt the content padded to the smallest bucket", func(t *testing.T) {
+		assert.Len(t, encrypted, 256)
+		assert.True(t, written.Padded)
+	})
+
+	t.Run("it should wipe the padded content once it is encrypted", func(t *testing.T) {
+		assert.True(t, plaintext.Wiped(padded))
+	})
+
+	t.Run("and accessing it", func(t *testing.T) {
+		stored := written
+		stored.CipherText = cipherText
+
+		decrypted := append([]byte{}, encrypted...)
+		encryption.EXPECT().
+			Decrypt(gomock.Any(), cipherText).
+			Return(decrypted, nil)
+		dataStore.EXPECT().
+			AppendSecretHistory(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil).
+			AnyTimes()
+		dataStore.EXPECT().
+			ReadSecret(gomock.Any(), stored.ID).
+			Return(&stored)
+		dataStore.EXPECT().
+			IncreaseAccessCount(gomock.Any(), stored.ID).
+			Return(int64(1), nil)
+
+		response, err := commands.AccessSecret(context.Background(), dataStore, encryption, mocks.NewMockNotifier(ctrl), models.AccessRequest{ID: stored.ID})
+		require.NoError(t, err)
+
+		t.Run("it should return the content without its padding", func(t *testing.T) {
+			assert.Equal(t, content, response.Content)
+		})
+
+		t.Run("it should wipe the decrypted content", func(t *testing.T) {
+			assert.True(t, plaintext.Wiped(decrypted))
+		})
+	})
+}
+
+func TestWhenAccessingASecretWithAllowedCIDRs(t *testing.T) {
+	secret := models.Secret{
+		ID:              testhelpers.RandomId(t),
+		Content:         []byte(testhelpers.RandomId(t)),
+		CipherText:      testhelpers.RandomId(t),
+		ContentType:     models.ContentTypeText,
+		AllowedCIDRs:    []string{"10.0.0.0/8"},
+		AccessLimit:     10,
+		ExpirationEpoch: testhelpers.EpochFromNow(time.Hour),
+	}
+
+	sut := func(clientIP string, callTimes int) (*models.Secret, error) {
+		ctrl := gomock.NewController(t)
+
+		encryption := mocks.NewMockEncryption(ctrl)
+		encryption.EXPECT().
+			Decrypt(gomock.Any(), secret.CipherText).
+			DoAndReturn(decryptsTo(secret.Content)).
+			Times(callTimes)
+
+		dataStore := newMockDataStore(ctrl)
+		dataStore.EXPECT().
+			AppendSecretHistory(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil).
+			AnyTimes()
+		dataStore.EXPECT().
+			ReadSecret(gomock.Any(), secret.ID).
+			Return(&secret)
+		dataStore.EXPECT().
+			IncreaseAccessCount(gomock.Any(), secret.ID).
+			Return(int64(1), nil).
+			Times(callTimes)
+
+		notifier := mocks.NewMockNotifier(ctrl)
+		return commands.AccessSecret(context.Background(), dataStore, encryption, notifier, models.AccessRequest{ID: secret.ID, Client: models.Client{IP: clientIP}})
+	}
+
+	t.Run("and the client is within the allowlist", func(t *testing.T) {
+		response, err := sut("10.20.30.40", 1)
+		require.NoError(t, err)
+
+		t.Run("it should return the content", func(t *testing.T) {
+			assert.Equal(t, secret.Content, response.Content)
+		})
+	})
+
+	t.Run("and the client is outside the allowlist", func(t *testing.T) {
+		response, err := sut("192.168.1.1", 0)
+
+		t.Run("it should return forbidden error without consuming an access", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsForbiddenError(err), "expected forbidden error")
+			assert.Nil(t, response)
+		})
+	})
+}
+
+func TestWhenAccessingASecretWithCountryRestrictions(t *testing.T) {
+	secret := models.Secret{
+		ID:               testhelpers.RandomId(t),
+		Content:          []byte(testhelpers.RandomId(t)),
+		CipherText:       testhelpers.RandomId(t),
+		ContentType:      models.ContentTypeText,
+		AllowedCountries: []string{"DE", "FR"},
+		DeniedCountries:  []string{"FR"},
+		AccessLimit:      10,
+		ExpirationEpoch:  testhelpers.EpochFromNow(time.Hour),
+	}
+
+	sut := func(country string, callTimes int) (*models.Secret, []models.SecretHistoryEvent, error) {
+		ctrl := gomock.NewController(t)
+
+		encryption := mocks.NewMockEncryption(ctrl)
+		encryption.EXPECT().
+			Decrypt(gomock.Any(), secret.CipherText).
+			DoAndReturn(decryptsTo(secret.Content)).
+			Times(callTimes)
+
+		var events []models.SecretHistoryEvent
+		dataStore := newMockDataStore(ctrl)
+		dataStore.EXPECT().
+			AppendSecretHistory(gomock.Any(), secret.ID, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, event models.SecretHistoryEvent) error {
+				events = append(events, event)
+				return nil
+			}).
+			AnyTimes()
+		dataStore.EXPECT().
+			ReadSecret(gomock.Any(), secret.ID).
+			Return(&secret)
+		dataStore.EXPECT().
+			IncreaseAccessCount(gomock.Any(), secret.ID).
+			Return(int64(1), nil).
+			Times(callTimes)
+
+		notifier := mocks.NewMockNotifier(ctrl)
+		response, err := commands.AccessSecret(context.Background(), dataStore, encryption, notifier, models.AccessRequest{
+			ID:     secret.ID,
+			Client: models.Client{IP: "192.0.2.1", Country: country},
+		})
+		return response, events, err
+	}
+
+	t.Run("and the client is in an allowed country", func(t *testing.T) {
+		response, events, err := sut("DE", 1)
+		require.NoError(t, err)
+
+		t.Run("it should return the content", func(t *testing.T) {
+			assert.Equal(t, secret.Content, response.Content)
+		})
+
+		t.Run("it should record the country in the access history", func(t *testing.T) {
+			require.Len(t, events, 1)
+			assert.EqualValues(t, models.SecretHistoryAccessed, events[0].Type)
+			assert.Equal(t, "DE", events[0].Country)
+		})
+	})
+
+	t.Run("and the client is in a denied country", func(t *testing.T) {
+		response, _, err := sut("FR", 0)
+
+		t.Run("it should return forbidden error without consuming an access", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsForbiddenError(err), "expected forbidden error")
+			assert.Nil(t, response)
+		})
+	})
+
+	t.Run("and the country of the client is unknown", func(t *testing.T) {
+		response, _, err := sut("", 0)
+
+		t.Run("it should return forbidden error without consuming an access", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsForbiddenError(err), "expected forbidden error")
+			assert.Nil(t, response)
+		})
+	})
+}
+
+func TestWhenAccessingABundleItem(t *testing.T) {
+	archive, items, err := bundles.Write([]bundles.File{
+		{Name: "cert.pem", Content: []byte("certificate")},
+		{Name: "key.pem", Content: []byte("private key")},
+	})
+	require.NoError(t, err)
+
+	secret := models.Secret{
+		ID:              testhelpers.RandomId(t),
+		Content:         archive,
+		CipherText:      testhelpers.RandomId(t),
+		ContentType:     models.ContentTypeBundle,
+		BundleItems:     items,
+		AccessLimit:     2,
+		ExpirationEpoch: testhelpers.EpochFromNow(time.Hour),
+	}
+
+	sut := func(secret models.Secret, index int, accessCount int64, callTimes int, deleteCallTimes int) (*models.Secret, error) {
+		ctrl := gomock.NewController(t)
+
+		encryption := mocks.NewMockEncryption(ctrl)
+		encryption.EXPECT().
+			Decrypt(gomock.Any(), secret.CipherText).
+			DoAndReturn(decryptsTo(secret.Content)).
+			Times(callTimes)
+
+		dataStore := newMockDataStore(ctrl)
+		dataStore.EXPECT().
+			AppendSecretHistory(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil).
+			AnyTimes()
+		dataStore.EXPECT().
+			ReadSecret(gomock.Any(), secret.ID).
+			Return(&secret)
+		dataStore.EXPECT().
+			IncreaseAccessCount(gomock.Any(), secret.ID).
+			Return(accessCount, nil).
+			Times(callTimes)
+		dataStore.EXPECT().
+			DeleteSecret(gomock.Any(), secret.ID).
+			Return(true, nil).
+			Times(deleteCallTimes)
+
+		notifier := mocks.NewMockNotifier(ctrl)
+		return commands.AccessSecretItem(context.Background(), dataStore, encryption, notifier, models.AccessRequest{ID: secret.ID}, index)
+	}
+
+	t.Run("and the item exists", func(t *testing.T) {
+		response, err := sut(secret, 1, 1, 1, 0)
+		require.NoError(t, err)
+
+		t.Run("it should return the item content", func(t *testing.T) {
+			assert.Equal(t, []byte("private key"), response.Content)
+		})
+
+		t.Run("it should return the item as a named file", func(t *testing.T) {
+			assert.Equal(t, models.ContentTypeFile, response.ContentType)
+			assert.Equal(t, "key.pem", response.Filename)
+		})
+	})
+
+	t.Run("and the access limit of the bundle is reached", func(t *testing.T) {
+		_, err := sut(secret, 0, 2, 1, 1)
+
+		t.Run("it should delete the whole bundle", func(t *testing.T) {
+			assert.NoError(t, err)
+		})
+	})
+
+	t.Run("and the item does not exist", func(t *testing.T) {
+		response, err := sut(secret, 2, 1, 0, 0)
+
+		t.Run("it should return validation error without consuming an access", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsValidationError(err), "expected validation error")
+			assert.Nil(t, response)
+		})
+	})
+
+	t.Run("and the secret is not a bundle", func(t *testing.T) {
+		text := secret
+		text.ContentType = models.ContentTypeText
+		text.BundleItems = nil
+		_, err := sut(text, 0, 1, 0, 0)
+
+		t.Run("it should return validation error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsValidationError(err), "expected validation error")
+		})
+	})
+}
+
 func TestWhenAccessingASecretThatDoesNotExist(t *testing.T) {
 
 	sut := func(decryptCallTimes, readSecretCallTimes, increaseAccessCountCallTimes int) (response *models.Secret, err error) {
@@ -435,7 +1415,11 @@ func TestWhenAccessingASecretThatDoesNotExist(t *testing.T) {
 			decryptCall.Times(decryptCallTimes)
 		}
 
-		dataStore := mocks.NewMockDataStore(ctrl)
+		dataStore := newMockDataStore(ctrl)
+		dataStore.EXPECT().
+			AppendSecretHistory(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil).
+			AnyTimes()
 		readSecretCall := dataStore.EXPECT().
 			ReadSecret(gomock.Any(), gomock.Any()).
 			Return(nil).
@@ -449,7 +1433,8 @@ func TestWhenAccessingASecretThatDoesNotExist(t *testing.T) {
 		if increaseAccessCountCallTimes >= 0 {
 			increaseAccessCountCall.Times(increaseAccessCountCallTimes)
 		}
-		return commands.AccessSecret(context.Background(), dataStore, encryption, testhelpers.RandomId(t))
+		notifier := mocks.NewMockNotifier(ctrl)
+		return commands.AccessSecret(context.Background(), dataStore, encryption, notifier, models.AccessRequest{ID: testhelpers.RandomId(t)})
 	}
 
 	t.Run("should return", func(t *testing.T) {
@@ -483,7 +1468,7 @@ func TestWhenGettingSecretMetadata(t *testing.T) {
 	sut := func(readSecretCallTimes, increaseAccessCountCallTimes int) (response *models.SecretMetadata) {
 		ctrl := gomock.NewController(t)
 
-		dataStore := mocks.NewMockDataStore(ctrl)
+		dataStore := newMockDataStore(ctrl)
 		readSecretCall := dataStore.EXPECT().
 			ReadSecret(gomock.Any(), secret.ID).
 			Return(&secret).
@@ -540,7 +1525,7 @@ func TestWhenGettingSecretMetadata(t *testing.T) {
 			cancel()
 
 			ctrl := gomock.NewController(t)
-			dataStore := mocks.NewMockDataStore(ctrl)
+			dataStore := newMockDataStore(ctrl)
 
 			response := commands.GetSecretMetadata(ctx, dataStore, secret.ID)
 
@@ -555,7 +1540,7 @@ func TestWhenGettingSecretMetadataForSecretThatDoesNotExist(t *testing.T) {
 	sut := func(readSecretCallTimes, increaseAccessCountCallTimes int) *models.SecretMetadata {
 		ctrl := gomock.NewController(t)
 
-		dataStore := mocks.NewMockDataStore(ctrl)
+		dataStore := newMockDataStore(ctrl)
 		readSecretCall := dataStore.EXPECT().
 			ReadSecret(gomock.Any(), gomock.Any()).
 			Return(nil).
@@ -591,7 +1576,7 @@ func TestWhenDeletingASecret(t *testing.T) {
 			ID: testhelpers.RandomId(t),
 		}
 
-		dataStore := mocks.NewMockDataStore(ctrl)
+		dataStore := newMockDataStore(ctrl)
 		deleteSecretCall := dataStore.EXPECT().
 			DeleteSecret(gomock.Any(), secret.ID).
 			Return(true, nil).
@@ -622,7 +1607,7 @@ func TestWhenDeletingASecret(t *testing.T) {
 			cancel()
 
 			ctrl := gomock.NewController(t)
-			dataStore := mocks.NewMockDataStore(ctrl)
+			dataStore := newMockDataStore(ctrl)
 
 			secret := &models.Secret{
 				ID: testhelpers.RandomId(t),
@@ -640,7 +1625,7 @@ func TestWhenDeletingASecretThatDoesNotExist(t *testing.T) {
 
 		ctrl := gomock.NewController(t)
 
-		dataStore := mocks.NewMockDataStore(ctrl)
+		dataStore := newMockDataStore(ctrl)
 		deleteSecretCall := dataStore.EXPECT().
 			DeleteSecret(gomock.Any(), gomock.Any()).
 			Return(false, nil).
@@ -667,3 +1652,201 @@ func TestWhenDeletingASecretThatDoesNotExist(t *testing.T) {
 	})
 	t.Run("should delete from database", func(t *testing.T) { _, _ = sut(1) })
 }
+
+func TestWhenUpdatingASecret(t *testing.T) {
+	ownerToken := testhelpers.RandomId(t)
+	ownerTokenHash := sha256.Sum256([]byte(ownerToken))
+
+	newSecret := func() models.Secret {
+		return models.Secret{
+			ID:              testhelpers.RandomId(t),
+			CipherText:      testhelpers.RandomId(t),
+			ContentType:     models.ContentTypeText,
+			OwnerTokenHash:  hex.EncodeToString(ownerTokenHash[:]),
+			AccessCount:     2,
+			AccessLimit:     5,
+			ExpirationEpoch: testhelpers.EpochFromNow(time.Hour),
+		}
+	}
+
+	sut := func(secret models.Secret, token string, update models.SecretUpdate, updateCallTimes int) (*models.SecretMetadata, []models.SecretHistoryEvent, error) {
+		ctrl := gomock.NewController(t)
+
+		appConfig := mocks.NewMockIAppConfiguration(ctrl)
+		appConfig.EXPECT().PaddingBuckets().Return(nil).AnyTimes()
+		appConfig.EXPECT().MaxAccessCount().Return(100).AnyTimes()
+		appConfig.EXPECT().MaxExpirationSeconds().Return(604800).AnyTimes()
+
+		var recorded []models.SecretHistoryEvent
+		dataStore := newMockDataStore(ctrl)
+		dataStore.EXPECT().
+			ReadSecret(gomock.Any(), secret.ID).
+			Return(&secret).
+			AnyTimes()
+		dataStore.EXPECT().
+			UpdateSecret(gomock.Any(), secret.ID, gomock.Any(), gomock.Any()).
+			Return(true, nil).
+			Times(updateCallTimes)
+		dataStore.EXPECT().
+			AppendSecretHistory(gomock.Any(), secret.ID, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, event models.SecretHistoryEvent) error {
+				recorded = append(recorded, event)
+				return nil
+			}).
+			AnyTimes()
+
+		metadata, err := commands.UpdateSecret(context.Background(), appConfig, dataStore, secret.ID, models.OwnerCredentials{OwnerToken: token}, update)
+		return metadata, recorded, err
+	}
+
+	t.Run("when owner token matches", func(t *testing.T) {
+		expirationEpoch := testhelpers.EpochFromNow(2 * time.Hour)
+		accessLimit := 10
+		metadata, recorded, err := sut(newSecret(), ownerToken, models.SecretUpdate{
+			ExpirationEpoch: &expirationEpoch,
+			AccessLimit:     &accessLimit,
+		}, 1)
+		require.NoError(t, err)
+
+		t.Run("it should return the new expiration", func(t *testing.T) {
+			assert.Equal(t, expirationEpoch, metadata.Expiration.Time().Unix())
+		})
+
+		t.Run("it should return the new access limit", func(t *testing.T) {
+			assert.Equal(t, accessLimit, metadata.AccessLimit)
+		})
+
+		t.Run("it should record the change in the history", func(t *testing.T) {
+			require.Len(t, recorded, 1)
+			assert.Equal(t, models.SecretHistoryEventType(models.SecretHistoryModified), recorded[0].Type)
+			assert.Equal(t, expirationEpoch, recorded[0].ExpirationEpoch)
+			assert.Equal(t, accessLimit, *recorded[0].AccessLimit)
+		})
+	})
+
+	t.Run("when owner token does not match", func(t *testing.T) {
+		expirationEpoch := testhelpers.EpochFromNow(2 * time.Hour)
+		_, _, err := sut(newSecret(), testhelpers.RandomId(t), models.SecretUpdate{ExpirationEpoch: &expirationEpoch}, 0)
+
+		t.Run("it should return forbidden error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsForbiddenError(err), "expected forbidden error")
+		})
+	})
+
+	t.Run("when the secret has no owner token", func(t *testing.T) {
+		secret := newSecret()
+		secret.OwnerTokenHash = ""
+		expirationEpoch := testhelpers.EpochFromNow(2 * time.Hour)
+		_, _, err := sut(secret, "", models.SecretUpdate{ExpirationEpoch: &expirationEpoch}, 0)
+
+		t.Run("it should return forbidden error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsForbiddenError(err), "expected forbidden error")
+		})
+	})
+
+	t.Run("when nothing is updated", func(t *testing.T) {
+		_, _, err := sut(newSecret(), ownerToken, models.SecretUpdate{}, 0)
+
+		t.Run("it should return validation error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsValidationError(err), "expected validation error")
+		})
+	})
+
+	t.Run("when expiration exceeds the maximum", func(t *testing.T) {
+		expirationEpoch := testhelpers.EpochFromNow(8 * 24 * time.Hour)
+		_, _, err := sut(newSecret(), ownerToken, models.SecretUpdate{ExpirationEpoch: &expirationEpoch}, 0)
+
+		t.Run("it should return validation error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsValidationError(err), "expected validation error")
+		})
+	})
+
+	t.Run("when access limit is not above the current access count", func(t *testing.T) {
+		accessLimit := 2
+		_, _, err := sut(newSecret(), ownerToken, models.SecretUpdate{AccessLimit: &accessLimit}, 0)
+
+		t.Run("it should return validation error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsValidationError(err), "expected validation error")
+		})
+	})
+}
+
+func TestWhenGettingSecretHistory(t *testing.T) {
+	ownerToken := testhelpers.RandomId(t)
+	ownerTokenHash := sha256.Sum256([]byte(ownerToken))
+
+	secret := models.Secret{
+		ID:              testhelpers.RandomId(t),
+		OwnerTokenHash:  hex.EncodeToString(ownerTokenHash[:]),
+		ExpirationEpoch: testhelpers.EpochFromNow(time.Hour),
+		Creator:         "alice@example.com",
+	}
+	history := []models.SecretHistoryEvent{
+		{Type: models.SecretHistoryAccessed, OccurredAt: time.Now().UTC(), AccessCount: 1},
+	}
+
+	sut := func(owner models.OwnerCredentials, readHistoryCallTimes int) ([]models.SecretHistoryEvent, error) {
+		ctrl := gomock.NewController(t)
+		dataStore := newMockDataStore(ctrl)
+		dataStore.EXPECT().
+			ReadSecret(gomock.Any(), secret.ID).
+			Return(&secret)
+		dataStore.EXPECT().
+			ReadSecretHistory(gomock.Any(), secret.ID).
+			Return(history, nil).
+			Times(readHistoryCallTimes)
+
+		return commands.GetSecretHistory(context.Background(), dataStore, secret.ID, owner)
+	}
+
+	t.Run("when owner token matches", func(t *testing.T) {
+		events, err := sut(models.OwnerCredentials{OwnerToken: ownerToken}, 1)
+		require.NoError(t, err)
+
+		t.Run("it should return the history", func(t *testing.T) {
+			assert.Equal(t, history, events)
+		})
+	})
+
+	t.Run("when owner token does not match", func(t *testing.T) {
+		_, err := sut(models.OwnerCredentials{OwnerToken: testhelpers.RandomId(t)}, 0)
+
+		t.Run("it should return forbidden error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsForbiddenError(err), "expected forbidden error")
+		})
+	})
+
+	t.Run("when the caller is the creator of the secret", func(t *testing.T) {
+		events, err := sut(models.OwnerCredentials{Subject: secret.Creator}, 1)
+		require.NoError(t, err)
+
+		t.Run("it should return the history", func(t *testing.T) {
+			assert.Equal(t, history, events)
+		})
+	})
+
+	t.Run("when the caller is another user", func(t *testing.T) {
+		_, err := sut(models.OwnerCredentials{Subject: "mallory@example.com"}, 0)
+
+		t.Run("it should return forbidden error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsForbiddenError(err), "expected forbidden error")
+		})
+	})
+}
+
+// decryptsTo returns a fresh copy of content on every call, as content returned by Decrypt is wiped once it is used.
+// keyIds derives key IDs the way the datastore does.
+var keyIds, _ = keyid.NewHasher("pepper")
+
+// newMockDataStore returns a datastore mock that derives key IDs the way the datastore does.
+func newMockDataStore(ctrl *gomock.Controller) *mocks.MockDataStore {
+	dataStore := mocks.NewMockDataStore(ctrl)
+	dataStore.EXPECT().KeyID(gomock.Any()).DoAndReturn(keyIds.Hash).AnyTimes()
+	return dataStore
+}
+
+func decryptsTo(content []byte) func(context.Context, string) ([]byte, error) {
+	return func(context.Context, string) ([]byte, error) {
+		return append([]byte{}, content...), nil
+	}
+}
@@ -0,0 +1,227 @@
+package commands
+
+import (
+	"cellar/pkg/cryptography"
+	"cellar/pkg/datastore"
+	pkgerrors "cellar/pkg/errors"
+	"cellar/pkg/models"
+	"cellar/pkg/notifications"
+	"cellar/pkg/plaintext"
+	"cellar/pkg/settings"
+	"cellar/pkg/shamir"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const maxSplitShares = 16
+
+func getSplitLogger(dataStore datastore.DataStore, splitId string) *log.Entry {
+	return log.WithFields(log.Fields{
+		"context": "split commands",
+		"splitId": dataStore.KeyID(splitId),
+	})
+}
+
+// CreateSplitSecret splits the secret content into shares with Shamir's secret sharing, so that
+// threshold of them are needed to reconstruct it. Each share is created as its own secret, hex encoded,
+// with the access limit, expiration and other parameters of the given secret.
+// Returns the split metadata, including the ID and owner token of every share.
+// Validation errors are returned as ValidationError types.
+// The context can be used to cancel the operation before completion.
+func CreateSplitSecret(ctx context.Context, appConfig settings.IAppConfiguration, dataStore datastore.DataStore, encryption cryptography.Encryption, secret models.Secret, shareCount int, threshold int) (*models.SplitSecretMetadata, error) {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return nil, err
+	}
+
+	if shareCount < 2 || shareCount > maxSplitShares {
+		return nil, pkgerrors.NewValidationError(fmt.Sprintf("shares must be between 2 and %d", maxSplitShares))
+	}
+	if threshold < 2 || threshold > shareCount {
+		return nil, pkgerrors.NewValidationError("threshold must be between 2 and the number of shares")
+	}
+
+	parts, err := shamir.Split(secret.Content, shareCount, threshold)
+	if err != nil {
+		return nil, pkgerrors.NewValidationError(err.Error())
+	}
+	defer wipeParts(parts)
+
+	id, err := randomId()
+	if err != nil {
+		return nil, err
+	}
+
+	logger := getSplitLogger(dataStore, id).WithFields(log.Fields{
+		"splitShares":    shareCount,
+		"splitThreshold": threshold,
+	})
+	logger.Info("Creating split secret shares")
+
+	split := models.SplitSecret{
+		ID:              id,
+		ContentType:     secret.ContentType,
+		Filename:        secret.Filename,
+		Threshold:       threshold,
+		ExpirationEpoch: secret.ExpirationEpoch,
+	}
+	shares := make([]models.SplitSecretShare, 0, shareCount)
+
+	deleteShares := func() {
+		for _, share := range shares {
+			if _, err := dataStore.DeleteSecret(ctx, share.ID); err != nil {
+				logger.WithError(err).WithField("secretId", dataStore.KeyID(share.ID)).Error("Error deleting split secret share")
+			}
+		}
+	}
+
+	for _, part := range parts {
+		share := secret
+		share.Content = plaintext.Alloc(hex.EncodedLen(len(part)))
+		hex.Encode(share.Content, part)
+		share.ContentType = models.ContentTypeText
+		share.Filename = ""
+		share.BundleItems = nil
+
+		metadata, err := CreateSecret(ctx, appConfig, dataStore, encryption, share)
+		plaintext.Wipe(share.Content)
+		if err != nil {
+			deleteShares()
+			return nil, err
+		}
+
+		split.ShareKeys = append(split.ShareKeys, dataStore.KeyID(metadata.ID))
+		shares = append(shares, models.SplitSecretShare{
+			ID:         metadata.ID,
+			OwnerToken: metadata.OwnerToken,
+		})
+	}
+
+	logger.Info("Writing split secret to datastore")
+	if err = dataStore.WriteSplitSecret(ctx, split); err != nil {
+		logger.WithError(err).Error("Error writing split secret to datastore")
+		deleteShares()
+		return nil, err
+	}
+
+	metadata := split.Metadata()
+	metadata.Shares = shares
+	return metadata, nil
+}
+
+// GetSplitSecretMetadata retrieves metadata for a split secret. The share IDs are never included.
+// Returns the metadata or nil if the split secret is not found.
+// The context can be used to cancel the operation before completion.
+func GetSplitSecretMetadata(ctx context.Context, dataStore datastore.DataStore, id string) *models.SplitSecretMetadata {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return nil
+	}
+
+	getSplitLogger(dataStore, id).Info("Querying for split secret metadata")
+
+	split := dataStore.ReadSplitSecret(ctx, id)
+	if split == nil {
+		return nil
+	}
+
+	return split.Metadata()
+}
+
+// CombineSplitSecret reconstructs the content of a split secret from the presented share IDs.
+// At least threshold distinct shares of the split must be presented and still be available to the client;
+// otherwise a ValidationError is returned before any share is accessed. Exactly threshold shares
+// are then accessed, consuming an access of each like any other secret.
+// Returns the reconstructed secret or nil if the split secret is not found.
+// The context can be used to cancel the operation before completion.
+func CombineSplitSecret(ctx context.Context, dataStore datastore.DataStore, encryption cryptography.Encryption, notifier notifications.Notifier, id string, shareIds []string, client models.Client) (*models.Secret, error) {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return nil, err
+	}
+
+	split := dataStore.ReadSplitSecret(ctx, id)
+	if split == nil {
+		return nil, nil
+	}
+
+	logger := getSplitLogger(dataStore, id)
+
+	presented := make([]string, 0, len(shareIds))
+	seen := make(map[string]bool, len(shareIds))
+	for _, shareId := range shareIds {
+		if shareId == "" || seen[shareId] {
+			continue
+		}
+		if !split.HasShareKey(dataStore.KeyID(shareId)) {
+			logger.Warn("Rejected combine with a secret that is not a share of the split")
+			return nil, pkgerrors.NewValidationError("share_ids must only contain shares of this split secret")
+		}
+		seen[shareId] = true
+		presented = append(presented, shareId)
+	}
+
+	if len(presented) < split.Threshold {
+		return nil, pkgerrors.NewValidationError(fmt.Sprintf("at least %d shares are required", split.Threshold))
+	}
+
+	// Check the shares before accessing any, so a failed combine does not use up the shares that remain.
+	available := make([]string, 0, split.Threshold)
+	now := time.Now()
+	for _, shareId := range presented {
+		if len(available) == split.Threshold {
+			break
+		}
+		if share := dataStore.ReadSecret(ctx, shareId); share != nil && share.IsAvailable(now) && clientAllowed(share, client) {
+			available = append(available, shareId)
+		}
+	}
+
+	if len(available) < split.Threshold {
+		logger.WithField("splitAvailableShares", len(available)).Info("Rejected combine with too few available shares")
+		return nil, pkgerrors.NewValidationError(fmt.Sprintf("only %d of the %d required shares are still available", len(available), split.Threshold))
+	}
+
+	parts := make([][]byte, 0, split.Threshold)
+	defer func() { wipeParts(parts) }()
+	for _, shareId := range available {
+		share, err := AccessSecret(ctx, dataStore, encryption, notifier, models.AccessRequest{ID: shareId, Client: client})
+		if err != nil {
+			return nil, err
+		}
+		if share == nil {
+			return nil, pkgerrors.NewValidationError("a share expired or was used while combining")
+		}
+
+		part := plaintext.Alloc(hex.DecodedLen(len(share.Content)))
+		_, err = hex.Decode(part, share.Content)
+		plaintext.Wipe(share.Content)
+		if err != nil {
+			plaintext.Wipe(part)
+			logger.WithError(err).Error("Error decoding split secret share")
+			return nil, err
+		}
+		parts = append(parts, part)
+	}
+
+	content, err := shamir.Combine(parts)
+	if err != nil {
+		logger.WithError(err).Error("Error combining split secret shares")
+		return nil, err
+	}
+
+	logger.Info("Combined split secret")
+	return &models.Secret{
+		ID:          id,
+		Content:     content,
+		ContentType: split.ContentType,
+		Filename:    split.Filename,
+	}, nil
+}
+
+func wipeParts(parts [][]byte) {
+	for _, part := range parts {
+		plaintext.Wipe(part)
+	}
+}
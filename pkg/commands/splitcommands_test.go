@@ -0,0 +1,240 @@
+package commands_test
+
+import (
+	"cellar/pkg/commands"
+	pkgerrors "cellar/pkg/errors"
+	"cellar/pkg/mocks"
+	"cellar/pkg/models"
+	"cellar/pkg/shamir"
+	"cellar/testing/testhelpers"
+	"context"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestWhenCreatingASplitSecret(t *testing.T) {
+	secret := models.Secret{
+		Content:         []byte("break glass credentials"),
+		ContentType:     models.ContentTypeText,
+		AccessLimit:     1,
+		ExpirationEpoch: testhelpers.EpochFromNow(time.Hour),
+		Creator:         "alice",
+	}
+
+	sut := func(shares, threshold int, writeSecretCallTimes int, writeSplitErr error, deleteCallTimes int) (*models.SplitSecretMetadata, []models.Secret, *models.SplitSecret, error) {
+		ctrl := gomock.NewController(t)
+
+		appConfig := mocks.NewMockIAppConfiguration(ctrl)
+		appConfig.EXPECT().MaxAccessCount().Return(100).AnyTimes()
+		appConfig.EXPECT().MaxExpirationSeconds().Return(604800).AnyTimes()
+		appConfig.EXPECT().AllowedCIDRs().Return(nil).AnyTimes()
+		appConfig.EXPECT().PaddingBuckets().Return(nil).AnyTimes()
+
+		encryption := mocks.NewMockEncryption(ctrl)
+		encryption.EXPECT().
+			Encrypt(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, content []byte) (string, error) {
+				return string(content), nil
+			}).
+			AnyTimes()
+
+		var writtenShares []models.Secret
+		var writtenSplit models.SplitSecret
+		dataStore := newMockDataStore(ctrl)
+		dataStore.EXPECT().
+			WriteSecret(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, secret models.Secret) error {
+				writtenShares = append(writtenShares, secret)
+				return nil
+			}).
+			Times(writeSecretCallTimes)
+		dataStore.EXPECT().
+			WriteSplitSecret(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, split models.SplitSecret) error {
+				writtenSplit = split
+				return writeSplitErr
+			}).
+			MaxTimes(1)
+		dataStore.EXPECT().
+			DeleteSecret(gomock.Any(), gomock.Any()).
+			Return(true, nil).
+			Times(deleteCallTimes)
+
+		metadata, err := commands.CreateSplitSecret(context.Background(), appConfig, dataStore, encryption, secret, shares, threshold)
+		return metadata, writtenShares, &writtenSplit, err
+	}
+
+	t.Run("when all parameters are valid", func(t *testing.T) {
+		metadata, shares, split, err := sut(3, 2, 3, nil, 0)
+		require.NoError(t, err)
+
+		t.Run("it should store each share as a secret with the given limits", func(t *testing.T) {
+			require.Len(t, shares, 3)
+			for _, share := range shares {
+				assert.Equal(t, models.ContentTypeText, share.ContentType)
+				assert.Equal(t, secret.AccessLimit, share.AccessLimit)
+				assert.Equal(t, secret.ExpirationEpoch, share.ExpirationEpoch)
+			}
+		})
+
+		t.Run("it should record the creator and creation time of each share", func(t *testing.T) {
+			for _, share := range shares {
+				assert.Equal(t, "alice", share.Creator)
+				assert.NotZero(t, share.CreatedEpoch)
+			}
+		})
+
+		t.Run("it should store shares that reconstruct the content", func(t *testing.T) {
+			parts := make([][]byte, 0, 2)
+			for _, share := range shares[1:] {
+				part, err := hex.DecodeString(share.CipherText)
+				require.NoError(t, err)
+				parts = append(parts, part)
+			}
+
+			content, err := shamir.Combine(parts)
+			require.NoError(t, err)
+			assert.Equal(t, secret.Content, content)
+		})
+
+		t.Run("it should track the shares in the split secret by their key ids", func(t *testing.T) {
+			assert.Equal(t, 2, split.Threshold)
+			assert.Equal(t, []string{keyIds.Hash(shares[0].ID), keyIds.Hash(shares[1].ID), keyIds.Hash(shares[2].ID)}, split.ShareKeys)
+		})
+
+		t.Run("it should return the ID and owner token of each share", func(t *testing.T) {
+			require.Len(t, metadata.Shares, 3)
+			assert.Equal(t, shares[0].ID, metadata.Shares[0].ID)
+			assert.NotEmpty(t, metadata.Shares[0].OwnerToken)
+		})
+	})
+
+	t.Run("when the threshold exceeds the number of shares", func(t *testing.T) {
+		_, _, _, err := sut(2, 3, 0, nil, 0)
+
+		t.Run("it should return validation error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsValidationError(err), "expected validation error")
+		})
+	})
+
+	t.Run("when the split secret cannot be written", func(t *testing.T) {
+		_, _, _, err := sut(3, 2, 3, errors.New("datastore unavailable"), 3)
+
+		t.Run("it should delete the shares", func(t *testing.T) {
+			assert.Error(t, err)
+		})
+	})
+}
+
+func TestWhenCombiningASplitSecret(t *testing.T) {
+	content := []byte("break glass credentials")
+	parts, err := shamir.Split(content, 3, 2)
+	require.NoError(t, err)
+
+	split := models.SplitSecret{
+		ID:              testhelpers.RandomId(t),
+		ContentType:     models.ContentTypeText,
+		Threshold:       2,
+		ExpirationEpoch: testhelpers.EpochFromNow(time.Hour),
+	}
+	shares := make(map[string]*models.Secret, len(parts))
+	var shareIds []string
+	for _, part := range parts {
+		share := &models.Secret{
+			ID:              testhelpers.RandomId(t),
+			CipherText:      hex.EncodeToString(part),
+			ContentType:     models.ContentTypeText,
+			AccessLimit:     1,
+			ExpirationEpoch: split.ExpirationEpoch,
+		}
+		shares[share.ID] = share
+		shareIds = append(shareIds, share.ID)
+		split.ShareKeys = append(split.ShareKeys, keyIds.Hash(share.ID))
+	}
+
+	sut := func(shareIds []string, missing map[string]bool, accessCallTimes int) (*models.Secret, error) {
+		ctrl := gomock.NewController(t)
+
+		encryption := mocks.NewMockEncryption(ctrl)
+		encryption.EXPECT().
+			Decrypt(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, cipherText string) ([]byte, error) {
+				return []byte(cipherText), nil
+			}).
+			Times(accessCallTimes)
+
+		dataStore := newMockDataStore(ctrl)
+		dataStore.EXPECT().
+			ReadSplitSecret(gomock.Any(), split.ID).
+			Return(&split)
+		dataStore.EXPECT().
+			ReadSecret(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, id string) *models.Secret {
+				if missing[id] {
+					return nil
+				}
+				return shares[id]
+			}).
+			AnyTimes()
+		dataStore.EXPECT().
+			IncreaseAccessCount(gomock.Any(), gomock.Any()).
+			Return(int64(1), nil).
+			Times(accessCallTimes)
+		dataStore.EXPECT().
+			DeleteSecret(gomock.Any(), gomock.Any()).
+			Return(true, nil).
+			Times(accessCallTimes)
+
+		notifier := mocks.NewMockNotifier(ctrl)
+		return commands.CombineSplitSecret(context.Background(), dataStore, encryption, notifier, split.ID, shareIds, models.Client{})
+	}
+
+	t.Run("and enough shares are presented", func(t *testing.T) {
+		response, err := sut(shareIds, nil, 2)
+		require.NoError(t, err)
+
+		t.Run("it should reconstruct the content", func(t *testing.T) {
+			assert.Equal(t, content, response.Content)
+			assert.Equal(t, split.ID, response.ID)
+		})
+	})
+
+	t.Run("and a presented share is no longer available", func(t *testing.T) {
+		response, err := sut(shareIds, map[string]bool{shareIds[0]: true}, 2)
+		require.NoError(t, err)
+
+		t.Run("it should reconstruct the content from the remaining shares", func(t *testing.T) {
+			assert.Equal(t, content, response.Content)
+		})
+	})
+
+	t.Run("and too few shares are available", func(t *testing.T) {
+		_, err := sut(shareIds[:2], map[string]bool{shareIds[0]: true}, 0)
+
+		t.Run("it should return validation error without accessing any share", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsValidationError(err), "expected validation error")
+		})
+	})
+
+	t.Run("and the same share is presented twice", func(t *testing.T) {
+		_, err := sut([]string{shareIds[0], shareIds[0]}, nil, 0)
+
+		t.Run("it should return validation error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsValidationError(err), "expected validation error")
+		})
+	})
+
+	t.Run("and a presented secret is not a share of the split", func(t *testing.T) {
+		_, err := sut([]string{shareIds[0], testhelpers.RandomId(t)}, nil, 0)
+
+		t.Run("it should return validation error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsValidationError(err), "expected validation error")
+		})
+	})
+}
@@ -0,0 +1,156 @@
+package commands
+
+import (
+	"cellar/pkg/datastore"
+	pkgerrors "cellar/pkg/errors"
+	"cellar/pkg/models"
+	"cellar/pkg/notifications"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+const (
+	// verificationCodeDigits is the length of the numeric codes sent to recipients.
+	verificationCodeDigits = 6
+
+	// verificationCodeTTL is how long a verification code can be used after it is sent.
+	verificationCodeTTL = 10 * time.Minute
+
+	// maxVerificationAttempts caps the attempts to use the verification codes of a secret within the attempt window.
+	// Requesting a new code does not reset the count, so the codes cannot be guessed by requesting more of them.
+	maxVerificationAttempts = 5
+
+	// verificationAttemptWindow is how long attempts are counted, from the first attempt.
+	verificationAttemptWindow = time.Hour
+)
+
+// RequestVerificationCode sends a new verification code to the recipient of a secret.
+// Secrets that do not require recipient verification return a ValidationError, and clients outside the allowlist
+// or country restrictions of the secret get a ForbiddenError, so no code is sent to them.
+// While a code that has not expired is pending, no new code is sent and a ConflictError is returned.
+// Returns false if the secret is not found.
+// The context can be used to cancel the operation before completion.
+func RequestVerificationCode(ctx context.Context, dataStore datastore.DataStore, notifier notifications.Notifier, request models.AccessRequest) (bool, error) {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return false, err
+	}
+
+	id := request.ID
+
+	secret := dataStore.ReadSecret(ctx, id)
+	if secret == nil {
+		return false, nil
+	}
+
+	logger := getLogger(dataStore, id)
+
+	if secret.RecipientEmail == "" {
+		return true, pkgerrors.NewValidationError("secret does not require recipient verification")
+	}
+
+	if !clientAllowed(secret, request.Client) {
+		logger.WithField("clientIP", request.Client.IP).
+			Warn("Rejected verification code request from a client the secret is restricted from")
+		return true, pkgerrors.NewForbiddenError("this secret cannot be accessed from this client")
+	}
+
+	code, err := newVerificationCode()
+	if err != nil {
+		return true, err
+	}
+
+	logger.Info("Writing new secret verification code")
+	written, err := dataStore.WriteVerificationCode(ctx, id, hashVerificationCode(id, code), verificationCodeTTL)
+	if err != nil {
+		logger.WithError(err).Error("Error writing secret verification code")
+		return true, err
+	}
+	if !written {
+		logger.Warn("Rejected verification code request while a code is pending")
+		return true, pkgerrors.NewConflictError("a verification code was already sent and has not expired")
+	}
+
+	now := time.Now().UTC()
+	logger.Info("Sending secret verification code")
+	err = notifier.Notify(ctx, models.Notification{
+		Type:       models.NotificationVerificationCode,
+		Recipient:  secret.RecipientEmail,
+		Reference:  models.SecretReference(id),
+		Code:       code,
+		OccurredAt: now,
+		ExpiresAt:  now.Add(verificationCodeTTL),
+	})
+	if err != nil {
+		logger.WithError(err).Error("Error sending secret verification code")
+		return true, err
+	}
+
+	return true, nil
+}
+
+// verifyRecipient checks the verification code presented to access a secret against its pending code.
+// A code can only be used once; a missing, expired, wrong or already used code, or a code presented after too many
+// attempts, returns an InvalidCredentialError.
+func verifyRecipient(ctx context.Context, dataStore datastore.DataStore, id string, code string) error {
+	logger := getLogger(dataStore, id)
+
+	if code == "" {
+		logger.Warn("Rejected access to secret without a verification code")
+		return pkgerrors.NewInvalidCredentialError("a verification code sent to the recipient is required to access this secret")
+	}
+
+	codeHash, attempts, err := dataStore.ReadVerificationCode(ctx, id, verificationAttemptWindow)
+	if err != nil {
+		logger.WithError(err).Error("Error reading secret verification code")
+		return err
+	}
+
+	if attempts > maxVerificationAttempts {
+		logger.Warn("Rejected access to secret after too many verification attempts")
+		return pkgerrors.NewInvalidCredentialError("too many attempts, try again later")
+	}
+
+	if codeHash == "" {
+		logger.Warn("Rejected access to secret without a pending verification code")
+		return pkgerrors.NewInvalidCredentialError("the verification code has expired or was never requested")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashVerificationCode(id, code)), []byte(codeHash)) != 1 {
+		logger.WithField("verificationAttempts", attempts).
+			Warn("Rejected access to secret with a wrong verification code")
+		return pkgerrors.NewInvalidCredentialError("invalid verification code")
+	}
+
+	consumed, err := dataStore.ConsumeVerificationCode(ctx, id, codeHash)
+	if err != nil {
+		logger.WithError(err).Error("Error consuming secret verification code")
+		return err
+	}
+	if !consumed {
+		logger.Warn("Rejected access to secret with a verification code that was already used")
+		return pkgerrors.NewInvalidCredentialError("the verification code has already been used")
+	}
+	return nil
+}
+
+// newVerificationCode generates a random numeric code, keeping leading zeros.
+func newVerificationCode() (string, error) {
+	limit := new(big.Int).Exp(big.NewInt(10), big.NewInt(verificationCodeDigits), nil)
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", verificationCodeDigits, n), nil
+}
+
+// hashVerificationCode binds the code to the secret, so a code hash is of no use for any other secret.
+func hashVerificationCode(id string, code string) string {
+	sum := sha256.Sum256([]byte(id + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,322 @@
+package commands_test
+
+import (
+	"cellar/pkg/commands"
+	pkgerrors "cellar/pkg/errors"
+	"cellar/pkg/mocks"
+	"cellar/pkg/models"
+	"cellar/testing/testhelpers"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestWhenRequestingAVerificationCode(t *testing.T) {
+	secret := models.Secret{
+		ID:              testhelpers.RandomId(t),
+		CipherText:      testhelpers.RandomId(t),
+		ContentType:     models.ContentTypeText,
+		RecipientEmail:  "recipient@example.com",
+		ExpirationEpoch: testhelpers.EpochFromNow(time.Hour),
+	}
+
+	t.Run("and the secret requires recipient verification", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		var codeHash string
+		var codeTTL time.Duration
+		dataStore := newMockDataStore(ctrl)
+		dataStore.EXPECT().
+			ReadSecret(gomock.Any(), secret.ID).
+			Return(&secret)
+		dataStore.EXPECT().
+			WriteVerificationCode(gomock.Any(), secret.ID, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, hash string, ttl time.Duration) (bool, error) {
+				codeHash = hash
+				codeTTL = ttl
+				return true, nil
+			})
+
+		var sent models.Notification
+		notifier := mocks.NewMockNotifier(ctrl)
+		notifier.EXPECT().
+			Notify(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, notification models.Notification) error {
+				sent = notification
+				return nil
+			})
+
+		found, err := commands.RequestVerificationCode(context.Background(), dataStore, notifier, models.AccessRequest{ID: secret.ID})
+		require.NoError(t, err)
+
+		t.Run("it should find the secret", func(t *testing.T) {
+			assert.True(t, found)
+		})
+
+		t.Run("it should email a six digit code to the recipient", func(t *testing.T) {
+			assert.Equal(t, models.NotificationType(models.NotificationVerificationCode), sent.Type)
+			assert.Equal(t, secret.RecipientEmail, sent.Recipient)
+			assert.Regexp(t, `^[0-9]{6}$`, sent.Code)
+		})
+
+		t.Run("it should only store a hash of the code", func(t *testing.T) {
+			assert.NotEmpty(t, codeHash)
+			assert.NotContains(t, codeHash, sent.Code)
+		})
+
+		t.Run("it should store the code for as long as it is valid", func(t *testing.T) {
+			assert.Equal(t, 10*time.Minute, codeTTL)
+			assert.WithinDuration(t, time.Now().Add(codeTTL), sent.ExpiresAt, time.Minute)
+		})
+	})
+
+	t.Run("and the secret does not require recipient verification", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		plain := secret
+		plain.RecipientEmail = ""
+		dataStore := newMockDataStore(ctrl)
+		dataStore.EXPECT().
+			ReadSecret(gomock.Any(), secret.ID).
+			Return(&plain)
+
+		notifier := mocks.NewMockNotifier(ctrl)
+		notifier.EXPECT().Notify(gomock.Any(), gomock.Any()).Times(0)
+
+		_, err := commands.RequestVerificationCode(context.Background(), dataStore, notifier, models.AccessRequest{ID: secret.ID})
+
+		t.Run("it should return validation error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsValidationError(err), "expected validation error")
+		})
+	})
+
+	t.Run("and the client is outside the allowlist of the secret", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		restricted := secret
+		restricted.AllowedCIDRs = []string{"10.0.0.0/8"}
+		dataStore := newMockDataStore(ctrl)
+		dataStore.EXPECT().
+			ReadSecret(gomock.Any(), secret.ID).
+			Return(&restricted)
+
+		notifier := mocks.NewMockNotifier(ctrl)
+		notifier.EXPECT().Notify(gomock.Any(), gomock.Any()).Times(0)
+
+		_, err := commands.RequestVerificationCode(context.Background(), dataStore, notifier, models.AccessRequest{
+			ID:     secret.ID,
+			Client: models.Client{IP: "192.168.1.1"},
+		})
+
+		t.Run("it should return forbidden error without sending a code", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsForbiddenError(err), "expected forbidden error")
+		})
+	})
+
+	t.Run("and the secret does not exist", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		dataStore := newMockDataStore(ctrl)
+		dataStore.EXPECT().
+			ReadSecret(gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		notifier := mocks.NewMockNotifier(ctrl)
+
+		found, err := commands.RequestVerificationCode(context.Background(), dataStore, notifier, models.AccessRequest{ID: testhelpers.RandomId(t)})
+
+		t.Run("it should return not found", func(t *testing.T) {
+			assert.NoError(t, err)
+			assert.False(t, found)
+		})
+	})
+
+	t.Run("and a code that has not expired is pending", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		dataStore := newMockDataStore(ctrl)
+		dataStore.EXPECT().
+			ReadSecret(gomock.Any(), secret.ID).
+			Return(&secret)
+		dataStore.EXPECT().
+			WriteVerificationCode(gomock.Any(), secret.ID, gomock.Any(), gomock.Any()).
+			Return(false, nil)
+
+		notifier := mocks.NewMockNotifier(ctrl)
+		notifier.EXPECT().Notify(gomock.Any(), gomock.Any()).Times(0)
+
+		_, err := commands.RequestVerificationCode(context.Background(), dataStore, notifier, models.AccessRequest{ID: secret.ID})
+
+		t.Run("it should return conflict error without sending a code", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsConflictError(err), "expected conflict error")
+		})
+	})
+
+	t.Run("and sending the code fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		dataStore := newMockDataStore(ctrl)
+		dataStore.EXPECT().
+			ReadSecret(gomock.Any(), secret.ID).
+			Return(&secret)
+		dataStore.EXPECT().
+			WriteVerificationCode(gomock.Any(), secret.ID, gomock.Any(), gomock.Any()).
+			Return(true, nil)
+
+		notifier := mocks.NewMockNotifier(ctrl)
+		notifier.EXPECT().
+			Notify(gomock.Any(), gomock.Any()).
+			Return(errors.New("notification queue is full"))
+
+		_, err := commands.RequestVerificationCode(context.Background(), dataStore, notifier, models.AccessRequest{ID: secret.ID})
+
+		t.Run("it should return error", func(t *testing.T) {
+			assert.Error(t, err)
+		})
+	})
+}
+
+func TestWhenAccessingASecretThatRequiresRecipientVerification(t *testing.T) {
+	secret := models.Secret{
+		ID:              testhelpers.RandomId(t),
+		Content:         []byte(testhelpers.RandomId(t)),
+		CipherText:      testhelpers.RandomId(t),
+		ContentType:     models.ContentTypeText,
+		RecipientEmail:  "recipient@example.com",
+		AccessLimit:     10,
+		ExpirationEpoch: testhelpers.EpochFromNow(time.Hour),
+	}
+
+	// requestCode sends a code through the command and returns it along with the hash that was stored.
+	requestCode := func(t *testing.T) (code string, codeHash string) {
+		ctrl := gomock.NewController(t)
+
+		dataStore := newMockDataStore(ctrl)
+		dataStore.EXPECT().
+			ReadSecret(gomock.Any(), secret.ID).
+			Return(&secret)
+		dataStore.EXPECT().
+			WriteVerificationCode(gomock.Any(), secret.ID, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, hash string, _ time.Duration) (bool, error) {
+				codeHash = hash
+				return true, nil
+			})
+
+		notifier := mocks.NewMockNotifier(ctrl)
+		notifier.EXPECT().
+			Notify(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, notification models.Notification) error {
+				code = notification.Code
+				return nil
+			})
+
+		_, err := commands.RequestVerificationCode(context.Background(), dataStore, notifier, models.AccessRequest{ID: secret.ID})
+		require.NoError(t, err)
+		return code, codeHash
+	}
+
+	sut := func(code string, storedHash string, attempts int64, consumed bool, consumeTimes int, callTimes int) (*models.Secret, error) {
+		ctrl := gomock.NewController(t)
+
+		encryption := mocks.NewMockEncryption(ctrl)
+		encryption.EXPECT().
+			Decrypt(gomock.Any(), secret.CipherText).
+			DoAndReturn(decryptsTo(secret.Content)).
+			Times(callTimes)
+
+		dataStore := newMockDataStore(ctrl)
+		dataStore.EXPECT().
+			AppendSecretHistory(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil).
+			AnyTimes()
+		dataStore.EXPECT().
+			ReadSecret(gomock.Any(), secret.ID).
+			Return(&secret)
+		dataStore.EXPECT().
+			ReadVerificationCode(gomock.Any(), secret.ID, time.Hour).
+			Return(storedHash, attempts, nil).
+			MaxTimes(1)
+		dataStore.EXPECT().
+			ConsumeVerificationCode(gomock.Any(), secret.ID, storedHash).
+			Return(consumed, nil).
+			Times(consumeTimes)
+		dataStore.EXPECT().
+			IncreaseAccessCount(gomock.Any(), secret.ID).
+			Return(int64(1), nil).
+			Times(callTimes)
+
+		notifier := mocks.NewMockNotifier(ctrl)
+		return commands.AccessSecret(context.Background(), dataStore, encryption, notifier, models.AccessRequest{ID: secret.ID, VerificationCode: code})
+	}
+
+	t.Run("and the code matches", func(t *testing.T) {
+		code, codeHash := requestCode(t)
+		response, err := sut(code, codeHash, 1, true, 1, 1)
+		require.NoError(t, err)
+
+		t.Run("it should return the content and use up the code", func(t *testing.T) {
+			assert.Equal(t, secret.Content, response.Content)
+		})
+	})
+
+	t.Run("and the code does not match", func(t *testing.T) {
+		code, codeHash := requestCode(t)
+		wrongCode := "000000"
+		if code == wrongCode {
+			wrongCode = "000001"
+		}
+		response, err := sut(wrongCode, codeHash, 1, false, 0, 0)
+
+		t.Run("it should return forbidden error without consuming an access", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsForbiddenError(err), "expected forbidden error")
+			assert.Nil(t, response)
+		})
+
+		t.Run("it should report invalid credentials", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsInvalidCredentialError(err), "expected invalid credential error")
+		})
+	})
+
+	t.Run("and the code is missing", func(t *testing.T) {
+		response, err := sut("", "", 0, false, 0, 0)
+
+		t.Run("it should return forbidden error without consuming an access", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsForbiddenError(err), "expected forbidden error")
+			assert.Nil(t, response)
+		})
+	})
+
+	t.Run("and no code is pending", func(t *testing.T) {
+		response, err := sut("123456", "", 1, false, 0, 0)
+
+		t.Run("it should return forbidden error without consuming an access", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsForbiddenError(err), "expected forbidden error")
+			assert.Nil(t, response)
+		})
+	})
+
+	t.Run("and the code was tried too many times", func(t *testing.T) {
+		code, codeHash := requestCode(t)
+		response, err := sut(code, codeHash, 6, false, 0, 0)
+
+		t.Run("it should return forbidden error, even for the right code", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsForbiddenError(err), "expected forbidden error")
+			assert.Nil(t, response)
+		})
+	})
+
+	t.Run("and the code was used by a concurrent access", func(t *testing.T) {
+		code, codeHash := requestCode(t)
+		response, err := sut(code, codeHash, 1, false, 1, 0)
+
+		t.Run("it should return forbidden error without consuming an access", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsForbiddenError(err), "expected forbidden error")
+			assert.Nil(t, response)
+		})
+	})
+}
@@ -0,0 +1,188 @@
+package admin
+
+import (
+	"cellar/pkg/commands"
+	"cellar/pkg/controllers"
+	"cellar/pkg/datastore"
+	pkgerrors "cellar/pkg/errors"
+	"cellar/pkg/models"
+	"cellar/pkg/tenants"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Get Secret Stats. Counts the live secrets of the caller's tenant and the bytes of their encrypted content
+// @Tags admin
+// @Produce json
+// @Success 200 {object} models.SecretStatsResponse
+// @Failure 401 {object} httputil.HTTPError "Unauthorized - API key with the admin scope is missing or invalid"
+// @Failure 403 {object} httputil.HTTPError "Forbidden - the admin scope is not granted"
+// @Failure 408 {object} httputil.HTTPError "Request Timeout - operation cancelled"
+// @Failure 500 {object} httputil.HTTPError
+// @Security ApiKeyAuth
+// @Router /admin/secrets/stats [get]
+func GetSecretStats(c *gin.Context) {
+	dataStore, err := adminDataStore(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	stats, err := commands.GetSecretStats(c.Request.Context(), dataStore, controllers.Actor(c))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SecretStatsResponse{
+		Count: stats.Count,
+		Bytes: stats.Bytes,
+	})
+}
+
+// @Summary Purge Secrets. Burns the secrets of the caller's tenant created before a time, by a creator, or both
+// @Tags admin
+// @Produce json
+// @Accept json
+// @Param filter body models.PurgeFilter true "Secrets to purge"
+// @Success 200 {object} models.PurgeSecretsResponse
+// @Failure 400 {object} httputil.HTTPError "Bad Request - validation error"
+// @Failure 401 {object} httputil.HTTPError "Unauthorized - API key with the admin scope is missing or invalid"
+// @Failure 403 {object} httputil.HTTPError "Forbidden - the admin scope is not granted"
+// @Failure 408 {object} httputil.HTTPError "Request Timeout - operation cancelled"
+// @Failure 500 {object} httputil.HTTPError
+// @Security ApiKeyAuth
+// @Router /admin/secrets/purge [post]
+func PurgeSecrets(c *gin.Context) {
+	dataStore, err := adminDataStore(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	var filter models.PurgeFilter
+	if err := c.ShouldBindJSON(&filter); err != nil {
+		_ = c.Error(pkgerrors.NewValidationError(err.Error()))
+		return
+	}
+
+	purged, err := commands.PurgeSecrets(c.Request.Context(), dataStore, controllers.Actor(c), filter)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PurgeSecretsResponse{Purged: purged})
+}
+
+// @Summary Burn Secret. Deletes a secret of the caller's tenant by its key ID, as the logs name it
+// @Tags admin
+// @Produce json
+// @Param keyid path string true "Key ID of the secret"
+// @Success 204 ""
+// @Failure 400 {object} httputil.HTTPError "Bad Request - invalid key ID"
+// @Failure 401 {object} httputil.HTTPError "Unauthorized - API key with the admin scope is missing or invalid"
+// @Failure 403 {object} httputil.HTTPError "Forbidden - the admin scope is not granted"
+// @Failure 404 {object} httputil.HTTPError
+// @Failure 408 {object} httputil.HTTPError "Request Timeout - operation cancelled"
+// @Failure 500 {object} httputil.HTTPError
+// @Security ApiKeyAuth
+// @Router /admin/secrets/{keyid} [delete]
+func BurnSecret(c *gin.Context) {
+	dataStore, err := adminDataStore(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	found, err := commands.BurnSecret(c.Request.Context(), dataStore, controllers.Actor(c), c.Param("keyid"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if !found {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Get Read-Only Mode
+// @Tags admin
+// @Produce json
+// @Success 200 {object} models.ReadOnlyModeResponse
+// @Failure 401 {object} httputil.HTTPError "Unauthorized - API key with the admin scope is missing or invalid"
+// @Failure 403 {object} httputil.HTTPError "Forbidden - the admin scope is not granted"
+// @Failure 500 {object} httputil.HTTPError
+// @Security ApiKeyAuth
+// @Router /admin/read-only [get]
+func GetReadOnly(c *gin.Context) {
+	dataStore, err := adminDataStore(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	readOnly, err := commands.GetReadOnly(c.Request.Context(), dataStore, controllers.Actor(c))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ReadOnlyModeResponse{ReadOnly: readOnly})
+}
+
+// @Summary Set Read-Only Mode. Stops or resumes the creation and change of secrets on the whole server
+// @Tags admin
+// @Produce json
+// @Accept json
+// @Param mode body models.ReadOnlyModeRequest true "Read-only mode"
+// @Success 200 {object} models.ReadOnlyModeResponse
+// @Failure 400 {object} httputil.HTTPError "Bad Request - validation error"
+// @Failure 401 {object} httputil.HTTPError "Unauthorized - API key with the admin scope is missing or invalid"
+// @Failure 403 {object} httputil.HTTPError "Forbidden - the admin scope is not granted, or the caller belongs to a tenant"
+// @Failure 500 {object} httputil.HTTPError
+// @Security ApiKeyAuth
+// @Router /admin/read-only [put]
+func SetReadOnly(c *gin.Context) {
+	// Read-only mode applies to every tenant, so only the administrators of the server can set it.
+	if _, resolved := c.Get(tenants.ResolvedKey); resolved {
+		_ = c.Error(pkgerrors.NewForbiddenError("read-only mode can only be set outside of tenants"))
+		return
+	}
+
+	dataStore, err := adminDataStore(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	var body models.ReadOnlyModeRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		_ = c.Error(pkgerrors.NewValidationError(err.Error()))
+		return
+	}
+	if body.ReadOnly == nil {
+		_ = c.Error(pkgerrors.NewValidationError("required parameter: read_only"))
+		return
+	}
+
+	if err := commands.SetReadOnly(c.Request.Context(), dataStore, controllers.Actor(c), *body.ReadOnly); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ReadOnlyModeResponse{ReadOnly: *body.ReadOnly})
+}
+
+func adminDataStore(c *gin.Context) (datastore.AdminDataStore, error) {
+	dataStore, ok := c.MustGet(datastore.Key).(datastore.AdminDataStore)
+	if !ok {
+		return nil, errors.New("the datastore cannot be administered")
+	}
+	return dataStore, nil
+}
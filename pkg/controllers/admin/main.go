@@ -0,0 +1,35 @@
+package admin
+
+import (
+	"cellar/pkg/middleware"
+	"cellar/pkg/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @title Cellar
+// @description Simple secret sharing with the infrastructure you already trust
+// @contact.name Aria Vesta
+// @contact.email dev@ariavesta.com
+// @contact.url http://cellar-app.io
+// @license.name MIT
+// @license.url https://gitlab.com/cellar-app/cellar-api/-/blob/main/LICENSE.txt
+// @BasePath /admin
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name Authorization
+// @description API key or, with OIDC enabled, SSO token given as "Bearer <token>", required by routes that need a scope when API keys are enabled
+func Register(router *gin.Engine) {
+	admin := router.Group("/admin", middleware.RateLimit(ratelimit.Tier2), middleware.RequireAdmin())
+	{
+		secrets := admin.Group("/secrets")
+		{
+			secrets.GET("stats", GetSecretStats)
+			secrets.POST("purge", PurgeSecrets)
+			secrets.DELETE(":keyid", BurnSecret)
+		}
+
+		admin.GET("/read-only", GetReadOnly)
+		admin.PUT("/read-only", SetReadOnly)
+	}
+}
@@ -1,13 +1,73 @@
 package controllers
 
 import (
-	"bytes"
-	"io"
+	"cellar/pkg/apikeys"
+	"cellar/pkg/geoip"
+	"cellar/pkg/models"
+	"cellar/pkg/oidc"
+	"cellar/pkg/plaintext"
+	"cellar/pkg/tenants"
+	"encoding/json"
+	"errors"
 	"mime/multipart"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
 )
 
-// FileToBytes reads a multipart file header and returns its contents as a byte slice.
-// The file is automatically closed after reading.
+// OwnerTokenHeader carries the owner token for operations restricted to a resource's owner.
+const OwnerTokenHeader = "X-Owner-Token"
+
+// SecretKeyHeader carries the key of a split-key secret, which the server never stores.
+const SecretKeyHeader = "X-Secret-Key"
+
+// VerificationCodeHeader carries the code sent to the recipient of a secret that requires recipient verification.
+const VerificationCodeHeader = "X-Verification-Code"
+
+// Client returns the IP address of the client and the country resolved for it by the GeoIP middleware, if any.
+func Client(c *gin.Context) models.Client {
+	return models.Client{
+		IP:      c.ClientIP(),
+		Country: c.GetString(geoip.CountryKey),
+	}
+}
+
+// Creator returns the subject of the SSO user authenticated for the request, or an empty string for requests
+// made with an API key or anonymously.
+func Creator(c *gin.Context) string {
+	if identity, exists := c.Get(oidc.IdentityKey); exists {
+		return identity.(*models.Identity).Subject
+	}
+	return ""
+}
+
+// Owner returns the credentials given by the request to prove it owns a resource: the owner token header,
+// and the authenticated SSO user who may have created it.
+func Owner(c *gin.Context) models.OwnerCredentials {
+	return models.OwnerCredentials{
+		OwnerToken: c.GetHeader(OwnerTokenHeader),
+		Subject:    Creator(c),
+	}
+}
+
+// Actor returns who makes an administrative request, as recorded in the audit log: the API key by its ID,
+// or the SSO user by their subject, along with the client IP address and the tenant the request was resolved to.
+func Actor(c *gin.Context) models.AdminActor {
+	actor := models.AdminActor{ClientIP: c.ClientIP()}
+	if key, exists := c.Get(apikeys.AuthenticatedKey); exists {
+		actor.Credential = "api-key:" + key.(*models.APIKey).ID
+	} else if identity, exists := c.Get(oidc.IdentityKey); exists {
+		actor.Credential = "sso:" + identity.(*models.Identity).Subject
+	}
+	if tenant, resolved := c.Get(tenants.ResolvedKey); resolved {
+		actor.Tenant = tenant.(*tenants.Tenant).ID
+	}
+	return actor
+}
+
+// FileToBytes reads a multipart file header and returns its contents as a byte slice allocated with plaintext.Alloc,
+// which the caller wipes once done with it. The file is automatically closed after reading.
 func FileToBytes(header *multipart.FileHeader) ([]byte, error) {
 	var file multipart.File
 	file, err := header.Open()
@@ -15,9 +75,117 @@ func FileToBytes(header *multipart.FileHeader) ([]byte, error) {
 		return nil, err
 	}
 	defer func() { _ = file.Close() }()
-	buf := bytes.NewBuffer(nil)
-	if _, err = io.Copy(buf, file); err != nil {
-		return nil, err
+	return plaintext.ReadFull(file, header.Size)
+}
+
+// WriteSecretContent writes text secret content as a models.SecretContentResponse. The response is built
+// in a slice that is wiped once written, without converting the content to a string.
+func WriteSecretContent(c *gin.Context, id string, content []byte) {
+	fields := []jsonField{
+		{name: "id", value: []byte(id), quote: true},
+		{name: "content", value: content, quote: true},
+	}
+	response := plaintext.Alloc(jsonObjectSize(fields))[:0]
+	defer plaintext.Wipe(response)
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", appendJSONObject(response, fields))
+}
+
+// WriteSecretKVContent writes kv secret content as a models.SecretKVContentResponse. The content is stored
+// as a JSON object already, so it is written as it is instead of being decoded into strings and encoded again.
+func WriteSecretKVContent(c *gin.Context, id string, content []byte) error {
+	if !json.Valid(content) {
+		return errors.New("kv content is not valid JSON")
+	}
+
+	fields := []jsonField{
+		{name: "id", value: []byte(id), quote: true},
+		{name: "content_type", value: []byte(models.ContentTypeKV), quote: true},
+		{name: "content", value: content},
+	}
+	response := plaintext.Alloc(jsonObjectSize(fields))[:0]
+	defer plaintext.Wipe(response)
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", appendJSONObject(response, fields))
+	return nil
+}
+
+// jsonField is a field of a JSON object, whose value is either encoded as a string or written as it is.
+type jsonField struct {
+	name  string
+	value []byte
+	quote bool
+}
+
+// jsonObjectSize returns the size of the JSON object appendJSONObject writes, so it can be written
+// into a slice of its exact size instead of one that grows and leaves copies of the content behind.
+func jsonObjectSize(fields []jsonField) int {
+	size := 0
+	for _, field := range fields {
+		size += len(field.name) + 4
+		if field.quote {
+			escapeJSON(field.value, func(part ...byte) { size += len(part) })
+		} else {
+			size += len(field.value)
+		}
+	}
+	return size + 1
+}
+
+func appendJSONObject(dst []byte, fields []jsonField) []byte {
+	for i, field := range fields {
+		separator := byte(',')
+		if i == 0 {
+			separator = '{'
+		}
+		dst = append(dst, separator, '"')
+		dst = append(dst, field.name...)
+		dst = append(dst, '"', ':')
+		if field.quote {
+			escapeJSON(field.value, func(part ...byte) { dst = append(dst, part...) })
+		} else {
+			dst = append(dst, field.value...)
+		}
+	}
+	return append(dst, '}')
+}
+
+// escapeJSON emits value as a JSON string, escaped the way encoding/json escapes strings.
+func escapeJSON(value []byte, emit func(part ...byte)) {
+	const hex = "0123456789abcdef"
+
+	emit('"')
+	for i := 0; i < len(value); {
+		b := value[i]
+		if b < utf8.RuneSelf {
+			switch {
+			case b == '"' || b == '\\':
+				emit('\\', b)
+			case b == '\n':
+				emit('\\', 'n')
+			case b == '\r':
+				emit('\\', 'r')
+			case b == '\t':
+				emit('\\', 't')
+			case b < 0x20 || b == '<' || b == '>' || b == '&':
+				emit('\\', 'u', '0', '0', hex[b>>4], hex[b&0xF])
+			default:
+				emit(b)
+			}
+			i++
+			continue
+		}
+
+		r, size := utf8.DecodeRune(value[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			emit('\\', 'u', 'f', 'f', 'f', 'd')
+		case r == '\u2028' || r == '\u2029':
+			emit('\\', 'u', '2', '0', '2', hex[r&0xF])
+		default:
+			emit(value[i : i+size]...)
+		}
+		i += size
 	}
-	return buf.Bytes(), nil
+	emit('"')
 }
@@ -2,6 +2,7 @@ package v1
 
 import (
 	"cellar/pkg/middleware"
+	"cellar/pkg/models"
 	"cellar/pkg/ratelimit"
 
 	"github.com/gin-gonic/gin"
@@ -15,15 +16,19 @@ import (
 // @license.name MIT
 // @license.url https://gitlab.com/cellar-app/cellar-api/-/blob/main/LICENSE.txt
 // @BasePath /v1
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name Authorization
+// @description API key or, with OIDC enabled, SSO token given as "Bearer <token>", required by routes that need a scope when API keys are enabled
 func Register(router *gin.Engine) {
 	v1 := router.Group("/v1")
 	{
 		secrets := v1.Group("/secrets")
 		{
-			secrets.POST("", middleware.RateLimit(ratelimit.Tier1), CreateSecret)
-			secrets.POST(":id/access", middleware.RateLimit(ratelimit.Tier1), AccessSecretContent)
-			secrets.GET(":id", middleware.RateLimit(ratelimit.Tier2), GetSecretMetadata)
-			secrets.DELETE(":id", middleware.RateLimit(ratelimit.Tier2), DeleteSecret)
+			secrets.POST("", middleware.RateLimit(ratelimit.Tier1), middleware.RequireScope(models.ScopeCreate), middleware.Writable(), middleware.UserCreateLimit(), CreateSecret)
+			secrets.POST(":id/access", middleware.RateLimit(ratelimit.Tier1), middleware.BruteForceProtection(), middleware.UniformAccessResponse(), middleware.GeoIP(), AccessSecretContent)
+			secrets.GET(":id", middleware.RateLimit(ratelimit.Tier2), middleware.BruteForceProtection(), middleware.UniformResponse(), GetSecretMetadata)
+			secrets.DELETE(":id", middleware.RateLimit(ratelimit.Tier2), middleware.RequireScope(models.ScopeDeleteAny), middleware.BruteForceProtection(), middleware.UniformResponse(), DeleteSecret)
 		}
 	}
 }
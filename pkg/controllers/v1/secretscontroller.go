@@ -2,10 +2,13 @@ package v1
 
 import (
 	"cellar/pkg/commands"
+	"cellar/pkg/controllers"
 	"cellar/pkg/cryptography"
 	"cellar/pkg/datastore"
 	pkgerrors "cellar/pkg/errors"
 	"cellar/pkg/models"
+	"cellar/pkg/notifications"
+	"cellar/pkg/plaintext"
 	"cellar/pkg/settings"
 	"context"
 	"net/http"
@@ -20,7 +23,10 @@ import (
 // @Param secret body models.CreateSecretRequest true "Add secret"
 // @Success 201 {object} models.SecretMetadataResponse
 // @Failure 400 {object} httputil.HTTPError
+// @Failure 401 {object} httputil.HTTPError "Unauthorized - API key with the required scope is missing or invalid"
 // @Failure 500 {object} httputil.HTTPError
+// @Failure 503 {object} httputil.HTTPError "Service Unavailable - the server is in read-only mode"
+// @Security ApiKeyAuth
 // @Router /v1/secrets [post]
 func CreateSecret(c *gin.Context) {
 	cfg := c.MustGet(settings.Key).(settings.IConfiguration)
@@ -39,6 +45,7 @@ func CreateSecret(c *gin.Context) {
 		return
 	}
 	secret.Content = []byte(*body.Content)
+	defer plaintext.Wipe(secret.Content)
 	secret.ContentType = string(models.ContentTypeText)
 
 	if body.ExpirationEpoch == nil {
@@ -53,6 +60,8 @@ func CreateSecret(c *gin.Context) {
 		secret.AccessLimit = *body.AccessLimit
 	}
 
+	secret.Creator = controllers.Creator(c)
+
 	metadata, err := commands.CreateSecret(context.Background(), cfg.App(), dataStore, encryption, secret)
 	if err != nil {
 		_ = c.Error(err)
@@ -79,10 +88,11 @@ func CreateSecret(c *gin.Context) {
 func AccessSecretContent(c *gin.Context) {
 	dataStore := c.MustGet(datastore.Key).(datastore.DataStore)
 	encryption := c.MustGet(cryptography.Key).(cryptography.Encryption)
+	notifier := c.MustGet(notifications.Key).(notifications.Notifier)
 
 	id := c.Param("id")
 
-	secret, err := commands.AccessSecret(context.Background(), dataStore, encryption, id)
+	secret, err := commands.AccessSecret(context.Background(), dataStore, encryption, notifier, models.AccessRequest{ID: id, Client: controllers.Client(c)})
 	if err != nil {
 		_ = c.Error(err)
 		return
@@ -92,11 +102,9 @@ func AccessSecretContent(c *gin.Context) {
 		c.Status(http.StatusNotFound)
 		return
 	}
+	defer plaintext.Wipe(secret.Content)
 
-	c.JSON(http.StatusOK, models.SecretContentResponse{
-		ID:      secret.ID,
-		Content: string(secret.Content),
-	})
+	controllers.WriteSecretContent(c, secret.ID, secret.Content)
 }
 
 // @Summary Get Secret Metadata
@@ -131,8 +139,10 @@ func GetSecretMetadata(c *gin.Context) {
 // @Accept json
 // @Param id path string true "Secret ID"
 // @Success 204 ""
+// @Failure 401 {object} httputil.HTTPError "Unauthorized - API key with the required scope is missing or invalid"
 // @Failure 404 {object} httputil.HTTPError
 // @Failure 500 {object} httputil.HTTPError
+// @Security ApiKeyAuth
 // @Router /v1/secrets/{id} [delete]
 func DeleteSecret(c *gin.Context) {
 	dataStore := c.MustGet(datastore.Key).(datastore.DataStore)
@@ -4,15 +4,18 @@ import (
 	"cellar/pkg/commands"
 	"cellar/pkg/models"
 	"cellar/pkg/settings"
+	"cellar/pkg/tenants"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
-// @Summary Get Configuration
+// @Summary Get Configuration. With tenants, returns the limits of the caller's tenant
 // @Tags v2
 // @Produce json
 // @Success 200 {object} models.ConfigResponse
+// @Failure 401 {object} httputil.HTTPError "Unauthorized - API key with the required scope is missing or invalid"
+// @Security ApiKeyAuth
 // @Router /v2/config [get]
 func GetConfig(c *gin.Context) {
 	cfg := c.MustGet(settings.Key).(settings.IConfiguration)
@@ -22,7 +25,16 @@ func GetConfig(c *gin.Context) {
 	response := models.ConfigResponse{
 		Limits: limits,
 	}
+	if tenant, resolved := c.Get(tenants.ResolvedKey); resolved {
+		response.Tenant = tenant.(*tenants.Tenant).ID
+	}
 
-	c.Header("Cache-Control", "public, max-age=86400")
+	if cfg.Tenants().Enabled() {
+		// The response depends on the tenant of the caller, so shared caches must not serve it to others.
+		c.Header("Cache-Control", "private, max-age=86400")
+		c.Header("Vary", "Authorization")
+	} else {
+		c.Header("Cache-Control", "public, max-age=86400")
+	}
 	c.JSON(http.StatusOK, response)
 }
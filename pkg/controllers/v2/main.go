@@ -2,6 +2,7 @@ package v2
 
 import (
 	"cellar/pkg/middleware"
+	"cellar/pkg/models"
 	"cellar/pkg/ratelimit"
 
 	"github.com/gin-gonic/gin"
@@ -15,17 +16,40 @@ import (
 // @license.name MIT
 // @license.url https://gitlab.com/cellar-app/cellar-api/-/blob/main/LICENSE.txt
 // @BasePath /v2
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name Authorization
+// @description API key or, with OIDC enabled, SSO token given as "Bearer <token>", required by routes that need a scope when API keys are enabled
 func Register(router *gin.Engine) {
 	v2 := router.Group("/v2")
 	{
-		v2.GET("/config", middleware.RateLimit(ratelimit.Tier3), GetConfig)
+		v2.GET("/config", middleware.RateLimit(ratelimit.Tier3), middleware.RequireScope(models.ScopeReadConfig), GetConfig)
 
 		secrets := v2.Group("/secrets")
 		{
-			secrets.POST("", middleware.RateLimit(ratelimit.Tier1), CreateSecret)
-			secrets.POST(":id/access", middleware.RateLimit(ratelimit.Tier1), AccessSecretContent)
-			secrets.GET(":id", middleware.RateLimit(ratelimit.Tier2), GetSecretMetadata)
-			secrets.DELETE(":id", middleware.RateLimit(ratelimit.Tier2), DeleteSecret)
+			secrets.POST("", middleware.RateLimit(ratelimit.Tier1), middleware.RequireScope(models.ScopeCreate), middleware.Writable(), middleware.UserCreateLimit(), CreateSecret)
+			secrets.POST("generate", middleware.RateLimit(ratelimit.Tier1), middleware.RequireScope(models.ScopeCreate), middleware.Writable(), middleware.UserCreateLimit(), GenerateSecret)
+			secrets.POST(":id/access", middleware.RateLimit(ratelimit.Tier1), middleware.BruteForceProtection(), middleware.UniformAccessResponse(), middleware.GeoIP(), AccessSecretContent)
+			secrets.POST(":id/items/:index/access", middleware.RateLimit(ratelimit.Tier1), middleware.BruteForceProtection(), middleware.UniformAccessResponse(), middleware.GeoIP(), AccessSecretItem)
+			secrets.POST(":id/verification", middleware.RateLimit(ratelimit.Tier1), middleware.BruteForceProtection(), middleware.UniformResponse(), middleware.GeoIP(), RequestVerificationCode)
+			secrets.GET(":id", middleware.RateLimit(ratelimit.Tier2), middleware.BruteForceProtection(), middleware.UniformResponse(), GetSecretMetadata)
+			secrets.PATCH(":id", middleware.RateLimit(ratelimit.Tier2), middleware.Writable(), middleware.BruteForceProtection(), middleware.UniformResponse(), UpdateSecret)
+			secrets.GET(":id/history", middleware.RateLimit(ratelimit.Tier2), middleware.BruteForceProtection(), middleware.UniformResponse(), GetSecretHistory)
+			secrets.DELETE(":id", middleware.RateLimit(ratelimit.Tier2), middleware.RequireScope(models.ScopeDeleteAny), middleware.BruteForceProtection(), middleware.UniformResponse(), DeleteSecret)
+		}
+
+		splits := v2.Group("/splits")
+		{
+			splits.POST("", middleware.RateLimit(ratelimit.Tier1), middleware.RequireScope(models.ScopeCreate), middleware.Writable(), middleware.UserCreateLimit(), CreateSplitSecret)
+			splits.POST(":id/combine", middleware.RateLimit(ratelimit.Tier1), middleware.BruteForceProtection(), middleware.UniformAccessResponse(), middleware.GeoIP(), CombineSplitSecret)
+			splits.GET(":id", middleware.RateLimit(ratelimit.Tier2), middleware.BruteForceProtection(), middleware.UniformResponse(), GetSplitSecretMetadata)
+		}
+
+		requests := v2.Group("/requests")
+		{
+			requests.POST("", middleware.RateLimit(ratelimit.Tier2), middleware.RequireScope(models.ScopeCreate), middleware.Writable(), middleware.UserCreateLimit(), CreateSecretRequest)
+			requests.POST(":id/submit", middleware.RateLimit(ratelimit.Tier1), middleware.Writable(), middleware.BruteForceProtection(), middleware.UniformResponse(), SubmitSecretRequest)
+			requests.GET(":id", middleware.RateLimit(ratelimit.Tier2), middleware.BruteForceProtection(), middleware.UniformResponse(), GetSecretRequestMetadata)
 		}
 	}
 }
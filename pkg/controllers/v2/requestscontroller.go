@@ -0,0 +1,166 @@
+package v2
+
+import (
+	"cellar/pkg/commands"
+	"cellar/pkg/controllers"
+	"cellar/pkg/cryptography"
+	"cellar/pkg/datastore"
+	pkgerrors "cellar/pkg/errors"
+	"cellar/pkg/models"
+	"cellar/pkg/plaintext"
+	"cellar/pkg/settings"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Create Secret Request. The owner token in the response is only returned once and is required to access the submitted secret
+// @Tags v2
+// @Produce application/json
+// @Accept multipart/form-data
+// @Param content_type formData string false "Content type the request accepts (text or file), any if omitted"
+// @Param max_size_bytes formData int false "Maximum size of the submitted content in bytes, defaults to the maximum file size"
+// @Param access_limit formData int false "Access limit of the submitted secret"
+// @Param expiration_epoch formData int true "Expiration of the request and the submitted secret in Unix Epoch Time"
+// @Success 201 {object} models.CreateSecretRequestResponse
+// @Failure 400 {object} httputil.HTTPError "Bad Request - validation error"
+// @Failure 401 {object} httputil.HTTPError "Unauthorized - API key with the required scope is missing or invalid"
+// @Failure 408 {object} httputil.HTTPError "Request Timeout - operation cancelled"
+// @Failure 500 {object} httputil.HTTPError
+// @Failure 503 {object} httputil.HTTPError "Service Unavailable - the server is in read-only mode"
+// @Security ApiKeyAuth
+// @Router /v2/requests [post]
+func CreateSecretRequest(c *gin.Context) {
+	ctx := c.Request.Context()
+	cfg := c.MustGet(settings.Key).(settings.IConfiguration)
+	dataStore := c.MustGet(datastore.Key).(datastore.DataStore)
+
+	request := models.SecretRequest{
+		ContentType: c.PostForm("content_type"),
+	}
+
+	if maxSizeStr := c.PostForm("max_size_bytes"); maxSizeStr != "" {
+		maxSizeBytes, err := strconv.ParseInt(maxSizeStr, 10, 64)
+		if err != nil {
+			_ = c.Error(pkgerrors.NewValidationError("optional parameter: max_size_bytes: invalid value"))
+			return
+		}
+		request.MaxSizeBytes = maxSizeBytes
+	}
+
+	if accessLimitStr := c.PostForm("access_limit"); accessLimitStr != "" {
+		accessLimit, err := strconv.Atoi(accessLimitStr)
+		if err != nil {
+			_ = c.Error(pkgerrors.NewValidationError("optional parameter: access_limit: invalid value"))
+			return
+		}
+		request.AccessLimit = accessLimit
+	}
+
+	expirationEpoch, err := strconv.ParseInt(c.PostForm("expiration_epoch"), 10, 64)
+	if err != nil {
+		_ = c.Error(pkgerrors.NewValidationError("required parameter: expiration_epoch"))
+		return
+	}
+	request.ExpirationEpoch = expirationEpoch
+	request.Creator = controllers.Creator(c)
+
+	metadata, ownerToken, err := commands.CreateSecretRequest(ctx, cfg.App(), dataStore, request)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.CreateSecretRequestResponse{
+		ID:           metadata.ID,
+		OwnerToken:   ownerToken,
+		ContentType:  metadata.ContentType,
+		MaxSizeBytes: metadata.MaxSizeBytes,
+		AccessLimit:  metadata.AccessLimit,
+		Expiration:   metadata.Expiration,
+	})
+}
+
+// @Summary Get Secret Request Metadata. The submitted secret ID is only included for the owner
+// @Tags v2
+// @Produce json
+// @Accept json
+// @Param id path string true "Secret Request ID"
+// @Param X-Owner-Token header string false "Owner token returned when the request was created"
+// @Success 200 {object} models.SecretRequestMetadataResponse
+// @Failure 404 {object} httputil.HTTPError
+// @Failure 408 {object} httputil.HTTPError "Request Timeout - operation cancelled"
+// @Failure 500 {object} httputil.HTTPError
+// @Router /v2/requests/{id} [get]
+func GetSecretRequestMetadata(c *gin.Context) {
+	ctx := c.Request.Context()
+	dataStore := c.MustGet(datastore.Key).(datastore.DataStore)
+
+	id := c.Param("id")
+
+	metadata := commands.GetSecretRequestMetadata(ctx, dataStore, id, c.GetHeader(controllers.OwnerTokenHeader))
+	if metadata == nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, secretRequestMetadataResponse(metadata))
+}
+
+// @Summary Submit Secret Request. A request accepts a single submission
+// @Tags v2
+// @Produce application/json
+// @Accept multipart/form-data
+// @Param id path string true "Secret Request ID"
+// @Param content formData string false "Secret content"
+// @Param file formData file false "Secret content as a file"
+// @Success 201 {object} models.SecretRequestMetadataResponse
+// @Failure 400 {object} httputil.HTTPError "Bad Request - validation error"
+// @Failure 404 {object} httputil.HTTPError
+// @Failure 408 {object} httputil.HTTPError "Request Timeout - operation cancelled"
+// @Failure 409 {object} httputil.HTTPError "Conflict - request already fulfilled"
+// @Failure 413 {object} httputil.HTTPError "Payload Too Large - content exceeds size limit"
+// @Failure 500 {object} httputil.HTTPError
+// @Failure 503 {object} httputil.HTTPError "Service Unavailable - the server is in read-only mode"
+// @Router /v2/requests/{id}/submit [post]
+func SubmitSecretRequest(c *gin.Context) {
+	ctx := c.Request.Context()
+	cfg := c.MustGet(settings.Key).(settings.IConfiguration)
+	dataStore := c.MustGet(datastore.Key).(datastore.DataStore)
+	encryption := c.MustGet(cryptography.Key).(cryptography.Encryption)
+
+	id := c.Param("id")
+
+	var secret models.Secret
+	if err := readSecretContent(c, cfg, &secret); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	defer plaintext.Wipe(secret.Content)
+
+	metadata, err := commands.SubmitSecretRequest(ctx, cfg.App(), dataStore, encryption, id, secret)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if metadata == nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusCreated, secretRequestMetadataResponse(metadata))
+}
+
+func secretRequestMetadataResponse(metadata *models.SecretRequestMetadata) models.SecretRequestMetadataResponse {
+	return models.SecretRequestMetadataResponse{
+		ID:           metadata.ID,
+		ContentType:  metadata.ContentType,
+		MaxSizeBytes: metadata.MaxSizeBytes,
+		AccessLimit:  metadata.AccessLimit,
+		Expiration:   metadata.Expiration,
+		Fulfilled:    metadata.Fulfilled,
+		SecretID:     metadata.SecretID,
+	}
+}
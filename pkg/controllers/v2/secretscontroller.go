@@ -2,17 +2,24 @@ package v2
 
 import (
 	"bytes"
+	"cellar/pkg/bundles"
 	"cellar/pkg/commands"
 	"cellar/pkg/controllers"
 	"cellar/pkg/cryptography"
 	"cellar/pkg/datastore"
 	pkgerrors "cellar/pkg/errors"
 	"cellar/pkg/models"
+	"cellar/pkg/notifications"
+	"cellar/pkg/plaintext"
+	"cellar/pkg/recipients"
 	"cellar/pkg/settings"
 	"cellar/pkg/validators"
+	"encoding/json"
 	"fmt"
+	"mime/multipart"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -25,11 +32,26 @@ import (
 // @Param access_limit formData int false "Access limit"
 // @Param expiration_epoch formData int true "Expiration of the secret in Unix Epoch Time"
 // @Param file formData file false "Secret content as a file"
+// @Param kv formData string false "Secret content as a JSON object of string fields"
+// @Param files formData file false "Files of a bundle, repeat the field for each file"
+// @Param note formData string false "Text note to include in a bundle"
+// @Param available_from_epoch formData int false "Time from which the secret can be accessed in Unix Epoch Time"
+// @Param view_window_seconds formData int false "Seconds the secret stays accessible after it is first accessed"
+// @Param notify_email formData string false "Email address to notify when the secret is opened or expires unread"
+// @Param recipient_public_key formData string false "age X25519 recipient or armored OpenPGP public key to seal the content to"
+// @Param split_key formData bool false "Encrypt the content with a key that is returned once and never stored"
+// @Param allowed_cidrs formData []string false "IP ranges the secret can be accessed from, repeated or comma separated" collectionFormat(multi)
+// @Param allowed_countries formData []string false "ISO country codes the secret can be accessed from, repeated or comma separated" collectionFormat(multi)
+// @Param denied_countries formData []string false "ISO country codes the secret cannot be accessed from, repeated or comma separated" collectionFormat(multi)
+// @Param recipient_email formData string false "Email address the recipient must receive a verification code at before accessing the secret"
 // @Success 201 {object} models.SecretMetadataResponseV2
 // @Failure 400 {object} httputil.HTTPError "Bad Request - validation error"
+// @Failure 401 {object} httputil.HTTPError "Unauthorized - API key with the required scope is missing or invalid"
 // @Failure 408 {object} httputil.HTTPError "Request Timeout - operation cancelled"
 // @Failure 413 {object} httputil.HTTPError "Payload Too Large - file exceeds size limit"
 // @Failure 500 {object} httputil.HTTPError
+// @Failure 503 {object} httputil.HTTPError "Service Unavailable - the server is in read-only mode"
+// @Security ApiKeyAuth
 // @Router /v2/secrets [post]
 func CreateSecret(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -38,86 +60,127 @@ func CreateSecret(c *gin.Context) {
 	encryption := c.MustGet(cryptography.Key).(cryptography.Encryption)
 
 	var secret models.Secret
+	if err := readSecretOptions(c, &secret); err != nil {
+		_ = c.Error(err)
+		return
+	}
 
-	if accessLimitStr := c.PostForm("access_limit"); accessLimitStr != "" {
-		accessLimit, err := strconv.Atoi(accessLimitStr)
-		if err != nil {
-			_ = c.Error(pkgerrors.NewValidationError("optional parameter: access_limit: invalid value"))
+	if err := readAccessOptions(c, &secret); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := readSecretContent(c, cfg, &secret); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	defer plaintext.Wipe(secret.Content)
+
+	if publicKey := c.PostForm("recipient_public_key"); publicKey != "" {
+		if err := sealSecretContent(publicKey, &secret); err != nil {
+			_ = c.Error(err)
 			return
 		}
-		secret.AccessLimit = accessLimit
 	}
 
-	expirationEpoch, err := strconv.ParseInt(c.PostForm("expiration_epoch"), 10, 64)
+	secret.Creator = controllers.Creator(c)
+
+	metadata, err := commands.CreateSecret(ctx, cfg.App(), dataStore, encryption, secret)
 	if err != nil {
-		_ = c.Error(pkgerrors.NewValidationError("required parameter: expiration_epoch"))
+		_ = c.Error(err)
 		return
 	}
-	secret.ExpirationEpoch = expirationEpoch
 
-	content := c.PostForm("content")
-	fileHeader, err := c.FormFile("file")
-	if err != nil && err != http.ErrMissingFile {
-		_ = c.Error(pkgerrors.NewValidationError("required parameter: file: invalid value"))
+	c.JSON(http.StatusCreated, createdSecretResponse(metadata))
+}
+
+// @Summary Generate Secret. Creates a secret from a password, token, UUID or key pair generated on the server. Key pairs are stored as a bundle of the private and public key
+// @Tags v2
+// @Produce application/json
+// @Accept multipart/form-data
+// @Param kind formData string true "Kind of secret to generate (password, token, uuid, ssh or age)"
+// @Param length formData int false "Password length in characters (default 24) or token length in bytes (default 32)"
+// @Param character_classes formData string false "Comma separated password character classes (lowercase, uppercase, digits, symbols), all if omitted"
+// @Param access_limit formData int false "Access limit"
+// @Param expiration_epoch formData int true "Expiration of the secret in Unix Epoch Time"
+// @Param available_from_epoch formData int false "Time from which the secret can be accessed in Unix Epoch Time"
+// @Param view_window_seconds formData int false "Seconds the secret stays accessible after it is first accessed"
+// @Param notify_email formData string false "Email address to notify when the secret is opened or expires unread"
+// @Param split_key formData bool false "Encrypt the content with a key that is returned once and never stored"
+// @Param allowed_cidrs formData []string false "IP ranges the secret can be accessed from, repeated or comma separated" collectionFormat(multi)
+// @Param allowed_countries formData []string false "ISO country codes the secret can be accessed from, repeated or comma separated" collectionFormat(multi)
+// @Param denied_countries formData []string false "ISO country codes the secret cannot be accessed from, repeated or comma separated" collectionFormat(multi)
+// @Param recipient_email formData string false "Email address the recipient must receive a verification code at before accessing the secret"
+// @Success 201 {object} models.SecretMetadataResponseV2
+// @Failure 400 {object} httputil.HTTPError "Bad Request - validation error"
+// @Failure 401 {object} httputil.HTTPError "Unauthorized - API key with the required scope is missing or invalid"
+// @Failure 408 {object} httputil.HTTPError "Request Timeout - operation cancelled"
+// @Failure 500 {object} httputil.HTTPError
+// @Failure 503 {object} httputil.HTTPError "Service Unavailable - the server is in read-only mode"
+// @Security ApiKeyAuth
+// @Router /v2/secrets/generate [post]
+func GenerateSecret(c *gin.Context) {
+	ctx := c.Request.Context()
+	cfg := c.MustGet(settings.Key).(settings.IConfiguration)
+	dataStore := c.MustGet(datastore.Key).(datastore.DataStore)
+	encryption := c.MustGet(cryptography.Key).(cryptography.Encryption)
+
+	options := models.GenerateSecretOptions{
+		Kind: c.PostForm("kind"),
+	}
+	if options.Kind == "" {
+		_ = c.Error(pkgerrors.NewValidationError("required parameter: kind"))
 		return
 	}
 
-	if content != "" {
-		if fileHeader != nil {
-			_ = c.Error(pkgerrors.NewValidationError("secret with both content and file is not allowed"))
+	if lengthStr := c.PostForm("length"); lengthStr != "" {
+		length, err := strconv.Atoi(lengthStr)
+		if err != nil {
+			_ = c.Error(pkgerrors.NewValidationError("optional parameter: length: invalid value"))
 			return
 		}
+		options.Length = length
+	}
 
-		secret.Content = []byte(content)
-		secret.ContentType = models.ContentTypeText
-	} else {
-		if fileHeader == nil {
-			_ = c.Error(pkgerrors.NewValidationError("required parameter: file or content"))
-			return
-		}
-
-		if fileHeader.Size == 0 {
-			_ = c.Error(pkgerrors.NewValidationError("file cannot be empty"))
-			return
+	if classes := c.PostForm("character_classes"); classes != "" {
+		for _, class := range strings.Split(classes, ",") {
+			options.CharacterClasses = append(options.CharacterClasses, strings.TrimSpace(class))
 		}
+	}
 
-		maxSizeBytes := int64(cfg.App().MaxFileSizeMB() * 1024 * 1024)
-		if fileHeader.Size > maxSizeBytes {
-			_ = c.Error(pkgerrors.NewFileTooLargeError(fmt.Sprintf("file size %d bytes exceeds maximum allowed size of %d MB", fileHeader.Size, cfg.App().MaxFileSizeMB())))
-			return
-		}
+	var secret models.Secret
+	if err := readSecretOptions(c, &secret); err != nil {
+		_ = c.Error(err)
+		return
+	}
 
-		secret.Content, err = controllers.FileToBytes(fileHeader)
-		if err != nil {
-			_ = c.Error(pkgerrors.NewValidationError(err.Error()))
-			return
-		}
-		secret.ContentType = models.ContentTypeFile
-		secret.Filename = validators.SanitizeFilename(fileHeader.Filename)
+	if err := readAccessOptions(c, &secret); err != nil {
+		_ = c.Error(err)
+		return
 	}
 
-	metadata, err := commands.CreateSecret(ctx, cfg.App(), dataStore, encryption, secret)
+	secret.Creator = controllers.Creator(c)
+
+	metadata, err := commands.GenerateSecret(ctx, cfg.App(), dataStore, encryption, secret, options)
 	if err != nil {
 		_ = c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, models.SecretMetadataResponseV2{
-		ID:          metadata.ID,
-		AccessCount: metadata.AccessCount,
-		AccessLimit: metadata.AccessLimit,
-		ContentType: metadata.ContentType,
-		Filename:    metadata.Filename,
-		Expiration:  metadata.Expiration,
-	})
+	c.JSON(http.StatusCreated, createdSecretResponse(metadata))
 }
 
-// @Summary Access Secret Content. If the content is a file it the response will be an application/octet-stream
+// @Summary Access Secret Content. If the content is a file it the response will be an application/octet-stream, if it is a bundle an application/zip, and if it is sealed to a recipient the armored ciphertext
 // @Tags v2
-// @Produce application/json,application/octet-stream
+// @Produce application/json,application/octet-stream,application/zip,application/pgp-encrypted,application/x-age-encrypted
 // @Accept application/json
 // @Param id path string true "Secret ID"
+// @Param X-Owner-Token header string false "Owner token, required for secrets that have an owner"
+// @Param X-Secret-Key header string false "Key returned when a split-key secret was created, required for split-key secrets"
+// @Param X-Verification-Code header string false "Code sent to the recipient, required for secrets that require recipient verification"
 // @Success 200 {object} models.SecretContentResponse
+// @Success 200 {object} models.SecretKVContentResponse "kv secrets"
+// @Failure 403 {object} httputil.HTTPError "Forbidden - owner token, key or verification code missing or invalid, client outside the allowed ranges or countries, or secret not yet available"
 // @Failure 404 {object} httputil.HTTPError
 // @Failure 408 {object} httputil.HTTPError "Request Timeout - operation cancelled"
 // @Failure 500 {object} httputil.HTTPError
@@ -126,10 +189,11 @@ func AccessSecretContent(c *gin.Context) {
 	ctx := c.Request.Context()
 	dataStore := c.MustGet(datastore.Key).(datastore.DataStore)
 	encryption := c.MustGet(cryptography.Key).(cryptography.Encryption)
+	notifier := c.MustGet(notifications.Key).(notifications.Notifier)
 
 	id := c.Param("id")
 
-	secret, err := commands.AccessSecret(ctx, dataStore, encryption, id)
+	secret, err := commands.AccessSecret(ctx, dataStore, encryption, notifier, accessRequest(c, id))
 	if err != nil {
 		_ = c.Error(err)
 		return
@@ -139,33 +203,88 @@ func AccessSecretContent(c *gin.Context) {
 		c.Status(http.StatusNotFound)
 		return
 	}
+	defer plaintext.Wipe(secret.Content)
 
-	if secret.ContentType == models.ContentTypeFile {
-		reader := bytes.NewReader(secret.Content)
-		contentLength := reader.Size()
-		contentType := "application/octet-stream"
+	writeSecretContent(c, secret)
+}
 
-		filename := secret.Filename
-		if filename == "" {
-			filename = fmt.Sprintf("cellar-%s", secret.ID[:8])
-		}
+// @Summary Access Secret Bundle Item. Counts as an access of the whole bundle
+// @Tags v2
+// @Produce application/octet-stream
+// @Accept application/json
+// @Param id path string true "Secret ID"
+// @Param index path int true "Position of the item in the bundle"
+// @Param X-Owner-Token header string false "Owner token, required for secrets that have an owner"
+// @Param X-Secret-Key header string false "Key returned when a split-key secret was created, required for split-key secrets"
+// @Param X-Verification-Code header string false "Code sent to the recipient, required for secrets that require recipient verification"
+// @Success 200 {file} file
+// @Failure 400 {object} httputil.HTTPError "Bad Request - secret is not a bundle or item does not exist"
+// @Failure 403 {object} httputil.HTTPError "Forbidden - owner token, key or verification code missing or invalid, client outside the allowed ranges or countries, or secret not yet available"
+// @Failure 404 {object} httputil.HTTPError
+// @Failure 408 {object} httputil.HTTPError "Request Timeout - operation cancelled"
+// @Failure 500 {object} httputil.HTTPError
+// @Router /v2/secrets/{id}/items/{index}/access [post]
+func AccessSecretItem(c *gin.Context) {
+	ctx := c.Request.Context()
+	dataStore := c.MustGet(datastore.Key).(datastore.DataStore)
+	encryption := c.MustGet(cryptography.Key).(cryptography.Encryption)
+	notifier := c.MustGet(notifications.Key).(notifications.Notifier)
 
-		extraHeaders := map[string]string{
-			"Content-Disposition":     fmt.Sprintf(`attachment; filename="%s"`, filename),
-			"X-Content-Type-Options":  "nosniff",
-			"Content-Security-Policy": "default-src 'none'",
-			"X-Frame-Options":         "DENY",
-			"Cache-Control":           "no-store, no-cache, must-revalidate",
-		}
+	id := c.Param("id")
 
-		c.DataFromReader(http.StatusOK, contentLength, contentType, reader, extraHeaders)
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil {
+		_ = c.Error(pkgerrors.NewValidationError("required parameter: index: invalid value"))
 		return
 	}
 
-	c.JSON(http.StatusOK, models.SecretContentResponse{
-		ID:      secret.ID,
-		Content: string(secret.Content),
-	})
+	secret, err := commands.AccessSecretItem(ctx, dataStore, encryption, notifier, accessRequest(c, id), index)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if secret == nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	defer plaintext.Wipe(secret.Content)
+
+	writeAttachment(c, secret.Content, "application/octet-stream", secret.Filename)
+}
+
+// @Summary Request Verification Code. Emails a new code to the recipient of a secret that requires recipient verification. No new code is sent while one is pending
+// @Tags v2
+// @Produce json
+// @Accept json
+// @Param id path string true "Secret ID"
+// @Success 202 ""
+// @Failure 400 {object} httputil.HTTPError "Bad Request - secret does not require recipient verification"
+// @Failure 403 {object} httputil.HTTPError "Forbidden - client outside the allowed ranges or countries"
+// @Failure 404 {object} httputil.HTTPError
+// @Failure 408 {object} httputil.HTTPError "Request Timeout - operation cancelled"
+// @Failure 409 {object} httputil.HTTPError "Conflict - a code that has not expired is pending"
+// @Failure 500 {object} httputil.HTTPError
+// @Router /v2/secrets/{id}/verification [post]
+func RequestVerificationCode(c *gin.Context) {
+	ctx := c.Request.Context()
+	dataStore := c.MustGet(datastore.Key).(datastore.DataStore)
+	notifier := c.MustGet(notifications.Key).(notifications.Notifier)
+
+	id := c.Param("id")
+
+	found, err := commands.RequestVerificationCode(ctx, dataStore, notifier, accessRequest(c, id))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if !found {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
 }
 
 // @Summary Get Secret Metadata
@@ -188,24 +307,141 @@ func GetSecretMetadata(c *gin.Context) {
 		c.Status(http.StatusNotFound)
 	} else {
 		c.JSON(http.StatusOK, models.SecretMetadataResponseV2{
-			ID:          secretMetadata.ID,
-			AccessCount: secretMetadata.AccessCount,
-			AccessLimit: secretMetadata.AccessLimit,
-			ContentType: secretMetadata.ContentType,
-			Expiration:  secretMetadata.Expiration,
+			ID:                secretMetadata.ID,
+			AccessCount:       secretMetadata.AccessCount,
+			AccessLimit:       secretMetadata.AccessLimit,
+			ContentType:       secretMetadata.ContentType,
+			Expiration:        secretMetadata.Expiration,
+			AvailableFrom:     secretMetadata.AvailableFrom,
+			ViewWindowSeconds: secretMetadata.ViewWindowSeconds,
+			ViewWindowStarted: secretMetadata.ViewWindowStarted,
+			ViewWindowEnds:    secretMetadata.ViewWindowEnds(),
+			Items:             secretMetadata.BundleItems,
+			SplitKey:          secretMetadata.SplitKey,
+
+			RecipientVerification: secretMetadata.RecipientVerification,
 		})
 	}
 }
 
+// @Summary Update Secret. Changes the expiration and access limit of a secret within the configured limits
+// @Tags v2
+// @Produce json
+// @Accept multipart/form-data
+// @Param id path string true "Secret ID"
+// @Param X-Owner-Token header string false "Owner token returned when the secret was created, not needed by the SSO user who created it"
+// @Param expiration_epoch formData int false "New expiration of the secret in Unix Epoch Time"
+// @Param access_limit formData int false "New access limit"
+// @Success 200 {object} models.SecretMetadataResponseV2
+// @Failure 400 {object} httputil.HTTPError "Bad Request - validation error"
+// @Failure 403 {object} httputil.HTTPError "Forbidden - owner token missing or invalid"
+// @Failure 404 {object} httputil.HTTPError
+// @Failure 408 {object} httputil.HTTPError "Request Timeout - operation cancelled"
+// @Failure 500 {object} httputil.HTTPError
+// @Failure 503 {object} httputil.HTTPError "Service Unavailable - the server is in read-only mode"
+// @Router /v2/secrets/{id} [patch]
+func UpdateSecret(c *gin.Context) {
+	ctx := c.Request.Context()
+	cfg := c.MustGet(settings.Key).(settings.IConfiguration)
+	dataStore := c.MustGet(datastore.Key).(datastore.DataStore)
+
+	id := c.Param("id")
+
+	var update models.SecretUpdate
+
+	if expirationStr := c.PostForm("expiration_epoch"); expirationStr != "" {
+		expirationEpoch, err := strconv.ParseInt(expirationStr, 10, 64)
+		if err != nil {
+			_ = c.Error(pkgerrors.NewValidationError("optional parameter: expiration_epoch: invalid value"))
+			return
+		}
+		update.ExpirationEpoch = &expirationEpoch
+	}
+
+	if accessLimitStr := c.PostForm("access_limit"); accessLimitStr != "" {
+		accessLimit, err := strconv.Atoi(accessLimitStr)
+		if err != nil {
+			_ = c.Error(pkgerrors.NewValidationError("optional parameter: access_limit: invalid value"))
+			return
+		}
+		update.AccessLimit = &accessLimit
+	}
+
+	metadata, err := commands.UpdateSecret(ctx, cfg.App(), dataStore, id, controllers.Owner(c), update)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if metadata == nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SecretMetadataResponseV2{
+		ID:                metadata.ID,
+		AccessCount:       metadata.AccessCount,
+		AccessLimit:       metadata.AccessLimit,
+		ContentType:       metadata.ContentType,
+		Expiration:        metadata.Expiration,
+		AvailableFrom:     metadata.AvailableFrom,
+		ViewWindowSeconds: metadata.ViewWindowSeconds,
+		ViewWindowStarted: metadata.ViewWindowStarted,
+		ViewWindowEnds:    metadata.ViewWindowEnds(),
+	})
+}
+
+// @Summary Get Secret History. Lists the accesses and modifications of a secret
+// @Tags v2
+// @Produce json
+// @Accept json
+// @Param id path string true "Secret ID"
+// @Param X-Owner-Token header string false "Owner token returned when the secret was created, not needed by the SSO user who created it"
+// @Success 200 {object} models.SecretHistoryResponse
+// @Failure 403 {object} httputil.HTTPError "Forbidden - owner token missing or invalid"
+// @Failure 404 {object} httputil.HTTPError
+// @Failure 408 {object} httputil.HTTPError "Request Timeout - operation cancelled"
+// @Failure 500 {object} httputil.HTTPError
+// @Router /v2/secrets/{id}/history [get]
+func GetSecretHistory(c *gin.Context) {
+	ctx := c.Request.Context()
+	dataStore := c.MustGet(datastore.Key).(datastore.DataStore)
+
+	id := c.Param("id")
+
+	events, err := commands.GetSecretHistory(ctx, dataStore, id, controllers.Owner(c))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if events == nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	response := models.SecretHistoryResponse{
+		ID:     id,
+		Events: make([]models.SecretHistoryEventResponse, 0, len(events)),
+	}
+	for _, event := range events {
+		response.Events = append(response.Events, event.Response())
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
 // @Summary Delete Secret
 // @Tags v2
 // @Produce json
 // @Accept json
 // @Param id path string true "Secret ID"
 // @Success 204 ""
+// @Failure 401 {object} httputil.HTTPError "Unauthorized - API key with the required scope is missing or invalid"
 // @Failure 404 {object} httputil.HTTPError
 // @Failure 408 {object} httputil.HTTPError "Request Timeout - operation cancelled"
 // @Failure 500 {object} httputil.HTTPError
+// @Security ApiKeyAuth
 // @Router /v2/secrets/{id} [delete]
 func DeleteSecret(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -226,3 +462,339 @@ func DeleteSecret(c *gin.Context) {
 
 	c.Status(http.StatusNoContent)
 }
+
+// readSecretOptions reads the access limit, expiration, activation time, view window and notification
+// email of a new secret from the form.
+// Returns a ValidationError if a value is missing or invalid.
+func readSecretOptions(c *gin.Context, secret *models.Secret) error {
+	if accessLimitStr := c.PostForm("access_limit"); accessLimitStr != "" {
+		accessLimit, err := strconv.Atoi(accessLimitStr)
+		if err != nil {
+			return pkgerrors.NewValidationError("optional parameter: access_limit: invalid value")
+		}
+		secret.AccessLimit = accessLimit
+	}
+
+	expirationEpoch, err := strconv.ParseInt(c.PostForm("expiration_epoch"), 10, 64)
+	if err != nil {
+		return pkgerrors.NewValidationError("required parameter: expiration_epoch")
+	}
+	secret.ExpirationEpoch = expirationEpoch
+
+	if availableFromStr := c.PostForm("available_from_epoch"); availableFromStr != "" {
+		availableFromEpoch, err := strconv.ParseInt(availableFromStr, 10, 64)
+		if err != nil {
+			return pkgerrors.NewValidationError("optional parameter: available_from_epoch: invalid value")
+		}
+		secret.AvailableFromEpoch = availableFromEpoch
+	}
+
+	if viewWindowStr := c.PostForm("view_window_seconds"); viewWindowStr != "" {
+		viewWindowSeconds, err := strconv.Atoi(viewWindowStr)
+		if err != nil {
+			return pkgerrors.NewValidationError("optional parameter: view_window_seconds: invalid value")
+		}
+		secret.ViewWindowSeconds = viewWindowSeconds
+	}
+
+	if notifyEmail := c.PostForm("notify_email"); notifyEmail != "" {
+		if !validators.IsValidEmail(notifyEmail) {
+			return pkgerrors.NewValidationError("optional parameter: notify_email: invalid value")
+		}
+		secret.NotifyEmail = notifyEmail
+	}
+
+	return nil
+}
+
+// createdSecretResponse builds the response to a newly created secret, which carries the owner token.
+func createdSecretResponse(metadata *models.SecretMetadata) models.SecretMetadataResponseV2 {
+	return models.SecretMetadataResponseV2{
+		ID:                metadata.ID,
+		OwnerToken:        metadata.OwnerToken,
+		AccessCount:       metadata.AccessCount,
+		AccessLimit:       metadata.AccessLimit,
+		ContentType:       metadata.ContentType,
+		Filename:          metadata.Filename,
+		Expiration:        metadata.Expiration,
+		AvailableFrom:     metadata.AvailableFrom,
+		ViewWindowSeconds: metadata.ViewWindowSeconds,
+		Items:             metadata.BundleItems,
+		SplitKey:          metadata.SplitKey,
+		Key:               metadata.Key,
+		AllowedCIDRs:      metadata.AllowedCIDRs,
+		AllowedCountries:  metadata.AllowedCountries,
+		DeniedCountries:   metadata.DeniedCountries,
+
+		RecipientVerification: metadata.RecipientVerification,
+	}
+}
+
+// readAccessOptions reads whether the secret is encrypted with a per-secret key that is never stored,
+// the client IP ranges and countries it can be accessed from, and the email address of a recipient
+// who has to verify it before accessing the secret.
+func readAccessOptions(c *gin.Context, secret *models.Secret) error {
+	if splitKeyStr := c.PostForm("split_key"); splitKeyStr != "" {
+		splitKey, err := strconv.ParseBool(splitKeyStr)
+		if err != nil {
+			return pkgerrors.NewValidationError("optional parameter: split_key: invalid value")
+		}
+		secret.SplitKey = splitKey
+	}
+
+	secret.AllowedCIDRs = postFormList(c, "allowed_cidrs")
+	secret.AllowedCountries = postFormList(c, "allowed_countries")
+	secret.DeniedCountries = postFormList(c, "denied_countries")
+
+	if recipientEmail := c.PostForm("recipient_email"); recipientEmail != "" {
+		if !validators.IsValidEmail(recipientEmail) {
+			return pkgerrors.NewValidationError("optional parameter: recipient_email: invalid value")
+		}
+		cfg := c.MustGet(settings.Key).(settings.IConfiguration)
+		if !cfg.Notifications().Smtp().Enabled() {
+			return pkgerrors.NewValidationError("optional parameter: recipient_email: email notifications are not enabled")
+		}
+		secret.RecipientEmail = recipientEmail
+	}
+	return nil
+}
+
+// postFormList reads a form field that can be repeated, comma separated, or both.
+func postFormList(c *gin.Context, key string) []string {
+	var list []string
+	for _, value := range c.PostFormArray(key) {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	return list
+}
+
+// accessRequest collects what the caller presents to access the secret with the given ID.
+func accessRequest(c *gin.Context, id string) models.AccessRequest {
+	return models.AccessRequest{
+		ID:         id,
+		OwnerToken: c.GetHeader(controllers.OwnerTokenHeader),
+		Key:        c.GetHeader(controllers.SecretKeyHeader),
+		Client:     controllers.Client(c),
+
+		VerificationCode: c.GetHeader(controllers.VerificationCodeHeader),
+	}
+}
+
+// readSecretContent reads the secret content from either the content, the file or the kv form field,
+// or a bundle from the files and note form fields.
+// Returns a ValidationError or FileTooLargeError if the form does not hold valid content.
+func readSecretContent(c *gin.Context, cfg settings.IConfiguration, secret *models.Secret) error {
+	content := c.PostForm("content")
+	fileHeader, err := c.FormFile("file")
+	if err != nil && err != http.ErrMissingFile {
+		return pkgerrors.NewValidationError("required parameter: file: invalid value")
+	}
+
+	var bundleFiles []*multipart.FileHeader
+	if form, err := c.MultipartForm(); err == nil {
+		bundleFiles = form.File["files"]
+	}
+
+	if kv := c.PostForm("kv"); kv != "" {
+		if content != "" || fileHeader != nil || len(bundleFiles) > 0 {
+			return pkgerrors.NewValidationError("secret with both kv and content or files is not allowed")
+		}
+		return readSecretKV(cfg, kv, secret)
+	}
+
+	if len(bundleFiles) > 0 {
+		if content != "" || fileHeader != nil {
+			return pkgerrors.NewValidationError("secret with both a bundle and content or file is not allowed")
+		}
+		return readSecretBundle(cfg, bundleFiles, c.PostForm("note"), secret)
+	}
+
+	if content != "" {
+		if fileHeader != nil {
+			return pkgerrors.NewValidationError("secret with both content and file is not allowed")
+		}
+
+		secret.Content = []byte(content)
+		secret.ContentType = models.ContentTypeText
+		return nil
+	}
+
+	if fileHeader == nil {
+		return pkgerrors.NewValidationError("required parameter: file or content")
+	}
+
+	if fileHeader.Size == 0 {
+		return pkgerrors.NewValidationError("file cannot be empty")
+	}
+
+	maxSizeBytes := int64(cfg.App().MaxFileSizeMB() * 1024 * 1024)
+	if fileHeader.Size > maxSizeBytes {
+		return pkgerrors.NewFileTooLargeError(fmt.Sprintf("file size %d bytes exceeds maximum allowed size of %d MB", fileHeader.Size, cfg.App().MaxFileSizeMB()))
+	}
+
+	secret.Content, err = controllers.FileToBytes(fileHeader)
+	if err != nil {
+		return pkgerrors.NewValidationError(err.Error())
+	}
+	secret.ContentType = models.ContentTypeFile
+	secret.Filename = validators.SanitizeFilename(fileHeader.Filename)
+	return nil
+}
+
+// readSecretKV reads a JSON object of string fields within the configured field count and key length.
+// The fields are stored as a whole, re-encoded with sorted keys.
+func readSecretKV(cfg settings.IConfiguration, kv string, secret *models.Secret) error {
+	maxSizeBytes := cfg.App().MaxFileSizeMB() * 1024 * 1024
+	if len(kv) > maxSizeBytes {
+		return pkgerrors.NewFileTooLargeError(fmt.Sprintf("kv size %d bytes exceeds maximum allowed size of %d MB", len(kv), cfg.App().MaxFileSizeMB()))
+	}
+
+	var fields map[string]string
+	if err := json.Unmarshal([]byte(kv), &fields); err != nil || fields == nil {
+		return pkgerrors.NewValidationError("kv must be a JSON object of string fields")
+	}
+
+	if len(fields) == 0 {
+		return pkgerrors.NewValidationError("kv must have at least one field")
+	}
+	if len(fields) > cfg.App().MaxKVFields() {
+		return pkgerrors.NewValidationError(fmt.Sprintf("kv cannot have more than %d fields", cfg.App().MaxKVFields()))
+	}
+	for key := range fields {
+		if key == "" {
+			return pkgerrors.NewValidationError("kv field names cannot be empty")
+		}
+		if len(key) > cfg.App().MaxKVKeyLength() {
+			return pkgerrors.NewValidationError(fmt.Sprintf("kv field names cannot exceed %d characters", cfg.App().MaxKVKeyLength()))
+		}
+	}
+
+	content, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+
+	secret.Content = content
+	secret.ContentType = models.ContentTypeKV
+	return nil
+}
+
+// readSecretBundle packs the files and the optional note into a bundle.
+// The combined size of the items is limited to the maximum file size.
+func readSecretBundle(cfg settings.IConfiguration, fileHeaders []*multipart.FileHeader, note string, secret *models.Secret) error {
+	maxSizeBytes := int64(cfg.App().MaxFileSizeMB() * 1024 * 1024)
+	totalSize := int64(len(note))
+	for _, fileHeader := range fileHeaders {
+		if fileHeader.Size == 0 {
+			return pkgerrors.NewValidationError("bundle files cannot be empty")
+		}
+		totalSize += fileHeader.Size
+	}
+	if totalSize > maxSizeBytes {
+		return pkgerrors.NewFileTooLargeError(fmt.Sprintf("bundle size %d bytes exceeds maximum allowed size of %d MB", totalSize, cfg.App().MaxFileSizeMB()))
+	}
+
+	files := make([]bundles.File, 0, len(fileHeaders)+1)
+	defer func() {
+		for _, file := range files {
+			plaintext.Wipe(file.Content)
+		}
+	}()
+	for _, fileHeader := range fileHeaders {
+		content, err := controllers.FileToBytes(fileHeader)
+		if err != nil {
+			return pkgerrors.NewValidationError(err.Error())
+		}
+		files = append(files, bundles.File{
+			Name:    validators.SanitizeFilename(fileHeader.Filename),
+			Content: content,
+		})
+	}
+	if note != "" {
+		files = append(files, bundles.File{
+			Name:    bundles.NoteFilename,
+			Content: []byte(note),
+		})
+	}
+
+	archive, items, err := bundles.Write(files)
+	if err != nil {
+		return err
+	}
+
+	secret.Content = archive
+	secret.ContentType = models.ContentTypeBundle
+	secret.BundleItems = items
+	return nil
+}
+
+// writeSecretContent writes decrypted secret content as JSON for text and kv, or as a download for files and bundles.
+func writeSecretContent(c *gin.Context, secret *models.Secret) {
+	switch secret.ContentType {
+	case models.ContentTypeFile:
+		filename := secret.Filename
+		if filename == "" {
+			filename = fmt.Sprintf("cellar-%s", secret.ID[:8])
+		}
+		writeAttachment(c, secret.Content, "application/octet-stream", filename)
+		return
+	case models.ContentTypeBundle:
+		writeAttachment(c, secret.Content, "application/zip", fmt.Sprintf("cellar-%s.zip", secret.ID[:8]))
+		return
+	case models.ContentTypeAge:
+		writeAttachment(c, secret.Content, "application/x-age-encrypted", sealedFilename(secret, ".age"))
+		return
+	case models.ContentTypeOpenPGP:
+		writeAttachment(c, secret.Content, "application/pgp-encrypted", sealedFilename(secret, ".asc"))
+		return
+	case models.ContentTypeKV:
+		if err := controllers.WriteSecretKVContent(c, secret.ID, secret.Content); err != nil {
+			_ = c.Error(err)
+		}
+		return
+	}
+
+	controllers.WriteSecretContent(c, secret.ID, secret.Content)
+}
+
+// sealSecretContent replaces the secret content with armored ciphertext sealed to the recipient's public key.
+// The original filename is kept so the recipient can restore it after decrypting.
+func sealSecretContent(publicKey string, secret *models.Secret) error {
+	sealed, contentType, err := recipients.Seal(publicKey, secret.Content)
+	if err != nil {
+		return err
+	}
+
+	plaintext.Wipe(secret.Content)
+	secret.Content = sealed
+	secret.ContentType = contentType
+	secret.BundleItems = nil
+	return nil
+}
+
+// sealedFilename names the download of sealed content after the original file, if there was one.
+func sealedFilename(secret *models.Secret, extension string) string {
+	if secret.Filename != "" {
+		return secret.Filename + extension
+	}
+	return fmt.Sprintf("cellar-%s%s", secret.ID[:8], extension)
+}
+
+// writeAttachment streams content as a file download with headers that stop browsers from rendering it.
+func writeAttachment(c *gin.Context, content []byte, contentType string, filename string) {
+	reader := bytes.NewReader(content)
+
+	extraHeaders := map[string]string{
+		"Content-Disposition":     fmt.Sprintf(`attachment; filename="%s"`, filename),
+		"X-Content-Type-Options":  "nosniff",
+		"Content-Security-Policy": "default-src 'none'",
+		"X-Frame-Options":         "DENY",
+		"Cache-Control":           "no-store, no-cache, must-revalidate",
+	}
+
+	c.DataFromReader(http.StatusOK, reader.Size(), contentType, reader, extraHeaders)
+}
@@ -2,19 +2,28 @@ package v2
 
 import (
 	"bytes"
+	"cellar/pkg/controllers"
 	"cellar/pkg/cryptography"
 	"cellar/pkg/datastore"
 	"cellar/pkg/middleware"
 	"cellar/pkg/mocks"
 	"cellar/pkg/models"
+	"cellar/pkg/notifications"
 	"cellar/pkg/settings"
+	"cellar/testing/testhelpers"
+	"encoding/json"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"filippo.io/age"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
 
@@ -47,6 +56,7 @@ func TestCreateSecret(t *testing.T) {
 			cfg = settings.NewConfiguration()
 			ctrl = gomock.NewController(t)
 			mockDataStore = mocks.NewMockDataStore(ctrl)
+			mockDataStore.EXPECT().KeyID(gomock.Any()).Return("key-id").AnyTimes()
 			mockEncryption = mocks.NewMockEncryption(ctrl)
 
 			router.Use(middleware.ErrorHandler())
@@ -103,6 +113,363 @@ func TestCreateSecret(t *testing.T) {
 				assert.Equal(t, http.StatusBadRequest, w.Code)
 			})
 		})
+
+		createBundleRequest := func(files map[string][]byte, fields map[string]string) *http.Request {
+			body := &bytes.Buffer{}
+			writer := multipart.NewWriter(body)
+
+			for filename, content := range files {
+				part, _ := writer.CreateFormFile("files", filename)
+				_, _ = part.Write(content)
+			}
+			for key, value := range fields {
+				_ = writer.WriteField(key, value)
+			}
+			_ = writer.WriteField("expiration_epoch", strconv.FormatInt(testhelpers.EpochFromNow(time.Hour), 10))
+			_ = writer.Close()
+
+			req, _ := http.NewRequest("POST", "/v2/secrets", body)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			return req
+		}
+
+		t.Run("and a bundle is provided", func(t *testing.T) {
+			setupRouter()
+
+			var written models.Secret
+			mockEncryption.EXPECT().Encrypt(gomock.Any(), gomock.Any()).Return("encrypted", nil)
+			mockDataStore.EXPECT().WriteSecret(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ any, secret models.Secret) error {
+					written = secret
+					return nil
+				})
+
+			req := createBundleRequest(map[string][]byte{
+				"cert.pem": []byte("certificate"),
+				"key.pem":  []byte("private key"),
+			}, map[string]string{"note": "rotate by friday"})
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			t.Run("it should create the secret", func(t *testing.T) {
+				assert.Equal(t, http.StatusCreated, w.Code)
+			})
+
+			t.Run("it should store a bundle of the files and the note", func(t *testing.T) {
+				assert.Equal(t, models.ContentTypeBundle, written.ContentType)
+				assert.Len(t, written.BundleItems, 3)
+			})
+		})
+
+		t.Run("and a bundle is provided with content", func(t *testing.T) {
+			setupRouter()
+
+			t.Run("it should return 400 Bad Request", func(t *testing.T) {
+				req := createBundleRequest(map[string][]byte{"cert.pem": []byte("certificate")}, map[string]string{"content": "text"})
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+
+				assert.Equal(t, http.StatusBadRequest, w.Code)
+			})
+		})
+
+		t.Run("and a recipient public key is provided", func(t *testing.T) {
+			setupRouter()
+
+			identity, err := age.GenerateX25519Identity()
+			require.NoError(t, err)
+
+			var encrypted []byte
+			var written models.Secret
+			mockEncryption.EXPECT().Encrypt(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ any, content []byte) (string, error) {
+					encrypted = append([]byte{}, content...)
+					return "encrypted", nil
+				})
+			mockDataStore.EXPECT().WriteSecret(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ any, secret models.Secret) error {
+					written = secret
+					return nil
+				})
+
+			body := &bytes.Buffer{}
+			writer := multipart.NewWriter(body)
+			_ = writer.WriteField("content", "Super Secret Test Content")
+			_ = writer.WriteField("recipient_public_key", identity.Recipient().String())
+			_ = writer.WriteField("expiration_epoch", strconv.FormatInt(testhelpers.EpochFromNow(time.Hour), 10))
+			_ = writer.Close()
+
+			req, _ := http.NewRequest("POST", "/v2/secrets", body)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			t.Run("it should create the secret", func(t *testing.T) {
+				assert.Equal(t, http.StatusCreated, w.Code)
+			})
+
+			t.Run("it should seal the content before encrypting it", func(t *testing.T) {
+				assert.Equal(t, models.ContentTypeAge, written.ContentType)
+				assert.True(t, bytes.HasPrefix(encrypted, []byte("-----BEGIN AGE ENCRYPTED FILE-----")))
+			})
+		})
+
+		t.Run("and a split key is requested", func(t *testing.T) {
+			setupRouter()
+
+			var written models.Secret
+			mockEncryption.EXPECT().Encrypt(gomock.Any(), gomock.Any()).Return("encrypted", nil)
+			mockDataStore.EXPECT().WriteSecret(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ any, secret models.Secret) error {
+					written = secret
+					return nil
+				})
+
+			body := &bytes.Buffer{}
+			writer := multipart.NewWriter(body)
+			_ = writer.WriteField("content", "Super Secret Test Content")
+			_ = writer.WriteField("split_key", "true")
+			_ = writer.WriteField("expiration_epoch", strconv.FormatInt(testhelpers.EpochFromNow(time.Hour), 10))
+			_ = writer.Close()
+
+			req, _ := http.NewRequest("POST", "/v2/secrets", body)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			t.Run("it should create a split-key secret", func(t *testing.T) {
+				require.Equal(t, http.StatusCreated, w.Code)
+				assert.True(t, written.SplitKey)
+			})
+
+			t.Run("it should return the key", func(t *testing.T) {
+				var response models.SecretMetadataResponseV2
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.NotEmpty(t, response.Key)
+			})
+		})
+
+		t.Run("and allowed CIDRs are provided", func(t *testing.T) {
+			setupRouter()
+
+			var written models.Secret
+			mockEncryption.EXPECT().Encrypt(gomock.Any(), gomock.Any()).Return("encrypted", nil)
+			mockDataStore.EXPECT().WriteSecret(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ any, secret models.Secret) error {
+					written = secret
+					return nil
+				})
+
+			body := &bytes.Buffer{}
+			writer := multipart.NewWriter(body)
+			_ = writer.WriteField("content", "Super Secret Test Content")
+			_ = writer.WriteField("allowed_cidrs", "10.0.0.0/8, 172.16.0.0/12")
+			_ = writer.WriteField("allowed_cidrs", "2001:db8::/32")
+			_ = writer.WriteField("expiration_epoch", strconv.FormatInt(testhelpers.EpochFromNow(time.Hour), 10))
+			_ = writer.Close()
+
+			req, _ := http.NewRequest("POST", "/v2/secrets", body)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			t.Run("it should store every range, repeated or comma separated", func(t *testing.T) {
+				require.Equal(t, http.StatusCreated, w.Code)
+				assert.Equal(t, []string{"10.0.0.0/8", "172.16.0.0/12", "2001:db8::/32"}, written.AllowedCIDRs)
+			})
+		})
+
+		t.Run("and country restrictions are provided", func(t *testing.T) {
+			setupRouter()
+
+			var written models.Secret
+			mockEncryption.EXPECT().Encrypt(gomock.Any(), gomock.Any()).Return("encrypted", nil)
+			mockDataStore.EXPECT().WriteSecret(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ any, secret models.Secret) error {
+					written = secret
+					return nil
+				})
+
+			body := &bytes.Buffer{}
+			writer := multipart.NewWriter(body)
+			_ = writer.WriteField("content", "Super Secret Test Content")
+			_ = writer.WriteField("allowed_countries", "de, fr")
+			_ = writer.WriteField("allowed_countries", "NL")
+			_ = writer.WriteField("denied_countries", "US")
+			_ = writer.WriteField("expiration_epoch", strconv.FormatInt(testhelpers.EpochFromNow(time.Hour), 10))
+			_ = writer.Close()
+
+			req, _ := http.NewRequest("POST", "/v2/secrets", body)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			t.Run("it should store the normalized country codes", func(t *testing.T) {
+				require.Equal(t, http.StatusCreated, w.Code)
+				assert.Equal(t, []string{"DE", "FR", "NL"}, written.AllowedCountries)
+				assert.Equal(t, []string{"US"}, written.DeniedCountries)
+			})
+
+			t.Run("it should return the country restrictions to the creator", func(t *testing.T) {
+				var response models.SecretMetadataResponseV2
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, []string{"DE", "FR", "NL"}, response.AllowedCountries)
+				assert.Equal(t, []string{"US"}, response.DeniedCountries)
+			})
+		})
+
+		createRecipientRequest := func(recipientEmail string) *http.Request {
+			body := &bytes.Buffer{}
+			writer := multipart.NewWriter(body)
+			_ = writer.WriteField("content", "Super Secret Test Content")
+			_ = writer.WriteField("recipient_email", recipientEmail)
+			_ = writer.WriteField("expiration_epoch", strconv.FormatInt(testhelpers.EpochFromNow(time.Hour), 10))
+			_ = writer.Close()
+
+			req, _ := http.NewRequest("POST", "/v2/secrets", body)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			return req
+		}
+
+		t.Run("and a recipient email is provided", func(t *testing.T) {
+			t.Setenv("NOTIFICATIONS_SMTP_ENABLED", "true")
+			setupRouter()
+
+			var written models.Secret
+			mockEncryption.EXPECT().Encrypt(gomock.Any(), gomock.Any()).Return("encrypted", nil)
+			mockDataStore.EXPECT().WriteSecret(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ any, secret models.Secret) error {
+					written = secret
+					return nil
+				})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, createRecipientRequest("recipient@example.com"))
+
+			t.Run("it should store the recipient email", func(t *testing.T) {
+				require.Equal(t, http.StatusCreated, w.Code)
+				assert.Equal(t, "recipient@example.com", written.RecipientEmail)
+			})
+
+			t.Run("it should report that recipient verification is required", func(t *testing.T) {
+				var response models.SecretMetadataResponseV2
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.True(t, response.RecipientVerification)
+			})
+		})
+
+		t.Run("and the recipient email is invalid", func(t *testing.T) {
+			t.Setenv("NOTIFICATIONS_SMTP_ENABLED", "true")
+			setupRouter()
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, createRecipientRequest("not-an-email"))
+
+			t.Run("it should return 400 Bad Request", func(t *testing.T) {
+				assert.Equal(t, http.StatusBadRequest, w.Code)
+			})
+		})
+
+		t.Run("and a recipient email is provided without email notifications enabled", func(t *testing.T) {
+			t.Setenv("NOTIFICATIONS_SMTP_ENABLED", "false")
+			setupRouter()
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, createRecipientRequest("recipient@example.com"))
+
+			t.Run("it should return 400 Bad Request", func(t *testing.T) {
+				assert.Equal(t, http.StatusBadRequest, w.Code)
+			})
+		})
+
+		createKVRequest := func(kv string) *http.Request {
+			body := &bytes.Buffer{}
+			writer := multipart.NewWriter(body)
+			_ = writer.WriteField("kv", kv)
+			_ = writer.WriteField("expiration_epoch", strconv.FormatInt(testhelpers.EpochFromNow(time.Hour), 10))
+			_ = writer.Close()
+
+			req, _ := http.NewRequest("POST", "/v2/secrets", body)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			return req
+		}
+
+		t.Run("and kv fields are provided", func(t *testing.T) {
+			setupRouter()
+
+			var encrypted []byte
+			var written models.Secret
+			mockEncryption.EXPECT().Encrypt(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ any, content []byte) (string, error) {
+					encrypted = append([]byte{}, content...)
+					return "encrypted", nil
+				})
+			mockDataStore.EXPECT().WriteSecret(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ any, secret models.Secret) error {
+					written = secret
+					return nil
+				})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, createKVRequest(`{"username":"admin","password":"hunter2","host":"db.internal"}`))
+
+			t.Run("it should create the secret", func(t *testing.T) {
+				assert.Equal(t, http.StatusCreated, w.Code)
+			})
+
+			t.Run("it should store the fields as a whole", func(t *testing.T) {
+				assert.Equal(t, models.ContentTypeKV, written.ContentType)
+				assert.JSONEq(t, `{"username":"admin","password":"hunter2","host":"db.internal"}`, string(encrypted))
+			})
+		})
+
+		t.Run("and kv is not an object of string fields", func(t *testing.T) {
+			setupRouter()
+
+			t.Run("it should return 400 Bad Request", func(t *testing.T) {
+				for _, kv := range []string{`["admin"]`, `{"port":5432}`, `{}`, `null`, `{"":"value"}`} {
+					w := httptest.NewRecorder()
+					router.ServeHTTP(w, createKVRequest(kv))
+					assert.Equal(t, http.StatusBadRequest, w.Code, kv)
+				}
+			})
+		})
+
+		t.Run("and kv exceeds the schema limits", func(t *testing.T) {
+			setupRouter()
+
+			t.Run("it should return 400 Bad Request", func(t *testing.T) {
+				fields := make(map[string]string, cfg.App().MaxKVFields()+1)
+				for i := 0; i <= cfg.App().MaxKVFields(); i++ {
+					fields[strconv.Itoa(i)] = "value"
+				}
+				tooManyFields, _ := json.Marshal(fields)
+
+				longKey, _ := json.Marshal(map[string]string{strings.Repeat("k", cfg.App().MaxKVKeyLength()+1): "value"})
+
+				for _, kv := range [][]byte{tooManyFields, longKey} {
+					w := httptest.NewRecorder()
+					router.ServeHTTP(w, createKVRequest(string(kv)))
+					assert.Equal(t, http.StatusBadRequest, w.Code)
+				}
+			})
+		})
+
+		t.Run("and bundle size exceeds limit", func(t *testing.T) {
+			setupRouter()
+			halfSize := cfg.App().MaxFileSizeMB()*1024*1024/2 + 1
+
+			t.Run("it should return 413 Payload Too Large", func(t *testing.T) {
+				req := createBundleRequest(map[string][]byte{
+					"a.bin": make([]byte, halfSize),
+					"b.bin": make([]byte, halfSize),
+				}, nil)
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+
+				assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+			})
+		})
 	})
 }
 
@@ -114,12 +481,15 @@ func TestAccessSecretContent(t *testing.T) {
 		cfg := settings.NewConfiguration()
 		ctrl := gomock.NewController(t)
 		mockDataStore := mocks.NewMockDataStore(ctrl)
+		mockDataStore.EXPECT().KeyID(gomock.Any()).Return("key-id").AnyTimes()
 		mockEncryption := mocks.NewMockEncryption(ctrl)
+		mockNotifier := mocks.NewMockNotifier(ctrl)
 
 		router.Use(func(c *gin.Context) {
 			c.Set(settings.Key, cfg)
 			c.Set(datastore.Key, mockDataStore)
 			c.Set(cryptography.Key, mockEncryption)
+			c.Set(notifications.Key, mockNotifier)
 			c.Next()
 		})
 
@@ -133,6 +503,7 @@ func TestAccessSecretContent(t *testing.T) {
 
 		mockDataStore.EXPECT().ReadSecret(gomock.Any(), "test-id-123").Return(secret)
 		mockDataStore.EXPECT().IncreaseAccessCount(gomock.Any(), "test-id-123").Return(int64(1), nil)
+		mockDataStore.EXPECT().AppendSecretHistory(gomock.Any(), "test-id-123", gomock.Any()).Return(nil)
 		mockEncryption.EXPECT().Decrypt(gomock.Any(), gomock.Any()).Return(secret.Content, nil)
 
 		req, _ := http.NewRequest("POST", "/v2/secrets/test-id-123/access", nil)
@@ -155,4 +526,97 @@ func TestAccessSecretContent(t *testing.T) {
 			assert.Equal(t, "no-store, no-cache, must-revalidate", w.Header().Get("Cache-Control"))
 		})
 	})
+
+	t.Run("when accessing a secret that requires recipient verification", func(t *testing.T) {
+		router := gin.New()
+		ctrl := gomock.NewController(t)
+		mockDataStore := mocks.NewMockDataStore(ctrl)
+		mockDataStore.EXPECT().KeyID(gomock.Any()).Return("key-id").AnyTimes()
+		mockEncryption := mocks.NewMockEncryption(ctrl)
+		mockNotifier := mocks.NewMockNotifier(ctrl)
+
+		router.Use(middleware.ErrorHandler())
+		router.Use(func(c *gin.Context) {
+			c.Set(datastore.Key, mockDataStore)
+			c.Set(cryptography.Key, mockEncryption)
+			c.Set(notifications.Key, mockNotifier)
+			c.Next()
+		})
+
+		router.POST("/v2/secrets/:id/access", AccessSecretContent)
+
+		secret := &models.Secret{
+			ID:             "test-id-123",
+			ContentType:    models.ContentTypeText,
+			RecipientEmail: "recipient@example.com",
+		}
+
+		t.Run("and the verification code header is set", func(t *testing.T) {
+			mockDataStore.EXPECT().ReadSecret(gomock.Any(), "test-id-123").Return(secret)
+			mockDataStore.EXPECT().ReadVerificationCode(gomock.Any(), "test-id-123", gomock.Any()).Return("stored-code-hash", int64(1), nil)
+
+			req, _ := http.NewRequest("POST", "/v2/secrets/test-id-123/access", nil)
+			req.Header.Set(controllers.VerificationCodeHeader, "123456")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			t.Run("it should check the code and reject a wrong one with 403 Forbidden", func(t *testing.T) {
+				assert.Equal(t, http.StatusForbidden, w.Code)
+			})
+		})
+
+		t.Run("and the verification code header is missing", func(t *testing.T) {
+			mockDataStore.EXPECT().ReadSecret(gomock.Any(), "test-id-123").Return(secret)
+
+			req, _ := http.NewRequest("POST", "/v2/secrets/test-id-123/access", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			t.Run("it should return 403 Forbidden", func(t *testing.T) {
+				assert.Equal(t, http.StatusForbidden, w.Code)
+			})
+		})
+	})
+
+	t.Run("when accessing a kv secret", func(t *testing.T) {
+		router := gin.New()
+		ctrl := gomock.NewController(t)
+		mockDataStore := mocks.NewMockDataStore(ctrl)
+		mockDataStore.EXPECT().KeyID(gomock.Any()).Return("key-id").AnyTimes()
+		mockEncryption := mocks.NewMockEncryption(ctrl)
+		mockNotifier := mocks.NewMockNotifier(ctrl)
+
+		router.Use(func(c *gin.Context) {
+			c.Set(datastore.Key, mockDataStore)
+			c.Set(cryptography.Key, mockEncryption)
+			c.Set(notifications.Key, mockNotifier)
+			c.Next()
+		})
+
+		router.POST("/v2/secrets/:id/access", AccessSecretContent)
+
+		secret := &models.Secret{
+			ID:          "test-id-123",
+			Content:     []byte(`{"password":"hunter2","username":"admin"}`),
+			ContentType: models.ContentTypeKV,
+		}
+
+		mockDataStore.EXPECT().ReadSecret(gomock.Any(), "test-id-123").Return(secret)
+		mockDataStore.EXPECT().IncreaseAccessCount(gomock.Any(), "test-id-123").Return(int64(1), nil)
+		mockDataStore.EXPECT().AppendSecretHistory(gomock.Any(), "test-id-123", gomock.Any()).Return(nil)
+		mockEncryption.EXPECT().Decrypt(gomock.Any(), gomock.Any()).Return(secret.Content, nil)
+
+		req, _ := http.NewRequest("POST", "/v2/secrets/test-id-123/access", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		t.Run("it should return the fields as a JSON object", func(t *testing.T) {
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var response models.SecretKVContentResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, models.ContentType(models.ContentTypeKV), response.ContentType)
+			assert.Equal(t, map[string]string{"username": "admin", "password": "hunter2"}, response.Content)
+		})
+	})
 }
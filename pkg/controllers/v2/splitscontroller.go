@@ -0,0 +1,160 @@
+package v2
+
+import (
+	"cellar/pkg/commands"
+	"cellar/pkg/controllers"
+	"cellar/pkg/cryptography"
+	"cellar/pkg/datastore"
+	pkgerrors "cellar/pkg/errors"
+	"cellar/pkg/models"
+	"cellar/pkg/notifications"
+	"cellar/pkg/plaintext"
+	"cellar/pkg/settings"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Create Split Secret. Splits the content into Shamir shares, each stored as its own secret, of which threshold reconstruct it
+// @Tags v2
+// @Produce application/json
+// @Accept multipart/form-data
+// @Param shares formData int true "Number of shares to create"
+// @Param threshold formData int true "Number of shares required to reconstruct the content"
+// @Param content formData string false "Secret content"
+// @Param file formData file false "Secret content as a file"
+// @Param access_limit formData int false "Access limit of each share"
+// @Param expiration_epoch formData int true "Expiration of the shares in Unix Epoch Time"
+// @Param available_from_epoch formData int false "Time from which the shares can be accessed in Unix Epoch Time"
+// @Param view_window_seconds formData int false "Seconds each share stays accessible after it is first accessed"
+// @Param notify_email formData string false "Email address to notify when a share is opened or expires unread"
+// @Success 201 {object} models.CreateSplitSecretResponse
+// @Failure 400 {object} httputil.HTTPError "Bad Request - validation error"
+// @Failure 401 {object} httputil.HTTPError "Unauthorized - API key with the required scope is missing or invalid"
+// @Failure 408 {object} httputil.HTTPError "Request Timeout - operation cancelled"
+// @Failure 413 {object} httputil.HTTPError "Payload Too Large - file exceeds size limit"
+// @Failure 500 {object} httputil.HTTPError
+// @Failure 503 {object} httputil.HTTPError "Service Unavailable - the server is in read-only mode"
+// @Security ApiKeyAuth
+// @Router /v2/splits [post]
+func CreateSplitSecret(c *gin.Context) {
+	ctx := c.Request.Context()
+	cfg := c.MustGet(settings.Key).(settings.IConfiguration)
+	dataStore := c.MustGet(datastore.Key).(datastore.DataStore)
+	encryption := c.MustGet(cryptography.Key).(cryptography.Encryption)
+
+	shares, err := strconv.Atoi(c.PostForm("shares"))
+	if err != nil {
+		_ = c.Error(pkgerrors.NewValidationError("required parameter: shares"))
+		return
+	}
+
+	threshold, err := strconv.Atoi(c.PostForm("threshold"))
+	if err != nil {
+		_ = c.Error(pkgerrors.NewValidationError("required parameter: threshold"))
+		return
+	}
+
+	var secret models.Secret
+	if err = readSecretOptions(c, &secret); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err = readSecretContent(c, cfg, &secret); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	defer plaintext.Wipe(secret.Content)
+
+	secret.Creator = controllers.Creator(c)
+
+	metadata, err := commands.CreateSplitSecret(ctx, cfg.App(), dataStore, encryption, secret, shares, threshold)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.CreateSplitSecretResponse{
+		ID:          metadata.ID,
+		ContentType: metadata.ContentType,
+		Threshold:   metadata.Threshold,
+		Shares:      metadata.Shares,
+		Expiration:  metadata.Expiration,
+	})
+}
+
+// @Summary Get Split Secret Metadata
+// @Tags v2
+// @Produce json
+// @Accept json
+// @Param id path string true "Split Secret ID"
+// @Success 200 {object} models.SplitSecretMetadataResponse
+// @Failure 404 {object} httputil.HTTPError
+// @Failure 408 {object} httputil.HTTPError "Request Timeout - operation cancelled"
+// @Failure 500 {object} httputil.HTTPError
+// @Router /v2/splits/{id} [get]
+func GetSplitSecretMetadata(c *gin.Context) {
+	ctx := c.Request.Context()
+	dataStore := c.MustGet(datastore.Key).(datastore.DataStore)
+
+	id := c.Param("id")
+
+	metadata := commands.GetSplitSecretMetadata(ctx, dataStore, id)
+	if metadata == nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SplitSecretMetadataResponse{
+		ID:          metadata.ID,
+		ContentType: metadata.ContentType,
+		Threshold:   metadata.Threshold,
+		ShareCount:  metadata.ShareCount,
+		Expiration:  metadata.Expiration,
+	})
+}
+
+// @Summary Combine Split Secret. Reconstructs the content from threshold share IDs, consuming an access of each share used
+// @Tags v2
+// @Produce application/json,application/octet-stream,application/zip
+// @Accept multipart/form-data
+// @Param id path string true "Split Secret ID"
+// @Param share_ids formData []string true "IDs of the shares, repeated or comma separated" collectionFormat(multi)
+// @Success 200 {object} models.SecretContentResponse
+// @Failure 400 {object} httputil.HTTPError "Bad Request - too few available shares or share does not belong to the split"
+// @Failure 404 {object} httputil.HTTPError
+// @Failure 408 {object} httputil.HTTPError "Request Timeout - operation cancelled"
+// @Failure 500 {object} httputil.HTTPError
+// @Router /v2/splits/{id}/combine [post]
+func CombineSplitSecret(c *gin.Context) {
+	ctx := c.Request.Context()
+	dataStore := c.MustGet(datastore.Key).(datastore.DataStore)
+	encryption := c.MustGet(cryptography.Key).(cryptography.Encryption)
+	notifier := c.MustGet(notifications.Key).(notifications.Notifier)
+
+	id := c.Param("id")
+
+	var shareIds []string
+	for _, value := range c.PostFormArray("share_ids") {
+		for _, shareId := range strings.Split(value, ",") {
+			shareIds = append(shareIds, strings.TrimSpace(shareId))
+		}
+	}
+
+	secret, err := commands.CombineSplitSecret(ctx, dataStore, encryption, notifier, id, shareIds, controllers.Client(c))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if secret == nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	defer plaintext.Wipe(secret.Content)
+
+	writeSecretContent(c, secret)
+}
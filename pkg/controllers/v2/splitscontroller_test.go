@@ -0,0 +1,81 @@
+package v2
+
+import (
+	"bytes"
+	"cellar/pkg/cryptography"
+	"cellar/pkg/datastore"
+	"cellar/pkg/middleware"
+	"cellar/pkg/mocks"
+	"cellar/pkg/models"
+	"cellar/pkg/oidc"
+	"cellar/pkg/settings"
+	"cellar/testing/testhelpers"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateSplitSecret(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("when an SSO user creates a split secret", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockEncryption := mocks.NewMockEncryption(ctrl)
+		mockEncryption.EXPECT().Encrypt(gomock.Any(), gomock.Any()).Return("encrypted", nil).Times(2)
+
+		var written []models.Secret
+		mockDataStore := mocks.NewMockDataStore(ctrl)
+		mockDataStore.EXPECT().KeyID(gomock.Any()).Return("key-id").AnyTimes()
+		mockDataStore.EXPECT().
+			WriteSecret(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, secret models.Secret) error {
+				written = append(written, secret)
+				return nil
+			}).
+			Times(2)
+		mockDataStore.EXPECT().WriteSplitSecret(gomock.Any(), gomock.Any()).Return(nil)
+
+		router := gin.New()
+		router.Use(middleware.ErrorHandler())
+		router.Use(func(c *gin.Context) {
+			c.Set(settings.Key, settings.NewConfiguration())
+			c.Set(datastore.Key, mockDataStore)
+			c.Set(cryptography.Key, mockEncryption)
+			c.Set(oidc.IdentityKey, &models.Identity{Subject: "alice@example.com"})
+			c.Next()
+		})
+		router.POST("/v2/splits", CreateSplitSecret)
+
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		_ = writer.WriteField("shares", "2")
+		_ = writer.WriteField("threshold", "2")
+		_ = writer.WriteField("content", "my very secret text")
+		_ = writer.WriteField("expiration_epoch", strconv.FormatInt(testhelpers.EpochFromNow(time.Hour), 10))
+		_ = writer.Close()
+
+		req, _ := http.NewRequest("POST", "/v2/splits", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		t.Run("it should return 201 Created", func(t *testing.T) {
+			assert.Equal(t, http.StatusCreated, w.Code)
+		})
+
+		t.Run("it should record the user as the creator of every share", func(t *testing.T) {
+			assert.Len(t, written, 2)
+			for _, share := range written {
+				assert.Equal(t, "alice@example.com", share.Creator)
+			}
+		})
+	})
+}
@@ -3,6 +3,7 @@ package vault
 import (
 	pkgerrors "cellar/pkg/errors"
 	"cellar/pkg/models"
+	securemem "cellar/pkg/plaintext"
 	"cellar/pkg/settings/cryptography"
 	"context"
 	"encoding/base64"
@@ -101,13 +102,12 @@ func (vault EncryptionClient) Encrypt(ctx context.Context, plaintext []byte) (ci
 		return "", err
 	}
 
-	base64Content := base64.StdEncoding.EncodeToString(plaintext)
+	body := encryptRequestBody(plaintext)
+	defer securemem.Wipe(body)
 
 	vault.logger.Debug("attempting to encrypt content with vault")
 	path := fmt.Sprintf("transit/encrypt/%s", vault.configuration.EncryptionTokenName())
-	response, err := vault.client.Logical().Write(path, map[string]interface{}{
-		"plaintext": base64Content,
-	})
+	response, err := vault.client.Logical().WriteBytesWithContext(ctx, path, body)
 
 	if err != nil {
 		vault.logger.WithError(err).
@@ -154,14 +154,28 @@ func (vault EncryptionClient) Decrypt(ctx context.Context, ciphertext string) (p
 		if !ok {
 			return nil, errors.New("vault returned non-string plaintext")
 		}
-		if bytes, err := base64.StdEncoding.DecodeString(base64Content); err != nil {
+		content := securemem.Alloc(base64.StdEncoding.DecodedLen(len(base64Content)))
+		if n, err := base64.StdEncoding.Decode(content, []byte(base64Content)); err != nil {
+			securemem.Wipe(content)
 			vault.logger.WithError(err).
 				Error("error base64 decoding decrypted content")
 		} else {
 			vault.logger.Debug("content decryption successful")
-			return bytes, nil
+			return content[:n], nil
 		}
 	}
 
 	return nil, errors.New("unexpected response while decrypting secret")
 }
+
+// encryptRequestBody encodes the body of a transit encrypt request into a slice allocated with plaintext.Alloc,
+// so the base64 encoded content never becomes a string that cannot be wiped.
+func encryptRequestBody(content []byte) []byte {
+	const prefix, suffix = `{"plaintext":"`, `"}`
+
+	body := securemem.Alloc(len(prefix) + base64.StdEncoding.EncodedLen(len(content)) + len(suffix))
+	copy(body, prefix)
+	base64.StdEncoding.Encode(body[len(prefix):], content)
+	copy(body[len(body)-len(suffix):], suffix)
+	return body
+}
@@ -3,6 +3,7 @@ package datastore
 import (
 	"cellar/pkg/models"
 	"context"
+	"time"
 )
 
 var Key = "DATASTORE"
@@ -10,8 +11,36 @@ var Key = "DATASTORE"
 //go:generate mockgen -destination=../mocks/mock_datastore.go -package=mocks . DataStore
 type DataStore interface {
 	Health(ctx context.Context) models.Health
+	KeyID(id string) (keyId string)
 	WriteSecret(ctx context.Context, secret models.Secret) (err error)
 	ReadSecret(ctx context.Context, id string) (secret *models.Secret)
 	IncreaseAccessCount(ctx context.Context, id string) (accessCount int64, err error)
 	DeleteSecret(ctx context.Context, id string) (found bool, err error)
+	StartViewWindow(ctx context.Context, id string, expirationEpoch int64) (err error)
+	UpdateSecret(ctx context.Context, id string, expirationEpoch int64, accessLimit int) (found bool, err error)
+	AppendSecretHistory(ctx context.Context, id string, event models.SecretHistoryEvent) (err error)
+	ReadSecretHistory(ctx context.Context, id string) (events []models.SecretHistoryEvent, err error)
+	CancelExpiryNotification(ctx context.Context, id string) (err error)
+	TakeExpiryNotification(ctx context.Context, keyId string) (notice models.ExpiryNotice, err error)
+	WriteVerificationCode(ctx context.Context, id string, codeHash string, ttl time.Duration) (written bool, err error)
+	ReadVerificationCode(ctx context.Context, id string, attemptWindow time.Duration) (codeHash string, attempts int64, err error)
+	ConsumeVerificationCode(ctx context.Context, id string, codeHash string) (consumed bool, err error)
+	WriteSecretRequest(ctx context.Context, request models.SecretRequest) (err error)
+	ReadSecretRequest(ctx context.Context, id string) (request *models.SecretRequest)
+	FulfillSecretRequest(ctx context.Context, request models.SecretRequest, secretId string) (fulfilled bool, err error)
+	WriteSplitSecret(ctx context.Context, split models.SplitSecret) (err error)
+	ReadSplitSecret(ctx context.Context, id string) (split *models.SplitSecret)
+}
+
+// AdminDataStore is a DataStore that can also be administered as a whole: its secrets can be scanned and burned
+// by key ID, and it can be put in read-only mode. Scanning only visits the secrets of the datastore's own namespace,
+// while read-only mode applies to the whole server.
+//
+//go:generate mockgen -destination=../mocks/mock_admin_datastore.go -package=mocks . AdminDataStore
+type AdminDataStore interface {
+	DataStore
+	ScanSecrets(ctx context.Context, visit func(record models.SecretRecord) error) (err error)
+	BurnSecret(ctx context.Context, keyId string) (found bool, err error)
+	ReadOnly(ctx context.Context) (readOnly bool, err error)
+	SetReadOnly(ctx context.Context, readOnly bool) (err error)
 }
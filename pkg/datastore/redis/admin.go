@@ -0,0 +1,127 @@
+package redis
+
+import (
+	pkgerrors "cellar/pkg/errors"
+	"cellar/pkg/models"
+	"context"
+	"strings"
+)
+
+// adminScanCount is the number of keys asked for on each SCAN while visiting the secrets of the datastore.
+const adminScanCount = 1000
+
+// ScanSecrets visits every secret stored under the key prefix of the datastore, with the size of its
+// encrypted content. Secrets of other tenants are skipped. A secret written or removed during the scan
+// may or may not be visited, but none is visited twice. An error returned by visit stops the scan.
+func (redis DataStore) ScanSecrets(ctx context.Context, visit func(record models.SecretRecord) error) error {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+	iter := redis.client.Scan(ctx, 0, escapeGlob(redis.keyPrefix)+"secrets:*:content", adminScanCount).Iterator()
+	for iter.Next(ctx) {
+		scopedId, ok := keyIdFromContentKey(iter.Val())
+		if !ok || seen[scopedId] {
+			continue
+		}
+		seen[scopedId] = true
+
+		keySet := redisKeySetForScopedId(scopedId)
+		if keySet.prefix != redis.keyPrefix {
+			continue
+		}
+
+		record, found, err := redis.readSecretRecord(ctx, keySet)
+		if err != nil {
+			return err
+		}
+		if !found {
+			continue
+		}
+		if err = visit(record); err != nil {
+			return err
+		}
+	}
+
+	return iter.Err()
+}
+
+func (redis DataStore) readSecretRecord(ctx context.Context, keySet *RedisKey) (models.SecretRecord, bool, error) {
+	var size *intCmd
+	var creator, created *stringCmd
+	_, err := redis.client.Pipelined(ctx, func(pipe pipeliner) error {
+		size = pipe.StrLen(ctx, keySet.Content())
+		creator = pipe.Get(ctx, keySet.Creator())
+		created = pipe.Get(ctx, keySet.Created())
+		return nil
+	})
+	if err != nil && !isNil(err) {
+		return models.SecretRecord{}, false, err
+	}
+
+	// The content expired or was deleted since the scan found it.
+	if size.Val() == 0 {
+		return models.SecretRecord{}, false, nil
+	}
+
+	createdEpoch, _ := created.Int64()
+	return models.SecretRecord{
+		KeyID:        keySet.id,
+		Creator:      creator.Val(),
+		CreatedEpoch: createdEpoch,
+		Size:         size.Val(),
+	}, true, nil
+}
+
+// BurnSecret deletes the secret stored under the key ID in the datastore's namespace, for when only
+// the key ID of a secret is known, as it is from the logs.
+func (redis DataStore) BurnSecret(ctx context.Context, keyId string) (bool, error) {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return false, err
+	}
+	if keyId == "" || strings.ContainsAny(keyId, ":*?[]\\") {
+		return false, pkgerrors.NewValidationError("invalid key id")
+	}
+
+	keySet := redisKeySetForKeyId(keyId)
+	keySet.prefix = redis.keyPrefix
+	return redis.deleteSecret(ctx, keySet)
+}
+
+// ReadOnly reports whether the server is in read-only mode.
+func (redis DataStore) ReadOnly(ctx context.Context) (bool, error) {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return false, err
+	}
+
+	count, err := redis.client.Exists(ctx, readOnlyKey).Result()
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// SetReadOnly puts the server in or out of read-only mode, for every instance and tenant sharing the redis server.
+func (redis DataStore) SetReadOnly(ctx context.Context, readOnly bool) error {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return err
+	}
+
+	if readOnly {
+		return redis.client.Set(ctx, readOnlyKey, "1", 0).Err()
+	}
+	return redis.client.Del(ctx, readOnlyKey).Err()
+}
+
+// escapeGlob escapes the characters SCAN would read as part of a pattern, so the prefix only matches itself.
+func escapeGlob(prefix string) string {
+	var escaped strings.Builder
+	for _, r := range prefix {
+		if strings.ContainsRune(`*?[]\`, r) {
+			escaped.WriteByte('\\')
+		}
+		escaped.WriteRune(r)
+	}
+	return escaped.String()
+}
@@ -0,0 +1,176 @@
+package redis
+
+import (
+	pkgerrors "cellar/pkg/errors"
+	"cellar/pkg/lifecycle"
+	"cellar/pkg/models"
+	"cellar/pkg/settings/datastore"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	log "github.com/sirupsen/logrus"
+)
+
+const notifyKeyspaceEventsParameter = "notify-keyspace-events"
+
+// ExpiryListener turns secrets expiring in Redis into lifecycle events.
+// It subscribes to keyspace expired events for secret content keys and periodically sweeps
+// the expiration index so that expiries missed while no listener was running are still processed.
+type ExpiryListener struct {
+	client        *redis.Client
+	handler       lifecycle.Handler
+	channel       string
+	sweepInterval time.Duration
+	logger        *log.Entry
+}
+
+func NewExpiryListener(dataStore *DataStore, configuration datastore.IRedisConfiguration, handler lifecycle.Handler) *ExpiryListener {
+	return &ExpiryListener{
+		client:        dataStore.Client(),
+		handler:       handler,
+		channel:       fmt.Sprintf("__keyevent@%d__:expired", configuration.DB()),
+		sweepInterval: time.Duration(configuration.ExpirySweepIntervalSeconds()) * time.Second,
+		logger: log.WithFields(log.Fields{
+			"context":  "expiry listener",
+			"instance": "redis",
+		}),
+	}
+}
+
+// Run listens for expiry events until the context is cancelled.
+// A reconciliation sweep runs once the subscription is established and again on every sweep interval.
+func (listener *ExpiryListener) Run(ctx context.Context) error {
+	if err := listener.enableKeyspaceNotifications(ctx); err != nil {
+		listener.logger.WithError(err).
+			Warn("unable to enable keyspace notifications, expiries will only be found by reconciliation sweeps")
+	}
+
+	pubsub := listener.client.Subscribe(ctx, listener.channel)
+	defer func() { _ = pubsub.Close() }()
+
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return err
+	}
+	listener.logger.WithField("channel", listener.channel).Info("listening for secret expiry events")
+
+	listener.sweepAndLog(ctx)
+
+	ticker := time.NewTicker(listener.sweepInterval)
+	defer ticker.Stop()
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case message, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			if keyId, ok := keyIdFromContentKey(message.Payload); ok {
+				if _, err := listener.expire(ctx, keyId); err != nil {
+					listener.logger.WithError(err).Error("error processing secret expiry event")
+				}
+			}
+		case <-ticker.C:
+			listener.sweepAndLog(ctx)
+		}
+	}
+}
+
+// Sweep emits expiry events for every indexed secret that is past its expiration and no longer exists.
+// Secrets whose expiration was extended are re-indexed at their new expiration instead.
+// Returns the number of expiry events emitted.
+func (listener *ExpiryListener) Sweep(ctx context.Context) (int, error) {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	keyIds, err := listener.client.ZRangeByScore(ctx, expirationIndexKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(now.Unix(), 10),
+	}).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, keyId := range keyIds {
+		ttl, err := listener.client.TTL(ctx, redisKeySetForScopedId(keyId).Content()).Result()
+		if err != nil {
+			return expired, err
+		}
+
+		if ttl > 0 {
+			entry := expirationIndexEntry(keyId, now.Add(ttl).Unix())
+			if err = listener.client.ZAdd(ctx, expirationIndexKey, entry).Err(); err != nil {
+				return expired, err
+			}
+			continue
+		}
+
+		claimed, err := listener.expire(ctx, keyId)
+		if err != nil {
+			return expired, err
+		}
+		if claimed {
+			expired++
+		}
+	}
+
+	return expired, nil
+}
+
+func (listener *ExpiryListener) sweepAndLog(ctx context.Context) {
+	expired, err := listener.Sweep(ctx)
+	if err != nil {
+		listener.logger.WithError(err).Error("error during expiry reconciliation sweep")
+		return
+	}
+	listener.logger.WithField("expired", expired).Debug("expiry reconciliation sweep complete")
+}
+
+// expire claims the expiry of a secret by removing it from the expiration index and emits an expired event.
+// Only one caller can remove a key ID, so each expiry is emitted once even with several listeners running.
+// Secrets that were deleted before expiring are no longer indexed and are ignored.
+func (listener *ExpiryListener) expire(ctx context.Context, keyId string) (bool, error) {
+	claimed, err := listener.client.ZRem(ctx, expirationIndexKey, keyId).Result()
+	if err != nil {
+		return false, err
+	}
+	if claimed == 0 {
+		return false, nil
+	}
+
+	listener.handler.Handle(ctx, models.LifecycleEvent{
+		Type:       models.LifecycleSecretExpired,
+		KeyID:      keyId,
+		OccurredAt: time.Now().UTC(),
+	})
+
+	return true, nil
+}
+
+func (listener *ExpiryListener) enableKeyspaceNotifications(ctx context.Context) error {
+	current, err := listener.client.ConfigGet(ctx, notifyKeyspaceEventsParameter).Result()
+	if err != nil {
+		return err
+	}
+
+	flags := current[notifyKeyspaceEventsParameter]
+	if hasExpiredKeyEvents(flags) {
+		return nil
+	}
+
+	return listener.client.ConfigSet(ctx, notifyKeyspaceEventsParameter, flags+"Ex").Err()
+}
+
+// hasExpiredKeyEvents reports whether the notify-keyspace-events flags publish keyevent notifications for expired keys.
+func hasExpiredKeyEvents(flags string) bool {
+	return strings.Contains(flags, "E") && strings.ContainsAny(flags, "xA")
+}
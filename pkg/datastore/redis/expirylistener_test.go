@@ -0,0 +1,102 @@
+package redis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyIdFromContentKey(t *testing.T) {
+	t.Run("when key is a secret content key", func(t *testing.T) {
+		id, ok := keyIdFromContentKey("secrets:1234567890:content")
+
+		t.Run("it should return true", func(t *testing.T) {
+			assert.True(t, ok)
+		})
+
+		t.Run("it should return the key id", func(t *testing.T) {
+			assert.Equal(t, "1234567890", id)
+		})
+	})
+
+	t.Run("when key is another secret key", func(t *testing.T) {
+		t.Run("it should return false", func(t *testing.T) {
+			_, ok := keyIdFromContentKey("secrets:1234567890:contenttype")
+			assert.False(t, ok)
+		})
+	})
+
+	t.Run("when key is not a secret key", func(t *testing.T) {
+		t.Run("it should return false", func(t *testing.T) {
+			_, ok := keyIdFromContentKey("cellar:ratelimit:127.0.0.1:tier1")
+			assert.False(t, ok)
+		})
+	})
+
+	t.Run("when key has no id", func(t *testing.T) {
+		t.Run("it should return false", func(t *testing.T) {
+			_, ok := keyIdFromContentKey("secrets::content")
+			assert.False(t, ok)
+		})
+	})
+
+	t.Run("when key is the content key of a secret stored with a key prefix", func(t *testing.T) {
+		id, ok := keyIdFromContentKey("tenants:finance:secrets:1234567890:content")
+
+		t.Run("it should return the key id qualified by the prefix", func(t *testing.T) {
+			assert.True(t, ok)
+			assert.Equal(t, "tenants:finance:1234567890", id)
+		})
+
+		t.Run("it should name the keys of the secret", func(t *testing.T) {
+			assert.Equal(t, "tenants:finance:secrets:1234567890:content", redisKeySetForScopedId(id).Content())
+		})
+	})
+
+	t.Run("when key has a prefix that is not separated from the secret keys", func(t *testing.T) {
+		t.Run("it should return false", func(t *testing.T) {
+			_, ok := keyIdFromContentKey("financesecrets:1234567890:content")
+			assert.False(t, ok)
+		})
+	})
+}
+
+func TestRedisKeySetForScopedId(t *testing.T) {
+	t.Run("when the scoped id has no prefix", func(t *testing.T) {
+		keySet := redisKeySetForScopedId("1234567890")
+
+		t.Run("it should name the keys without a prefix", func(t *testing.T) {
+			assert.Equal(t, "secrets:1234567890:content", keySet.Content())
+			assert.Equal(t, "1234567890", keySet.scopedId())
+		})
+	})
+
+	t.Run("when the scoped id has a prefix", func(t *testing.T) {
+		keySet := redisKeySetForScopedId("tenants:finance:1234567890")
+
+		t.Run("it should name the keys under the prefix", func(t *testing.T) {
+			assert.Equal(t, "tenants:finance:secrets:1234567890:content", keySet.Content())
+			assert.Equal(t, "tenants:finance:1234567890", keySet.scopedId())
+		})
+	})
+}
+
+func TestHasExpiredKeyEvents(t *testing.T) {
+	testCases := []struct {
+		flags    string
+		expected bool
+	}{
+		{flags: "", expected: false},
+		{flags: "Ex", expected: true},
+		{flags: "xE", expected: true},
+		{flags: "AKE", expected: true},
+		{flags: "Kx", expected: false},
+		{flags: "E", expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run("when flags are '"+tc.flags+"'", func(t *testing.T) {
+			assert.Equal(t, tc.expected, hasExpiredKeyEvents(tc.flags))
+		})
+	}
+}
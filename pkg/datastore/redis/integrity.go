@@ -0,0 +1,198 @@
+package redis
+
+import (
+	"cellar/pkg/integrity"
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// taggedRecord is the metadata of a secret covered by its integrity tag: everything a change made directly
+// in the datastore could use to keep a secret around longer, open it more often, lift its restrictions,
+// swap its content, redirect its notifications or hide it from purges. The access count is covered too,
+// and tagged again on every access. The expiry notice is not covered, as it outlives the secret and its tag.
+type taggedRecord struct {
+	accessCount        int
+	accessLimit        int
+	expirationEpoch    int64
+	contentType        string
+	filename           string
+	cipherText         string
+	ownerTokenHash     string
+	ownerOnly          bool
+	availableFromEpoch int64
+	viewWindowSeconds  int
+	splitKeyCheck      string
+	recipientEmail     string
+	allowedCIDRs       []string
+	allowedCountries   []string
+	deniedCountries    []string
+	padded             bool
+	bundleItems        string
+	notifyEmail        string
+	createdEpoch       int64
+	creator            string
+}
+
+// fields binds the record to its key ID, so a tagged record copied over another secret does not verify.
+// The creator is only covered if there is one, so secrets tagged before creators were recorded still verify.
+func (record taggedRecord) fields(keyId string) []string {
+	fields := []string{
+		keyId,
+		strconv.Itoa(record.accessCount),
+		strconv.Itoa(record.accessLimit),
+		strconv.FormatInt(record.expirationEpoch, 10),
+		record.contentType,
+		record.filename,
+		integrity.Digest(record.cipherText),
+		record.ownerTokenHash,
+		strconv.FormatBool(record.ownerOnly),
+		strconv.FormatInt(record.availableFromEpoch, 10),
+		strconv.Itoa(record.viewWindowSeconds),
+		record.splitKeyCheck,
+		record.recipientEmail,
+		strings.Join(record.allowedCIDRs, ","),
+		strings.Join(record.allowedCountries, ","),
+		strings.Join(record.deniedCountries, ","),
+		strconv.FormatBool(record.padded),
+		record.bundleItems,
+		record.notifyEmail,
+		strconv.FormatInt(record.createdEpoch, 10),
+	}
+	if record.creator != "" {
+		fields = append(fields, record.creator)
+	}
+	return fields
+}
+
+// readTaggedRecord reads the metadata of a secret covered by its tag through client.
+// Returns a nil error from the datastore if the secret does not exist.
+func readTaggedRecord(ctx context.Context, client cmdable, keySet *RedisKey) (taggedRecord, error) {
+	var record taggedRecord
+	var err error
+	if record.accessLimit, err = client.Get(ctx, keySet.AccessLimit()).Int(); err != nil {
+		return taggedRecord{}, err
+	}
+	if record.accessCount, err = client.Get(ctx, keySet.Access()).Int(); err != nil {
+		return taggedRecord{}, err
+	}
+	if record.expirationEpoch, err = client.Get(ctx, keySet.ExpirationEpoch()).Int64(); err != nil {
+		return taggedRecord{}, err
+	}
+	if record.contentType, err = client.Get(ctx, keySet.ContentType()).Result(); err != nil {
+		return taggedRecord{}, err
+	}
+	if record.cipherText, err = client.Get(ctx, keySet.Content()).Result(); err != nil {
+		return taggedRecord{}, err
+	}
+
+	for key, value := range map[string]*string{
+		keySet.Filename():       &record.filename,
+		keySet.OwnerToken():     &record.ownerTokenHash,
+		keySet.SplitKeyCheck():  &record.splitKeyCheck,
+		keySet.RecipientEmail(): &record.recipientEmail,
+		keySet.BundleItems():    &record.bundleItems,
+		keySet.NotifyEmail():    &record.notifyEmail,
+		keySet.Creator():        &record.creator,
+	} {
+		if *value, err = client.Get(ctx, key).Result(); err != nil && !isNil(err) {
+			return taggedRecord{}, err
+		}
+	}
+	for key, value := range map[string]*bool{
+		keySet.OwnerOnly(): &record.ownerOnly,
+		keySet.Padded():    &record.padded,
+	} {
+		if *value, err = client.Get(ctx, key).Bool(); err != nil && !isNil(err) {
+			return taggedRecord{}, err
+		}
+	}
+	if record.availableFromEpoch, err = client.Get(ctx, keySet.AvailableFrom()).Int64(); err != nil && !isNil(err) {
+		return taggedRecord{}, err
+	}
+	if record.viewWindowSeconds, err = client.Get(ctx, keySet.ViewWindow()).Int(); err != nil && !isNil(err) {
+		return taggedRecord{}, err
+	}
+	if record.createdEpoch, err = client.Get(ctx, keySet.Created()).Int64(); err != nil && !isNil(err) {
+		return taggedRecord{}, err
+	}
+	for key, list := range map[string]*[]string{
+		keySet.AllowedCIDRs():     &record.allowedCIDRs,
+		keySet.AllowedCountries(): &record.allowedCountries,
+		keySet.DeniedCountries():  &record.deniedCountries,
+	} {
+		value, err := client.Get(ctx, key).Bytes()
+		if isNil(err) {
+			continue
+		}
+		if err != nil {
+			return taggedRecord{}, err
+		}
+		if err = json.Unmarshal(value, list); err != nil {
+			return taggedRecord{}, err
+		}
+	}
+	return record, nil
+}
+
+// verifyIntegrity checks a secret read from the datastore against its tag, and raises an alert if it does not verify.
+func (redis DataStore) verifyIntegrity(ctx context.Context, client cmdable, keySet *RedisKey, record taggedRecord) error {
+	if !integrity.Enabled() {
+		return nil
+	}
+
+	tag, err := client.Get(ctx, keySet.Integrity()).Result()
+	if err != nil && !isNil(err) {
+		return err
+	}
+
+	if err = integrity.Verify(tag, record.fields(keySet.scopedId())...); err != nil {
+		redis.logger.WithError(err).
+			WithField(redisIdFieldKey, keySet.id).
+			WithField("alert", "integrity").
+			Error("secret failed its integrity check and may have been changed in the datastore")
+	}
+	return err
+}
+
+// secretTag is the tag a secret has once it is updated, and the expiration the secret has then.
+type secretTag struct {
+	value           string
+	expirationEpoch int64
+}
+
+// retag checks a secret against its tag and returns the tag it has once update is applied to it, under the current key.
+// The secret is read through client, which is the transaction the new tag is written in when the secret is watched.
+// Returns a nil tag if integrity keys are not configured, and a nil error from the datastore if the secret does not exist.
+func (redis DataStore) retag(ctx context.Context, client cmdable, keySet *RedisKey, update func(record *taggedRecord)) (*secretTag, error) {
+	if !integrity.Enabled() {
+		return nil, nil
+	}
+
+	record, err := readTaggedRecord(ctx, client, keySet)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = redis.verifyIntegrity(ctx, client, keySet, record); err != nil {
+		return nil, err
+	}
+
+	update(&record)
+	return &secretTag{
+		value:           integrity.Tag(record.fields(keySet.scopedId())...),
+		expirationEpoch: record.expirationEpoch,
+	}, nil
+}
+
+// queueTag queues the commands that store a secret's new tag and expire it along with the secret. The tag is written
+// whether or not it exists, so a secret written before integrity keys were configured is tagged the first time it changes.
+func queueTag(ctx context.Context, pipe pipeliner, keySet *RedisKey, tag *secretTag) {
+	if tag == nil {
+		return
+	}
+	pipe.Set(ctx, keySet.Integrity(), tag.value, 0)
+	pipe.ExpireAt(ctx, keySet.Integrity(), time.Unix(tag.expirationEpoch, 0))
+}
@@ -0,0 +1,174 @@
+package redis
+
+import (
+	pkgerrors "cellar/pkg/errors"
+	"cellar/pkg/keyid"
+	"cellar/pkg/models"
+	"context"
+	"encoding/json"
+	"strings"
+)
+
+// migrationScanCount is the number of keys asked for on each SCAN while looking for records to migrate.
+const migrationScanCount = 1000
+
+// MigrateKeys moves the records that were stored under their IDs, before key IDs were introduced, to their key IDs.
+// Keys keep their remaining time to live, the expiration index and expiry notifications are rewritten,
+// and split secrets track their shares by key ID.
+// It scans the whole keyspace, so it is run once, on request, rather than on every start.
+// It is safe to run repeatedly and alongside other instances, as records that were already moved are skipped.
+// Returns the number of records moved.
+func (redis DataStore) MigrateKeys(ctx context.Context) (int, error) {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return 0, err
+	}
+
+	migrated := 0
+	for _, prefix := range []string{"secrets:", "requests:", "splits:"} {
+		ids, err := redis.scanLegacyIds(ctx, prefix)
+		if err != nil {
+			return migrated, err
+		}
+
+		for _, id := range ids {
+			switch prefix {
+			case "secrets:":
+				err = redis.migrateSecret(ctx, id)
+			case "requests:":
+				err = redis.renameKeys(ctx, NewRedisRequestKeySet(redis.keyIds, id).AllKeys(), (&RedisRequestKey{id: id}).AllKeys())
+			case "splits:":
+				err = redis.migrateSplitSecret(ctx, id)
+			}
+			if err != nil {
+				return migrated, err
+			}
+			migrated++
+		}
+	}
+
+	return migrated, nil
+}
+
+// scanLegacyIds returns the distinct IDs that keys with the given prefix are still stored under.
+func (redis DataStore) scanLegacyIds(ctx context.Context, prefix string) ([]string, error) {
+	seen := make(map[string]bool)
+	var ids []string
+
+	iter := redis.client.Scan(ctx, 0, prefix+"*", migrationScanCount).Iterator()
+	for iter.Next(ctx) {
+		id, _, found := strings.Cut(strings.TrimPrefix(iter.Val(), prefix), ":")
+		if !found || seen[id] || !keyid.IsLegacy(id) {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+
+	return ids, iter.Err()
+}
+
+func (redis DataStore) migrateSecret(ctx context.Context, id string) error {
+	legacy := redisKeySetForKeyId(id)
+	keySet := NewRedisKeySet(redis.keyIds, id)
+	redis.logger.WithField(redisIdFieldKey, keySet.id).Info("migrating secret to its key id")
+
+	if value, err := redis.client.Get(ctx, legacy.ExpiryNotification()).Result(); err == nil {
+		notice, err := parseExpiryNotice(id, value)
+		if err != nil {
+			return err
+		}
+		payload, err := json.Marshal(notice)
+		if err != nil {
+			return err
+		}
+		if err = redis.client.SetArgs(ctx, legacy.ExpiryNotification(), payload, setArgsKeepTTL).Err(); err != nil && !isNil(err) {
+			return err
+		}
+	} else if !isNil(err) {
+		return err
+	}
+
+	if err := redis.renameKeys(ctx, keySet.AllKeys(), legacy.AllKeys()); err != nil {
+		return err
+	}
+
+	score, err := redis.client.ZScore(ctx, expirationIndexKey, id).Result()
+	if isNil(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = redis.client.TxPipelined(ctx, func(pipe pipeliner) error {
+		pipe.ZAdd(ctx, expirationIndexKey, expirationIndexEntry(keySet.id, int64(score)))
+		pipe.ZRem(ctx, expirationIndexKey, id)
+		return nil
+	})
+	return err
+}
+
+func (redis DataStore) migrateSplitSecret(ctx context.Context, id string) error {
+	keySet := NewRedisSplitKeySet(redis.keyIds, id)
+	redis.logger.WithField(redisIdFieldKey, keySet.id).Info("migrating split secret to its key id")
+
+	if err := redis.renameKeys(ctx, keySet.AllKeys(), (&RedisSplitKey{id: id}).AllKeys()); err != nil {
+		return err
+	}
+
+	sharesVal, err := redis.client.Get(ctx, keySet.Shares()).Bytes()
+	if isNil(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var shares []string
+	if err = json.Unmarshal(sharesVal, &shares); err != nil {
+		return err
+	}
+	for i, share := range shares {
+		if keyid.IsLegacy(share) {
+			shares[i] = redis.keyIds.Hash(share)
+		}
+	}
+
+	payload, err := json.Marshal(shares)
+	if err != nil {
+		return err
+	}
+	err = redis.client.SetArgs(ctx, keySet.Shares(), payload, setArgsKeepTTL).Err()
+	if isNil(err) {
+		return nil
+	}
+	return err
+}
+
+// renameKeys renames each of the legacy keys to the key at the same position, keeping its time to live.
+// Keys that no longer exist, because they expired or were already renamed, are skipped.
+func (redis DataStore) renameKeys(ctx context.Context, keys []string, legacyKeys []string) error {
+	for i, legacyKey := range legacyKeys {
+		err := redis.client.Rename(ctx, legacyKey, keys[i]).Err()
+		if err != nil && !strings.Contains(err.Error(), "no such key") {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseExpiryNotice reads a stored expiry notification. Notifications stored before key IDs were introduced
+// only hold the recipient, and are referenced by the ID they are still stored under.
+func parseExpiryNotice(storedId string, value string) (models.ExpiryNotice, error) {
+	if !strings.HasPrefix(value, "{") {
+		notice := models.ExpiryNotice{Recipient: value}
+		if keyid.IsLegacy(storedId) {
+			notice.Reference = models.SecretReference(storedId)
+		}
+		return notice, nil
+	}
+
+	var notice models.ExpiryNotice
+	err := json.Unmarshal([]byte(value), &notice)
+	return notice, err
+}
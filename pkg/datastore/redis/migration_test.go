@@ -0,0 +1,38 @@
+package redis
+
+import (
+	"cellar/pkg/keyid"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExpiryNotice(t *testing.T) {
+	id := "22b6fff1be15d1fd54b7b8ec6ad22e80e66275195c914c4b0f9652248a498680"
+	keyIds, err := keyid.NewHasher("pepper")
+	require.NoError(t, err)
+
+	t.Run("when the notice was stored under a key id", func(t *testing.T) {
+		notice, err := parseExpiryNotice(keyIds.Hash(id), `{"recipient":"sender@example.com","reference":"22b6fff1"}`)
+		require.NoError(t, err)
+
+		t.Run("it should return the recipient and reference", func(t *testing.T) {
+			assert.Equal(t, "sender@example.com", notice.Recipient)
+			assert.Equal(t, "22b6fff1", notice.Reference)
+		})
+	})
+
+	t.Run("when the notice was stored under a secret id", func(t *testing.T) {
+		notice, err := parseExpiryNotice(id, "sender@example.com")
+		require.NoError(t, err)
+
+		t.Run("it should return the recipient", func(t *testing.T) {
+			assert.Equal(t, "sender@example.com", notice.Recipient)
+		})
+
+		t.Run("it should reference the secret by a shortened id", func(t *testing.T) {
+			assert.Equal(t, "22b6fff1", notice.Reference)
+		})
+	})
+}
@@ -2,12 +2,17 @@ package redis
 
 import (
 	pkgerrors "cellar/pkg/errors"
+	"cellar/pkg/integrity"
+	"cellar/pkg/keyid"
 	"cellar/pkg/models"
 	"cellar/pkg/settings/datastore"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 	log "github.com/sirupsen/logrus"
@@ -15,8 +20,11 @@ import (
 
 type (
 	DataStore struct {
-		client *redis.Client
-		logger *log.Entry
+		client            *redis.Client
+		logger            *log.Entry
+		expiryEventsIndex bool
+		keyPrefix         string
+		keyIds            *keyid.Hasher
 	}
 	Info struct {
 		Version string `json:"redis_version"`
@@ -29,7 +37,15 @@ func (redis *DataStore) Client() *redis.Client {
 
 const redisIdFieldKey = "redis_key"
 
-func NewDataStore(configuration datastore.IRedisConfiguration) *DataStore {
+// expiryNotificationGracePeriod is how long an expiry notification is retained after
+// the secret itself expires, giving expiry processing time to pick it up.
+const expiryNotificationGracePeriod = 24 * time.Hour
+
+// maxSecretHistoryEvents bounds the history kept for a single secret.
+const maxSecretHistoryEvents = 100
+
+// NewDataStore returns a datastore that stores records under the key IDs the hasher derives from their IDs.
+func NewDataStore(configuration datastore.IRedisConfiguration, keyIds *keyid.Hasher) *DataStore {
 
 	return &DataStore{
 		client: redis.NewClient(&redis.Options{
@@ -37,10 +53,30 @@ func NewDataStore(configuration datastore.IRedisConfiguration) *DataStore {
 			Password: configuration.Password(),
 			DB:       configuration.DB(),
 		}),
-		logger: initializeLogger(configuration),
+		logger:            initializeLogger(configuration),
+		expiryEventsIndex: configuration.ExpiryEventsEnabled(),
+		keyIds:            keyIds,
+	}
+}
+
+// WithKeyPrefix returns a datastore that shares the connection of this one, but stores secrets, requests and splits
+// under keys that start with the prefix, so they are only found through a datastore with the same prefix.
+// The returned datastore is closed with this one.
+func (redis *DataStore) WithKeyPrefix(prefix string) *DataStore {
+	return &DataStore{
+		client:            redis.client,
+		logger:            redis.logger.WithField("keyPrefix", prefix),
+		expiryEventsIndex: redis.expiryEventsIndex,
+		keyPrefix:         prefix,
+		keyIds:            redis.keyIds,
 	}
 }
 
+// KeyID returns the key ID the records of a secret, request or split with the given ID are stored under.
+func (redis DataStore) KeyID(id string) string {
+	return redis.keyIds.Hash(id)
+}
+
 func initializeLogger(configuration datastore.IRedisConfiguration) *log.Entry {
 	logger := log.WithFields(log.Fields{
 		"context":  "datastore",
@@ -86,7 +122,7 @@ func (redis DataStore) WriteSecret(ctx context.Context, secret models.Secret) er
 		return err
 	}
 
-	keySet := NewRedisKeySet(secret.ID)
+	keySet := redis.secretKeySet(secret.ID)
 	redis.logger.WithField(redisIdFieldKey, keySet.id).Debug("Writing secret to datastore")
 
 	err := redis.client.Set(ctx, keySet.Access(), strconv.Itoa(0), secret.Duration()).Err()
@@ -110,6 +146,43 @@ func (redis DataStore) WriteSecret(ctx context.Context, secret models.Secret) er
 		return err
 	}
 
+	bundleItems := ""
+	if len(secret.BundleItems) > 0 {
+		value, err := json.Marshal(secret.BundleItems)
+		if err != nil {
+			return err
+		}
+		bundleItems = string(value)
+	}
+
+	record := taggedRecord{
+		accessLimit:        secret.AccessLimit,
+		expirationEpoch:    secret.ExpirationEpoch,
+		contentType:        secret.ContentType,
+		filename:           secret.Filename,
+		cipherText:         secret.CipherText,
+		ownerTokenHash:     secret.OwnerTokenHash,
+		ownerOnly:          secret.OwnerOnly,
+		availableFromEpoch: secret.AvailableFromEpoch,
+		viewWindowSeconds:  secret.ViewWindowSeconds,
+		splitKeyCheck:      secret.SplitKeyCheck,
+		recipientEmail:     secret.RecipientEmail,
+		allowedCIDRs:       secret.AllowedCIDRs,
+		allowedCountries:   secret.AllowedCountries,
+		deniedCountries:    secret.DeniedCountries,
+		padded:             secret.Padded,
+		bundleItems:        bundleItems,
+		notifyEmail:        secret.NotifyEmail,
+		createdEpoch:       secret.CreatedEpoch,
+		creator:            secret.Creator,
+	}
+	if tag := integrity.Tag(record.fields(keySet.scopedId())...); tag != "" {
+		err = redis.client.Set(ctx, keySet.Integrity(), tag, secret.Duration()).Err()
+		if err != nil {
+			return err
+		}
+	}
+
 	if secret.Filename != "" {
 		err = redis.client.Set(ctx, keySet.Filename(), secret.Filename, secret.Duration()).Err()
 		if err != nil {
@@ -117,6 +190,119 @@ func (redis DataStore) WriteSecret(ctx context.Context, secret models.Secret) er
 		}
 	}
 
+	if secret.NotifyEmail != "" {
+		err = redis.client.Set(ctx, keySet.NotifyEmail(), secret.NotifyEmail, secret.Duration()).Err()
+		if err != nil {
+			return err
+		}
+		notice, err := json.Marshal(models.ExpiryNotice{
+			Recipient: secret.NotifyEmail,
+			Reference: models.SecretReference(secret.ID),
+		})
+		if err != nil {
+			return err
+		}
+		err = redis.client.Set(ctx, keySet.ExpiryNotification(), notice, secret.Duration()+expiryNotificationGracePeriod).Err()
+		if err != nil {
+			return err
+		}
+	}
+
+	if secret.AvailableFromEpoch > 0 {
+		err = redis.client.Set(ctx, keySet.AvailableFrom(), secret.AvailableFromEpoch, secret.Duration()).Err()
+		if err != nil {
+			return err
+		}
+	}
+
+	if secret.ViewWindowSeconds > 0 {
+		err = redis.client.Set(ctx, keySet.ViewWindow(), strconv.Itoa(secret.ViewWindowSeconds), secret.Duration()).Err()
+		if err != nil {
+			return err
+		}
+	}
+
+	if secret.OwnerTokenHash != "" {
+		err = redis.client.Set(ctx, keySet.OwnerToken(), secret.OwnerTokenHash, secret.Duration()).Err()
+		if err != nil {
+			return err
+		}
+	}
+
+	if secret.OwnerOnly {
+		err = redis.client.Set(ctx, keySet.OwnerOnly(), strconv.FormatBool(secret.OwnerOnly), secret.Duration()).Err()
+		if err != nil {
+			return err
+		}
+	}
+
+	if secret.Padded {
+		err = redis.client.Set(ctx, keySet.Padded(), strconv.FormatBool(secret.Padded), secret.Duration()).Err()
+		if err != nil {
+			return err
+		}
+	}
+
+	if bundleItems != "" {
+		err = redis.client.Set(ctx, keySet.BundleItems(), bundleItems, secret.Duration()).Err()
+		if err != nil {
+			return err
+		}
+	}
+
+	if secret.SplitKeyCheck != "" {
+		err = redis.client.Set(ctx, keySet.SplitKeyCheck(), secret.SplitKeyCheck, secret.Duration()).Err()
+		if err != nil {
+			return err
+		}
+	}
+
+	if secret.RecipientEmail != "" {
+		err = redis.client.Set(ctx, keySet.RecipientEmail(), secret.RecipientEmail, secret.Duration()).Err()
+		if err != nil {
+			return err
+		}
+	}
+
+	if secret.Creator != "" {
+		err = redis.client.Set(ctx, keySet.Creator(), secret.Creator, secret.Duration()).Err()
+		if err != nil {
+			return err
+		}
+	}
+
+	if secret.CreatedEpoch != 0 {
+		err = redis.client.Set(ctx, keySet.Created(), secret.CreatedEpoch, secret.Duration()).Err()
+		if err != nil {
+			return err
+		}
+	}
+
+	for key, list := range map[string][]string{
+		keySet.AllowedCIDRs():     secret.AllowedCIDRs,
+		keySet.AllowedCountries(): secret.AllowedCountries,
+		keySet.DeniedCountries():  secret.DeniedCountries,
+	} {
+		if len(list) == 0 {
+			continue
+		}
+		value, err := json.Marshal(list)
+		if err != nil {
+			return err
+		}
+		err = redis.client.Set(ctx, key, value, secret.Duration()).Err()
+		if err != nil {
+			return err
+		}
+	}
+
+	if redis.expiryEventsIndex {
+		err = redis.client.ZAdd(ctx, expirationIndexKey, expirationIndexEntry(keySet.scopedId(), secret.ExpirationEpoch)).Err()
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -125,10 +311,390 @@ func (redis DataStore) ReadSecret(ctx context.Context, id string) (secret *model
 		return nil
 	}
 
-	keySet := NewRedisKeySet(id)
+	keySet := redis.secretKeySet(id)
 	redis.logger.WithField(redisIdFieldKey, keySet.id).Debug("reading secret from redis")
 
-	accessLimit, err := redis.client.Get(ctx, keySet.AccessLimit()).Int()
+	record, err := readTaggedRecord(ctx, redis.client, keySet)
+	if err != nil {
+		return nil
+	}
+	if err = redis.verifyIntegrity(ctx, redis.client, keySet, record); err != nil {
+		return nil
+	}
+
+	var bundleItems []models.BundleItem
+	if record.bundleItems != "" {
+		if err = json.Unmarshal([]byte(record.bundleItems), &bundleItems); err != nil {
+			redis.logger.WithError(err).WithField(redisIdFieldKey, keySet.id).Error("Error reading bundle items")
+			return nil
+		}
+	}
+
+	return &models.Secret{
+		ID:              id,
+		CipherText:      record.cipherText,
+		ContentType:     record.contentType,
+		Filename:        record.filename,
+		NotifyEmail:     record.notifyEmail,
+		OwnerTokenHash:  record.ownerTokenHash,
+		AccessCount:     record.accessCount,
+		AccessLimit:     record.accessLimit,
+		ExpirationEpoch: record.expirationEpoch,
+
+		OwnerOnly:          record.ownerOnly,
+		AvailableFromEpoch: record.availableFromEpoch,
+		ViewWindowSeconds:  record.viewWindowSeconds,
+
+		BundleItems: bundleItems,
+
+		SplitKey:      record.splitKeyCheck != "",
+		SplitKeyCheck: record.splitKeyCheck,
+
+		AllowedCIDRs:     record.allowedCIDRs,
+		AllowedCountries: record.allowedCountries,
+		DeniedCountries:  record.deniedCountries,
+
+		RecipientEmail: record.recipientEmail,
+		Creator:        record.creator,
+		CreatedEpoch:   record.createdEpoch,
+
+		Padded: record.padded,
+	}
+}
+
+// IncreaseAccessCount counts an access to a secret. If integrity keys are configured, the secret is tagged again
+// with its new access count in the same transaction, so an access count lowered in the datastore does not verify.
+func (redis DataStore) IncreaseAccessCount(ctx context.Context, id string) (accessCount int64, err error) {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return 0, err
+	}
+	keySet := redis.secretKeySet(id)
+	redis.logger.WithField(redisIdFieldKey, keySet.id).Debug("increasing secret access count in redis")
+	if !integrity.Enabled() {
+		return redis.client.Incr(ctx, keySet.Access()).Result()
+	}
+
+	var increased *intCmd
+	err = redis.watchSecret(ctx, keySet, func(tx *watchTx) error {
+		tag, err := redis.retag(ctx, tx, keySet, func(record *taggedRecord) {
+			record.accessCount++
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe pipeliner) error {
+			queueTag(ctx, pipe, keySet, tag)
+			increased = pipe.Incr(ctx, keySet.Access())
+			return nil
+		})
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return increased.Val(), nil
+}
+
+func (redis DataStore) DeleteSecret(ctx context.Context, id string) (bool, error) {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return false, err
+	}
+	return redis.deleteSecret(ctx, redis.secretKeySet(id))
+}
+
+func (redis DataStore) deleteSecret(ctx context.Context, keySet *RedisKey) (bool, error) {
+	redis.logger.WithField(redisIdFieldKey, keySet.id).Debug("deleting secret from redis")
+	numDeleted, err := redis.client.Del(ctx, keySet.AllKeys()...).Result()
+	if err != nil {
+		return false, err
+	}
+
+	if redis.expiryEventsIndex {
+		if err = redis.client.ZRem(ctx, expirationIndexKey, keySet.scopedId()).Err(); err != nil {
+			return false, err
+		}
+	}
+
+	return numDeleted > int64(0), nil
+}
+
+// StartViewWindow moves the expiration of a secret forward to the end of its view window.
+// All of the secret's keys and its expiration index entry are updated in a single transaction,
+// which is retried if the secret changes while it is prepared.
+func (redis DataStore) StartViewWindow(ctx context.Context, id string, expirationEpoch int64) error {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return err
+	}
+	keySet := redis.secretKeySet(id)
+	redis.logger.WithField(redisIdFieldKey, keySet.id).Debug("starting secret view window in redis")
+
+	err := redis.watchSecret(ctx, keySet, func(tx *watchTx) error {
+		tag, err := redis.retag(ctx, tx, keySet, func(record *taggedRecord) {
+			record.expirationEpoch = expirationEpoch
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe pipeliner) error {
+			queueTag(ctx, pipe, keySet, tag)
+			redis.queueExpiration(ctx, pipe, keySet, expirationEpoch)
+			return nil
+		})
+		return err
+	})
+	if isNil(err) {
+		return nil
+	}
+	return err
+}
+
+// UpdateSecret changes the expiration and access limit of an existing secret.
+// All of the secret's keys and its expiration index entry are updated in a single transaction,
+// which is retried if the secret changes while it is prepared.
+// Returns false if the secret does not exist.
+func (redis DataStore) UpdateSecret(ctx context.Context, id string, expirationEpoch int64, accessLimit int) (bool, error) {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return false, err
+	}
+	keySet := redis.secretKeySet(id)
+	redis.logger.WithField(redisIdFieldKey, keySet.id).Debug("updating secret in redis")
+
+	err := redis.watchSecret(ctx, keySet, func(tx *watchTx) error {
+		tag, err := redis.retag(ctx, tx, keySet, func(record *taggedRecord) {
+			record.expirationEpoch = expirationEpoch
+			record.accessLimit = accessLimit
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe pipeliner) error {
+			queueTag(ctx, pipe, keySet, tag)
+			redis.queueExpiration(ctx, pipe, keySet, expirationEpoch)
+			pipe.SetArgs(ctx, keySet.AccessLimit(), strconv.Itoa(accessLimit), setArgsKeepTTL)
+			return nil
+		})
+		return err
+	})
+	if isNil(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// queueExpiration queues the commands that move every key of a secret to a new expiration.
+// Keys that do not exist are left alone, the expiry notification keeps its grace period,
+// and a pending verification code keeps its own, shorter lifetime.
+func (redis DataStore) queueExpiration(ctx context.Context, pipe pipeliner, keySet *RedisKey, expirationEpoch int64) {
+	expiration := time.Unix(expirationEpoch, 0)
+	for _, key := range keySet.AllKeys() {
+		if key == keySet.ExpiryNotification() {
+			pipe.ExpireAt(ctx, key, expiration.Add(expiryNotificationGracePeriod))
+			continue
+		}
+		if key == keySet.VerificationCode() || key == keySet.VerificationAttempts() {
+			continue
+		}
+		pipe.ExpireAt(ctx, key, expiration)
+	}
+	pipe.SetArgs(ctx, keySet.ExpirationEpoch(), expirationEpoch, setArgsKeepTTL)
+	if redis.expiryEventsIndex {
+		pipe.ZAddXX(ctx, expirationIndexKey, expirationIndexEntry(keySet.scopedId(), expirationEpoch))
+	}
+}
+
+// AppendSecretHistory records an event in the history of a secret.
+// The history expires with the secret and keeps only the most recent events.
+// Events for secrets that no longer exist are dropped.
+func (redis DataStore) AppendSecretHistory(ctx context.Context, id string, event models.SecretHistoryEvent) error {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return err
+	}
+	keySet := redis.secretKeySet(id)
+	redis.logger.WithField(redisIdFieldKey, keySet.id).Debug("appending secret history in redis")
+
+	ttl, err := redis.client.PTTL(ctx, keySet.Content()).Result()
+	if err != nil {
+		return err
+	}
+	if ttl <= 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = redis.client.TxPipelined(ctx, func(pipe pipeliner) error {
+		pipe.RPush(ctx, keySet.History(), payload)
+		pipe.LTrim(ctx, keySet.History(), -maxSecretHistoryEvents, -1)
+		pipe.PExpire(ctx, keySet.History(), ttl)
+		return nil
+	})
+	return err
+}
+
+func (redis DataStore) ReadSecretHistory(ctx context.Context, id string) ([]models.SecretHistoryEvent, error) {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return nil, err
+	}
+	keySet := redis.secretKeySet(id)
+	redis.logger.WithField(redisIdFieldKey, keySet.id).Debug("reading secret history from redis")
+
+	entries, err := redis.client.LRange(ctx, keySet.History(), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]models.SecretHistoryEvent, 0, len(entries))
+	for _, entry := range entries {
+		var event models.SecretHistoryEvent
+		if err = json.Unmarshal([]byte(entry), &event); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func (redis DataStore) CancelExpiryNotification(ctx context.Context, id string) error {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return err
+	}
+	keySet := redis.secretKeySet(id)
+	redis.logger.WithField(redisIdFieldKey, keySet.id).Debug("cancelling secret expiry notification in redis")
+	return redis.client.Del(ctx, keySet.ExpiryNotification()).Err()
+}
+
+// TakeExpiryNotification removes and returns the pending expiry notification of the secret with the given key ID.
+// Returns an empty notice if none is pending.
+func (redis DataStore) TakeExpiryNotification(ctx context.Context, keyId string) (models.ExpiryNotice, error) {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return models.ExpiryNotice{}, err
+	}
+	keySet := redisKeySetForScopedId(keyId)
+	redis.logger.WithField(redisIdFieldKey, keySet.id).Debug("taking secret expiry notification from redis")
+	value, err := redis.client.GetDel(ctx, keySet.ExpiryNotification()).Result()
+	if isNil(err) {
+		return models.ExpiryNotice{}, nil
+	}
+	if err != nil {
+		return models.ExpiryNotice{}, err
+	}
+	return parseExpiryNotice(keyId, value)
+}
+
+// WriteVerificationCode stores the hash of a new verification code for a secret.
+// Returns false, leaving the pending code in place, if a code that has not expired is still pending.
+func (redis DataStore) WriteVerificationCode(ctx context.Context, id string, codeHash string, ttl time.Duration) (bool, error) {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return false, err
+	}
+	keySet := redis.secretKeySet(id)
+	redis.logger.WithField(redisIdFieldKey, keySet.id).Debug("writing secret verification code to redis")
+
+	return redis.client.SetNX(ctx, keySet.VerificationCode(), codeHash, ttl).Result()
+}
+
+// ReadVerificationCode returns the hash of the pending verification code of a secret and counts an attempt to use it.
+// Attempts are counted for the secret rather than for the code, from the first attempt until the attempt window
+// has passed, so requesting a new code does not reset them.
+// Returns an empty hash if no code is pending.
+func (redis DataStore) ReadVerificationCode(ctx context.Context, id string, attemptWindow time.Duration) (string, int64, error) {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return "", 0, err
+	}
+	keySet := redis.secretKeySet(id)
+	redis.logger.WithField(redisIdFieldKey, keySet.id).Debug("reading secret verification code from redis")
+
+	var codeHash *stringCmd
+	var attempts *intCmd
+	_, err := redis.client.TxPipelined(ctx, func(pipe pipeliner) error {
+		pipe.SetNX(ctx, keySet.VerificationAttempts(), 0, attemptWindow)
+		attempts = pipe.Incr(ctx, keySet.VerificationAttempts())
+		codeHash = pipe.Get(ctx, keySet.VerificationCode())
+		return nil
+	})
+	if err != nil && !isNil(err) {
+		return "", 0, err
+	}
+	return codeHash.Val(), attempts.Val(), nil
+}
+
+// ConsumeVerificationCode removes the pending verification code of a secret if it is the code with the given hash.
+// Of concurrent calls for the same code, only one consumes it. Returns false if the code was not consumed.
+func (redis DataStore) ConsumeVerificationCode(ctx context.Context, id string, codeHash string) (bool, error) {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return false, err
+	}
+	keySet := redis.secretKeySet(id)
+	redis.logger.WithField(redisIdFieldKey, keySet.id).Debug("consuming secret verification code in redis")
+
+	consumed := false
+	err := redis.client.Watch(ctx, func(tx *watchTx) error {
+		pending, err := tx.Get(ctx, keySet.VerificationCode()).Result()
+		if err != nil || pending != codeHash {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe pipeliner) error {
+			pipe.Del(ctx, keySet.VerificationCode(), keySet.VerificationAttempts())
+			return nil
+		})
+		consumed = err == nil
+		return err
+	}, keySet.VerificationCode())
+	if isNil(err) || errors.Is(err, errTxFailed) {
+		return false, nil
+	}
+	return consumed, err
+}
+
+func (redis DataStore) WriteSecretRequest(ctx context.Context, request models.SecretRequest) error {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return err
+	}
+
+	keySet := redis.requestKeySet(request.ID)
+	redis.logger.WithField(redisIdFieldKey, keySet.id).Debug("Writing secret request to datastore")
+
+	err := redis.client.Set(ctx, keySet.OwnerToken(), request.OwnerTokenHash, request.Duration()).Err()
+	if err != nil {
+		return err
+	}
+	err = redis.client.Set(ctx, keySet.ContentType(), request.ContentType, request.Duration()).Err()
+	if err != nil {
+		return err
+	}
+	err = redis.client.Set(ctx, keySet.MaxSize(), request.MaxSizeBytes, request.Duration()).Err()
+	if err != nil {
+		return err
+	}
+	err = redis.client.Set(ctx, keySet.AccessLimit(), strconv.Itoa(request.AccessLimit), request.Duration()).Err()
+	if err != nil {
+		return err
+	}
+	if request.Creator != "" {
+		err = redis.client.Set(ctx, keySet.Creator(), request.Creator, request.Duration()).Err()
+		if err != nil {
+			return err
+		}
+	}
+	return redis.client.Set(ctx, keySet.ExpirationEpoch(), request.ExpirationEpoch, request.Duration()).Err()
+}
+
+func (redis DataStore) ReadSecretRequest(ctx context.Context, id string) (request *models.SecretRequest) {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return nil
+	}
+
+	keySet := redis.requestKeySet(id)
+	redis.logger.WithField(redisIdFieldKey, keySet.id).Debug("reading secret request from redis")
+
+	ownerTokenHash, err := redis.client.Get(ctx, keySet.OwnerToken()).Result()
 	if err != nil {
 		return nil
 	}
@@ -138,12 +704,12 @@ func (redis DataStore) ReadSecret(ctx context.Context, id string) (secret *model
 		return nil
 	}
 
-	content, err := redis.client.Get(ctx, keySet.Content()).Result()
+	maxSizeBytes, err := redis.client.Get(ctx, keySet.MaxSize()).Int64()
 	if err != nil {
 		return nil
 	}
 
-	accessCount, err := redis.client.Get(ctx, keySet.Access()).Int()
+	accessLimit, err := redis.client.Get(ctx, keySet.AccessLimit()).Int()
 	if err != nil {
 		return nil
 	}
@@ -153,41 +719,178 @@ func (redis DataStore) ReadSecret(ctx context.Context, id string) (secret *model
 		return nil
 	}
 
-	filename := ""
-	if filenameVal, err := redis.client.Get(ctx, keySet.Filename()).Result(); err == nil {
-		filename = filenameVal
+	secretId := ""
+	if secretIdVal, err := redis.client.Get(ctx, keySet.SecretId()).Result(); err == nil {
+		secretId = secretIdVal
 	}
 
-	return &models.Secret{
+	creator := ""
+	if creatorVal, err := redis.client.Get(ctx, keySet.Creator()).Result(); err == nil {
+		creator = creatorVal
+	}
+
+	return &models.SecretRequest{
 		ID:              id,
-		CipherText:      content,
+		OwnerTokenHash:  ownerTokenHash,
 		ContentType:     contentType,
-		Filename:        filename,
-		AccessCount:     accessCount,
+		MaxSizeBytes:    maxSizeBytes,
 		AccessLimit:     accessLimit,
 		ExpirationEpoch: expirationEpoch,
+		SecretID:        secretId,
+		Creator:         creator,
 	}
 }
 
-func (redis DataStore) IncreaseAccessCount(ctx context.Context, id string) (accessCount int64, err error) {
+func (redis DataStore) FulfillSecretRequest(ctx context.Context, request models.SecretRequest, secretId string) (bool, error) {
 	if err := pkgerrors.CheckContext(ctx); err != nil {
-		return 0, err
+		return false, err
 	}
-	keySet := NewRedisKeySet(id)
-	redis.logger.WithField(redisIdFieldKey, keySet.id).Debug("increasing secret access count in redis")
-	return redis.client.Incr(ctx, keySet.Access()).Result()
+	keySet := redis.requestKeySet(request.ID)
+	redis.logger.WithField(redisIdFieldKey, keySet.id).Debug("fulfilling secret request in redis")
+	return redis.client.SetNX(ctx, keySet.SecretId(), secretId, request.Duration()).Result()
 }
 
-func (redis DataStore) DeleteSecret(ctx context.Context, id string) (bool, error) {
+func (redis DataStore) WriteSplitSecret(ctx context.Context, split models.SplitSecret) error {
 	if err := pkgerrors.CheckContext(ctx); err != nil {
-		return false, err
+		return err
+	}
+
+	keySet := redis.splitKeySet(split.ID)
+	redis.logger.WithField(redisIdFieldKey, keySet.id).Debug("Writing split secret to datastore")
+
+	shares, err := json.Marshal(split.ShareKeys)
+	if err != nil {
+		return err
+	}
+
+	err = redis.client.Set(ctx, keySet.ContentType(), split.ContentType, split.Duration()).Err()
+	if err != nil {
+		return err
+	}
+	err = redis.client.Set(ctx, keySet.Threshold(), strconv.Itoa(split.Threshold), split.Duration()).Err()
+	if err != nil {
+		return err
+	}
+	err = redis.client.Set(ctx, keySet.Shares(), shares, split.Duration()).Err()
+	if err != nil {
+		return err
+	}
+	if split.Filename != "" {
+		err = redis.client.Set(ctx, keySet.Filename(), split.Filename, split.Duration()).Err()
+		if err != nil {
+			return err
+		}
+	}
+	return redis.client.Set(ctx, keySet.ExpirationEpoch(), split.ExpirationEpoch, split.Duration()).Err()
+}
+
+func (redis DataStore) ReadSplitSecret(ctx context.Context, id string) (split *models.SplitSecret) {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return nil
+	}
+
+	keySet := redis.splitKeySet(id)
+	redis.logger.WithField(redisIdFieldKey, keySet.id).Debug("reading split secret from redis")
+
+	contentType, err := redis.client.Get(ctx, keySet.ContentType()).Result()
+	if err != nil {
+		return nil
+	}
+
+	threshold, err := redis.client.Get(ctx, keySet.Threshold()).Int()
+	if err != nil {
+		return nil
+	}
+
+	sharesVal, err := redis.client.Get(ctx, keySet.Shares()).Bytes()
+	if err != nil {
+		return nil
+	}
+	var shareKeys []string
+	if err = json.Unmarshal(sharesVal, &shareKeys); err != nil {
+		redis.logger.WithError(err).WithField(redisIdFieldKey, keySet.id).Error("Error reading split secret shares")
+		return nil
+	}
+
+	expirationEpoch, err := redis.client.Get(ctx, keySet.ExpirationEpoch()).Int64()
+	if err != nil {
+		return nil
+	}
+
+	filename := ""
+	if filenameVal, err := redis.client.Get(ctx, keySet.Filename()).Result(); err == nil {
+		filename = filenameVal
+	}
+
+	return &models.SplitSecret{
+		ID:              id,
+		ContentType:     contentType,
+		Filename:        filename,
+		Threshold:       threshold,
+		ShareKeys:       shareKeys,
+		ExpirationEpoch: expirationEpoch,
 	}
-	keySet := NewRedisKeySet(id)
-	redis.logger.WithField(redisIdFieldKey, keySet.id).Debug("deleting secret from redis")
-	numDeleted, err := redis.client.Del(ctx, keySet.AllKeys()...).Result()
-	return numDeleted > int64(0), err
 }
 
 func (redis DataStore) Close() error {
 	return redis.client.Close()
 }
+
+// pipeliner, cmdable, watchTx, stringCmd, intCmd, setArgsKeepTTL and errTxFailed let methods,
+// whose receiver shadows the redis package, use its types.
+type (
+	pipeliner = redis.Pipeliner
+	cmdable   = redis.Cmdable
+	watchTx   = redis.Tx
+	stringCmd = redis.StringCmd
+	intCmd    = redis.IntCmd
+)
+
+var setArgsKeepTTL = redis.SetArgs{Mode: "XX", KeepTTL: true}
+
+var errTxFailed = redis.TxFailedErr
+
+// maxWatchRetries bounds how often a transaction is retried when the secret it updates changes while it is prepared.
+const maxWatchRetries = 10
+
+// watchSecret runs update while watching every key of the secret, so the transaction update queues is aborted,
+// and update run again on the new values, if the secret changes between the reads of update and its transaction.
+func (redis DataStore) watchSecret(ctx context.Context, keySet *RedisKey, update func(tx *watchTx) error) error {
+	for attempt := 0; attempt < maxWatchRetries; attempt++ {
+		err := redis.client.Watch(ctx, update, keySet.AllKeys()...)
+		if !errors.Is(err, errTxFailed) {
+			return err
+		}
+	}
+	return fmt.Errorf("secret kept changing while being updated: %w", errTxFailed)
+}
+
+func (redis DataStore) secretKeySet(id string) *RedisKey {
+	keySet := NewRedisKeySet(redis.keyIds, id)
+	keySet.prefix = redis.keyPrefix
+	return keySet
+}
+
+func (redis DataStore) requestKeySet(id string) *RedisRequestKey {
+	keySet := NewRedisRequestKeySet(redis.keyIds, id)
+	keySet.prefix = redis.keyPrefix
+	return keySet
+}
+
+func (redis DataStore) splitKeySet(id string) *RedisSplitKey {
+	keySet := NewRedisSplitKeySet(redis.keyIds, id)
+	keySet.prefix = redis.keyPrefix
+	return keySet
+}
+
+func expirationIndexEntry(keyId string, expirationEpoch int64) redis.Z {
+	return redis.Z{
+		Score:  float64(expirationEpoch),
+		Member: keyId,
+	}
+}
+
+// isNil reports whether err is the redis client's sentinel for a missing key.
+func isNil(err error) bool {
+	return errors.Is(err, redis.Nil)
+}
@@ -1,13 +1,46 @@
 package redis
 
-import "fmt"
+import (
+	"cellar/pkg/keyid"
+	"fmt"
+	"strings"
+)
 
+// expirationIndexKey is a sorted set of secret key IDs scored by their expiration epoch.
+// It lets expiry processing find secrets whose keys have expired and, by removing
+// a key ID from it, claim the expiry so each one is processed exactly once.
+const expirationIndexKey = "cellar:secrets:expirations"
+
+// readOnlyKey is set while the server is in read-only mode. It is shared by every tenant.
+const readOnlyKey = "cellar:readonly"
+
+// RedisKey names the keys of a secret after its key ID, so the secret ID itself never appears in the datastore.
+// The keys of a secret stored for a tenant start with the key prefix of the tenant.
 type RedisKey struct {
-	id string
+	prefix string
+	id     string
+}
+
+func NewRedisKeySet(keyIds *keyid.Hasher, id string) *RedisKey {
+	return &RedisKey{id: keyIds.Hash(id)}
+}
+
+// redisKeySetForKeyId names the keys of a secret that is only known by the ID its keys are stored under.
+func redisKeySetForKeyId(keyId string) *RedisKey {
+	return &RedisKey{id: keyId}
+}
+
+// redisKeySetForScopedId names the keys of a secret that is only known by its scoped ID,
+// such as a secret found in the expiration index.
+func redisKeySetForScopedId(scopedId string) *RedisKey {
+	separator := strings.LastIndex(scopedId, ":")
+	return &RedisKey{prefix: scopedId[:separator+1], id: scopedId[separator+1:]}
 }
 
-func NewRedisKeySet(id string) *RedisKey {
-	return &RedisKey{id: id}
+// scopedId is the key ID qualified by the key prefix, which tells secrets apart across every tenant.
+// It is what the expiration index and expiry events name secrets by.
+func (key RedisKey) scopedId() string {
+	return key.prefix + key.id
 }
 
 func (key RedisKey) AccessLimit() string {
@@ -34,6 +67,87 @@ func (key RedisKey) Filename() string {
 	return key.buildKey("filename")
 }
 
+func (key RedisKey) NotifyEmail() string {
+	return key.buildKey("notifyemail")
+}
+
+// ExpiryNotification outlives the rest of the secret so the recipient of an
+// expiry notification is still known after the secret's own keys have expired.
+func (key RedisKey) ExpiryNotification() string {
+	return key.buildKey("expirynotification")
+}
+
+func (key RedisKey) OwnerToken() string {
+	return key.buildKey("ownertoken")
+}
+
+func (key RedisKey) OwnerOnly() string {
+	return key.buildKey("owneronly")
+}
+
+func (key RedisKey) Padded() string {
+	return key.buildKey("padded")
+}
+
+func (key RedisKey) History() string {
+	return key.buildKey("history")
+}
+
+func (key RedisKey) AvailableFrom() string {
+	return key.buildKey("availablefrom")
+}
+
+func (key RedisKey) ViewWindow() string {
+	return key.buildKey("viewwindow")
+}
+
+func (key RedisKey) BundleItems() string {
+	return key.buildKey("bundleitems")
+}
+
+func (key RedisKey) SplitKeyCheck() string {
+	return key.buildKey("splitkeycheck")
+}
+
+func (key RedisKey) AllowedCIDRs() string {
+	return key.buildKey("allowedcidrs")
+}
+
+func (key RedisKey) AllowedCountries() string {
+	return key.buildKey("allowedcountries")
+}
+
+func (key RedisKey) DeniedCountries() string {
+	return key.buildKey("deniedcountries")
+}
+
+func (key RedisKey) RecipientEmail() string {
+	return key.buildKey("recipientemail")
+}
+
+func (key RedisKey) Creator() string {
+	return key.buildKey("creator")
+}
+
+func (key RedisKey) Created() string {
+	return key.buildKey("created")
+}
+
+// Integrity holds the tag that detects changes made to the secret's metadata directly in the datastore.
+func (key RedisKey) Integrity() string {
+	return key.buildKey("integrity")
+}
+
+// VerificationCode and VerificationAttempts expire on their own, well before the rest of the secret.
+// VerificationAttempts counts the attempts at every code sent within the attempt window, not just the pending one.
+func (key RedisKey) VerificationCode() string {
+	return key.buildKey("verificationcode")
+}
+
+func (key RedisKey) VerificationAttempts() string {
+	return key.buildKey("verificationattempts")
+}
+
 func (key RedisKey) AllKeys() []string {
 	return []string{
 		key.ContentType(),
@@ -42,9 +156,149 @@ func (key RedisKey) AllKeys() []string {
 		key.AccessLimit(),
 		key.ExpirationEpoch(),
 		key.Filename(),
+		key.NotifyEmail(),
+		key.ExpiryNotification(),
+		key.OwnerToken(),
+		key.ViewWindow(),
+		key.AvailableFrom(),
+		key.OwnerOnly(),
+		key.Padded(),
+		key.History(),
+		key.BundleItems(),
+		key.SplitKeyCheck(),
+		key.AllowedCIDRs(),
+		key.AllowedCountries(),
+		key.DeniedCountries(),
+		key.RecipientEmail(),
+		key.Creator(),
+		key.Created(),
+		key.Integrity(),
+		key.VerificationCode(),
+		key.VerificationAttempts(),
 	}
 }
 
 func (key RedisKey) buildKey(tail string) string {
-	return fmt.Sprintf("secrets:%s:%s", key.id, tail)
+	return fmt.Sprintf("%ssecrets:%s:%s", key.prefix, key.id, tail)
+}
+
+type RedisRequestKey struct {
+	prefix string
+	id     string
+}
+
+func NewRedisRequestKeySet(keyIds *keyid.Hasher, id string) *RedisRequestKey {
+	return &RedisRequestKey{id: keyIds.Hash(id)}
+}
+
+func (key RedisRequestKey) OwnerToken() string {
+	return key.buildKey("ownertoken")
+}
+
+func (key RedisRequestKey) ContentType() string {
+	return key.buildKey("contenttype")
+}
+
+func (key RedisRequestKey) MaxSize() string {
+	return key.buildKey("maxsize")
+}
+
+func (key RedisRequestKey) AccessLimit() string {
+	return key.buildKey("accesslimit")
+}
+
+func (key RedisRequestKey) ExpirationEpoch() string {
+	return key.buildKey("expirationepoch")
+}
+
+// SecretId is only set once the request is fulfilled, so setting it if absent
+// is what guarantees a request accepts a single submission.
+// It holds the secret ID itself, which is of no use without the owner token of the request.
+func (key RedisRequestKey) SecretId() string {
+	return key.buildKey("secretid")
+}
+
+func (key RedisRequestKey) Creator() string {
+	return key.buildKey("creator")
+}
+
+func (key RedisRequestKey) AllKeys() []string {
+	return []string{
+		key.OwnerToken(),
+		key.ContentType(),
+		key.MaxSize(),
+		key.AccessLimit(),
+		key.ExpirationEpoch(),
+		key.SecretId(),
+		key.Creator(),
+	}
+}
+
+func (key RedisRequestKey) buildKey(tail string) string {
+	return fmt.Sprintf("%srequests:%s:%s", key.prefix, key.id, tail)
+}
+
+type RedisSplitKey struct {
+	prefix string
+	id     string
+}
+
+func NewRedisSplitKeySet(keyIds *keyid.Hasher, id string) *RedisSplitKey {
+	return &RedisSplitKey{id: keyIds.Hash(id)}
+}
+
+func (key RedisSplitKey) ContentType() string {
+	return key.buildKey("contenttype")
+}
+
+func (key RedisSplitKey) Filename() string {
+	return key.buildKey("filename")
+}
+
+func (key RedisSplitKey) Threshold() string {
+	return key.buildKey("threshold")
+}
+
+func (key RedisSplitKey) Shares() string {
+	return key.buildKey("shares")
+}
+
+func (key RedisSplitKey) ExpirationEpoch() string {
+	return key.buildKey("expirationepoch")
+}
+
+func (key RedisSplitKey) AllKeys() []string {
+	return []string{
+		key.ContentType(),
+		key.Filename(),
+		key.Threshold(),
+		key.Shares(),
+		key.ExpirationEpoch(),
+	}
+}
+
+func (key RedisSplitKey) buildKey(tail string) string {
+	return fmt.Sprintf("%ssplits:%s:%s", key.prefix, key.id, tail)
+}
+
+// keyIdFromContentKey extracts the scoped ID of a secret from its content key.
+// Returns false if the key is not a secret content key.
+func keyIdFromContentKey(key string) (string, bool) {
+	const infix, suffix = "secrets:", ":content"
+	if !strings.HasSuffix(key, suffix) {
+		return "", false
+	}
+
+	key = strings.TrimSuffix(key, suffix)
+	start := strings.LastIndex(key, infix)
+	if start < 0 {
+		return "", false
+	}
+
+	prefix, id := key[:start], key[start+len(infix):]
+	if id == "" || strings.Contains(id, ":") || (prefix != "" && !strings.HasSuffix(prefix, ":")) {
+		return "", false
+	}
+
+	return prefix + id, true
 }
@@ -2,27 +2,69 @@ package redis_test
 
 import (
 	"cellar/pkg/datastore/redis"
+	"cellar/pkg/keyid"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
 var id = "1234567890"
-var sut = redis.NewRedisKeySet(id)
+var keyIds, _ = keyid.NewHasher("pepper")
+var keyId = keyIds.Hash(id)
+var sut = redis.NewRedisKeySet(keyIds, id)
 
 var keys = struct {
-	access          string
-	contentType     string
-	content         string
-	accessLimit     string
-	expirationEpoch string
+	access           string
+	contentType      string
+	content          string
+	accessLimit      string
+	expirationEpoch  string
+	notifyEmail      string
+	expiryNotice     string
+	ownerToken       string
+	viewWindow       string
+	availableFrom    string
+	ownerOnly        string
+	padded           string
+	history          string
+	bundleItems      string
+	splitKeyCheck    string
+	allowedCIDRs     string
+	allowedCountries string
+	deniedCountries  string
+	recipientEmail   string
+	creator          string
+	created          string
+	integrity        string
+	verificationCode string
+	verificationTry  string
 }{
-	access:          fmt.Sprintf("secrets:%s:access", id),
-	contentType:     fmt.Sprintf("secrets:%s:contenttype", id),
-	content:         fmt.Sprintf("secrets:%s:content", id),
-	accessLimit:     fmt.Sprintf("secrets:%s:accesslimit", id),
-	expirationEpoch: fmt.Sprintf("secrets:%s:expirationepoch", id),
+	access:           fmt.Sprintf("secrets:%s:access", keyId),
+	contentType:      fmt.Sprintf("secrets:%s:contenttype", keyId),
+	content:          fmt.Sprintf("secrets:%s:content", keyId),
+	accessLimit:      fmt.Sprintf("secrets:%s:accesslimit", keyId),
+	expirationEpoch:  fmt.Sprintf("secrets:%s:expirationepoch", keyId),
+	notifyEmail:      fmt.Sprintf("secrets:%s:notifyemail", keyId),
+	expiryNotice:     fmt.Sprintf("secrets:%s:expirynotification", keyId),
+	ownerToken:       fmt.Sprintf("secrets:%s:ownertoken", keyId),
+	viewWindow:       fmt.Sprintf("secrets:%s:viewwindow", keyId),
+	availableFrom:    fmt.Sprintf("secrets:%s:availablefrom", keyId),
+	ownerOnly:        fmt.Sprintf("secrets:%s:owneronly", keyId),
+	padded:           fmt.Sprintf("secrets:%s:padded", keyId),
+	history:          fmt.Sprintf("secrets:%s:history", keyId),
+	bundleItems:      fmt.Sprintf("secrets:%s:bundleitems", keyId),
+	splitKeyCheck:    fmt.Sprintf("secrets:%s:splitkeycheck", keyId),
+	allowedCIDRs:     fmt.Sprintf("secrets:%s:allowedcidrs", keyId),
+	allowedCountries: fmt.Sprintf("secrets:%s:allowedcountries", keyId),
+	deniedCountries:  fmt.Sprintf("secrets:%s:deniedcountries", keyId),
+	recipientEmail:   fmt.Sprintf("secrets:%s:recipientemail", keyId),
+	creator:          fmt.Sprintf("secrets:%s:creator", keyId),
+	created:          fmt.Sprintf("secrets:%s:created", keyId),
+	integrity:        fmt.Sprintf("secrets:%s:integrity", keyId),
+	verificationCode: fmt.Sprintf("secrets:%s:verificationcode", keyId),
+	verificationTry:  fmt.Sprintf("secrets:%s:verificationattempts", keyId),
 }
 
 func TestRedisKey_Access(t *testing.T) {
@@ -45,9 +87,189 @@ func TestRedisKey_ExpirationEpoch(t *testing.T) {
 	assert.Equal(t, keys.expirationEpoch, sut.ExpirationEpoch())
 }
 
+func TestRedisKey_NotifyEmail(t *testing.T) {
+	assert.Equal(t, keys.notifyEmail, sut.NotifyEmail())
+}
+
+func TestRedisKey_ExpiryNotification(t *testing.T) {
+	assert.Equal(t, keys.expiryNotice, sut.ExpiryNotification())
+}
+
+func TestRedisKey_OwnerToken(t *testing.T) {
+	assert.Equal(t, keys.ownerToken, sut.OwnerToken())
+}
+
+func TestRedisKey_ViewWindow(t *testing.T) {
+	assert.Equal(t, keys.viewWindow, sut.ViewWindow())
+}
+
+func TestRedisKey_AvailableFrom(t *testing.T) {
+	assert.Equal(t, keys.availableFrom, sut.AvailableFrom())
+}
+
+func TestRedisKey_OwnerOnly(t *testing.T) {
+	assert.Equal(t, keys.ownerOnly, sut.OwnerOnly())
+}
+
+func TestRedisKey_Padded(t *testing.T) {
+	assert.Equal(t, keys.padded, sut.Padded())
+}
+
+func TestRedisKey_History(t *testing.T) {
+	assert.Equal(t, keys.history, sut.History())
+}
+
+func TestRedisKey_BundleItems(t *testing.T) {
+	assert.Equal(t, keys.bundleItems, sut.BundleItems())
+}
+
+func TestRedisKey_SplitKeyCheck(t *testing.T) {
+	assert.Equal(t, keys.splitKeyCheck, sut.SplitKeyCheck())
+}
+
+func TestRedisKey_AllowedCIDRs(t *testing.T) {
+	assert.Equal(t, keys.allowedCIDRs, sut.AllowedCIDRs())
+}
+
+func TestRedisKey_AllowedCountries(t *testing.T) {
+	assert.Equal(t, keys.allowedCountries, sut.AllowedCountries())
+}
+
+func TestRedisKey_DeniedCountries(t *testing.T) {
+	assert.Equal(t, keys.deniedCountries, sut.DeniedCountries())
+}
+
+func TestRedisKey_RecipientEmail(t *testing.T) {
+	assert.Equal(t, keys.recipientEmail, sut.RecipientEmail())
+}
+
+func TestRedisKey_Creator(t *testing.T) {
+	assert.Equal(t, keys.creator, sut.Creator())
+}
+
+func TestRedisKey_Created(t *testing.T) {
+	assert.Equal(t, keys.created, sut.Created())
+}
+
+func TestRedisKey_Integrity(t *testing.T) {
+	assert.Equal(t, keys.integrity, sut.Integrity())
+}
+
+func TestRedisKey_VerificationCode(t *testing.T) {
+	assert.Equal(t, keys.verificationCode, sut.VerificationCode())
+}
+
+func TestRedisKey_VerificationAttempts(t *testing.T) {
+	assert.Equal(t, keys.verificationTry, sut.VerificationAttempts())
+}
+
 func TestRedisKey_AllKeys(t *testing.T) {
 	allKeys := sut.AllKeys()
-	for _, expected := range []string{keys.contentType, keys.content, keys.access, keys.accessLimit, keys.expirationEpoch} {
+	for _, expected := range []string{keys.contentType, keys.content, keys.access, keys.accessLimit, keys.expirationEpoch, keys.notifyEmail, keys.expiryNotice, keys.ownerToken, keys.viewWindow, keys.availableFrom, keys.ownerOnly, keys.padded, keys.history, keys.bundleItems, keys.splitKeyCheck, keys.allowedCIDRs, keys.allowedCountries, keys.deniedCountries, keys.recipientEmail, keys.creator, keys.created, keys.integrity, keys.verificationCode, keys.verificationTry} {
+		assert.Contains(t, allKeys, expected)
+	}
+}
+
+func TestRedisKey_KeyId(t *testing.T) {
+	for _, key := range sut.AllKeys() {
+		assert.False(t, strings.Contains(key, id), "expected %s to not contain the secret id", key)
+	}
+}
+
+var requestSut = redis.NewRedisRequestKeySet(keyIds, id)
+
+var requestKeys = struct {
+	ownerToken      string
+	contentType     string
+	maxSize         string
+	accessLimit     string
+	expirationEpoch string
+	secretId        string
+	creator         string
+}{
+	ownerToken:      fmt.Sprintf("requests:%s:ownertoken", keyId),
+	contentType:     fmt.Sprintf("requests:%s:contenttype", keyId),
+	maxSize:         fmt.Sprintf("requests:%s:maxsize", keyId),
+	accessLimit:     fmt.Sprintf("requests:%s:accesslimit", keyId),
+	expirationEpoch: fmt.Sprintf("requests:%s:expirationepoch", keyId),
+	secretId:        fmt.Sprintf("requests:%s:secretid", keyId),
+	creator:         fmt.Sprintf("requests:%s:creator", keyId),
+}
+
+func TestRedisRequestKey_OwnerToken(t *testing.T) {
+	assert.Equal(t, requestKeys.ownerToken, requestSut.OwnerToken())
+}
+
+func TestRedisRequestKey_ContentType(t *testing.T) {
+	assert.Equal(t, requestKeys.contentType, requestSut.ContentType())
+}
+
+func TestRedisRequestKey_MaxSize(t *testing.T) {
+	assert.Equal(t, requestKeys.maxSize, requestSut.MaxSize())
+}
+
+func TestRedisRequestKey_AccessLimit(t *testing.T) {
+	assert.Equal(t, requestKeys.accessLimit, requestSut.AccessLimit())
+}
+
+func TestRedisRequestKey_ExpirationEpoch(t *testing.T) {
+	assert.Equal(t, requestKeys.expirationEpoch, requestSut.ExpirationEpoch())
+}
+
+func TestRedisRequestKey_SecretId(t *testing.T) {
+	assert.Equal(t, requestKeys.secretId, requestSut.SecretId())
+}
+
+func TestRedisRequestKey_Creator(t *testing.T) {
+	assert.Equal(t, requestKeys.creator, requestSut.Creator())
+}
+
+func TestRedisRequestKey_AllKeys(t *testing.T) {
+	allKeys := requestSut.AllKeys()
+	for _, expected := range []string{requestKeys.ownerToken, requestKeys.contentType, requestKeys.maxSize, requestKeys.accessLimit, requestKeys.expirationEpoch, requestKeys.secretId, requestKeys.creator} {
+		assert.Contains(t, allKeys, expected)
+	}
+}
+
+var splitSut = redis.NewRedisSplitKeySet(keyIds, id)
+
+var splitKeys = struct {
+	contentType     string
+	filename        string
+	threshold       string
+	shares          string
+	expirationEpoch string
+}{
+	contentType:     fmt.Sprintf("splits:%s:contenttype", keyId),
+	filename:        fmt.Sprintf("splits:%s:filename", keyId),
+	threshold:       fmt.Sprintf("splits:%s:threshold", keyId),
+	shares:          fmt.Sprintf("splits:%s:shares", keyId),
+	expirationEpoch: fmt.Sprintf("splits:%s:expirationepoch", keyId),
+}
+
+func TestRedisSplitKey_ContentType(t *testing.T) {
+	assert.Equal(t, splitKeys.contentType, splitSut.ContentType())
+}
+
+func TestRedisSplitKey_Filename(t *testing.T) {
+	assert.Equal(t, splitKeys.filename, splitSut.Filename())
+}
+
+func TestRedisSplitKey_Threshold(t *testing.T) {
+	assert.Equal(t, splitKeys.threshold, splitSut.Threshold())
+}
+
+func TestRedisSplitKey_Shares(t *testing.T) {
+	assert.Equal(t, splitKeys.shares, splitSut.Shares())
+}
+
+func TestRedisSplitKey_ExpirationEpoch(t *testing.T) {
+	assert.Equal(t, splitKeys.expirationEpoch, splitSut.ExpirationEpoch())
+}
+
+func TestRedisSplitKey_AllKeys(t *testing.T) {
+	allKeys := splitSut.AllKeys()
+	for _, expected := range []string{splitKeys.contentType, splitKeys.filename, splitKeys.threshold, splitKeys.shares, splitKeys.expirationEpoch} {
 		assert.Contains(t, allKeys, expected)
 	}
 }
@@ -3,6 +3,7 @@ package errors
 import (
 	"context"
 	"errors"
+	"time"
 )
 
 // ErrContextCancelled is returned when an operation is cancelled due to context cancellation
@@ -120,3 +121,173 @@ func GetRateLimitError(err error) *RateLimitError {
 	}
 	return nil
 }
+
+// NotYetAvailableError represents an error caused by accessing a resource before it becomes available
+type NotYetAvailableError struct {
+	message       string
+	availableFrom time.Time
+}
+
+// Error implements the error interface
+func (e *NotYetAvailableError) Error() string {
+	return e.message
+}
+
+// AvailableFrom returns the time from which the resource is available
+func (e *NotYetAvailableError) AvailableFrom() time.Time {
+	return e.availableFrom
+}
+
+// NewNotYetAvailableError creates a new not yet available error with the given message and availability time
+func NewNotYetAvailableError(msg string, availableFrom time.Time) error {
+	return &NotYetAvailableError{
+		message:       msg,
+		availableFrom: availableFrom,
+	}
+}
+
+// IsNotYetAvailableError checks if an error is a not yet available error
+func IsNotYetAvailableError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var ne *NotYetAvailableError
+	return errors.As(err, &ne)
+}
+
+// GetNotYetAvailableError attempts to extract a NotYetAvailableError from an error chain
+func GetNotYetAvailableError(err error) *NotYetAvailableError {
+	if err == nil {
+		return nil
+	}
+	var ne *NotYetAvailableError
+	if errors.As(err, &ne) {
+		return ne
+	}
+	return nil
+}
+
+// ForbiddenError represents an error caused by a caller that is not allowed to perform an operation
+type ForbiddenError struct {
+	message string
+}
+
+// Error implements the error interface
+func (e *ForbiddenError) Error() string {
+	return e.message
+}
+
+// NewForbiddenError creates a new forbidden error with the given message
+func NewForbiddenError(msg string) error {
+	return &ForbiddenError{message: msg}
+}
+
+// IsForbiddenError checks if an error is a forbidden error
+func IsForbiddenError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var fe *ForbiddenError
+	return errors.As(err, &fe)
+}
+
+// UnauthorizedError represents an error caused by a caller that did not authenticate, or failed to
+type UnauthorizedError struct {
+	message string
+}
+
+// Error implements the error interface
+func (e *UnauthorizedError) Error() string {
+	return e.message
+}
+
+// NewUnauthorizedError creates a new unauthorized error with the given message
+func NewUnauthorizedError(msg string) error {
+	return &UnauthorizedError{message: msg}
+}
+
+// IsUnauthorizedError checks if an error is an unauthorized error
+func IsUnauthorizedError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var ue *UnauthorizedError
+	return errors.As(err, &ue)
+}
+
+// ConflictError represents an error caused by an operation that conflicts with the current state of a resource
+type ConflictError struct {
+	message string
+}
+
+// Error implements the error interface
+func (e *ConflictError) Error() string {
+	return e.message
+}
+
+// NewConflictError creates a new conflict error with the given message
+func NewConflictError(msg string) error {
+	return &ConflictError{message: msg}
+}
+
+// IsConflictError checks if an error is a conflict error
+func IsConflictError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var ce *ConflictError
+	return errors.As(err, &ce)
+}
+
+// InvalidCredentialError represents a forbidden error caused by a missing or invalid owner token, key or verification code
+type InvalidCredentialError struct {
+	forbidden *ForbiddenError
+}
+
+// Error implements the error interface
+func (e *InvalidCredentialError) Error() string {
+	return e.forbidden.Error()
+}
+
+// Unwrap returns the forbidden error, so an invalid credential error is also a forbidden error
+func (e *InvalidCredentialError) Unwrap() error {
+	return e.forbidden
+}
+
+// NewInvalidCredentialError creates a new invalid credential error with the given message
+func NewInvalidCredentialError(msg string) error {
+	return &InvalidCredentialError{forbidden: &ForbiddenError{message: msg}}
+}
+
+// IsInvalidCredentialError checks if an error is an invalid credential error
+func IsInvalidCredentialError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var ie *InvalidCredentialError
+	return errors.As(err, &ie)
+}
+
+// ReadOnlyError represents an error caused by a change requested while the server is in read-only mode
+type ReadOnlyError struct {
+	message string
+}
+
+// Error implements the error interface
+func (e *ReadOnlyError) Error() string {
+	return e.message
+}
+
+// NewReadOnlyError creates a new read-only error with the given message
+func NewReadOnlyError(msg string) error {
+	return &ReadOnlyError{message: msg}
+}
+
+// IsReadOnlyError checks if an error is a read-only error
+func IsReadOnlyError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var re *ReadOnlyError
+	return errors.As(err, &re)
+}
@@ -61,3 +61,30 @@ func TestWhenCheckingContext(t *testing.T) {
 		})
 	})
 }
+
+func TestWhenCheckingIfErrorIsInvalidCredentialError(t *testing.T) {
+	t.Run("and error is an invalid credential error", func(t *testing.T) {
+		err := NewInvalidCredentialError("invalid verification code")
+
+		t.Run("it should return true", func(t *testing.T) {
+			assert.True(t, IsInvalidCredentialError(err))
+		})
+
+		t.Run("it should also be a forbidden error", func(t *testing.T) {
+			assert.True(t, IsForbiddenError(err))
+			assert.Equal(t, "invalid verification code", err.Error())
+		})
+	})
+
+	t.Run("and error is another forbidden error", func(t *testing.T) {
+		t.Run("it should return false", func(t *testing.T) {
+			assert.False(t, IsInvalidCredentialError(NewForbiddenError("this secret cannot be accessed from this country")))
+		})
+	})
+
+	t.Run("and error is nil", func(t *testing.T) {
+		t.Run("it should return false", func(t *testing.T) {
+			assert.False(t, IsInvalidCredentialError(nil))
+		})
+	})
+}
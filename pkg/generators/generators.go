@@ -0,0 +1,250 @@
+package generators
+
+import (
+	"cellar/pkg/bundles"
+	pkgerrors "cellar/pkg/errors"
+	"cellar/pkg/models"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+
+	"filippo.io/age"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	defaultPasswordLength = 24
+	minPasswordLength     = 8
+	maxPasswordLength     = 256
+
+	defaultTokenBytes = 32
+	minTokenBytes     = 16
+	maxTokenBytes     = 256
+)
+
+var characterClasses = map[string]string{
+	models.CharacterClassLowercase: "abcdefghijklmnopqrstuvwxyz",
+	models.CharacterClassUppercase: "ABCDEFGHIJKLMNOPQRSTUVWXYZ",
+	models.CharacterClassDigits:    "0123456789",
+	models.CharacterClassSymbols:   "!#$%&*+-=?@^_~.,:;()[]{}",
+}
+
+var allCharacterClasses = []string{
+	models.CharacterClassLowercase,
+	models.CharacterClassUppercase,
+	models.CharacterClassDigits,
+	models.CharacterClassSymbols,
+}
+
+// Generate fills the secret content with newly generated material of the requested kind.
+// Passwords, tokens and UUIDs are stored as text; key pairs are stored as a bundle of the private and public key.
+// Invalid options are returned as ValidationError types.
+func Generate(options models.GenerateSecretOptions, secret *models.Secret) error {
+	if options.Length != 0 && options.Kind != models.GeneratedSecretKindPassword && options.Kind != models.GeneratedSecretKindToken {
+		return pkgerrors.NewValidationError(fmt.Sprintf("length is not supported for %s secrets", options.Kind))
+	}
+	if len(options.CharacterClasses) > 0 && options.Kind != models.GeneratedSecretKindPassword {
+		return pkgerrors.NewValidationError(fmt.Sprintf("character_classes is not supported for %s secrets", options.Kind))
+	}
+
+	switch options.Kind {
+	case models.GeneratedSecretKindPassword:
+		password, err := Password(options.Length, options.CharacterClasses)
+		if err != nil {
+			return err
+		}
+		setText(secret, password)
+	case models.GeneratedSecretKindToken:
+		token, err := Token(options.Length)
+		if err != nil {
+			return err
+		}
+		setText(secret, token)
+	case models.GeneratedSecretKindUUID:
+		uuid, err := UUID()
+		if err != nil {
+			return err
+		}
+		setText(secret, uuid)
+	case models.GeneratedSecretKindSSH:
+		privateKey, publicKey, err := SSHKeyPair()
+		if err != nil {
+			return err
+		}
+		return setBundle(secret, []bundles.File{
+			{Name: "id_ed25519", Content: privateKey},
+			{Name: "id_ed25519.pub", Content: publicKey},
+		})
+	case models.GeneratedSecretKindAge:
+		identity, recipient, err := AgeKeyPair()
+		if err != nil {
+			return err
+		}
+		return setBundle(secret, []bundles.File{
+			{Name: "key.txt", Content: identity},
+			{Name: "key.txt.pub", Content: recipient},
+		})
+	default:
+		return pkgerrors.NewValidationError("kind must be password, token, uuid, ssh or age")
+	}
+
+	return nil
+}
+
+// Password returns a random password of the given length drawn from the given character classes.
+// Every class appears at least once. A length of zero uses the default length and no classes uses every class.
+func Password(length int, classes []string) (string, error) {
+	if length == 0 {
+		length = defaultPasswordLength
+	}
+	if length < minPasswordLength || length > maxPasswordLength {
+		return "", pkgerrors.NewValidationError(fmt.Sprintf("password length must be between %d and %d", minPasswordLength, maxPasswordLength))
+	}
+
+	if len(classes) == 0 {
+		classes = allCharacterClasses
+	}
+
+	alphabet := ""
+	seen := make(map[string]bool, len(classes))
+	for _, class := range classes {
+		characters, ok := characterClasses[class]
+		if !ok {
+			return "", pkgerrors.NewValidationError("character_classes must be lowercase, uppercase, digits or symbols")
+		}
+		if seen[class] {
+			continue
+		}
+		seen[class] = true
+		alphabet += characters
+	}
+	if length < len(seen) {
+		return "", pkgerrors.NewValidationError("password length must be at least the number of character classes")
+	}
+
+	password := make([]byte, 0, length)
+	for _, class := range classes {
+		if !seen[class] {
+			continue
+		}
+		delete(seen, class)
+		character, err := randomCharacter(characterClasses[class])
+		if err != nil {
+			return "", err
+		}
+		password = append(password, character)
+	}
+	for len(password) < length {
+		character, err := randomCharacter(alphabet)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, character)
+	}
+
+	// The required characters were placed first, so shuffle them into random positions.
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := randomInt(i + 1)
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j] = password[j], password[i]
+	}
+
+	return string(password), nil
+}
+
+// Token returns the given number of random bytes as a hex string. A size of zero uses the default size.
+func Token(size int) (string, error) {
+	if size == 0 {
+		size = defaultTokenBytes
+	}
+	if size < minTokenBytes || size > maxTokenBytes {
+		return "", pkgerrors.NewValidationError(fmt.Sprintf("token length must be between %d and %d bytes", minTokenBytes, maxTokenBytes))
+	}
+
+	token := make([]byte, size)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
+
+// UUID returns a random version 4 UUID.
+func UUID() (string, error) {
+	uuid := make([]byte, 16)
+	if _, err := rand.Read(uuid); err != nil {
+		return "", err
+	}
+	uuid[6] = (uuid[6] & 0x0f) | 0x40
+	uuid[8] = (uuid[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:]), nil
+}
+
+// SSHKeyPair returns a new Ed25519 key pair as an OpenSSH private key and an authorized_keys line.
+func SSHKeyPair() (privateKey []byte, publicKey []byte, err error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	block, err := ssh.MarshalPrivateKey(private, "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sshPublic, err := ssh.NewPublicKey(public)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pem.EncodeToMemory(block), ssh.MarshalAuthorizedKey(sshPublic), nil
+}
+
+// AgeKeyPair returns a new X25519 age identity in the age-keygen file format and its recipient.
+func AgeKeyPair() (identity []byte, recipient []byte, err error) {
+	key, err := age.GenerateX25519Identity()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	identity = fmt.Appendf(nil, "# created: %s\n# public key: %s\n%s\n",
+		time.Now().UTC().Format(time.RFC3339), key.Recipient(), key)
+	return identity, fmt.Appendf(nil, "%s\n", key.Recipient()), nil
+}
+
+func setText(secret *models.Secret, content string) {
+	secret.Content = []byte(content)
+	secret.ContentType = models.ContentTypeText
+}
+
+func setBundle(secret *models.Secret, files []bundles.File) error {
+	archive, items, err := bundles.Write(files)
+	if err != nil {
+		return err
+	}
+	secret.Content = archive
+	secret.ContentType = models.ContentTypeBundle
+	secret.BundleItems = items
+	return nil
+}
+
+func randomCharacter(characters string) (byte, error) {
+	i, err := randomInt(len(characters))
+	if err != nil {
+		return 0, err
+	}
+	return characters[i], nil
+}
+
+func randomInt(n int) (int, error) {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(i.Int64()), nil
+}
@@ -0,0 +1,158 @@
+package generators
+
+import (
+	"cellar/pkg/bundles"
+	pkgerrors "cellar/pkg/errors"
+	"cellar/pkg/models"
+	"regexp"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestPassword(t *testing.T) {
+	t.Run("when no options are given", func(t *testing.T) {
+		password, err := Password(0, nil)
+		require.NoError(t, err)
+
+		t.Run("it should use the default length", func(t *testing.T) {
+			assert.Len(t, password, defaultPasswordLength)
+		})
+
+		t.Run("it should include every character class", func(t *testing.T) {
+			for _, class := range allCharacterClasses {
+				assert.True(t, strings.ContainsAny(password, characterClasses[class]), "expected %s characters", class)
+			}
+		})
+	})
+
+	t.Run("when character classes are given", func(t *testing.T) {
+		password, err := Password(minPasswordLength, []string{models.CharacterClassDigits})
+		require.NoError(t, err)
+
+		t.Run("it should only use those classes", func(t *testing.T) {
+			assert.Regexp(t, regexp.MustCompile(`^[0-9]{8}$`), password)
+		})
+	})
+
+	t.Run("when the length is out of range", func(t *testing.T) {
+		_, err := Password(minPasswordLength-1, nil)
+
+		t.Run("it should return validation error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsValidationError(err), "expected validation error")
+		})
+	})
+
+	t.Run("when a character class is unknown", func(t *testing.T) {
+		_, err := Password(0, []string{"emoji"})
+
+		t.Run("it should return validation error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsValidationError(err), "expected validation error")
+		})
+	})
+}
+
+func TestToken(t *testing.T) {
+	t.Run("when generating a token", func(t *testing.T) {
+		token, err := Token(minTokenBytes)
+		require.NoError(t, err)
+
+		t.Run("it should hex encode the random bytes", func(t *testing.T) {
+			assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{32}$`), token)
+		})
+	})
+
+	t.Run("when the length is out of range", func(t *testing.T) {
+		_, err := Token(maxTokenBytes + 1)
+
+		t.Run("it should return validation error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsValidationError(err), "expected validation error")
+		})
+	})
+}
+
+func TestUUID(t *testing.T) {
+	uuid, err := UUID()
+	require.NoError(t, err)
+
+	t.Run("it should return a version 4 UUID", func(t *testing.T) {
+		assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), uuid)
+	})
+}
+
+func TestSSHKeyPair(t *testing.T) {
+	privateKey, publicKey, err := SSHKeyPair()
+	require.NoError(t, err)
+
+	t.Run("it should return a matching OpenSSH key pair", func(t *testing.T) {
+		signer, err := ssh.ParsePrivateKey(privateKey)
+		require.NoError(t, err)
+
+		parsed, _, _, _, err := ssh.ParseAuthorizedKey(publicKey)
+		require.NoError(t, err)
+		assert.Equal(t, parsed.Marshal(), signer.PublicKey().Marshal())
+	})
+}
+
+func TestAgeKeyPair(t *testing.T) {
+	identity, recipient, err := AgeKeyPair()
+	require.NoError(t, err)
+
+	t.Run("it should return a matching age identity and recipient", func(t *testing.T) {
+		identities, err := age.ParseIdentities(strings.NewReader(string(identity)))
+		require.NoError(t, err)
+		require.Len(t, identities, 1)
+
+		assert.Equal(t, strings.TrimSpace(string(recipient)), identities[0].(*age.X25519Identity).Recipient().String())
+	})
+}
+
+func TestGenerate(t *testing.T) {
+	t.Run("when generating a key pair", func(t *testing.T) {
+		var secret models.Secret
+		require.NoError(t, Generate(models.GenerateSecretOptions{Kind: models.GeneratedSecretKindSSH}, &secret))
+
+		t.Run("it should store a bundle of the private and public key", func(t *testing.T) {
+			assert.Equal(t, models.ContentTypeBundle, secret.ContentType)
+			require.Len(t, secret.BundleItems, 2)
+			assert.Equal(t, "id_ed25519", secret.BundleItems[0].Name)
+			assert.Equal(t, "id_ed25519.pub", secret.BundleItems[1].Name)
+
+			publicKey, err := bundles.ReadItem(secret.Content, 1)
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(string(publicKey), "ssh-ed25519 "))
+		})
+	})
+
+	t.Run("when generating a password", func(t *testing.T) {
+		var secret models.Secret
+		require.NoError(t, Generate(models.GenerateSecretOptions{Kind: models.GeneratedSecretKindPassword, Length: 32}, &secret))
+
+		t.Run("it should store the password as text", func(t *testing.T) {
+			assert.Equal(t, models.ContentTypeText, secret.ContentType)
+			assert.Len(t, secret.Content, 32)
+		})
+	})
+
+	t.Run("when an option does not apply to the kind", func(t *testing.T) {
+		var secret models.Secret
+		err := Generate(models.GenerateSecretOptions{Kind: models.GeneratedSecretKindUUID, Length: 32}, &secret)
+
+		t.Run("it should return validation error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsValidationError(err), "expected validation error")
+		})
+	})
+
+	t.Run("when the kind is unknown", func(t *testing.T) {
+		var secret models.Secret
+		err := Generate(models.GenerateSecretOptions{Kind: "pgp"}, &secret)
+
+		t.Run("it should return validation error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsValidationError(err), "expected validation error")
+		})
+	})
+}
@@ -0,0 +1,58 @@
+package geoip
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseCountries validates and normalizes a list of ISO 3166-1 alpha-2 country codes.
+// Codes are upper-cased and duplicates are removed.
+func ParseCountries(values []string) ([]string, error) {
+	countries := make([]string, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for _, value := range values {
+		country := strings.ToUpper(strings.TrimSpace(value))
+		if country == "" || seen[country] {
+			continue
+		}
+		if !isCountryCode(country) {
+			return nil, fmt.Errorf("invalid country code %q", value)
+		}
+		seen[country] = true
+		countries = append(countries, country)
+	}
+	return countries, nil
+}
+
+// Allows reports whether a client from the country passes the allowed and denied lists.
+// A denied country is always rejected. When there is an allowed list, only its countries pass,
+// so a client whose country is unknown is rejected by it.
+func Allows(allowed []string, denied []string, country string) bool {
+	for _, deniedCountry := range denied {
+		if deniedCountry == country {
+			return false
+		}
+	}
+
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, allowedCountry := range allowed {
+		if allowedCountry == country {
+			return true
+		}
+	}
+	return false
+}
+
+func isCountryCode(value string) bool {
+	if len(value) != 2 {
+		return false
+	}
+	for _, char := range value {
+		if char < 'A' || char > 'Z' {
+			return false
+		}
+	}
+	return true
+}
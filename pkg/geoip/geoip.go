@@ -0,0 +1,124 @@
+// Package geoip resolves the country of a client IP address from a local MaxMind DB file,
+// so access can be restricted by country without calling an external service.
+package geoip
+
+import (
+	"errors"
+	"net"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+//go:generate go run testdata/generate.go
+
+var Key = "GEOIP"
+
+// CountryKey holds the country resolved for the client of the current request.
+var CountryKey = "GEOIP_COUNTRY"
+
+//go:generate mockgen -destination=../mocks/mock_locator.go -package=mocks . Locator
+type Locator interface {
+	// Country returns the ISO 3166-1 alpha-2 code of the country of the IP address, or an empty string if it is unknown.
+	Country(ip string) string
+}
+
+// NoopLocator knows no countries. It is used when no GeoIP database is configured.
+type NoopLocator struct{}
+
+func NewNoopLocator() *NoopLocator {
+	return &NoopLocator{}
+}
+
+func (locator NoopLocator) Country(_ string) string {
+	return ""
+}
+
+// Database is a Locator backed by a MaxMind DB file, such as GeoLite2 Country.
+// The file is read into memory, so it can be replaced on disk and reloaded without interrupting lookups.
+type Database struct {
+	path    string
+	current atomic.Pointer[loadedDatabase]
+}
+
+type loadedDatabase struct {
+	reader  *maxminddb.Reader
+	modTime time.Time
+	size    int64
+}
+
+type countryRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	RegisteredCountry struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"registered_country"`
+}
+
+// Open loads the MaxMind DB file at the given path.
+func Open(path string) (*Database, error) {
+	database := &Database{path: path}
+	if _, err := database.Reload(); err != nil {
+		return nil, err
+	}
+	return database, nil
+}
+
+func (database *Database) Country(ip string) string {
+	address := net.ParseIP(ip)
+	if address == nil {
+		return ""
+	}
+
+	var record countryRecord
+	if err := database.current.Load().reader.Lookup(address, &record); err != nil {
+		return ""
+	}
+
+	if record.Country.ISOCode != "" {
+		return record.Country.ISOCode
+	}
+	return record.RegisteredCountry.ISOCode
+}
+
+// Reload loads the file again if it changed since it was last loaded.
+// If the new file cannot be read, the database that was loaded before stays in use.
+// Returns whether a new file was loaded.
+func (database *Database) Reload() (bool, error) {
+	info, err := os.Stat(database.path)
+	if err != nil {
+		return false, err
+	}
+
+	if current := database.current.Load(); current != nil && current.modTime.Equal(info.ModTime()) && current.size == info.Size() {
+		return false, nil
+	}
+
+	content, err := os.ReadFile(database.path)
+	if err != nil {
+		return false, err
+	}
+
+	reader, err := maxminddb.FromBytes(content)
+	if err != nil {
+		return false, err
+	}
+	if reader.Metadata.IPVersion != 4 && reader.Metadata.IPVersion != 6 {
+		return false, errors.New("unsupported GeoIP database IP version")
+	}
+
+	database.current.Store(&loadedDatabase{
+		reader:  reader,
+		modTime: info.ModTime(),
+		size:    info.Size(),
+	})
+	return true, nil
+}
+
+// DatabaseType returns the type of the loaded database, such as GeoLite2-Country.
+func (database *Database) DatabaseType() string {
+	return database.current.Load().reader.Metadata.DatabaseType
+}
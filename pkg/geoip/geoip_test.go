@@ -0,0 +1,129 @@
+package geoip
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fixturePath = "testdata/countries.mmdb"
+
+func TestDatabase(t *testing.T) {
+	database, err := Open(fixturePath)
+	require.NoError(t, err)
+
+	t.Run("when the address is in the database", func(t *testing.T) {
+		t.Run("it should return the country of IPv4 addresses", func(t *testing.T) {
+			assert.Equal(t, "DE", database.Country("192.0.2.10"))
+			assert.Equal(t, "US", database.Country("198.51.100.200"))
+		})
+
+		t.Run("it should return the country of IPv6 addresses", func(t *testing.T) {
+			assert.Equal(t, "JP", database.Country("2001:db8::1"))
+		})
+	})
+
+	t.Run("when the address is not in the database", func(t *testing.T) {
+		t.Run("it should return an empty country", func(t *testing.T) {
+			assert.Equal(t, "", database.Country("10.0.0.1"))
+		})
+	})
+
+	t.Run("when the address is invalid", func(t *testing.T) {
+		t.Run("it should return an empty country", func(t *testing.T) {
+			assert.Equal(t, "", database.Country("not an address"))
+		})
+	})
+}
+
+func TestDatabaseReload(t *testing.T) {
+	fixture, err := os.ReadFile(fixturePath)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "countries.mmdb")
+	require.NoError(t, os.WriteFile(path, fixture, 0o600))
+
+	database, err := Open(path)
+	require.NoError(t, err)
+
+	t.Run("when the file has not changed", func(t *testing.T) {
+		reloaded, err := database.Reload()
+
+		t.Run("it should keep the loaded database", func(t *testing.T) {
+			require.NoError(t, err)
+			assert.False(t, reloaded)
+		})
+	})
+
+	t.Run("when the file is replaced with an invalid database", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte("not a database"), 0o600))
+		require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+
+		_, err := database.Reload()
+
+		t.Run("it should return an error", func(t *testing.T) {
+			assert.Error(t, err)
+		})
+
+		t.Run("it should keep resolving with the loaded database", func(t *testing.T) {
+			assert.Equal(t, "DE", database.Country("192.0.2.10"))
+		})
+	})
+
+	t.Run("when the file is replaced with a valid database", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, fixture, 0o600))
+		require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(2*time.Minute)))
+
+		reloaded, err := database.Reload()
+
+		t.Run("it should load the new file", func(t *testing.T) {
+			require.NoError(t, err)
+			assert.True(t, reloaded)
+		})
+	})
+}
+
+func TestParseCountries(t *testing.T) {
+	t.Run("when the codes are valid", func(t *testing.T) {
+		countries, err := ParseCountries([]string{"de", " US ", "DE", ""})
+		require.NoError(t, err)
+
+		t.Run("it should normalize the codes and remove duplicates", func(t *testing.T) {
+			assert.Equal(t, []string{"DE", "US"}, countries)
+		})
+	})
+
+	t.Run("when a code is invalid", func(t *testing.T) {
+		_, err := ParseCountries([]string{"DEU"})
+
+		t.Run("it should return an error", func(t *testing.T) {
+			assert.Error(t, err)
+		})
+	})
+}
+
+func TestAllows(t *testing.T) {
+	t.Run("it should reject denied countries", func(t *testing.T) {
+		assert.False(t, Allows(nil, []string{"DE"}, "DE"))
+		assert.False(t, Allows([]string{"DE"}, []string{"DE"}, "DE"))
+	})
+
+	t.Run("it should only allow listed countries when there is an allowed list", func(t *testing.T) {
+		assert.True(t, Allows([]string{"DE", "FR"}, nil, "FR"))
+		assert.False(t, Allows([]string{"DE", "FR"}, nil, "US"))
+	})
+
+	t.Run("it should reject unknown countries when there is an allowed list", func(t *testing.T) {
+		assert.False(t, Allows([]string{"DE"}, nil, ""))
+	})
+
+	t.Run("it should allow every other country", func(t *testing.T) {
+		assert.True(t, Allows(nil, []string{"DE"}, "US"))
+		assert.True(t, Allows(nil, []string{"DE"}, ""))
+		assert.True(t, Allows(nil, nil, ""))
+	})
+}
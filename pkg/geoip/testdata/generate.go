@@ -0,0 +1,161 @@
+//go:build ignore
+
+// This program writes the MaxMind DB fixture used by the geoip tests.
+// The ranges are reserved for documentation, so the fixture makes no claim about real addresses.
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"log"
+	"net/netip"
+	"os"
+	"sort"
+)
+
+var countries = []struct {
+	prefix  string
+	country string
+}{
+	{prefix: "192.0.2.0/24", country: "DE"},
+	{prefix: "198.51.100.0/24", country: "US"},
+	{prefix: "203.0.113.0/24", country: "FR"},
+	{prefix: "2001:db8::/32", country: "JP"},
+}
+
+const recordSize = 24
+
+type record struct {
+	node int
+	data int
+	set  bool
+	leaf bool
+}
+
+type node struct {
+	records [2]record
+}
+
+func main() {
+	data := bytes.NewBuffer(nil)
+	nodes := []node{{}}
+
+	for _, entry := range countries {
+		prefix := netip.MustParsePrefix(entry.prefix)
+		bits := prefix.Bits()
+		addr := prefix.Addr()
+		if addr.Is4() {
+			// IPv4 networks live in the ::/96 subtree of an IPv6 database.
+			bits += 96
+		}
+		address := addr.As16()
+		if addr.Is4() {
+			address = [16]byte{}
+			copy(address[12:], addr.AsSlice())
+		}
+
+		offset := data.Len()
+		writeMap(data, map[string]func(*bytes.Buffer){
+			"country": func(buf *bytes.Buffer) {
+				writeMap(buf, map[string]func(*bytes.Buffer){
+					"iso_code": func(buf *bytes.Buffer) { writeString(buf, entry.country) },
+				})
+			},
+		})
+
+		current := 0
+		for i := 0; i < bits; i++ {
+			bit := (address[i/8] >> (7 - i%8)) & 1
+			if i == bits-1 {
+				nodes[current].records[bit] = record{data: offset, set: true, leaf: true}
+				break
+			}
+			next := nodes[current].records[bit]
+			if !next.set {
+				nodes = append(nodes, node{})
+				next = record{node: len(nodes) - 1, set: true}
+				nodes[current].records[bit] = next
+			}
+			current = next.node
+		}
+	}
+
+	nodeCount := len(nodes)
+	file := bytes.NewBuffer(nil)
+	for _, n := range nodes {
+		for _, r := range n.records {
+			value := nodeCount
+			switch {
+			case r.set && r.leaf:
+				value = nodeCount + 16 + r.data
+			case r.set:
+				value = r.node
+			}
+			file.Write([]byte{byte(value >> 16), byte(value >> 8), byte(value)})
+		}
+	}
+	file.Write(make([]byte, 16))
+	file.Write(data.Bytes())
+	file.WriteString("\xab\xcd\xefMaxMind.com")
+	writeMap(file, map[string]func(*bytes.Buffer){
+		"binary_format_major_version": func(buf *bytes.Buffer) { writeUint(buf, 5, 2) },
+		"binary_format_minor_version": func(buf *bytes.Buffer) { writeUint(buf, 5, 0) },
+		"build_epoch":                 func(buf *bytes.Buffer) { writeUint(buf, 9, 1735689600) },
+		"database_type":               func(buf *bytes.Buffer) { writeString(buf, "Cellar-Test-Country") },
+		"description": func(buf *bytes.Buffer) {
+			writeMap(buf, map[string]func(*bytes.Buffer){
+				"en": func(buf *bytes.Buffer) { writeString(buf, "Cellar GeoIP test fixture") },
+			})
+		},
+		"ip_version": func(buf *bytes.Buffer) { writeUint(buf, 5, 6) },
+		"languages": func(buf *bytes.Buffer) {
+			writeControl(buf, 11, 1)
+			writeString(buf, "en")
+		},
+		"node_count":  func(buf *bytes.Buffer) { writeUint(buf, 6, uint64(nodeCount)) },
+		"record_size": func(buf *bytes.Buffer) { writeUint(buf, 5, recordSize) },
+	})
+
+	if err := os.WriteFile("testdata/countries.mmdb", file.Bytes(), 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func writeControl(buf *bytes.Buffer, dataType int, size int) {
+	if size >= 29 {
+		log.Fatalf("size %d is not supported by this fixture writer", size)
+	}
+	if dataType > 7 {
+		buf.WriteByte(byte(size))
+		buf.WriteByte(byte(dataType - 7))
+		return
+	}
+	buf.WriteByte(byte(dataType<<5 | size))
+}
+
+func writeString(buf *bytes.Buffer, value string) {
+	writeControl(buf, 2, len(value))
+	buf.WriteString(value)
+}
+
+func writeUint(buf *bytes.Buffer, dataType int, value uint64) {
+	encoded := make([]byte, 8)
+	binary.BigEndian.PutUint64(encoded, value)
+	encoded = bytes.TrimLeft(encoded, "\x00")
+	writeControl(buf, dataType, len(encoded))
+	buf.Write(encoded)
+}
+
+func writeMap(buf *bytes.Buffer, entries map[string]func(*bytes.Buffer)) {
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	writeControl(buf, 7, len(keys))
+	for _, key := range keys {
+		writeString(buf, key)
+		entries[key](buf)
+	}
+}
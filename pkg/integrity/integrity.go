@@ -0,0 +1,129 @@
+// Package integrity tags stored records with an HMAC over their metadata under a server-side key,
+// so changes made directly in the datastore, such as raising an access limit or extending an expiration,
+// are detected when the record is read.
+package integrity
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrMissingTag is returned when a record has no tag while untagged records are not accepted.
+	ErrMissingTag = errors.New("record has no integrity tag")
+	// ErrUnknownKey is returned when a record is tagged with a key that is no longer configured.
+	ErrUnknownKey = errors.New("record is tagged with an unknown integrity key")
+	// ErrMismatch is returned when a record does not match its tag.
+	ErrMismatch = errors.New("record does not match its integrity tag")
+)
+
+type key struct {
+	id     string
+	secret []byte
+}
+
+var (
+	mutex         sync.RWMutex
+	keys          []key
+	allowUntagged bool
+)
+
+// SetKeys sets the keys records are tagged with, each given as "<key id>:<secret>".
+// The first key tags records, the others only verify them, so a key can be rotated by putting a new key first
+// and dropping the old one once the records it tagged have expired. Without keys, records are neither tagged nor verified.
+func SetKeys(entries []string) error {
+	parsed := make([]key, 0, len(entries))
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		id, secret, found := strings.Cut(entry, ":")
+		if !found || id == "" || secret == "" {
+			return fmt.Errorf("integrity key must be given as <key id>:<secret>")
+		}
+		if seen[id] {
+			return fmt.Errorf("integrity key %s is given more than once", id)
+		}
+		seen[id] = true
+		parsed = append(parsed, key{id: id, secret: []byte(secret)})
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	keys = parsed
+	return nil
+}
+
+// SetAllowUntagged sets whether records without a tag, such as records written before keys were configured, are accepted.
+func SetAllowUntagged(value bool) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	allowUntagged = value
+}
+
+// Enabled reports whether any keys are configured.
+func Enabled() bool {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return len(keys) > 0
+}
+
+// Tag returns the tag of a record's fields under the current key, as "<key id>:<mac>".
+// Returns an empty tag if no keys are configured.
+func Tag(fields ...string) string {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	if len(keys) == 0 {
+		return ""
+	}
+	return keys[0].id + ":" + sign(keys[0].secret, fields)
+}
+
+// Verify checks a record's fields against its tag. It accepts every record if no keys are configured.
+func Verify(tag string, fields ...string) error {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	if len(keys) == 0 {
+		return nil
+	}
+	if tag == "" {
+		if allowUntagged {
+			return nil
+		}
+		return ErrMissingTag
+	}
+
+	id, mac, _ := strings.Cut(tag, ":")
+	for _, k := range keys {
+		if k.id != id {
+			continue
+		}
+		if !hmac.Equal([]byte(mac), []byte(sign(k.secret, fields))) {
+			return ErrMismatch
+		}
+		return nil
+	}
+	return ErrUnknownKey
+}
+
+// Digest returns the SHA-256 of a field too large to tag directly, such as a ciphertext.
+func Digest(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// sign length-prefixes each field, so moving content from one field to the next changes the tag.
+func sign(secret []byte, fields []string) string {
+	mac := hmac.New(sha256.New, secret)
+	var length [8]byte
+	for _, field := range fields {
+		binary.BigEndian.PutUint64(length[:], uint64(len(field)))
+		mac.Write(length[:])
+		mac.Write([]byte(field))
+	}
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
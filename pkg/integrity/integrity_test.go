@@ -0,0 +1,99 @@
+package integrity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTag(t *testing.T) {
+	t.Cleanup(func() {
+		_ = SetKeys(nil)
+		SetAllowUntagged(false)
+	})
+
+	t.Run("when no keys are configured", func(t *testing.T) {
+		require.NoError(t, SetKeys(nil))
+
+		t.Run("it should not tag records", func(t *testing.T) {
+			assert.False(t, Enabled())
+			assert.Empty(t, Tag("10", "1700000000"))
+		})
+
+		t.Run("it should accept every record", func(t *testing.T) {
+			assert.NoError(t, Verify("", "10", "1700000000"))
+		})
+	})
+
+	t.Run("when a key is configured", func(t *testing.T) {
+		require.NoError(t, SetKeys([]string{"k1:secret"}))
+		tag := Tag("10", "1700000000")
+
+		t.Run("it should tag records with the key id", func(t *testing.T) {
+			assert.True(t, Enabled())
+			assert.Regexp(t, `^k1:`, tag)
+		})
+
+		t.Run("it should accept records that match their tag", func(t *testing.T) {
+			assert.NoError(t, Verify(tag, "10", "1700000000"))
+		})
+
+		t.Run("it should reject records that were changed", func(t *testing.T) {
+			assert.ErrorIs(t, Verify(tag, "100", "1700000000"), ErrMismatch)
+		})
+
+		t.Run("it should reject content moved between fields", func(t *testing.T) {
+			assert.ErrorIs(t, Verify(tag, "101", "700000000"), ErrMismatch)
+		})
+
+		t.Run("it should reject records without a tag", func(t *testing.T) {
+			assert.ErrorIs(t, Verify("", "10", "1700000000"), ErrMissingTag)
+		})
+
+		t.Run("and untagged records are allowed", func(t *testing.T) {
+			SetAllowUntagged(true)
+			t.Cleanup(func() { SetAllowUntagged(false) })
+
+			t.Run("it should accept records without a tag", func(t *testing.T) {
+				assert.NoError(t, Verify("", "10", "1700000000"))
+			})
+
+			t.Run("it should still reject records that were changed", func(t *testing.T) {
+				assert.ErrorIs(t, Verify(tag, "100", "1700000000"), ErrMismatch)
+			})
+		})
+
+		t.Run("and the key is rotated", func(t *testing.T) {
+			require.NoError(t, SetKeys([]string{"k2:another secret", "k1:secret"}))
+
+			t.Run("it should tag records with the new key", func(t *testing.T) {
+				assert.Regexp(t, `^k2:`, Tag("10", "1700000000"))
+			})
+
+			t.Run("it should still accept records tagged with the old key", func(t *testing.T) {
+				assert.NoError(t, Verify(tag, "10", "1700000000"))
+			})
+		})
+
+		t.Run("and the old key is dropped", func(t *testing.T) {
+			require.NoError(t, SetKeys([]string{"k2:another secret"}))
+
+			t.Run("it should reject records tagged with the old key", func(t *testing.T) {
+				assert.ErrorIs(t, Verify(tag, "10", "1700000000"), ErrUnknownKey)
+			})
+		})
+	})
+}
+
+func TestSetKeys(t *testing.T) {
+	t.Cleanup(func() { _ = SetKeys(nil) })
+
+	t.Run("when a key has no id", func(t *testing.T) {
+		assert.Error(t, SetKeys([]string{"secret"}))
+	})
+
+	t.Run("when a key id is given twice", func(t *testing.T) {
+		assert.Error(t, SetKeys([]string{"k1:secret", "k1:another secret"}))
+	})
+}
@@ -0,0 +1,42 @@
+// Package keyid derives the key IDs that records are stored and logged under, so that the IDs
+// handed to clients, which grant access to their secrets, never appear in the datastore or the logs.
+package keyid
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"regexp"
+)
+
+// legacyIdPattern matches the IDs that records were stored under before key IDs were introduced.
+var legacyIdPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// Hasher derives key IDs under a server-side secret, the pepper.
+// Every record written under one pepper is lost to lookups under another.
+type Hasher struct {
+	pepper []byte
+}
+
+// NewHasher returns a Hasher that derives key IDs under the pepper.
+// An empty pepper returns an error, as anyone could then derive key IDs from secret IDs.
+func NewHasher(pepper string) (*Hasher, error) {
+	if pepper == "" {
+		return nil, errors.New("the pepper must not be empty")
+	}
+	return &Hasher{pepper: []byte(pepper)}, nil
+}
+
+// Hash returns the key ID of a record ID: its HMAC-SHA256 under the pepper, encoded as unpadded URL-safe base64.
+// The encoding keeps key IDs apart from the hex encoded IDs that records were previously stored under.
+func (hasher *Hasher) Hash(id string) string {
+	mac := hmac.New(sha256.New, hasher.pepper)
+	mac.Write([]byte(id))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// IsLegacy reports whether a stored ID is a record ID that still has to be replaced by its key ID.
+func IsLegacy(storedId string) bool {
+	return legacyIdPattern.MatchString(storedId)
+}
@@ -0,0 +1,66 @@
+package keyid
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const id = "22b6fff1be15d1fd54b7b8ec6ad22e80e66275195c914c4b0f9652248a498680"
+
+func TestNewHasher(t *testing.T) {
+	t.Run("when the pepper is empty", func(t *testing.T) {
+		_, err := NewHasher("")
+
+		t.Run("it should return error", func(t *testing.T) {
+			assert.Error(t, err)
+		})
+	})
+}
+
+func TestHash(t *testing.T) {
+	sut, err := NewHasher("pepper")
+	require.NoError(t, err)
+	hashed := sut.Hash(id)
+
+	t.Run("it should not contain the id", func(t *testing.T) {
+		assert.NotContains(t, hashed, id)
+		assert.NotContains(t, hashed, id[:8])
+	})
+
+	t.Run("it should be stable", func(t *testing.T) {
+		assert.Equal(t, hashed, sut.Hash(id))
+	})
+
+	t.Run("it should be safe to use in a key name", func(t *testing.T) {
+		assert.Len(t, hashed, 43)
+		assert.False(t, strings.ContainsAny(hashed, ":*?[]"))
+	})
+
+	t.Run("it should not be mistaken for a legacy id", func(t *testing.T) {
+		assert.False(t, IsLegacy(hashed))
+	})
+
+	t.Run("when the pepper changes", func(t *testing.T) {
+		other, err := NewHasher("another pepper")
+		require.NoError(t, err)
+
+		t.Run("it should derive another key id", func(t *testing.T) {
+			assert.NotEqual(t, hashed, other.Hash(id))
+		})
+	})
+}
+
+func TestIsLegacy(t *testing.T) {
+	t.Run("it should match record ids", func(t *testing.T) {
+		assert.True(t, IsLegacy(id))
+	})
+
+	t.Run("it should not match anything else", func(t *testing.T) {
+		assert.False(t, IsLegacy(strings.ToUpper(id)))
+		assert.False(t, IsLegacy(id[:63]))
+		assert.False(t, IsLegacy(""))
+	})
+}
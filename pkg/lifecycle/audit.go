@@ -0,0 +1,28 @@
+package lifecycle
+
+import (
+	"cellar/pkg/models"
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// AuditHandler records every lifecycle event to the audit log.
+type AuditHandler struct {
+	logger *log.Entry
+}
+
+func NewAuditHandler() *AuditHandler {
+	return &AuditHandler{
+		logger: log.WithField("context", "audit"),
+	}
+}
+
+func (handler AuditHandler) Handle(_ context.Context, event models.LifecycleEvent) {
+	handler.logger.WithFields(log.Fields{
+		"event":      event.Type,
+		"secretId":   event.KeyID,
+		"occurredAt": event.OccurredAt.UTC().Format(time.RFC3339),
+	}).Info("Secret lifecycle event")
+}
@@ -0,0 +1,26 @@
+package lifecycle
+
+import (
+	"cellar/pkg/models"
+	"context"
+)
+
+//go:generate mockgen -destination=../mocks/mock_lifecycle_handler.go -package=mocks -mock_names=Handler=MockLifecycleHandler . Handler
+type Handler interface {
+	Handle(ctx context.Context, event models.LifecycleEvent)
+}
+
+// Dispatcher fans a lifecycle event out to every registered handler in order.
+type Dispatcher struct {
+	handlers []Handler
+}
+
+func NewDispatcher(handlers ...Handler) *Dispatcher {
+	return &Dispatcher{handlers: handlers}
+}
+
+func (dispatcher Dispatcher) Handle(ctx context.Context, event models.LifecycleEvent) {
+	for _, handler := range dispatcher.handlers {
+		handler.Handle(ctx, event)
+	}
+}
@@ -0,0 +1,70 @@
+package lifecycle_test
+
+import (
+	"cellar/pkg/lifecycle"
+	"cellar/pkg/mocks"
+	"cellar/pkg/models"
+	"cellar/testing/testhelpers"
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestDispatcher(t *testing.T) {
+	t.Run("when handling an event", func(t *testing.T) {
+		event := models.LifecycleEvent{
+			Type:       models.LifecycleSecretExpired,
+			KeyID:      testhelpers.RandomId(t),
+			OccurredAt: time.Now(),
+		}
+
+		t.Run("it should pass the event to every handler", func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			first := mocks.NewMockLifecycleHandler(ctrl)
+			first.EXPECT().Handle(gomock.Any(), event).Times(1)
+			second := mocks.NewMockLifecycleHandler(ctrl)
+			second.EXPECT().Handle(gomock.Any(), event).Times(1)
+
+			lifecycle.NewDispatcher(first, second).Handle(context.Background(), event)
+		})
+	})
+}
+
+func TestNotificationHandler(t *testing.T) {
+	t.Run("when a secret expires", func(t *testing.T) {
+		id := testhelpers.RandomId(t)
+
+		t.Run("it should send any pending expiry notification", func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			dataStore := mocks.NewMockDataStore(ctrl)
+			dataStore.EXPECT().
+				TakeExpiryNotification(gomock.Any(), id).
+				Return(models.ExpiryNotice{Recipient: "sender@example.com", Reference: "22b6fff1"}, nil)
+			notifier := mocks.NewMockNotifier(ctrl)
+			notifier.EXPECT().
+				Notify(gomock.Any(), gomock.Any()).
+				Return(nil).
+				Times(1)
+
+			lifecycle.NewNotificationHandler(dataStore, notifier).Handle(context.Background(), models.LifecycleEvent{
+				Type:  models.LifecycleSecretExpired,
+				KeyID: id,
+			})
+		})
+	})
+
+	t.Run("when the event is not one senders are notified about", func(t *testing.T) {
+		t.Run("it should not touch the datastore or notifier", func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			dataStore := mocks.NewMockDataStore(ctrl)
+			notifier := mocks.NewMockNotifier(ctrl)
+
+			lifecycle.NewNotificationHandler(dataStore, notifier).Handle(context.Background(), models.LifecycleEvent{
+				Type:  "unknown",
+				KeyID: testhelpers.RandomId(t),
+			})
+		})
+	})
+}
@@ -0,0 +1,29 @@
+package lifecycle
+
+import (
+	"cellar/pkg/commands"
+	"cellar/pkg/datastore"
+	"cellar/pkg/models"
+	"cellar/pkg/notifications"
+	"context"
+)
+
+// NotificationHandler forwards lifecycle events that senders can subscribe to on to the notifier.
+type NotificationHandler struct {
+	dataStore datastore.DataStore
+	notifier  notifications.Notifier
+}
+
+func NewNotificationHandler(dataStore datastore.DataStore, notifier notifications.Notifier) *NotificationHandler {
+	return &NotificationHandler{
+		dataStore: dataStore,
+		notifier:  notifier,
+	}
+}
+
+func (handler NotificationHandler) Handle(ctx context.Context, event models.LifecycleEvent) {
+	if event.Type == models.LifecycleSecretExpired {
+		// errors are logged by the command and expiry processing has nothing further to do with them
+		_ = commands.NotifySecretExpired(ctx, handler.dataStore, handler.notifier, event.KeyID)
+	}
+}
@@ -0,0 +1,194 @@
+package middleware
+
+import (
+	"cellar/pkg/apikeys"
+	pkgerrors "cellar/pkg/errors"
+	"cellar/pkg/models"
+	"cellar/pkg/oidc"
+	"cellar/pkg/ratelimit"
+	"cellar/pkg/settings"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// Authenticate checks the API key, or with OIDC enabled the SSO token, given in the Authorization header
+// as a bearer token when API keys are enabled, and makes the key or identity available to RequireScope
+// and the handlers of the route. Requests without a token pass as anonymous, but a token that is given
+// and invalid is always rejected, as are users outside the allowed groups.
+func Authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := c.MustGet(settings.Key).(settings.IConfiguration)
+
+		header := c.GetHeader("Authorization")
+		if !cfg.Auth().Enabled() || header == "" {
+			c.Next()
+			return
+		}
+
+		token, found := strings.CutPrefix(header, "Bearer ")
+		if !found {
+			_ = c.Error(pkgerrors.NewUnauthorizedError("the Authorization header must hold a bearer token"))
+			c.Abort()
+			return
+		}
+		token = strings.TrimSpace(token)
+
+		var err error
+		if apikeys.IsKey(token) || !cfg.Auth().OIDC().Enabled() {
+			err = authenticateKey(c, token)
+		} else {
+			err = authenticateIdentity(c, cfg.Auth().OIDC(), token)
+		}
+		if err != nil {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func authenticateKey(c *gin.Context, token string) error {
+	store := c.MustGet(apikeys.Key).(apikeys.Store)
+	key, err := apikeys.Authenticate(c.Request.Context(), store, token)
+	if errors.Is(err, apikeys.ErrInvalidKey) {
+		log.WithField("clientIp", c.ClientIP()).Warn("rejected request with an invalid API key")
+		return pkgerrors.NewUnauthorizedError("invalid API key")
+	}
+	if err != nil {
+		return fmt.Errorf("error while checking API key: %w", err)
+	}
+
+	c.Set(apikeys.AuthenticatedKey, key)
+	return nil
+}
+
+func authenticateIdentity(c *gin.Context, config settings.IOIDCConfiguration, token string) error {
+	verifier := c.MustGet(oidc.Key).(oidc.Verifier)
+	identity, err := verifier.Verify(c.Request.Context(), token)
+	if errors.Is(err, oidc.ErrInvalidToken) {
+		log.WithError(err).WithField("clientIp", c.ClientIP()).Warn("rejected request with an invalid token")
+		return pkgerrors.NewUnauthorizedError("invalid token")
+	}
+	if err != nil {
+		return fmt.Errorf("error while checking token: %w", err)
+	}
+
+	if allowed := config.AllowedGroups(); len(allowed) > 0 && !identity.InAnyGroup(allowed) {
+		log.WithField("subject", identity.Subject).Warn("rejected request from a user outside the allowed groups")
+		return pkgerrors.NewForbiddenError("the user is not in a group allowed to use this server")
+	}
+
+	c.Set(oidc.IdentityKey, identity)
+	return nil
+}
+
+// RequireScope restricts the route to API keys granted the scope when API keys are enabled.
+// Anonymous requests pass if the scope is one of the configured anonymous scopes.
+func RequireScope(scope models.Scope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := c.MustGet(settings.Key).(settings.IConfiguration)
+
+		if !cfg.Auth().Enabled() || hasScope(c, scope) {
+			c.Next()
+			return
+		}
+
+		if !authenticated(c) {
+			_ = c.Error(pkgerrors.NewUnauthorizedError(fmt.Sprintf("an API key with the %s scope is required", scope)))
+		} else {
+			_ = c.Error(pkgerrors.NewForbiddenError(fmt.Sprintf("the %s scope is not granted", scope)))
+		}
+		c.Abort()
+	}
+}
+
+// RequireAdmin restricts the route to API keys and SSO users granted the admin scope. Unlike RequireScope,
+// it never lets anonymous requests through, so the route is closed while API keys are disabled.
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := c.MustGet(settings.Key).(settings.IConfiguration)
+
+		switch {
+		case !cfg.Auth().Enabled():
+			_ = c.Error(pkgerrors.NewForbiddenError("the admin API requires API keys to be enabled"))
+		case !authenticated(c):
+			_ = c.Error(pkgerrors.NewUnauthorizedError(fmt.Sprintf("an API key with the %s scope is required", models.ScopeAdmin)))
+		case !hasScope(c, models.ScopeAdmin):
+			_ = c.Error(pkgerrors.NewForbiddenError(fmt.Sprintf("the %s scope is not granted", models.ScopeAdmin)))
+		default:
+			c.Next()
+			return
+		}
+		c.Abort()
+	}
+}
+
+// hasScope reports whether the request was granted the scope, by its API key, by the scopes granted to SSO users,
+// or, without either, as an anonymous request.
+func hasScope(c *gin.Context, scope models.Scope) bool {
+	if key, authenticated := c.Get(apikeys.AuthenticatedKey); authenticated {
+		return key.(*models.APIKey).HasScope(scope)
+	}
+
+	cfg := c.MustGet(settings.Key).(settings.IConfiguration)
+	granted := cfg.Auth().AnonymousScopes()
+	if _, authenticated := c.Get(oidc.IdentityKey); authenticated {
+		granted = cfg.Auth().OIDC().Scopes()
+	}
+	for _, name := range granted {
+		if models.Scope(name) == scope || models.Scope(name) == models.ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+func authenticated(c *gin.Context) bool {
+	_, hasKey := c.Get(apikeys.AuthenticatedKey)
+	_, hasIdentity := c.Get(oidc.IdentityKey)
+	return hasKey || hasIdentity
+}
+
+// UserCreateLimit limits how much each SSO user can create per window when a limit is configured.
+// Only creations that succeed count towards the limit. Requests made with an API key or anonymously are not limited.
+func UserCreateLimit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, exists := c.Get(oidc.IdentityKey)
+		if !exists {
+			c.Next()
+			return
+		}
+
+		identity := value.(*models.Identity)
+		quota := c.MustGet(ratelimit.QuotaKey).(ratelimit.UserQuota)
+
+		retryAfter, err := quota.Exhausted(c.Request.Context(), identity.Subject)
+		if err != nil {
+			log.WithError(err).WithField("subject", identity.Subject).Error("failed to check user quota")
+		} else if retryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			_ = c.Error(pkgerrors.NewRateLimitError(
+				fmt.Sprintf("Creation limit reached. Try again in %d seconds.", retryAfter),
+				retryAfter,
+			))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+
+		if c.Writer.Status() < http.StatusBadRequest && len(c.Errors) == 0 {
+			if err := quota.Record(c.Request.Context(), identity.Subject); err != nil {
+				log.WithError(err).WithField("subject", identity.Subject).Error("failed to record user creation")
+			}
+		}
+	}
+}
@@ -1,33 +1,102 @@
 package middleware
 
 import (
+	"cellar/pkg/apikeys"
 	"cellar/pkg/cryptography"
 	"cellar/pkg/cryptography/aws"
 	"cellar/pkg/cryptography/vault"
 	"cellar/pkg/datastore"
 	"cellar/pkg/datastore/redis"
+	"cellar/pkg/geoip"
+	"cellar/pkg/keyid"
+	"cellar/pkg/notifications"
+	"cellar/pkg/notifications/smtp"
+	"cellar/pkg/oidc"
 	"cellar/pkg/ratelimit"
 	"cellar/pkg/settings"
+	"cellar/pkg/tenants"
 	"context"
 	"errors"
+	"fmt"
 
 	"github.com/gin-gonic/gin"
 )
 
-func injectDependencies(router *gin.Engine, cfg settings.IConfiguration) {
+// Dependencies holds the clients shared by every request, so that work running outside of requests can reuse them.
+type Dependencies struct {
+	Configuration settings.IConfiguration
+	Encryption    cryptography.Encryption
+	DataStore     datastore.DataStore
+	Notifier      notifications.Notifier
+	RateLimiter   ratelimit.RateLimiter
+	BruteForce    ratelimit.BruteForceDetector
+	Locator       geoip.Locator
+	APIKeys       apikeys.Store
+	Verifier      oidc.Verifier
+	UserQuota     ratelimit.UserQuota
+	Tenants       *tenants.Namespaces
+}
+
+// Close waits for the work the clients have queued, such as notifications that are not yet sent, to finish.
+// It is called once the server stops taking requests.
+func (dependencies *Dependencies) Close() {
+	if closer, ok := dependencies.Notifier.(interface{ Close() }); ok {
+		closer.Close()
+	}
+}
+
+func injectDependencies(router *gin.Engine, cfg settings.IConfiguration) *Dependencies {
 	encryptionClient, err := getEncryptionClient(cfg)
 	HandleError("error while initializing cryptography engine connection", err)
 
-	dataStore := getDatastoreClient(cfg)
+	notifier, err := getNotifier(cfg)
+	HandleError("error while initializing notifications", err)
+
+	locator, err := getLocator(cfg)
+	HandleError("error while loading the GeoIP database", err)
+
+	dataStore, err := getDatastoreClient(cfg)
+	HandleError("error while initializing datastore", err)
+
 	rateLimiter := getRateLimiterClient(cfg, dataStore)
+	bruteForce := getBruteForceDetector(cfg, dataStore)
+	apiKeys := getAPIKeyStore(dataStore)
+	userQuota := getUserQuota(cfg, dataStore)
+
+	verifier, err := oidc.NewJWKSVerifier(cfg.Auth().OIDC())
+	HandleError("error while initializing OIDC", err)
+
+	namespaces, err := getNamespaces(cfg, dataStore, encryptionClient)
+	HandleError("error while initializing tenants", err)
 
 	router.Use(func(c *gin.Context) {
 		c.Set(settings.Key, cfg)
 		c.Set(cryptography.Key, encryptionClient)
 		c.Set(datastore.Key, dataStore)
+		c.Set(notifications.Key, notifier)
 		c.Set(ratelimit.Key, rateLimiter)
+		c.Set(ratelimit.BruteForceKey, bruteForce)
+		c.Set(geoip.Key, locator)
+		c.Set(apikeys.Key, apiKeys)
+		c.Set(oidc.Key, verifier)
+		c.Set(ratelimit.QuotaKey, userQuota)
+		c.Set(tenants.Key, namespaces)
 		c.Next()
 	})
+
+	return &Dependencies{
+		Configuration: cfg,
+		Encryption:    encryptionClient,
+		DataStore:     dataStore,
+		Notifier:      notifier,
+		RateLimiter:   rateLimiter,
+		BruteForce:    bruteForce,
+		Locator:       locator,
+		APIKeys:       apiKeys,
+		Verifier:      verifier,
+		UserQuota:     userQuota,
+		Tenants:       namespaces,
+	}
 }
 
 func getEncryptionClient(cfg settings.IConfiguration) (cryptography.Encryption, error) {
@@ -46,8 +115,29 @@ func getEncryptionClient(cfg settings.IConfiguration) (cryptography.Encryption,
 	return nil, errors.New("at least one cryptography engine is required")
 }
 
-func getDatastoreClient(cfg settings.IConfiguration) datastore.DataStore {
-	return redis.NewDataStore(cfg.Datastore().Redis())
+func getNotifier(cfg settings.IConfiguration) (notifications.Notifier, error) {
+	if cfg.Notifications().Smtp().Enabled() {
+		return smtp.NewNotifier(cfg.Notifications().Smtp())
+	}
+
+	return notifications.NewNoopNotifier(), nil
+}
+
+func getLocator(cfg settings.IConfiguration) (geoip.Locator, error) {
+	if cfg.GeoIP().Enabled() {
+		return geoip.Open(cfg.GeoIP().DatabasePath())
+	}
+
+	return geoip.NewNoopLocator(), nil
+}
+
+// getDatastoreClient returns the datastore, which stores records under key IDs derived with the configured pepper.
+func getDatastoreClient(cfg settings.IConfiguration) (datastore.DataStore, error) {
+	keyIds, err := keyid.NewHasher(cfg.Datastore().IDPepper())
+	if err != nil {
+		return nil, fmt.Errorf("datastore id pepper: %w", err)
+	}
+	return redis.NewDataStore(cfg.Datastore().Redis(), keyIds), nil
 }
 
 func getRateLimiterClient(cfg settings.IConfiguration, dataStore datastore.DataStore) ratelimit.RateLimiter {
@@ -57,3 +147,67 @@ func getRateLimiterClient(cfg settings.IConfiguration, dataStore datastore.DataS
 	}
 	return ratelimit.NewRedisRateLimiter(redisDataStore.Client(), cfg.RateLimit())
 }
+
+func getBruteForceDetector(cfg settings.IConfiguration, dataStore datastore.DataStore) ratelimit.BruteForceDetector {
+	redisDataStore, ok := dataStore.(*redis.DataStore)
+	if !ok {
+		HandleError("datastore must be Redis for brute force detection", errors.New("invalid datastore type"))
+	}
+	return ratelimit.NewRedisBruteForceDetector(redisDataStore.Client(), cfg.Hardening())
+}
+
+func getAPIKeyStore(dataStore datastore.DataStore) apikeys.Store {
+	redisDataStore, ok := dataStore.(*redis.DataStore)
+	if !ok {
+		HandleError("datastore must be Redis for API keys", errors.New("invalid datastore type"))
+	}
+	return apikeys.NewRedisStore(redisDataStore.Client())
+}
+
+func getUserQuota(cfg settings.IConfiguration, dataStore datastore.DataStore) ratelimit.UserQuota {
+	redisDataStore, ok := dataStore.(*redis.DataStore)
+	if !ok {
+		HandleError("datastore must be Redis for user quotas", errors.New("invalid datastore type"))
+	}
+	return ratelimit.NewRedisUserQuota(redisDataStore.Client(), cfg.Auth().OIDC())
+}
+
+// getNamespaces loads the tenants and gives each its own configuration, datastore key prefix and rate limiter.
+// Tenants with their own encryption key get their own encryption client, and the others share the server's.
+func getNamespaces(cfg settings.IConfiguration, dataStore datastore.DataStore, encryption cryptography.Encryption) (*tenants.Namespaces, error) {
+	if !cfg.Tenants().Enabled() {
+		return tenants.NewNamespaces(nil), nil
+	}
+
+	definitions, err := tenants.Load(cfg.Tenants().File())
+	if err != nil {
+		return nil, err
+	}
+
+	redisDataStore, ok := dataStore.(*redis.DataStore)
+	if !ok {
+		return nil, errors.New("datastore must be Redis for tenants")
+	}
+
+	namespaces := make([]*tenants.Namespace, 0, len(definitions))
+	for i := range definitions {
+		tenant := &definitions[i]
+		tenantConfig := tenants.NewConfiguration(cfg, tenant)
+
+		tenantEncryption := encryption
+		if tenant.VaultTransitKey != "" || tenant.AwsKmsKeyId != "" {
+			if tenantEncryption, err = getEncryptionClient(tenantConfig); err != nil {
+				return nil, fmt.Errorf("tenant %q: %w", tenant.ID, err)
+			}
+		}
+
+		namespaces = append(namespaces, &tenants.Namespace{
+			Tenant:        tenant,
+			Configuration: tenantConfig,
+			Encryption:    tenantEncryption,
+			DataStore:     redisDataStore.WithKeyPrefix(tenant.KeyPrefix),
+			RateLimiter:   ratelimit.NewRedisRateLimiter(redisDataStore.Client(), tenantConfig.RateLimit()),
+		})
+	}
+	return tenants.NewNamespaces(namespaces), nil
+}
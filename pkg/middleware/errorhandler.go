@@ -2,7 +2,10 @@ package middleware
 
 import (
 	pkgerrors "cellar/pkg/errors"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
@@ -30,6 +33,26 @@ func ErrorHandler() gin.HandlerFunc {
 			case pkgerrors.IsFileTooLargeError(err):
 				statusCode = http.StatusRequestEntityTooLarge
 				logLevel = "warn"
+			case pkgerrors.IsNotYetAvailableError(err):
+				statusCode = http.StatusForbidden
+				logLevel = "warn"
+				retryAfter := int(math.Ceil(time.Until(pkgerrors.GetNotYetAvailableError(err).AvailableFrom()).Seconds()))
+				if retryAfter > 0 {
+					c.Header("Retry-After", strconv.Itoa(retryAfter))
+				}
+			case pkgerrors.IsUnauthorizedError(err):
+				statusCode = http.StatusUnauthorized
+				logLevel = "warn"
+				c.Header("WWW-Authenticate", "Bearer")
+			case pkgerrors.IsForbiddenError(err):
+				statusCode = http.StatusForbidden
+				logLevel = "warn"
+			case pkgerrors.IsConflictError(err):
+				statusCode = http.StatusConflict
+				logLevel = "warn"
+			case pkgerrors.IsReadOnlyError(err):
+				statusCode = http.StatusServiceUnavailable
+				logLevel = "warn"
 			case pkgerrors.IsValidationError(err):
 				statusCode = http.StatusBadRequest
 				logLevel = "warn"
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	pkgerrors "cellar/pkg/errors"
+	"cellar/pkg/geoip"
+	"cellar/pkg/settings"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// GeoIP resolves the country of the client for the access history and per-secret country restrictions,
+// and rejects clients from countries that the configured global lists do not allow.
+func GeoIP() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := c.MustGet(settings.Key).(settings.IConfiguration)
+
+		locator, exists := c.Get(geoip.Key)
+		if !exists {
+			log.Warn("GeoIP locator not found in context, skipping country resolution")
+			c.Next()
+			return
+		}
+
+		clientIP := c.ClientIP()
+		country := locator.(geoip.Locator).Country(clientIP)
+		c.Set(geoip.CountryKey, country)
+
+		if !geoip.Allows(cfg.GeoIP().AllowedCountries(), cfg.GeoIP().DeniedCountries(), country) {
+			log.WithFields(log.Fields{
+				"clientIP":      clientIP,
+				"clientCountry": country,
+			}).Warn("Rejected access from a country that is not allowed")
+			_ = c.Error(pkgerrors.NewForbiddenError("secrets cannot be accessed from this country"))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
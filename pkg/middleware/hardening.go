@@ -0,0 +1,249 @@
+package middleware
+
+import (
+	"bytes"
+	"cellar/pkg/cryptography"
+	"cellar/pkg/datastore"
+	pkgerrors "cellar/pkg/errors"
+	"cellar/pkg/plaintext"
+	"cellar/pkg/ratelimit"
+	"cellar/pkg/settings"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// decoyContentSize is the size of the content decrypted in place of a secret that does not exist.
+	decoyContentSize = 64
+
+	// decoyIdSize is the size, in bytes, of the random IDs read from the datastore in place of a secret that does not exist.
+	decoyIdSize = 32
+)
+
+// BruteForceProtection locks out clients that fail too many secret lookups.
+// Not found responses of the route count towards the not found limit, and lookups rejected for invalid credentials
+// towards the failure limit. Repeated lockouts last longer each time.
+func BruteForceProtection() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := c.MustGet(settings.Key).(settings.IConfiguration)
+
+		if !cfg.Hardening().BruteForceEnabled() {
+			c.Next()
+			return
+		}
+
+		bruteForce, exists := c.Get(ratelimit.BruteForceKey)
+		if !exists {
+			log.Warn("brute force detector not found in context, skipping brute force detection")
+			c.Next()
+			return
+		}
+
+		detector := bruteForce.(ratelimit.BruteForceDetector)
+		identifier := c.ClientIP()
+
+		retryAfter, err := detector.Blocked(c.Request.Context(), identifier)
+		if err != nil {
+			log.WithError(err).WithField("identifier", identifier).Error("failed to check brute force block")
+		} else if retryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			_ = c.Error(pkgerrors.NewRateLimitError(
+				fmt.Sprintf("Too many failed secret lookups. Try again in %d seconds.", retryAfter),
+				retryAfter,
+			))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+
+		if c.Writer.Status() == http.StatusNotFound {
+			if _, err := detector.RecordNotFound(c.Request.Context(), identifier); err != nil {
+				log.WithError(err).WithField("identifier", identifier).Error("failed to record lookup of a secret that does not exist")
+			}
+		} else if hasInvalidCredentials(c) {
+			if _, err := detector.RecordFailure(c.Request.Context(), identifier); err != nil {
+				log.WithError(err).WithField("identifier", identifier).Error("failed to record lookup with invalid credentials")
+			}
+		}
+	}
+}
+
+// hasInvalidCredentials reports whether the lookup was rejected for invalid credentials.
+// Other errors, such as an address outside the secret's allowlist, do not point at guessing and are not counted.
+func hasInvalidCredentials(c *gin.Context) bool {
+	for _, err := range c.Errors {
+		if pkgerrors.IsInvalidCredentialError(err.Err) {
+			return true
+		}
+	}
+	return false
+}
+
+// UniformResponse pads every response of the route to the configured minimum time when uniform responses are enabled,
+// so how long a response takes does not tell whether the secret exists. The response is held back until then,
+// so not even its first byte is sent earlier.
+func UniformResponse() gin.HandlerFunc {
+	return uniformResponse(false)
+}
+
+// UniformAccessResponse is UniformResponse for routes that decrypt the secret they access.
+// Lookups of secrets that do not exist read the datastore and decrypt decoy content instead,
+// so they make the same round trips to the datastore and the cryptography engine.
+func UniformAccessResponse() gin.HandlerFunc {
+	return uniformResponse(true)
+}
+
+func uniformResponse(decoy bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := c.MustGet(settings.Key).(settings.IConfiguration)
+
+		if !cfg.Hardening().UniformResponsesEnabled() {
+			c.Next()
+			return
+		}
+
+		deadline := time.Now().Add(time.Duration(cfg.Hardening().UniformResponseMilliseconds()) * time.Millisecond)
+
+		writer := &heldResponseWriter{ResponseWriter: c.Writer, status: http.StatusOK, size: -1}
+		c.Writer = writer
+		defer func() {
+			c.Writer = writer.ResponseWriter
+			writer.release()
+		}()
+
+		c.Next()
+
+		if decoy && writer.Status() == http.StatusNotFound {
+			accessDecoy(c)
+		}
+
+		timer := time.NewTimer(time.Until(deadline))
+		defer timer.Stop()
+		select {
+		case <-c.Request.Context().Done():
+		case <-timer.C:
+		}
+	}
+}
+
+// heldResponseWriter holds the status and body of a response back until it is released.
+type heldResponseWriter struct {
+	gin.ResponseWriter
+	status int
+	size   int
+	body   bytes.Buffer
+}
+
+func (w *heldResponseWriter) WriteHeader(code int) {
+	if code > 0 && !w.Written() {
+		w.status = code
+	}
+}
+
+func (w *heldResponseWriter) WriteHeaderNow() {
+	if !w.Written() {
+		w.size = 0
+	}
+}
+
+func (w *heldResponseWriter) Write(data []byte) (int, error) {
+	w.WriteHeaderNow()
+	n, err := w.body.Write(data)
+	w.size += n
+	return n, err
+}
+
+func (w *heldResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *heldResponseWriter) Status() int {
+	return w.status
+}
+
+func (w *heldResponseWriter) Size() int {
+	return w.size
+}
+
+func (w *heldResponseWriter) Written() bool {
+	return w.size != -1
+}
+
+// Flush is ignored, as flushing would send the response before it is released.
+func (w *heldResponseWriter) Flush() {}
+
+// release sends the held response, then wipes it, as it can hold secret content.
+// A response that was not written keeps only its status, so errors handled after the route are still written.
+func (w *heldResponseWriter) release() {
+	w.ResponseWriter.WriteHeader(w.status)
+	if !w.Written() {
+		return
+	}
+	w.ResponseWriter.WriteHeaderNow()
+	if _, err := w.ResponseWriter.Write(w.body.Bytes()); err != nil {
+		log.WithError(err).Warn("failed to write held response")
+	}
+	plaintext.Wipe(w.body.Bytes())
+}
+
+// decoyCipherTexts holds, for each cryptography engine, the ciphertext of random content
+// that lookups of secrets that do not exist decrypt in place of a secret.
+var decoyCipherTexts sync.Map
+
+// accessDecoy makes the round trips of accessing a secret: it reads a secret that does not exist
+// from the datastore and decrypts decoy content.
+func accessDecoy(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	if dataStore, exists := c.Get(datastore.Key); exists {
+		id := make([]byte, decoyIdSize)
+		if _, err := rand.Read(id); err == nil {
+			dataStore.(datastore.DataStore).ReadSecret(ctx, hex.EncodeToString(id))
+		}
+	}
+
+	value, exists := c.Get(cryptography.Key)
+	if !exists {
+		return
+	}
+	encryption := value.(cryptography.Encryption)
+
+	cipherText, err := decoyCipherText(ctx, encryption)
+	if err != nil {
+		log.WithError(err).Warn("failed to encrypt decoy content")
+		return
+	}
+
+	if _, err = encryption.Decrypt(ctx, cipherText); err != nil {
+		log.WithError(err).Warn("failed to decrypt decoy content")
+	}
+}
+
+// decoyCipherText returns the decoy ciphertext of the cryptography engine, encrypting it on first use.
+func decoyCipherText(ctx context.Context, encryption cryptography.Encryption) (string, error) {
+	if cipherText, ok := decoyCipherTexts.Load(encryption); ok {
+		return cipherText.(string), nil
+	}
+
+	content := make([]byte, decoyContentSize)
+	if _, err := rand.Read(content); err != nil {
+		return "", err
+	}
+
+	cipherText, err := encryption.Encrypt(ctx, content)
+	if err != nil {
+		return "", err
+	}
+	decoyCipherTexts.Store(encryption, cipherText)
+	return cipherText, nil
+}
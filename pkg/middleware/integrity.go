@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"cellar/pkg/integrity"
+	"cellar/pkg/settings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func configureIntegrity(cfg settings.IConfiguration) {
+	HandleError("error while configuring datastore integrity keys", integrity.SetKeys(cfg.Datastore().IntegrityKeys()))
+	integrity.SetAllowUntagged(cfg.Datastore().IntegrityAllowUntagged())
+	if !integrity.Enabled() {
+		log.WithField("context", "datastore").
+			Warn("datastore integrity keys are empty, changes made directly in the datastore will not be detected")
+	}
+}
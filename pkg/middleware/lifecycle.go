@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"cellar/pkg/datastore/redis"
+	"cellar/pkg/geoip"
+	"cellar/pkg/lifecycle"
+	"context"
+	"errors"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// StartExpiryListener processes secret expiry events in the background until the context is cancelled.
+// It does nothing when expiry events are disabled in the datastore configuration.
+func StartExpiryListener(ctx context.Context, dependencies *Dependencies) {
+	listener, err := newExpiryListener(dependencies)
+	HandleError("error while initializing expiry listener", err)
+	if listener == nil {
+		return
+	}
+
+	go func() {
+		if err := listener.Run(ctx); err != nil {
+			log.WithError(err).
+				WithField("context", "expiry listener").
+				Error("expiry listener stopped")
+		}
+	}()
+}
+
+// StartGeoIPReloader checks the GeoIP database file for changes in the background until the context is cancelled,
+// so an updated database is picked up without a restart.
+// It does nothing when no GeoIP database is configured or reloading is disabled.
+func StartGeoIPReloader(ctx context.Context, dependencies *Dependencies) {
+	database, ok := dependencies.Locator.(*geoip.Database)
+	interval := time.Duration(dependencies.Configuration.GeoIP().ReloadIntervalSeconds()) * time.Second
+	if !ok || interval <= 0 {
+		return
+	}
+
+	logger := log.WithField("context", "geoip reloader")
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				reloaded, err := database.Reload()
+				if err != nil {
+					logger.WithError(err).Error("Error reloading the GeoIP database, keeping the loaded database")
+				} else if reloaded {
+					logger.WithField("databaseType", database.DatabaseType()).Info("Reloaded the GeoIP database")
+				}
+			}
+		}
+	}()
+}
+
+// SweepExpiredSecrets runs a single expiry reconciliation sweep.
+// It is the alternative to StartExpiryListener for deployments without long-running processes, such as scheduled Lambda invocations.
+// Returns the number of expiry events emitted.
+func SweepExpiredSecrets(ctx context.Context, dependencies *Dependencies) (int, error) {
+	listener, err := newExpiryListener(dependencies)
+	if err != nil || listener == nil {
+		return 0, err
+	}
+
+	return listener.Sweep(ctx)
+}
+
+func newExpiryListener(dependencies *Dependencies) (*redis.ExpiryListener, error) {
+	redisConfiguration := dependencies.Configuration.Datastore().Redis()
+	if !redisConfiguration.ExpiryEventsEnabled() {
+		return nil, nil
+	}
+
+	redisDataStore, ok := dependencies.DataStore.(*redis.DataStore)
+	if !ok {
+		return nil, errors.New("datastore must be Redis for expiry events")
+	}
+
+	handler := lifecycle.NewDispatcher(
+		lifecycle.NewAuditHandler(),
+		lifecycle.NewNotificationHandler(dependencies.DataStore, dependencies.Notifier),
+	)
+
+	return redis.NewExpiryListener(redisDataStore, redisConfiguration, handler), nil
+}
@@ -0,0 +1,10 @@
+package middleware
+
+import (
+	"cellar/pkg/plaintext"
+	"cellar/pkg/settings"
+)
+
+func configureMemoryLocking(cfg settings.IConfiguration) {
+	plaintext.SetLocking(cfg.App().LockMemory())
+}
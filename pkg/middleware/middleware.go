@@ -6,10 +6,15 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func Setup(router *gin.Engine, cfg settings.IConfiguration) {
+func Setup(router *gin.Engine, cfg settings.IConfiguration) *Dependencies {
 	configureAppLogging(cfg)
 	configureWebLogging(router)
 	router.Use(ErrorHandler())
-	injectDependencies(router, cfg)
+	configureIntegrity(cfg)
+	configureMemoryLocking(cfg)
+	dependencies := injectDependencies(router, cfg)
+	router.Use(Authenticate())
+	router.Use(ResolveTenant())
 	configureSwagger(cfg)
+	return dependencies
 }
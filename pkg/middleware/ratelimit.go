@@ -4,6 +4,7 @@ import (
 	pkgerrors "cellar/pkg/errors"
 	"cellar/pkg/ratelimit"
 	"cellar/pkg/settings"
+	"cellar/pkg/tenants"
 	"fmt"
 	"strconv"
 
@@ -29,6 +30,9 @@ func RateLimit(tier ratelimit.Tier) gin.HandlerFunc {
 
 		limiter := rateLimiter.(ratelimit.RateLimiter)
 		identifier := c.ClientIP()
+		if tenant, resolved := c.Get(tenants.ResolvedKey); resolved {
+			identifier = tenant.(*tenants.Tenant).ID + ":" + identifier
+		}
 
 		result, err := limiter.Allow(c.Request.Context(), identifier, tier)
 		if err != nil {
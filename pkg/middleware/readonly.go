@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"cellar/pkg/datastore"
+	pkgerrors "cellar/pkg/errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Writable rejects requests to routes that create or change secrets while the server is in read-only mode.
+// Datastores that cannot be put in read-only mode are always writable.
+func Writable() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		dataStore, ok := c.MustGet(datastore.Key).(datastore.AdminDataStore)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		readOnly, err := dataStore.ReadOnly(c.Request.Context())
+		if err != nil {
+			_ = c.Error(fmt.Errorf("error while checking read-only mode: %w", err))
+			c.Abort()
+			return
+		}
+		if readOnly {
+			_ = c.Error(pkgerrors.NewReadOnlyError("the server is in read-only mode"))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
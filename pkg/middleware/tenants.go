@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"cellar/pkg/apikeys"
+	"cellar/pkg/cryptography"
+	"cellar/pkg/datastore"
+	pkgerrors "cellar/pkg/errors"
+	"cellar/pkg/models"
+	"cellar/pkg/oidc"
+	"cellar/pkg/ratelimit"
+	"cellar/pkg/settings"
+	"cellar/pkg/tenants"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// ResolveTenant resolves the tenant of the request from its API key, the tenant claim of its SSO token,
+// or its host name, and replaces the configuration and clients of the request with those of the tenant.
+// Requests that resolve to no tenant use the server's. Credentials of one tenant are rejected on the host of another.
+func ResolveTenant() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := c.MustGet(settings.Key).(settings.IConfiguration)
+		if !cfg.Tenants().Enabled() {
+			c.Next()
+			return
+		}
+
+		namespaces := c.MustGet(tenants.Key).(*tenants.Namespaces)
+		namespace, err := resolveNamespace(c, namespaces)
+		if err != nil {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+
+		if namespace != nil {
+			c.Set(tenants.ResolvedKey, namespace.Tenant)
+			c.Set(settings.Key, namespace.Configuration)
+			c.Set(cryptography.Key, namespace.Encryption)
+			c.Set(datastore.Key, namespace.DataStore)
+			c.Set(ratelimit.Key, namespace.RateLimiter)
+		}
+
+		c.Next()
+	}
+}
+
+func resolveNamespace(c *gin.Context, namespaces *tenants.Namespaces) (*tenants.Namespace, error) {
+	host := namespaces.ByHost(c.Request.Host)
+
+	tenant := credentialTenant(c)
+	if tenant == "" {
+		return host, nil
+	}
+
+	namespace := namespaces.ByID(tenant)
+	if namespace == nil {
+		log.WithField("tenant", tenant).Warn("rejected request with credentials of an unknown tenant")
+		return nil, pkgerrors.NewForbiddenError("the credentials belong to an unknown tenant")
+	}
+	if host != nil && host != namespace {
+		log.WithFields(log.Fields{
+			"tenant":     tenant,
+			"hostTenant": host.Tenant.ID,
+		}).Warn("rejected request with credentials of another tenant")
+		return nil, pkgerrors.NewForbiddenError("the credentials belong to another tenant")
+	}
+	return namespace, nil
+}
+
+// credentialTenant returns the tenant named by the API key or SSO token of the request, if any.
+func credentialTenant(c *gin.Context) string {
+	if key, authenticated := c.Get(apikeys.AuthenticatedKey); authenticated {
+		return key.(*models.APIKey).Tenant
+	}
+	if identity, authenticated := c.Get(oidc.IdentityKey); authenticated {
+		return identity.(*models.Identity).Tenant
+	}
+	return ""
+}
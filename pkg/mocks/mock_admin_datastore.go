@@ -0,0 +1,391 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: cellar/pkg/datastore (interfaces: AdminDataStore)
+//
+// Generated by this command:
+//
+//	mockgen -destination=../mocks/mock_admin_datastore.go -package=mocks . AdminDataStore
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	models "cellar/pkg/models"
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAdminDataStore is a mock of AdminDataStore interface.
+type MockAdminDataStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdminDataStoreMockRecorder
+	isgomock struct{}
+}
+
+// MockAdminDataStoreMockRecorder is the mock recorder for MockAdminDataStore.
+type MockAdminDataStoreMockRecorder struct {
+	mock *MockAdminDataStore
+}
+
+// NewMockAdminDataStore creates a new mock instance.
+func NewMockAdminDataStore(ctrl *gomock.Controller) *MockAdminDataStore {
+	mock := &MockAdminDataStore{ctrl: ctrl}
+	mock.recorder = &MockAdminDataStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdminDataStore) EXPECT() *MockAdminDataStoreMockRecorder {
+	return m.recorder
+}
+
+// AppendSecretHistory mocks base method.
+func (m *MockAdminDataStore) AppendSecretHistory(ctx context.Context, id string, event models.SecretHistoryEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AppendSecretHistory", ctx, id, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AppendSecretHistory indicates an expected call of AppendSecretHistory.
+func (mr *MockAdminDataStoreMockRecorder) AppendSecretHistory(ctx, id, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppendSecretHistory", reflect.TypeOf((*MockAdminDataStore)(nil).AppendSecretHistory), ctx, id, event)
+}
+
+// BurnSecret mocks base method.
+func (m *MockAdminDataStore) BurnSecret(ctx context.Context, keyId string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BurnSecret", ctx, keyId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BurnSecret indicates an expected call of BurnSecret.
+func (mr *MockAdminDataStoreMockRecorder) BurnSecret(ctx, keyId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BurnSecret", reflect.TypeOf((*MockAdminDataStore)(nil).BurnSecret), ctx, keyId)
+}
+
+// CancelExpiryNotification mocks base method.
+func (m *MockAdminDataStore) CancelExpiryNotification(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelExpiryNotification", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelExpiryNotification indicates an expected call of CancelExpiryNotification.
+func (mr *MockAdminDataStoreMockRecorder) CancelExpiryNotification(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelExpiryNotification", reflect.TypeOf((*MockAdminDataStore)(nil).CancelExpiryNotification), ctx, id)
+}
+
+// ConsumeVerificationCode mocks base method.
+func (m *MockAdminDataStore) ConsumeVerificationCode(ctx context.Context, id, codeHash string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeVerificationCode", ctx, id, codeHash)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeVerificationCode indicates an expected call of ConsumeVerificationCode.
+func (mr *MockAdminDataStoreMockRecorder) ConsumeVerificationCode(ctx, id, codeHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeVerificationCode", reflect.TypeOf((*MockAdminDataStore)(nil).ConsumeVerificationCode), ctx, id, codeHash)
+}
+
+// DeleteSecret mocks base method.
+func (m *MockAdminDataStore) DeleteSecret(ctx context.Context, id string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSecret", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteSecret indicates an expected call of DeleteSecret.
+func (mr *MockAdminDataStoreMockRecorder) DeleteSecret(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSecret", reflect.TypeOf((*MockAdminDataStore)(nil).DeleteSecret), ctx, id)
+}
+
+// FulfillSecretRequest mocks base method.
+func (m *MockAdminDataStore) FulfillSecretRequest(ctx context.Context, request models.SecretRequest, secretId string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FulfillSecretRequest", ctx, request, secretId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FulfillSecretRequest indicates an expected call of FulfillSecretRequest.
+func (mr *MockAdminDataStoreMockRecorder) FulfillSecretRequest(ctx, request, secretId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FulfillSecretRequest", reflect.TypeOf((*MockAdminDataStore)(nil).FulfillSecretRequest), ctx, request, secretId)
+}
+
+// Health mocks base method.
+func (m *MockAdminDataStore) Health(ctx context.Context) models.Health {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Health", ctx)
+	ret0, _ := ret[0].(models.Health)
+	return ret0
+}
+
+// Health indicates an expected call of Health.
+func (mr *MockAdminDataStoreMockRecorder) Health(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Health", reflect.TypeOf((*MockAdminDataStore)(nil).Health), ctx)
+}
+
+// IncreaseAccessCount mocks base method.
+func (m *MockAdminDataStore) IncreaseAccessCount(ctx context.Context, id string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncreaseAccessCount", ctx, id)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncreaseAccessCount indicates an expected call of IncreaseAccessCount.
+func (mr *MockAdminDataStoreMockRecorder) IncreaseAccessCount(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncreaseAccessCount", reflect.TypeOf((*MockAdminDataStore)(nil).IncreaseAccessCount), ctx, id)
+}
+
+// KeyID mocks base method.
+func (m *MockAdminDataStore) KeyID(id string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "KeyID", id)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// KeyID indicates an expected call of KeyID.
+func (mr *MockAdminDataStoreMockRecorder) KeyID(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KeyID", reflect.TypeOf((*MockAdminDataStore)(nil).KeyID), id)
+}
+
+// ReadOnly mocks base method.
+func (m *MockAdminDataStore) ReadOnly(ctx context.Context) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadOnly", ctx)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadOnly indicates an expected call of ReadOnly.
+func (mr *MockAdminDataStoreMockRecorder) ReadOnly(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadOnly", reflect.TypeOf((*MockAdminDataStore)(nil).ReadOnly), ctx)
+}
+
+// ReadSecret mocks base method.
+func (m *MockAdminDataStore) ReadSecret(ctx context.Context, id string) *models.Secret {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadSecret", ctx, id)
+	ret0, _ := ret[0].(*models.Secret)
+	return ret0
+}
+
+// ReadSecret indicates an expected call of ReadSecret.
+func (mr *MockAdminDataStoreMockRecorder) ReadSecret(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadSecret", reflect.TypeOf((*MockAdminDataStore)(nil).ReadSecret), ctx, id)
+}
+
+// ReadSecretHistory mocks base method.
+func (m *MockAdminDataStore) ReadSecretHistory(ctx context.Context, id string) ([]models.SecretHistoryEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadSecretHistory", ctx, id)
+	ret0, _ := ret[0].([]models.SecretHistoryEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadSecretHistory indicates an expected call of ReadSecretHistory.
+func (mr *MockAdminDataStoreMockRecorder) ReadSecretHistory(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadSecretHistory", reflect.TypeOf((*MockAdminDataStore)(nil).ReadSecretHistory), ctx, id)
+}
+
+// ReadSecretRequest mocks base method.
+func (m *MockAdminDataStore) ReadSecretRequest(ctx context.Context, id string) *models.SecretRequest {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadSecretRequest", ctx, id)
+	ret0, _ := ret[0].(*models.SecretRequest)
+	return ret0
+}
+
+// ReadSecretRequest indicates an expected call of ReadSecretRequest.
+func (mr *MockAdminDataStoreMockRecorder) ReadSecretRequest(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadSecretRequest", reflect.TypeOf((*MockAdminDataStore)(nil).ReadSecretRequest), ctx, id)
+}
+
+// ReadSplitSecret mocks base method.
+func (m *MockAdminDataStore) ReadSplitSecret(ctx context.Context, id string) *models.SplitSecret {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadSplitSecret", ctx, id)
+	ret0, _ := ret[0].(*models.SplitSecret)
+	return ret0
+}
+
+// ReadSplitSecret indicates an expected call of ReadSplitSecret.
+func (mr *MockAdminDataStoreMockRecorder) ReadSplitSecret(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadSplitSecret", reflect.TypeOf((*MockAdminDataStore)(nil).ReadSplitSecret), ctx, id)
+}
+
+// ReadVerificationCode mocks base method.
+func (m *MockAdminDataStore) ReadVerificationCode(ctx context.Context, id string, attemptWindow time.Duration) (string, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadVerificationCode", ctx, id, attemptWindow)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ReadVerificationCode indicates an expected call of ReadVerificationCode.
+func (mr *MockAdminDataStoreMockRecorder) ReadVerificationCode(ctx, id, attemptWindow any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadVerificationCode", reflect.TypeOf((*MockAdminDataStore)(nil).ReadVerificationCode), ctx, id, attemptWindow)
+}
+
+// ScanSecrets mocks base method.
+func (m *MockAdminDataStore) ScanSecrets(ctx context.Context, visit func(models.SecretRecord) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScanSecrets", ctx, visit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ScanSecrets indicates an expected call of ScanSecrets.
+func (mr *MockAdminDataStoreMockRecorder) ScanSecrets(ctx, visit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScanSecrets", reflect.TypeOf((*MockAdminDataStore)(nil).ScanSecrets), ctx, visit)
+}
+
+// SetReadOnly mocks base method.
+func (m *MockAdminDataStore) SetReadOnly(ctx context.Context, readOnly bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetReadOnly", ctx, readOnly)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetReadOnly indicates an expected call of SetReadOnly.
+func (mr *MockAdminDataStoreMockRecorder) SetReadOnly(ctx, readOnly any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReadOnly", reflect.TypeOf((*MockAdminDataStore)(nil).SetReadOnly), ctx, readOnly)
+}
+
+// StartViewWindow mocks base method.
+func (m *MockAdminDataStore) StartViewWindow(ctx context.Context, id string, expirationEpoch int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartViewWindow", ctx, id, expirationEpoch)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StartViewWindow indicates an expected call of StartViewWindow.
+func (mr *MockAdminDataStoreMockRecorder) StartViewWindow(ctx, id, expirationEpoch any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartViewWindow", reflect.TypeOf((*MockAdminDataStore)(nil).StartViewWindow), ctx, id, expirationEpoch)
+}
+
+// TakeExpiryNotification mocks base method.
+func (m *MockAdminDataStore) TakeExpiryNotification(ctx context.Context, keyId string) (models.ExpiryNotice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TakeExpiryNotification", ctx, keyId)
+	ret0, _ := ret[0].(models.ExpiryNotice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TakeExpiryNotification indicates an expected call of TakeExpiryNotification.
+func (mr *MockAdminDataStoreMockRecorder) TakeExpiryNotification(ctx, keyId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakeExpiryNotification", reflect.TypeOf((*MockAdminDataStore)(nil).TakeExpiryNotification), ctx, keyId)
+}
+
+// UpdateSecret mocks base method.
+func (m *MockAdminDataStore) UpdateSecret(ctx context.Context, id string, expirationEpoch int64, accessLimit int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSecret", ctx, id, expirationEpoch, accessLimit)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateSecret indicates an expected call of UpdateSecret.
+func (mr *MockAdminDataStoreMockRecorder) UpdateSecret(ctx, id, expirationEpoch, accessLimit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSecret", reflect.TypeOf((*MockAdminDataStore)(nil).UpdateSecret), ctx, id, expirationEpoch, accessLimit)
+}
+
+// WriteSecret mocks base method.
+func (m *MockAdminDataStore) WriteSecret(ctx context.Context, secret models.Secret) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteSecret", ctx, secret)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteSecret indicates an expected call of WriteSecret.
+func (mr *MockAdminDataStoreMockRecorder) WriteSecret(ctx, secret any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteSecret", reflect.TypeOf((*MockAdminDataStore)(nil).WriteSecret), ctx, secret)
+}
+
+// WriteSecretRequest mocks base method.
+func (m *MockAdminDataStore) WriteSecretRequest(ctx context.Context, request models.SecretRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteSecretRequest", ctx, request)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteSecretRequest indicates an expected call of WriteSecretRequest.
+func (mr *MockAdminDataStoreMockRecorder) WriteSecretRequest(ctx, request any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteSecretRequest", reflect.TypeOf((*MockAdminDataStore)(nil).WriteSecretRequest), ctx, request)
+}
+
+// WriteSplitSecret mocks base method.
+func (m *MockAdminDataStore) WriteSplitSecret(ctx context.Context, split models.SplitSecret) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteSplitSecret", ctx, split)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteSplitSecret indicates an expected call of WriteSplitSecret.
+func (mr *MockAdminDataStoreMockRecorder) WriteSplitSecret(ctx, split any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteSplitSecret", reflect.TypeOf((*MockAdminDataStore)(nil).WriteSplitSecret), ctx, split)
+}
+
+// WriteVerificationCode mocks base method.
+func (m *MockAdminDataStore) WriteVerificationCode(ctx context.Context, id, codeHash string, ttl time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteVerificationCode", ctx, id, codeHash, ttl)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WriteVerificationCode indicates an expected call of WriteVerificationCode.
+func (mr *MockAdminDataStoreMockRecorder) WriteVerificationCode(ctx, id, codeHash, ttl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteVerificationCode", reflect.TypeOf((*MockAdminDataStore)(nil).WriteVerificationCode), ctx, id, codeHash, ttl)
+}
@@ -39,6 +39,20 @@ func (m *MockIAppConfiguration) EXPECT() *MockIAppConfigurationMockRecorder {
 	return m.recorder
 }
 
+// AllowedCIDRs mocks base method.
+func (m *MockIAppConfiguration) AllowedCIDRs() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AllowedCIDRs")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// AllowedCIDRs indicates an expected call of AllowedCIDRs.
+func (mr *MockIAppConfigurationMockRecorder) AllowedCIDRs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllowedCIDRs", reflect.TypeOf((*MockIAppConfiguration)(nil).AllowedCIDRs))
+}
+
 // BindAddress mocks base method.
 func (m *MockIAppConfiguration) BindAddress() string {
 	m.ctrl.T.Helper()
@@ -67,6 +81,20 @@ func (mr *MockIAppConfigurationMockRecorder) ClientAddress() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClientAddress", reflect.TypeOf((*MockIAppConfiguration)(nil).ClientAddress))
 }
 
+// LockMemory mocks base method.
+func (m *MockIAppConfiguration) LockMemory() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockMemory")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// LockMemory indicates an expected call of LockMemory.
+func (mr *MockIAppConfigurationMockRecorder) LockMemory() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockMemory", reflect.TypeOf((*MockIAppConfiguration)(nil).LockMemory))
+}
+
 // MaxAccessCount mocks base method.
 func (m *MockIAppConfiguration) MaxAccessCount() int {
 	m.ctrl.T.Helper()
@@ -109,6 +137,48 @@ func (mr *MockIAppConfigurationMockRecorder) MaxFileSizeMB() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxFileSizeMB", reflect.TypeOf((*MockIAppConfiguration)(nil).MaxFileSizeMB))
 }
 
+// MaxKVFields mocks base method.
+func (m *MockIAppConfiguration) MaxKVFields() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MaxKVFields")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// MaxKVFields indicates an expected call of MaxKVFields.
+func (mr *MockIAppConfigurationMockRecorder) MaxKVFields() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxKVFields", reflect.TypeOf((*MockIAppConfiguration)(nil).MaxKVFields))
+}
+
+// MaxKVKeyLength mocks base method.
+func (m *MockIAppConfiguration) MaxKVKeyLength() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MaxKVKeyLength")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// MaxKVKeyLength indicates an expected call of MaxKVKeyLength.
+func (mr *MockIAppConfigurationMockRecorder) MaxKVKeyLength() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxKVKeyLength", reflect.TypeOf((*MockIAppConfiguration)(nil).MaxKVKeyLength))
+}
+
+// PaddingBuckets mocks base method.
+func (m *MockIAppConfiguration) PaddingBuckets() []int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PaddingBuckets")
+	ret0, _ := ret[0].([]int)
+	return ret0
+}
+
+// PaddingBuckets indicates an expected call of PaddingBuckets.
+func (mr *MockIAppConfigurationMockRecorder) PaddingBuckets() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PaddingBuckets", reflect.TypeOf((*MockIAppConfiguration)(nil).PaddingBuckets))
+}
+
 // Version mocks base method.
 func (m *MockIAppConfiguration) Version() string {
 	m.ctrl.T.Helper()
@@ -0,0 +1,83 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: cellar/pkg/settings (interfaces: IAuthConfiguration)
+//
+// Generated by this command:
+//
+//	mockgen -destination=../mocks/mock_auth_configuration.go -package=mocks cellar/pkg/settings IAuthConfiguration
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	settings "cellar/pkg/settings"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIAuthConfiguration is a mock of IAuthConfiguration interface.
+type MockIAuthConfiguration struct {
+	ctrl     *gomock.Controller
+	recorder *MockIAuthConfigurationMockRecorder
+	isgomock struct{}
+}
+
+// MockIAuthConfigurationMockRecorder is the mock recorder for MockIAuthConfiguration.
+type MockIAuthConfigurationMockRecorder struct {
+	mock *MockIAuthConfiguration
+}
+
+// NewMockIAuthConfiguration creates a new mock instance.
+func NewMockIAuthConfiguration(ctrl *gomock.Controller) *MockIAuthConfiguration {
+	mock := &MockIAuthConfiguration{ctrl: ctrl}
+	mock.recorder = &MockIAuthConfigurationMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIAuthConfiguration) EXPECT() *MockIAuthConfigurationMockRecorder {
+	return m.recorder
+}
+
+// AnonymousScopes mocks base method.
+func (m *MockIAuthConfiguration) AnonymousScopes() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnonymousScopes")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// AnonymousScopes indicates an expected call of AnonymousScopes.
+func (mr *MockIAuthConfigurationMockRecorder) AnonymousScopes() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnonymousScopes", reflect.TypeOf((*MockIAuthConfiguration)(nil).AnonymousScopes))
+}
+
+// Enabled mocks base method.
+func (m *MockIAuthConfiguration) Enabled() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enabled")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Enabled indicates an expected call of Enabled.
+func (mr *MockIAuthConfigurationMockRecorder) Enabled() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enabled", reflect.TypeOf((*MockIAuthConfiguration)(nil).Enabled))
+}
+
+// OIDC mocks base method.
+func (m *MockIAuthConfiguration) OIDC() settings.IOIDCConfiguration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OIDC")
+	ret0, _ := ret[0].(settings.IOIDCConfiguration)
+	return ret0
+}
+
+// OIDC indicates an expected call of OIDC.
+func (mr *MockIAuthConfigurationMockRecorder) OIDC() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OIDC", reflect.TypeOf((*MockIAuthConfiguration)(nil).OIDC))
+}
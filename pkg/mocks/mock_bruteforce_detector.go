@@ -0,0 +1,86 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: cellar/pkg/ratelimit (interfaces: BruteForceDetector)
+//
+// Generated by this command:
+//
+//	mockgen -destination=../mocks/mock_bruteforce_detector.go -package=mocks . BruteForceDetector
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockBruteForceDetector is a mock of BruteForceDetector interface.
+type MockBruteForceDetector struct {
+	ctrl     *gomock.Controller
+	recorder *MockBruteForceDetectorMockRecorder
+	isgomock struct{}
+}
+
+// MockBruteForceDetectorMockRecorder is the mock recorder for MockBruteForceDetector.
+type MockBruteForceDetectorMockRecorder struct {
+	mock *MockBruteForceDetector
+}
+
+// NewMockBruteForceDetector creates a new mock instance.
+func NewMockBruteForceDetector(ctrl *gomock.Controller) *MockBruteForceDetector {
+	mock := &MockBruteForceDetector{ctrl: ctrl}
+	mock.recorder = &MockBruteForceDetectorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBruteForceDetector) EXPECT() *MockBruteForceDetectorMockRecorder {
+	return m.recorder
+}
+
+// Blocked mocks base method.
+func (m *MockBruteForceDetector) Blocked(ctx context.Context, identifier string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Blocked", ctx, identifier)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Blocked indicates an expected call of Blocked.
+func (mr *MockBruteForceDetectorMockRecorder) Blocked(ctx, identifier any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Blocked", reflect.TypeOf((*MockBruteForceDetector)(nil).Blocked), ctx, identifier)
+}
+
+// RecordFailure mocks base method.
+func (m *MockBruteForceDetector) RecordFailure(ctx context.Context, identifier string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordFailure", ctx, identifier)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordFailure indicates an expected call of RecordFailure.
+func (mr *MockBruteForceDetectorMockRecorder) RecordFailure(ctx, identifier any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordFailure", reflect.TypeOf((*MockBruteForceDetector)(nil).RecordFailure), ctx, identifier)
+}
+
+// RecordNotFound mocks base method.
+func (m *MockBruteForceDetector) RecordNotFound(ctx context.Context, identifier string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordNotFound", ctx, identifier)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordNotFound indicates an expected call of RecordNotFound.
+func (mr *MockBruteForceDetectorMockRecorder) RecordNotFound(ctx, identifier any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordNotFound", reflect.TypeOf((*MockBruteForceDetector)(nil).RecordNotFound), ctx, identifier)
+}
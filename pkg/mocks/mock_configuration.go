@@ -13,6 +13,7 @@ import (
 	settings "cellar/pkg/settings"
 	cryptography "cellar/pkg/settings/cryptography"
 	datastore "cellar/pkg/settings/datastore"
+	notifications "cellar/pkg/settings/notifications"
 	reflect "reflect"
 
 	gomock "go.uber.org/mock/gomock"
@@ -56,6 +57,20 @@ func (mr *MockIConfigurationMockRecorder) App() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "App", reflect.TypeOf((*MockIConfiguration)(nil).App))
 }
 
+// Auth mocks base method.
+func (m *MockIConfiguration) Auth() settings.IAuthConfiguration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Auth")
+	ret0, _ := ret[0].(settings.IAuthConfiguration)
+	return ret0
+}
+
+// Auth indicates an expected call of Auth.
+func (mr *MockIConfigurationMockRecorder) Auth() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Auth", reflect.TypeOf((*MockIConfiguration)(nil).Auth))
+}
+
 // Datastore mocks base method.
 func (m *MockIConfiguration) Datastore() datastore.IDatastoreConfiguration {
 	m.ctrl.T.Helper()
@@ -84,6 +99,34 @@ func (mr *MockIConfigurationMockRecorder) Encryption() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Encryption", reflect.TypeOf((*MockIConfiguration)(nil).Encryption))
 }
 
+// GeoIP mocks base method.
+func (m *MockIConfiguration) GeoIP() settings.IGeoIPConfiguration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GeoIP")
+	ret0, _ := ret[0].(settings.IGeoIPConfiguration)
+	return ret0
+}
+
+// GeoIP indicates an expected call of GeoIP.
+func (mr *MockIConfigurationMockRecorder) GeoIP() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GeoIP", reflect.TypeOf((*MockIConfiguration)(nil).GeoIP))
+}
+
+// Hardening mocks base method.
+func (m *MockIConfiguration) Hardening() settings.IHardeningConfiguration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Hardening")
+	ret0, _ := ret[0].(settings.IHardeningConfiguration)
+	return ret0
+}
+
+// Hardening indicates an expected call of Hardening.
+func (mr *MockIConfigurationMockRecorder) Hardening() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Hardening", reflect.TypeOf((*MockIConfiguration)(nil).Hardening))
+}
+
 // Logging mocks base method.
 func (m *MockIConfiguration) Logging() settings.ILoggingConfiguration {
 	m.ctrl.T.Helper()
@@ -98,6 +141,20 @@ func (mr *MockIConfigurationMockRecorder) Logging() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logging", reflect.TypeOf((*MockIConfiguration)(nil).Logging))
 }
 
+// Notifications mocks base method.
+func (m *MockIConfiguration) Notifications() notifications.INotificationsConfiguration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Notifications")
+	ret0, _ := ret[0].(notifications.INotificationsConfiguration)
+	return ret0
+}
+
+// Notifications indicates an expected call of Notifications.
+func (mr *MockIConfigurationMockRecorder) Notifications() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Notifications", reflect.TypeOf((*MockIConfiguration)(nil).Notifications))
+}
+
 // RateLimit mocks base method.
 func (m *MockIConfiguration) RateLimit() settings.IRateLimitConfiguration {
 	m.ctrl.T.Helper()
@@ -111,3 +168,17 @@ func (mr *MockIConfigurationMockRecorder) RateLimit() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RateLimit", reflect.TypeOf((*MockIConfiguration)(nil).RateLimit))
 }
+
+// Tenants mocks base method.
+func (m *MockIConfiguration) Tenants() settings.ITenantsConfiguration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Tenants")
+	ret0, _ := ret[0].(settings.ITenantsConfiguration)
+	return ret0
+}
+
+// Tenants indicates an expected call of Tenants.
+func (mr *MockIConfigurationMockRecorder) Tenants() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Tenants", reflect.TypeOf((*MockIConfiguration)(nil).Tenants))
+}
@@ -13,6 +13,7 @@ import (
 	models "cellar/pkg/models"
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -41,6 +42,49 @@ func (m *MockDataStore) EXPECT() *MockDataStoreMockRecorder {
 	return m.recorder
 }
 
+// AppendSecretHistory mocks base method.
+func (m *MockDataStore) AppendSecretHistory(ctx context.Context, id string, event models.SecretHistoryEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AppendSecretHistory", ctx, id, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AppendSecretHistory indicates an expected call of AppendSecretHistory.
+func (mr *MockDataStoreMockRecorder) AppendSecretHistory(ctx, id, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppendSecretHistory", reflect.TypeOf((*MockDataStore)(nil).AppendSecretHistory), ctx, id, event)
+}
+
+// CancelExpiryNotification mocks base method.
+func (m *MockDataStore) CancelExpiryNotification(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelExpiryNotification", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelExpiryNotification indicates an expected call of CancelExpiryNotification.
+func (mr *MockDataStoreMockRecorder) CancelExpiryNotification(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelExpiryNotification", reflect.TypeOf((*MockDataStore)(nil).CancelExpiryNotification), ctx, id)
+}
+
+// ConsumeVerificationCode mocks base method.
+func (m *MockDataStore) ConsumeVerificationCode(ctx context.Context, id, codeHash string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeVerificationCode", ctx, id, codeHash)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeVerificationCode indicates an expected call of ConsumeVerificationCode.
+func (mr *MockDataStoreMockRecorder) ConsumeVerificationCode(ctx, id, codeHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeVerificationCode", reflect.TypeOf((*MockDataStore)(nil).ConsumeVerificationCode), ctx, id, codeHash)
+}
+
 // DeleteSecret mocks base method.
 func (m *MockDataStore) DeleteSecret(ctx context.Context, id string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -56,6 +100,21 @@ func (mr *MockDataStoreMockRecorder) DeleteSecret(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSecret", reflect.TypeOf((*MockDataStore)(nil).DeleteSecret), ctx, id)
 }
 
+// FulfillSecretRequest mocks base method.
+func (m *MockDataStore) FulfillSecretRequest(ctx context.Context, request models.SecretRequest, secretId string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FulfillSecretRequest", ctx, request, secretId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FulfillSecretRequest indicates an expected call of FulfillSecretRequest.
+func (mr *MockDataStoreMockRecorder) FulfillSecretRequest(ctx, request, secretId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FulfillSecretRequest", reflect.TypeOf((*MockDataStore)(nil).FulfillSecretRequest), ctx, request, secretId)
+}
+
 // Health mocks base method.
 func (m *MockDataStore) Health(ctx context.Context) models.Health {
 	m.ctrl.T.Helper()
@@ -85,6 +144,20 @@ func (mr *MockDataStoreMockRecorder) IncreaseAccessCount(ctx, id any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncreaseAccessCount", reflect.TypeOf((*MockDataStore)(nil).IncreaseAccessCount), ctx, id)
 }
 
+// KeyID mocks base method.
+func (m *MockDataStore) KeyID(id string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "KeyID", id)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// KeyID indicates an expected call of KeyID.
+func (mr *MockDataStoreMockRecorder) KeyID(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KeyID", reflect.TypeOf((*MockDataStore)(nil).KeyID), id)
+}
+
 // ReadSecret mocks base method.
 func (m *MockDataStore) ReadSecret(ctx context.Context, id string) *models.Secret {
 	m.ctrl.T.Helper()
@@ -99,6 +172,109 @@ func (mr *MockDataStoreMockRecorder) ReadSecret(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadSecret", reflect.TypeOf((*MockDataStore)(nil).ReadSecret), ctx, id)
 }
 
+// ReadSecretHistory mocks base method.
+func (m *MockDataStore) ReadSecretHistory(ctx context.Context, id string) ([]models.SecretHistoryEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadSecretHistory", ctx, id)
+	ret0, _ := ret[0].([]models.SecretHistoryEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadSecretHistory indicates an expected call of ReadSecretHistory.
+func (mr *MockDataStoreMockRecorder) ReadSecretHistory(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadSecretHistory", reflect.TypeOf((*MockDataStore)(nil).ReadSecretHistory), ctx, id)
+}
+
+// ReadSecretRequest mocks base method.
+func (m *MockDataStore) ReadSecretRequest(ctx context.Context, id string) *models.SecretRequest {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadSecretRequest", ctx, id)
+	ret0, _ := ret[0].(*models.SecretRequest)
+	return ret0
+}
+
+// ReadSecretRequest indicates an expected call of ReadSecretRequest.
+func (mr *MockDataStoreMockRecorder) ReadSecretRequest(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadSecretRequest", reflect.TypeOf((*MockDataStore)(nil).ReadSecretRequest), ctx, id)
+}
+
+// ReadSplitSecret mocks base method.
+func (m *MockDataStore) ReadSplitSecret(ctx context.Context, id string) *models.SplitSecret {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadSplitSecret", ctx, id)
+	ret0, _ := ret[0].(*models.SplitSecret)
+	return ret0
+}
+
+// ReadSplitSecret indicates an expected call of ReadSplitSecret.
+func (mr *MockDataStoreMockRecorder) ReadSplitSecret(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadSplitSecret", reflect.TypeOf((*MockDataStore)(nil).ReadSplitSecret), ctx, id)
+}
+
+// ReadVerificationCode mocks base method.
+func (m *MockDataStore) ReadVerificationCode(ctx context.Context, id string, attemptWindow time.Duration) (string, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadVerificationCode", ctx, id, attemptWindow)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ReadVerificationCode indicates an expected call of ReadVerificationCode.
+func (mr *MockDataStoreMockRecorder) ReadVerificationCode(ctx, id, attemptWindow any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadVerificationCode", reflect.TypeOf((*MockDataStore)(nil).ReadVerificationCode), ctx, id, attemptWindow)
+}
+
+// StartViewWindow mocks base method.
+func (m *MockDataStore) StartViewWindow(ctx context.Context, id string, expirationEpoch int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartViewWindow", ctx, id, expirationEpoch)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StartViewWindow indicates an expected call of StartViewWindow.
+func (mr *MockDataStoreMockRecorder) StartViewWindow(ctx, id, expirationEpoch any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartViewWindow", reflect.TypeOf((*MockDataStore)(nil).StartViewWindow), ctx, id, expirationEpoch)
+}
+
+// TakeExpiryNotification mocks base method.
+func (m *MockDataStore) TakeExpiryNotification(ctx context.Context, keyId string) (models.ExpiryNotice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TakeExpiryNotification", ctx, keyId)
+	ret0, _ := ret[0].(models.ExpiryNotice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TakeExpiryNotification indicates an expected call of TakeExpiryNotification.
+func (mr *MockDataStoreMockRecorder) TakeExpiryNotification(ctx, keyId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakeExpiryNotification", reflect.TypeOf((*MockDataStore)(nil).TakeExpiryNotification), ctx, keyId)
+}
+
+// UpdateSecret mocks base method.
+func (m *MockDataStore) UpdateSecret(ctx context.Context, id string, expirationEpoch int64, accessLimit int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSecret", ctx, id, expirationEpoch, accessLimit)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateSecret indicates an expected call of UpdateSecret.
+func (mr *MockDataStoreMockRecorder) UpdateSecret(ctx, id, expirationEpoch, accessLimit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSecret", reflect.TypeOf((*MockDataStore)(nil).UpdateSecret), ctx, id, expirationEpoch, accessLimit)
+}
+
 // WriteSecret mocks base method.
 func (m *MockDataStore) WriteSecret(ctx context.Context, secret models.Secret) error {
 	m.ctrl.T.Helper()
@@ -112,3 +288,46 @@ func (mr *MockDataStoreMockRecorder) WriteSecret(ctx, secret any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteSecret", reflect.TypeOf((*MockDataStore)(nil).WriteSecret), ctx, secret)
 }
+
+// WriteSecretRequest mocks base method.
+func (m *MockDataStore) WriteSecretRequest(ctx context.Context, request models.SecretRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteSecretRequest", ctx, request)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteSecretRequest indicates an expected call of WriteSecretRequest.
+func (mr *MockDataStoreMockRecorder) WriteSecretRequest(ctx, request any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteSecretRequest", reflect.TypeOf((*MockDataStore)(nil).WriteSecretRequest), ctx, request)
+}
+
+// WriteSplitSecret mocks base method.
+func (m *MockDataStore) WriteSplitSecret(ctx context.Context, split models.SplitSecret) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteSplitSecret", ctx, split)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteSplitSecret indicates an expected call of WriteSplitSecret.
+func (mr *MockDataStoreMockRecorder) WriteSplitSecret(ctx, split any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteSplitSecret", reflect.TypeOf((*MockDataStore)(nil).WriteSplitSecret), ctx, split)
+}
+
+// WriteVerificationCode mocks base method.
+func (m *MockDataStore) WriteVerificationCode(ctx context.Context, id, codeHash string, ttl time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteVerificationCode", ctx, id, codeHash, ttl)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WriteVerificationCode indicates an expected call of WriteVerificationCode.
+func (mr *MockDataStoreMockRecorder) WriteVerificationCode(ctx, id, codeHash, ttl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteVerificationCode", reflect.TypeOf((*MockDataStore)(nil).WriteVerificationCode), ctx, id, codeHash, ttl)
+}
@@ -0,0 +1,110 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: cellar/pkg/settings (interfaces: IGeoIPConfiguration)
+//
+// Generated by this command:
+//
+//	mockgen -destination=../mocks/mock_geoip_configuration.go -package=mocks cellar/pkg/settings IGeoIPConfiguration
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIGeoIPConfiguration is a mock of IGeoIPConfiguration interface.
+type MockIGeoIPConfiguration struct {
+	ctrl     *gomock.Controller
+	recorder *MockIGeoIPConfigurationMockRecorder
+	isgomock struct{}
+}
+
+// MockIGeoIPConfigurationMockRecorder is the mock recorder for MockIGeoIPConfiguration.
+type MockIGeoIPConfigurationMockRecorder struct {
+	mock *MockIGeoIPConfiguration
+}
+
+// NewMockIGeoIPConfiguration creates a new mock instance.
+func NewMockIGeoIPConfiguration(ctrl *gomock.Controller) *MockIGeoIPConfiguration {
+	mock := &MockIGeoIPConfiguration{ctrl: ctrl}
+	mock.recorder = &MockIGeoIPConfigurationMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIGeoIPConfiguration) EXPECT() *MockIGeoIPConfigurationMockRecorder {
+	return m.recorder
+}
+
+// AllowedCountries mocks base method.
+func (m *MockIGeoIPConfiguration) AllowedCountries() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AllowedCountries")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// AllowedCountries indicates an expected call of AllowedCountries.
+func (mr *MockIGeoIPConfigurationMockRecorder) AllowedCountries() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllowedCountries", reflect.TypeOf((*MockIGeoIPConfiguration)(nil).AllowedCountries))
+}
+
+// DatabasePath mocks base method.
+func (m *MockIGeoIPConfiguration) DatabasePath() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DatabasePath")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// DatabasePath indicates an expected call of DatabasePath.
+func (mr *MockIGeoIPConfigurationMockRecorder) DatabasePath() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DatabasePath", reflect.TypeOf((*MockIGeoIPConfiguration)(nil).DatabasePath))
+}
+
+// DeniedCountries mocks base method.
+func (m *MockIGeoIPConfiguration) DeniedCountries() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeniedCountries")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// DeniedCountries indicates an expected call of DeniedCountries.
+func (mr *MockIGeoIPConfigurationMockRecorder) DeniedCountries() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeniedCountries", reflect.TypeOf((*MockIGeoIPConfiguration)(nil).DeniedCountries))
+}
+
+// Enabled mocks base method.
+func (m *MockIGeoIPConfiguration) Enabled() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enabled")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Enabled indicates an expected call of Enabled.
+func (mr *MockIGeoIPConfigurationMockRecorder) Enabled() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enabled", reflect.TypeOf((*MockIGeoIPConfiguration)(nil).Enabled))
+}
+
+// ReloadIntervalSeconds mocks base method.
+func (m *MockIGeoIPConfiguration) ReloadIntervalSeconds() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReloadIntervalSeconds")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// ReloadIntervalSeconds indicates an expected call of ReloadIntervalSeconds.
+func (mr *MockIGeoIPConfigurationMockRecorder) ReloadIntervalSeconds() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReloadIntervalSeconds", reflect.TypeOf((*MockIGeoIPConfiguration)(nil).ReloadIntervalSeconds))
+}
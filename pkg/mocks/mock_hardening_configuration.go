@@ -0,0 +1,166 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: cellar/pkg/settings (interfaces: IHardeningConfiguration)
+//
+// Generated by this command:
+//
+//	mockgen -destination=../mocks/mock_hardening_configuration.go -package=mocks cellar/pkg/settings IHardeningConfiguration
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIHardeningConfiguration is a mock of IHardeningConfiguration interface.
+type MockIHardeningConfiguration struct {
+	ctrl     *gomock.Controller
+	recorder *MockIHardeningConfigurationMockRecorder
+	isgomock struct{}
+}
+
+// MockIHardeningConfigurationMockRecorder is the mock recorder for MockIHardeningConfiguration.
+type MockIHardeningConfigurationMockRecorder struct {
+	mock *MockIHardeningConfiguration
+}
+
+// NewMockIHardeningConfiguration creates a new mock instance.
+func NewMockIHardeningConfiguration(ctrl *gomock.Controller) *MockIHardeningConfiguration {
+	mock := &MockIHardeningConfiguration{ctrl: ctrl}
+	mock.recorder = &MockIHardeningConfigurationMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIHardeningConfiguration) EXPECT() *MockIHardeningConfigurationMockRecorder {
+	return m.recorder
+}
+
+// BlockSeconds mocks base method.
+func (m *MockIHardeningConfiguration) BlockSeconds() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockSeconds")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// BlockSeconds indicates an expected call of BlockSeconds.
+func (mr *MockIHardeningConfigurationMockRecorder) BlockSeconds() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockSeconds", reflect.TypeOf((*MockIHardeningConfiguration)(nil).BlockSeconds))
+}
+
+// BruteForceEnabled mocks base method.
+func (m *MockIHardeningConfiguration) BruteForceEnabled() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BruteForceEnabled")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// BruteForceEnabled indicates an expected call of BruteForceEnabled.
+func (mr *MockIHardeningConfigurationMockRecorder) BruteForceEnabled() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BruteForceEnabled", reflect.TypeOf((*MockIHardeningConfiguration)(nil).BruteForceEnabled))
+}
+
+// FailureLimit mocks base method.
+func (m *MockIHardeningConfiguration) FailureLimit() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailureLimit")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// FailureLimit indicates an expected call of FailureLimit.
+func (mr *MockIHardeningConfigurationMockRecorder) FailureLimit() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailureLimit", reflect.TypeOf((*MockIHardeningConfiguration)(nil).FailureLimit))
+}
+
+// FailureWindowSeconds mocks base method.
+func (m *MockIHardeningConfiguration) FailureWindowSeconds() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailureWindowSeconds")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// FailureWindowSeconds indicates an expected call of FailureWindowSeconds.
+func (mr *MockIHardeningConfigurationMockRecorder) FailureWindowSeconds() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailureWindowSeconds", reflect.TypeOf((*MockIHardeningConfiguration)(nil).FailureWindowSeconds))
+}
+
+// MaxBlockSeconds mocks base method.
+func (m *MockIHardeningConfiguration) MaxBlockSeconds() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MaxBlockSeconds")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// MaxBlockSeconds indicates an expected call of MaxBlockSeconds.
+func (mr *MockIHardeningConfigurationMockRecorder) MaxBlockSeconds() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxBlockSeconds", reflect.TypeOf((*MockIHardeningConfiguration)(nil).MaxBlockSeconds))
+}
+
+// NotFoundLimit mocks base method.
+func (m *MockIHardeningConfiguration) NotFoundLimit() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotFoundLimit")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// NotFoundLimit indicates an expected call of NotFoundLimit.
+func (mr *MockIHardeningConfigurationMockRecorder) NotFoundLimit() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotFoundLimit", reflect.TypeOf((*MockIHardeningConfiguration)(nil).NotFoundLimit))
+}
+
+// NotFoundWindowSeconds mocks base method.
+func (m *MockIHardeningConfiguration) NotFoundWindowSeconds() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotFoundWindowSeconds")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// NotFoundWindowSeconds indicates an expected call of NotFoundWindowSeconds.
+func (mr *MockIHardeningConfigurationMockRecorder) NotFoundWindowSeconds() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotFoundWindowSeconds", reflect.TypeOf((*MockIHardeningConfiguration)(nil).NotFoundWindowSeconds))
+}
+
+// UniformResponseMilliseconds mocks base method.
+func (m *MockIHardeningConfiguration) UniformResponseMilliseconds() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UniformResponseMilliseconds")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// UniformResponseMilliseconds indicates an expected call of UniformResponseMilliseconds.
+func (mr *MockIHardeningConfigurationMockRecorder) UniformResponseMilliseconds() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UniformResponseMilliseconds", reflect.TypeOf((*MockIHardeningConfiguration)(nil).UniformResponseMilliseconds))
+}
+
+// UniformResponsesEnabled mocks base method.
+func (m *MockIHardeningConfiguration) UniformResponsesEnabled() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UniformResponsesEnabled")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// UniformResponsesEnabled indicates an expected call of UniformResponsesEnabled.
+func (mr *MockIHardeningConfigurationMockRecorder) UniformResponsesEnabled() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UniformResponsesEnabled", reflect.TypeOf((*MockIHardeningConfiguration)(nil).UniformResponsesEnabled))
+}
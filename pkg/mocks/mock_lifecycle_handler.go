@@ -0,0 +1,54 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: cellar/pkg/lifecycle (interfaces: Handler)
+//
+// Generated by this command:
+//
+//	mockgen -destination=../mocks/mock_lifecycle_handler.go -package=mocks -mock_names=Handler=MockLifecycleHandler . Handler
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	models "cellar/pkg/models"
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockLifecycleHandler is a mock of Handler interface.
+type MockLifecycleHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockLifecycleHandlerMockRecorder
+	isgomock struct{}
+}
+
+// MockLifecycleHandlerMockRecorder is the mock recorder for MockLifecycleHandler.
+type MockLifecycleHandlerMockRecorder struct {
+	mock *MockLifecycleHandler
+}
+
+// NewMockLifecycleHandler creates a new mock instance.
+func NewMockLifecycleHandler(ctrl *gomock.Controller) *MockLifecycleHandler {
+	mock := &MockLifecycleHandler{ctrl: ctrl}
+	mock.recorder = &MockLifecycleHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLifecycleHandler) EXPECT() *MockLifecycleHandlerMockRecorder {
+	return m.recorder
+}
+
+// Handle mocks base method.
+func (m *MockLifecycleHandler) Handle(ctx context.Context, event models.LifecycleEvent) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Handle", ctx, event)
+}
+
+// Handle indicates an expected call of Handle.
+func (mr *MockLifecycleHandlerMockRecorder) Handle(ctx, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Handle", reflect.TypeOf((*MockLifecycleHandler)(nil).Handle), ctx, event)
+}
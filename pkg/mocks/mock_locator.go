@@ -0,0 +1,54 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: cellar/pkg/geoip (interfaces: Locator)
+//
+// Generated by this command:
+//
+//	mockgen -destination=../mocks/mock_locator.go -package=mocks . Locator
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockLocator is a mock of Locator interface.
+type MockLocator struct {
+	ctrl     *gomock.Controller
+	recorder *MockLocatorMockRecorder
+	isgomock struct{}
+}
+
+// MockLocatorMockRecorder is the mock recorder for MockLocator.
+type MockLocatorMockRecorder struct {
+	mock *MockLocator
+}
+
+// NewMockLocator creates a new mock instance.
+func NewMockLocator(ctrl *gomock.Controller) *MockLocator {
+	mock := &MockLocator{ctrl: ctrl}
+	mock.recorder = &MockLocatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLocator) EXPECT() *MockLocatorMockRecorder {
+	return m.recorder
+}
+
+// Country mocks base method.
+func (m *MockLocator) Country(ip string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Country", ip)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Country indicates an expected call of Country.
+func (mr *MockLocatorMockRecorder) Country(ip any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Country", reflect.TypeOf((*MockLocator)(nil).Country), ip)
+}
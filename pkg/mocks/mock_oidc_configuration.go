@@ -0,0 +1,208 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: cellar/pkg/settings (interfaces: IOIDCConfiguration)
+//
+// Generated by this command:
+//
+//	mockgen -destination=../mocks/mock_oidc_configuration.go -package=mocks cellar/pkg/settings IOIDCConfiguration
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIOIDCConfiguration is a mock of IOIDCConfiguration interface.
+type MockIOIDCConfiguration struct {
+	ctrl     *gomock.Controller
+	recorder *MockIOIDCConfigurationMockRecorder
+	isgomock struct{}
+}
+
+// MockIOIDCConfigurationMockRecorder is the mock recorder for MockIOIDCConfiguration.
+type MockIOIDCConfigurationMockRecorder struct {
+	mock *MockIOIDCConfiguration
+}
+
+// NewMockIOIDCConfiguration creates a new mock instance.
+func NewMockIOIDCConfiguration(ctrl *gomock.Controller) *MockIOIDCConfiguration {
+	mock := &MockIOIDCConfiguration{ctrl: ctrl}
+	mock.recorder = &MockIOIDCConfigurationMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIOIDCConfiguration) EXPECT() *MockIOIDCConfigurationMockRecorder {
+	return m.recorder
+}
+
+// AllowedGroups mocks base method.
+func (m *MockIOIDCConfiguration) AllowedGroups() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AllowedGroups")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// AllowedGroups indicates an expected call of AllowedGroups.
+func (mr *MockIOIDCConfigurationMockRecorder) AllowedGroups() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllowedGroups", reflect.TypeOf((*MockIOIDCConfiguration)(nil).AllowedGroups))
+}
+
+// Audience mocks base method.
+func (m *MockIOIDCConfiguration) Audience() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Audience")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Audience indicates an expected call of Audience.
+func (mr *MockIOIDCConfigurationMockRecorder) Audience() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Audience", reflect.TypeOf((*MockIOIDCConfiguration)(nil).Audience))
+}
+
+// Enabled mocks base method.
+func (m *MockIOIDCConfiguration) Enabled() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enabled")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Enabled indicates an expected call of Enabled.
+func (mr *MockIOIDCConfigurationMockRecorder) Enabled() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enabled", reflect.TypeOf((*MockIOIDCConfiguration)(nil).Enabled))
+}
+
+// GroupsClaim mocks base method.
+func (m *MockIOIDCConfiguration) GroupsClaim() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GroupsClaim")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GroupsClaim indicates an expected call of GroupsClaim.
+func (mr *MockIOIDCConfigurationMockRecorder) GroupsClaim() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GroupsClaim", reflect.TypeOf((*MockIOIDCConfiguration)(nil).GroupsClaim))
+}
+
+// Issuer mocks base method.
+func (m *MockIOIDCConfiguration) Issuer() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Issuer")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Issuer indicates an expected call of Issuer.
+func (mr *MockIOIDCConfigurationMockRecorder) Issuer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Issuer", reflect.TypeOf((*MockIOIDCConfiguration)(nil).Issuer))
+}
+
+// JWKSCacheSeconds mocks base method.
+func (m *MockIOIDCConfiguration) JWKSCacheSeconds() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JWKSCacheSeconds")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// JWKSCacheSeconds indicates an expected call of JWKSCacheSeconds.
+func (mr *MockIOIDCConfigurationMockRecorder) JWKSCacheSeconds() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JWKSCacheSeconds", reflect.TypeOf((*MockIOIDCConfiguration)(nil).JWKSCacheSeconds))
+}
+
+// JWKSFile mocks base method.
+func (m *MockIOIDCConfiguration) JWKSFile() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JWKSFile")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// JWKSFile indicates an expected call of JWKSFile.
+func (mr *MockIOIDCConfigurationMockRecorder) JWKSFile() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JWKSFile", reflect.TypeOf((*MockIOIDCConfiguration)(nil).JWKSFile))
+}
+
+// JWKSURL mocks base method.
+func (m *MockIOIDCConfiguration) JWKSURL() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JWKSURL")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// JWKSURL indicates an expected call of JWKSURL.
+func (mr *MockIOIDCConfigurationMockRecorder) JWKSURL() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JWKSURL", reflect.TypeOf((*MockIOIDCConfiguration)(nil).JWKSURL))
+}
+
+// Scopes mocks base method.
+func (m *MockIOIDCConfiguration) Scopes() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Scopes")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// Scopes indicates an expected call of Scopes.
+func (mr *MockIOIDCConfigurationMockRecorder) Scopes() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Scopes", reflect.TypeOf((*MockIOIDCConfiguration)(nil).Scopes))
+}
+
+// TenantClaim mocks base method.
+func (m *MockIOIDCConfiguration) TenantClaim() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TenantClaim")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// TenantClaim indicates an expected call of TenantClaim.
+func (mr *MockIOIDCConfigurationMockRecorder) TenantClaim() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TenantClaim", reflect.TypeOf((*MockIOIDCConfiguration)(nil).TenantClaim))
+}
+
+// UserCreateLimit mocks base method.
+func (m *MockIOIDCConfiguration) UserCreateLimit() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserCreateLimit")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// UserCreateLimit indicates an expected call of UserCreateLimit.
+func (mr *MockIOIDCConfigurationMockRecorder) UserCreateLimit() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserCreateLimit", reflect.TypeOf((*MockIOIDCConfiguration)(nil).UserCreateLimit))
+}
+
+// UserCreateWindowSeconds mocks base method.
+func (m *MockIOIDCConfiguration) UserCreateWindowSeconds() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserCreateWindowSeconds")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// UserCreateWindowSeconds indicates an expected call of UserCreateWindowSeconds.
+func (mr *MockIOIDCConfigurationMockRecorder) UserCreateWindowSeconds() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserCreateWindowSeconds", reflect.TypeOf((*MockIOIDCConfiguration)(nil).UserCreateWindowSeconds))
+}
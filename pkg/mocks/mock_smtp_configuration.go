@@ -0,0 +1,166 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: cellar/pkg/settings/notifications (interfaces: ISmtpConfiguration)
+//
+// Generated by this command:
+//
+//	mockgen -destination=../../mocks/mock_smtp_configuration.go -package=mocks cellar/pkg/settings/notifications ISmtpConfiguration
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockISmtpConfiguration is a mock of ISmtpConfiguration interface.
+type MockISmtpConfiguration struct {
+	ctrl     *gomock.Controller
+	recorder *MockISmtpConfigurationMockRecorder
+	isgomock struct{}
+}
+
+// MockISmtpConfigurationMockRecorder is the mock recorder for MockISmtpConfiguration.
+type MockISmtpConfigurationMockRecorder struct {
+	mock *MockISmtpConfiguration
+}
+
+// NewMockISmtpConfiguration creates a new mock instance.
+func NewMockISmtpConfiguration(ctrl *gomock.Controller) *MockISmtpConfiguration {
+	mock := &MockISmtpConfiguration{ctrl: ctrl}
+	mock.recorder = &MockISmtpConfigurationMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockISmtpConfiguration) EXPECT() *MockISmtpConfigurationMockRecorder {
+	return m.recorder
+}
+
+// Enabled mocks base method.
+func (m *MockISmtpConfiguration) Enabled() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enabled")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Enabled indicates an expected call of Enabled.
+func (mr *MockISmtpConfigurationMockRecorder) Enabled() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enabled", reflect.TypeOf((*MockISmtpConfiguration)(nil).Enabled))
+}
+
+// From mocks base method.
+func (m *MockISmtpConfiguration) From() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "From")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// From indicates an expected call of From.
+func (mr *MockISmtpConfigurationMockRecorder) From() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "From", reflect.TypeOf((*MockISmtpConfiguration)(nil).From))
+}
+
+// Host mocks base method.
+func (m *MockISmtpConfiguration) Host() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Host")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Host indicates an expected call of Host.
+func (mr *MockISmtpConfigurationMockRecorder) Host() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Host", reflect.TypeOf((*MockISmtpConfiguration)(nil).Host))
+}
+
+// Password mocks base method.
+func (m *MockISmtpConfiguration) Password() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Password")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Password indicates an expected call of Password.
+func (mr *MockISmtpConfigurationMockRecorder) Password() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Password", reflect.TypeOf((*MockISmtpConfiguration)(nil).Password))
+}
+
+// Port mocks base method.
+func (m *MockISmtpConfiguration) Port() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Port")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// Port indicates an expected call of Port.
+func (mr *MockISmtpConfigurationMockRecorder) Port() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Port", reflect.TypeOf((*MockISmtpConfiguration)(nil).Port))
+}
+
+// QueueSize mocks base method.
+func (m *MockISmtpConfiguration) QueueSize() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueueSize")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// QueueSize indicates an expected call of QueueSize.
+func (mr *MockISmtpConfigurationMockRecorder) QueueSize() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueueSize", reflect.TypeOf((*MockISmtpConfiguration)(nil).QueueSize))
+}
+
+// TimeoutSeconds mocks base method.
+func (m *MockISmtpConfiguration) TimeoutSeconds() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TimeoutSeconds")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// TimeoutSeconds indicates an expected call of TimeoutSeconds.
+func (mr *MockISmtpConfigurationMockRecorder) TimeoutSeconds() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TimeoutSeconds", reflect.TypeOf((*MockISmtpConfiguration)(nil).TimeoutSeconds))
+}
+
+// Username mocks base method.
+func (m *MockISmtpConfiguration) Username() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Username")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Username indicates an expected call of Username.
+func (mr *MockISmtpConfigurationMockRecorder) Username() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Username", reflect.TypeOf((*MockISmtpConfiguration)(nil).Username))
+}
+
+// Validate mocks base method.
+func (m *MockISmtpConfiguration) Validate() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Validate")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Validate indicates an expected call of Validate.
+func (mr *MockISmtpConfigurationMockRecorder) Validate() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Validate", reflect.TypeOf((*MockISmtpConfiguration)(nil).Validate))
+}
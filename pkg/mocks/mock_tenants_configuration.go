@@ -0,0 +1,68 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: cellar/pkg/settings (interfaces: ITenantsConfiguration)
+//
+// Generated by this command:
+//
+//	mockgen -destination=../mocks/mock_tenants_configuration.go -package=mocks cellar/pkg/settings ITenantsConfiguration
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockITenantsConfiguration is a mock of ITenantsConfiguration interface.
+type MockITenantsConfiguration struct {
+	ctrl     *gomock.Controller
+	recorder *MockITenantsConfigurationMockRecorder
+	isgomock struct{}
+}
+
+// MockITenantsConfigurationMockRecorder is the mock recorder for MockITenantsConfiguration.
+type MockITenantsConfigurationMockRecorder struct {
+	mock *MockITenantsConfiguration
+}
+
+// NewMockITenantsConfiguration creates a new mock instance.
+func NewMockITenantsConfiguration(ctrl *gomock.Controller) *MockITenantsConfiguration {
+	mock := &MockITenantsConfiguration{ctrl: ctrl}
+	mock.recorder = &MockITenantsConfigurationMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockITenantsConfiguration) EXPECT() *MockITenantsConfigurationMockRecorder {
+	return m.recorder
+}
+
+// Enabled mocks base method.
+func (m *MockITenantsConfiguration) Enabled() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enabled")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Enabled indicates an expected call of Enabled.
+func (mr *MockITenantsConfigurationMockRecorder) Enabled() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enabled", reflect.TypeOf((*MockITenantsConfiguration)(nil).Enabled))
+}
+
+// File mocks base method.
+func (m *MockITenantsConfiguration) File() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "File")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// File indicates an expected call of File.
+func (mr *MockITenantsConfigurationMockRecorder) File() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "File", reflect.TypeOf((*MockITenantsConfiguration)(nil).File))
+}
@@ -0,0 +1,70 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: cellar/pkg/ratelimit (interfaces: UserQuota)
+//
+// Generated by this command:
+//
+//	mockgen -destination=../mocks/mock_user_quota.go -package=mocks . UserQuota
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockUserQuota is a mock of UserQuota interface.
+type MockUserQuota struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserQuotaMockRecorder
+	isgomock struct{}
+}
+
+// MockUserQuotaMockRecorder is the mock recorder for MockUserQuota.
+type MockUserQuotaMockRecorder struct {
+	mock *MockUserQuota
+}
+
+// NewMockUserQuota creates a new mock instance.
+func NewMockUserQuota(ctrl *gomock.Controller) *MockUserQuota {
+	mock := &MockUserQuota{ctrl: ctrl}
+	mock.recorder = &MockUserQuotaMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserQuota) EXPECT() *MockUserQuotaMockRecorder {
+	return m.recorder
+}
+
+// Exhausted mocks base method.
+func (m *MockUserQuota) Exhausted(ctx context.Context, subject string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Exhausted", ctx, subject)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Exhausted indicates an expected call of Exhausted.
+func (mr *MockUserQuotaMockRecorder) Exhausted(ctx, subject any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exhausted", reflect.TypeOf((*MockUserQuota)(nil).Exhausted), ctx, subject)
+}
+
+// Record mocks base method.
+func (m *MockUserQuota) Record(ctx context.Context, subject string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Record", ctx, subject)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Record indicates an expected call of Record.
+func (mr *MockUserQuotaMockRecorder) Record(ctx, subject any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockUserQuota)(nil).Record), ctx, subject)
+}
@@ -0,0 +1,62 @@
+package models
+
+type (
+	// SecretRecord describes a stored secret by what the datastore knows of it, without its content.
+	// The secret is identified by its key ID, as the secret ID itself is never stored.
+	SecretRecord struct {
+		KeyID        string
+		Creator      string
+		CreatedEpoch int64
+		Size         int64
+	}
+
+	// SecretStats sums up the live secrets of a namespace. Bytes is the size of their encrypted content.
+	SecretStats struct {
+		Count int64
+		Bytes int64
+	}
+
+	// PurgeFilter selects the secrets to purge: those created before the epoch, those made by the creator,
+	// or, with both set, those matching both. Secrets stored before creation times were recorded are never
+	// created before the epoch.
+	PurgeFilter struct {
+		CreatedBeforeEpoch int64  `json:"created_before_epoch" example:"1700000000"`
+		Creator            string `json:"creator" example:"alice"`
+	}
+
+	// AdminActor is who carried out an administrative action, as recorded in the audit log.
+	AdminActor struct {
+		Credential string
+		ClientIP   string
+		Tenant     string
+	}
+
+	SecretStatsResponse struct {
+		Count int64 `json:"count" example:"42"`
+		Bytes int64 `json:"bytes" example:"1048576"`
+	}
+
+	PurgeSecretsResponse struct {
+		Purged int64 `json:"purged" example:"3"`
+	}
+
+	ReadOnlyModeRequest struct {
+		ReadOnly *bool `json:"read_only" example:"true"`
+	}
+
+	ReadOnlyModeResponse struct {
+		ReadOnly bool `json:"read_only" example:"true"`
+	}
+)
+
+// Matches reports whether the filter selects the secret. Secrets without a creation time, stored before creation
+// times were recorded, are skipped by the creation time filter and can only be selected by creator.
+func (filter PurgeFilter) Matches(record SecretRecord) bool {
+	if filter.CreatedBeforeEpoch != 0 && (record.CreatedEpoch == 0 || record.CreatedEpoch >= filter.CreatedBeforeEpoch) {
+		return false
+	}
+	if filter.Creator != "" && record.Creator != filter.Creator {
+		return false
+	}
+	return true
+}
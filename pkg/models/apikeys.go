@@ -0,0 +1,73 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// ScopeCreate allows creating secrets, splits and secret requests.
+	ScopeCreate Scope = "create"
+	// ScopeDeleteAny allows deleting any secret by its ID.
+	ScopeDeleteAny Scope = "delete-any"
+	// ScopeAdmin allows administering the server, and grants every other scope.
+	ScopeAdmin Scope = "admin"
+	// ScopeReadConfig allows reading the server configuration.
+	ScopeReadConfig Scope = "read-config"
+)
+
+// Scopes lists every scope an API key can be granted.
+var Scopes = []Scope{ScopeCreate, ScopeDeleteAny, ScopeAdmin, ScopeReadConfig}
+
+type (
+	Scope string
+
+	// APIKey authenticates a client of the API. Only the hash of the key's secret is stored;
+	// the key itself is shown once, when it is created.
+	// A key bound to a tenant is confined to the namespace of that tenant.
+	APIKey struct {
+		ID           string
+		Name         string
+		SecretHash   string
+		Scopes       []Scope
+		Tenant       string
+		CreatedEpoch int64
+	}
+)
+
+// ParseScopes returns the scopes with the given names, or an error naming the first unknown one.
+func ParseScopes(names []string) ([]Scope, error) {
+	scopes := make([]Scope, 0, len(names))
+	for _, name := range names {
+		scope := Scope(name)
+		if !scope.Valid() {
+			return nil, fmt.Errorf("unknown scope %q", name)
+		}
+		scopes = append(scopes, scope)
+	}
+	return scopes, nil
+}
+
+// Valid reports whether the scope is one of the known scopes.
+func (scope Scope) Valid() bool {
+	for _, known := range Scopes {
+		if scope == known {
+			return true
+		}
+	}
+	return false
+}
+
+// HasScope reports whether the key was granted the scope, either directly or through the admin scope.
+func (key *APIKey) HasScope(scope Scope) bool {
+	for _, granted := range key.Scopes {
+		if granted == scope || granted == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+func (key *APIKey) Created() FormattedTime {
+	return FormattedTime(time.Unix(key.CreatedEpoch, 0).UTC())
+}
@@ -2,10 +2,13 @@ package models
 
 type ConfigResponse struct {
 	Limits LimitsConfig `json:"limits"`
+	Tenant string       `json:"tenant,omitempty" example:"finance"`
 }
 
 type LimitsConfig struct {
 	MaxFileSizeMB        int `json:"maxFileSizeMB" example:"8"`
 	MaxAccessCount       int `json:"maxAccessCount" example:"100"`
 	MaxExpirationSeconds int `json:"maxExpirationSeconds" example:"604800"`
+	MaxKVFields          int `json:"maxKvFields" example:"50"`
+	MaxKVKeyLength       int `json:"maxKvKeyLength" example:"64"`
 }
@@ -0,0 +1,25 @@
+package models
+
+const (
+	GeneratedSecretKindPassword = "password"
+	GeneratedSecretKindToken    = "token"
+	GeneratedSecretKindUUID     = "uuid"
+	GeneratedSecretKindSSH      = "ssh"
+	GeneratedSecretKindAge      = "age"
+)
+
+const (
+	CharacterClassLowercase = "lowercase"
+	CharacterClassUppercase = "uppercase"
+	CharacterClassDigits    = "digits"
+	CharacterClassSymbols   = "symbols"
+)
+
+// GenerateSecretOptions describes the material to generate for a new secret.
+// Length is the number of characters of a password or the number of random bytes of a token,
+// and defaults per kind when zero. CharacterClasses defaults to every class.
+type GenerateSecretOptions struct {
+	Kind             string
+	Length           int
+	CharacterClasses []string
+}
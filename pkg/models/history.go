@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	SecretHistoryAccessed = "accessed"
+	SecretHistoryModified = "modified"
+)
+
+type (
+	SecretHistoryEventType string
+
+	// SecretHistoryEvent records something that happened to a secret.
+	// Accessed events carry the country of the client, if known.
+	// Modified events carry the expiration and access limit the secret was changed to.
+	SecretHistoryEvent struct {
+		Type            SecretHistoryEventType `json:"type"`
+		OccurredAt      time.Time              `json:"occurred_at"`
+		AccessCount     int                    `json:"access_count,omitempty"`
+		AccessLimit     *int                   `json:"access_limit,omitempty"`
+		ExpirationEpoch int64                  `json:"expiration_epoch,omitempty"`
+		Country         string                 `json:"country,omitempty"`
+	}
+
+	// SecretUpdate holds the changes to apply to an existing secret. Nil fields are left unchanged.
+	SecretUpdate struct {
+		ExpirationEpoch *int64
+		AccessLimit     *int
+	}
+
+	SecretHistoryEventResponse struct {
+		Type        SecretHistoryEventType `json:"type" swaggertype:"string" example:"accessed"`
+		OccurredAt  FormattedTime          `json:"occurred_at" swaggertype:"string" example:"1970-01-01 00:00:00 UTC"`
+		AccessCount int                    `json:"access_count,omitempty" example:"1"`
+		AccessLimit *int                   `json:"access_limit,omitempty" example:"10"`
+		Expiration  *FormattedTime         `json:"expiration,omitempty" swaggertype:"string" example:"1970-01-01 00:00:00 UTC"`
+		Country     string                 `json:"country,omitempty" example:"DE"`
+	}
+
+	SecretHistoryResponse struct {
+		ID     string                       `json:"id" example:"22b6fff1be15d1fd54b7b8ec6ad22e80e66275195c914c4b0f9652248a498680"`
+		Events []SecretHistoryEventResponse `json:"events"`
+	}
+)
+
+func (event *SecretHistoryEvent) Response() SecretHistoryEventResponse {
+	response := SecretHistoryEventResponse{
+		Type:        event.Type,
+		OccurredAt:  FormattedTime(event.OccurredAt.UTC()),
+		AccessCount: event.AccessCount,
+		AccessLimit: event.AccessLimit,
+		Country:     event.Country,
+	}
+	if event.ExpirationEpoch > 0 {
+		expiration := FormattedTime(time.Unix(event.ExpirationEpoch, 0).UTC())
+		response.Expiration = &expiration
+	}
+	return response
+}
@@ -0,0 +1,21 @@
+package models
+
+// Identity is the SSO user a request was authenticated as.
+// The tenant is empty for users whose token does not name one.
+type Identity struct {
+	Subject string
+	Groups  []string
+	Tenant  string
+}
+
+// InAnyGroup reports whether the user is in at least one of the groups.
+func (identity *Identity) InAnyGroup(groups []string) bool {
+	for _, group := range groups {
+		for _, member := range identity.Groups {
+			if member == group {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	LifecycleSecretExpired = "secret_expired"
+)
+
+type (
+	LifecycleEventType string
+
+	// LifecycleEvent identifies the secret by its key ID, as events are raised from the datastore,
+	// where the secret ID is not known.
+	LifecycleEvent struct {
+		Type       LifecycleEventType
+		KeyID      string
+		OccurredAt time.Time
+	}
+)
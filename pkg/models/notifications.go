@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	NotificationSecretAccessed = "secret_accessed"
+	NotificationSecretExpired  = "secret_expired"
+
+	// NotificationVerificationCode sends the recipient of a secret the code needed to access it.
+	NotificationVerificationCode = "verification_code"
+)
+
+type (
+	NotificationType string
+
+	// Notification identifies the secret by its reference, as notifications must not carry a usable link to it.
+	Notification struct {
+		Type        NotificationType
+		Recipient   string
+		Reference   string
+		AccessCount int
+		AccessLimit int
+		OccurredAt  time.Time
+
+		// Code and ExpiresAt are only set for verification code notifications.
+		Code      string
+		ExpiresAt time.Time
+	}
+
+	// ExpiryNotice is kept for a secret with a notification address, so the sender can be told once it expires,
+	// by which time the secret ID is no longer known.
+	ExpiryNotice struct {
+		Recipient string `json:"recipient"`
+		Reference string `json:"reference"`
+	}
+)
+
+// SecretReference returns a shortened form of a secret ID that is safe to show in notifications
+// without revealing a usable link to the secret.
+func SecretReference(id string) string {
+	if len(id) <= 8 {
+		return id
+	}
+	return id[:8]
+}
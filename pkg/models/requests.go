@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+)
+
+type (
+	// SecretRequest asks a third party to send a secret to the requester.
+	// An empty ContentType accepts both text and file submissions.
+	SecretRequest struct {
+		ID              string
+		OwnerTokenHash  string
+		ContentType     string
+		MaxSizeBytes    int64
+		AccessLimit     int
+		ExpirationEpoch int64
+		SecretID        string
+		// Creator is the subject of the SSO identity that requested the secret, if it was requested with one.
+		// The submitted secret is recorded as created by it.
+		Creator string
+	}
+
+	SecretRequestMetadata struct {
+		ID           string
+		ContentType  ContentType
+		MaxSizeBytes int64
+		AccessLimit  int
+		Expiration   FormattedTime
+		Fulfilled    bool
+		SecretID     string
+	}
+
+	CreateSecretRequestResponse struct {
+		ID           string        `json:"id" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+		OwnerToken   string        `json:"owner_token" example:"60303ae22b998861bce3b28f33eec1be758a213c86c93c076dbe9f558c11c752"`
+		ContentType  ContentType   `json:"content_type,omitempty" swaggertype:"string" example:"text"`
+		MaxSizeBytes int64         `json:"max_size_bytes" example:"1048576"`
+		AccessLimit  int           `json:"access_limit" example:"1"`
+		Expiration   FormattedTime `json:"expiration" swaggertype:"string" example:"1970-01-01 00:00:00 UTC"`
+	}
+
+	SecretRequestMetadataResponse struct {
+		ID           string        `json:"id" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+		ContentType  ContentType   `json:"content_type,omitempty" swaggertype:"string" example:"text"`
+		MaxSizeBytes int64         `json:"max_size_bytes" example:"1048576"`
+		AccessLimit  int           `json:"access_limit" example:"1"`
+		Expiration   FormattedTime `json:"expiration" swaggertype:"string" example:"1970-01-01 00:00:00 UTC"`
+		Fulfilled    bool          `json:"fulfilled" example:"true"`
+		SecretID     string        `json:"secret_id,omitempty" example:"22b6fff1be15d1fd54b7b8ec6ad22e80e66275195c914c4b0f9652248a498680"`
+	}
+)
+
+func (request *SecretRequest) Expiration() FormattedTime {
+	return FormattedTime(time.Unix(request.ExpirationEpoch, 0).UTC())
+}
+
+func (request *SecretRequest) Duration() time.Duration {
+	return time.Until(request.Expiration().Time().UTC())
+}
+
+func (request *SecretRequest) Fulfilled() bool {
+	return request.SecretID != ""
+}
+
+// Metadata returns the request metadata without the ID of the submitted secret.
+func (request *SecretRequest) Metadata() *SecretRequestMetadata {
+	return &SecretRequestMetadata{
+		ID:           request.ID,
+		ContentType:  ContentType(request.ContentType),
+		MaxSizeBytes: request.MaxSizeBytes,
+		AccessLimit:  request.AccessLimit,
+		Expiration:   request.Expiration(),
+		Fulfilled:    request.Fulfilled(),
+	}
+}
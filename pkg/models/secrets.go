@@ -5,8 +5,15 @@ import (
 )
 
 const (
-	ContentTypeFile = "file"
-	ContentTypeText = "text"
+	ContentTypeFile   = "file"
+	ContentTypeText   = "text"
+	ContentTypeBundle = "bundle"
+	ContentTypeKV     = "kv"
+
+	// ContentTypeAge and ContentTypeOpenPGP mark content sealed to a recipient's public key,
+	// which is stored and returned as armored ciphertext.
+	ContentTypeAge     = "age"
+	ContentTypeOpenPGP = "openpgp"
 )
 
 type (
@@ -25,11 +32,34 @@ type (
 
 	SecretMetadataResponseV2 struct {
 		ID          string        `json:"id" example:"22b6fff1be15d1fd54b7b8ec6ad22e80e66275195c914c4b0f9652248a498680"`
+		OwnerToken  string        `json:"owner_token,omitempty" example:"60303ae22b998861bce3b28f33eec1be758a213c86c93c076dbe9f558c11c752"`
 		AccessCount int           `json:"access_count" example:"1"`
 		AccessLimit int           `json:"access_limit" example:"10"`
 		ContentType ContentType   `json:"content_type" swaggertype:"string" example:"text"`
 		Filename    string        `json:"filename,omitempty" example:"document.pdf"`
 		Expiration  FormattedTime `json:"expiration" swaggertype:"string" example:"1970-01-01 00:00:00 UTC"`
+
+		AvailableFrom     *FormattedTime `json:"available_from,omitempty" swaggertype:"string" example:"1970-01-01 00:00:00 UTC"`
+		ViewWindowSeconds int            `json:"view_window_seconds,omitempty" example:"900"`
+		ViewWindowStarted bool           `json:"view_window_started,omitempty" example:"true"`
+		ViewWindowEnds    *FormattedTime `json:"view_window_ends,omitempty" swaggertype:"string" example:"1970-01-01 00:15:00 UTC"`
+
+		Items []BundleItem `json:"items,omitempty"`
+
+		SplitKey bool   `json:"split_key,omitempty" example:"true"`
+		Key      string `json:"key,omitempty" example:"q83vEjRWeJq83vEjRWeJq83vEjRWeJq83vEjRWeJq80"`
+
+		AllowedCIDRs     []string `json:"allowed_cidrs,omitempty" example:"10.0.0.0/8"`
+		AllowedCountries []string `json:"allowed_countries,omitempty" example:"DE"`
+		DeniedCountries  []string `json:"denied_countries,omitempty" example:"US"`
+
+		RecipientVerification bool `json:"recipient_verification,omitempty" example:"true"`
+	}
+
+	// BundleItem describes one item of a bundle. Items are addressed by their position in the bundle.
+	BundleItem struct {
+		Name string `json:"name" example:"certificate.pem"`
+		Size int64  `json:"size" example:"1024"`
 	}
 
 	ContentType string
@@ -40,24 +70,116 @@ type (
 		CipherText      string
 		ContentType     string
 		Filename        string
+		NotifyEmail     string
+		OwnerTokenHash  string
 		AccessCount     int
 		AccessLimit     int
 		ExpirationEpoch int64
+
+		// OwnerOnly restricts access to the holder of the owner token.
+		OwnerOnly bool
+
+		// AvailableFromEpoch is when the secret can first be accessed, if set.
+		AvailableFromEpoch int64
+
+		// ViewWindowSeconds is how long the secret stays accessible after its first access, if set.
+		ViewWindowSeconds int
+
+		// BundleItems indexes the items of a bundle, whose content is a zip archive of them.
+		BundleItems []BundleItem
+
+		// SplitKey marks content encrypted with a per-secret key that is never stored.
+		// Only a check of the key is kept, to reject a wrong key before an access is consumed.
+		SplitKey      bool
+		SplitKeyCheck string
+
+		// AllowedCIDRs restricts access to clients within these ranges, if set.
+		AllowedCIDRs []string
+
+		// AllowedCountries and DeniedCountries restrict access by the country of the client.
+		AllowedCountries []string
+		DeniedCountries  []string
+
+		// Padded marks content that was padded to a bucket size before it was encrypted.
+		Padded bool
+
+		// RecipientEmail is where verification codes are sent. If set, the recipient must present
+		// a code sent to this address to access the secret.
+		RecipientEmail string
+
+		// Creator is the subject of the SSO identity that created the secret, if it was created with one.
+		// The creator owns the secret as the holder of its owner token does.
+		Creator string
+
+		// CreatedEpoch is when the secret was created. Secrets stored before creation times were recorded have none.
+		CreatedEpoch int64
+	}
+
+	// OwnerCredentials carries what a caller presents to prove it owns a secret: its owner token,
+	// or the subject of the SSO identity it was created with.
+	OwnerCredentials struct {
+		OwnerToken string
+		Subject    string
+	}
+
+	// AccessRequest carries what a caller presents to access a secret.
+	AccessRequest struct {
+		ID         string
+		OwnerToken string
+
+		// Key is the per-secret key of a split-key secret.
+		Key string
+
+		// VerificationCode is the code sent to the recipient of a secret that requires recipient verification.
+		VerificationCode string
+
+		Client Client
+	}
+
+	// Client describes where a request to access a secret comes from.
+	Client struct {
+		IP string
+
+		// Country is the ISO 3166-1 alpha-2 code resolved for the IP, if known.
+		Country string
 	}
 
 	SecretMetadata struct {
-		ID          string
-		ContentType ContentType
-		Filename    string
-		AccessCount int
-		AccessLimit int
-		Expiration  FormattedTime
+		// OwnerToken is only set when the secret is created, as only its hash is stored.
+		OwnerToken        string
+		ID                string
+		ContentType       ContentType
+		Filename          string
+		AccessCount       int
+		AccessLimit       int
+		Expiration        FormattedTime
+		AvailableFrom     *FormattedTime
+		ViewWindowSeconds int
+		ViewWindowStarted bool
+		BundleItems       []BundleItem
+		SplitKey          bool
+		AllowedCIDRs      []string
+		AllowedCountries  []string
+		DeniedCountries   []string
+
+		// RecipientVerification is set when a code sent to the recipient is required to access the secret.
+		RecipientVerification bool
+
+		// Key is only set when a split-key secret is created, as it is never stored.
+		Key string
 	}
 
 	SecretContentResponse struct {
 		ID      string `json:"id" example:"22b6fff1be15d1fd54b7b8ec6ad22e80e66275195c914c4b0f9652248a498680"`
 		Content string `json:"content" example:"my very secret text"`
 	}
+
+	// SecretKVContentResponse returns the fields of a kv secret as a JSON object.
+	SecretKVContentResponse struct {
+		ID          string            `json:"id" example:"22b6fff1be15d1fd54b7b8ec6ad22e80e66275195c914c4b0f9652248a498680"`
+		ContentType ContentType       `json:"content_type" swaggertype:"string" example:"kv"`
+		Content     map[string]string `json:"content"`
+	}
 )
 
 func (secret *Secret) Expiration() FormattedTime {
@@ -68,13 +190,62 @@ func (secret *Secret) Duration() time.Duration {
 	return time.Until(secret.Expiration().Time().UTC())
 }
 
+// AvailableFrom returns when the secret can first be accessed, or nil if it is available immediately.
+func (secret *Secret) AvailableFrom() *FormattedTime {
+	if secret.AvailableFromEpoch <= 0 {
+		return nil
+	}
+	availableFrom := FormattedTime(time.Unix(secret.AvailableFromEpoch, 0).UTC())
+	return &availableFrom
+}
+
+// IsAvailable reports whether the secret can be accessed at the given time.
+func (secret *Secret) IsAvailable(at time.Time) bool {
+	return secret.AvailableFromEpoch <= at.Unix()
+}
+
+// ViewWindowStarted reports whether the secret has a view window that was started by its first access.
+// Once started, the secret expires when the window ends.
+func (secret *Secret) ViewWindowStarted() bool {
+	return secret.ViewWindowSeconds > 0 && secret.AccessCount > 0
+}
+
+// ViewWindowExpirationEpoch returns when a view window starting at the given time ends.
+// The window never extends the secret past its original expiration.
+func (secret *Secret) ViewWindowExpirationEpoch(start time.Time) int64 {
+	end := start.Add(time.Duration(secret.ViewWindowSeconds) * time.Second).Unix()
+	if end > secret.ExpirationEpoch {
+		return secret.ExpirationEpoch
+	}
+	return end
+}
+
 func (secret *Secret) Metadata() *SecretMetadata {
 	return &SecretMetadata{
-		ID:          secret.ID,
-		ContentType: ContentType(secret.ContentType),
-		Filename:    secret.Filename,
-		AccessCount: secret.AccessCount,
-		AccessLimit: secret.AccessLimit,
-		Expiration:  secret.Expiration(),
+		ID:                secret.ID,
+		ContentType:       ContentType(secret.ContentType),
+		Filename:          secret.Filename,
+		AccessCount:       secret.AccessCount,
+		AccessLimit:       secret.AccessLimit,
+		Expiration:        secret.Expiration(),
+		AvailableFrom:     secret.AvailableFrom(),
+		ViewWindowSeconds: secret.ViewWindowSeconds,
+		ViewWindowStarted: secret.ViewWindowStarted(),
+		BundleItems:       secret.BundleItems,
+		SplitKey:          secret.SplitKey,
+		AllowedCIDRs:      secret.AllowedCIDRs,
+		AllowedCountries:  secret.AllowedCountries,
+		DeniedCountries:   secret.DeniedCountries,
+
+		RecipientVerification: secret.RecipientEmail != "",
+	}
+}
+
+// ViewWindowEnds returns when the started view window ends, or nil if the window has not started.
+func (metadata *SecretMetadata) ViewWindowEnds() *FormattedTime {
+	if !metadata.ViewWindowStarted {
+		return nil
 	}
+	ends := metadata.Expiration
+	return &ends
 }
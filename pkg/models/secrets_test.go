@@ -0,0 +1,88 @@
+package models_test
+
+import (
+	"cellar/pkg/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWhenCalculatingViewWindowExpiration(t *testing.T) {
+	start := time.Unix(1_000_000, 0)
+
+	t.Run("and the window ends before the secret expires", func(t *testing.T) {
+		secret := models.Secret{ExpirationEpoch: start.Add(time.Hour).Unix(), ViewWindowSeconds: 900}
+
+		t.Run("it should return the end of the window", func(t *testing.T) {
+			assert.Equal(t, start.Add(15*time.Minute).Unix(), secret.ViewWindowExpirationEpoch(start))
+		})
+	})
+
+	t.Run("and the window ends after the secret expires", func(t *testing.T) {
+		secret := models.Secret{ExpirationEpoch: start.Add(time.Minute).Unix(), ViewWindowSeconds: 900}
+
+		t.Run("it should return the original expiration", func(t *testing.T) {
+			assert.Equal(t, secret.ExpirationEpoch, secret.ViewWindowExpirationEpoch(start))
+		})
+	})
+}
+
+func TestWhenGettingSecretMetadata(t *testing.T) {
+	t.Run("and the view window has started", func(t *testing.T) {
+		secret := models.Secret{ExpirationEpoch: 1_000_000, AccessCount: 1, ViewWindowSeconds: 900}
+		metadata := secret.Metadata()
+
+		t.Run("it should report the window as started", func(t *testing.T) {
+			assert.True(t, metadata.ViewWindowStarted)
+		})
+
+		t.Run("it should report the window ending at the expiration", func(t *testing.T) {
+			if assert.NotNil(t, metadata.ViewWindowEnds()) {
+				assert.Equal(t, secret.Expiration(), *metadata.ViewWindowEnds())
+			}
+		})
+	})
+
+	t.Run("and the view window has not started", func(t *testing.T) {
+		secret := models.Secret{ExpirationEpoch: 1_000_000, ViewWindowSeconds: 900}
+		metadata := secret.Metadata()
+
+		t.Run("it should not report the window as started", func(t *testing.T) {
+			assert.False(t, metadata.ViewWindowStarted)
+			assert.Nil(t, metadata.ViewWindowEnds())
+		})
+	})
+}
+
+func TestWhenCheckingSecretAvailability(t *testing.T) {
+	now := time.Unix(1_000_000, 0)
+
+	t.Run("and available from is not set", func(t *testing.T) {
+		secret := models.Secret{}
+
+		t.Run("it should be available", func(t *testing.T) {
+			assert.True(t, secret.IsAvailable(now))
+		})
+
+		t.Run("it should not report an activation time", func(t *testing.T) {
+			assert.Nil(t, secret.AvailableFrom())
+		})
+	})
+
+	t.Run("and available from is in the future", func(t *testing.T) {
+		secret := models.Secret{AvailableFromEpoch: now.Add(time.Minute).Unix()}
+
+		t.Run("it should not be available", func(t *testing.T) {
+			assert.False(t, secret.IsAvailable(now))
+		})
+	})
+
+	t.Run("and available from is in the past", func(t *testing.T) {
+		secret := models.Secret{AvailableFromEpoch: now.Add(-time.Minute).Unix()}
+
+		t.Run("it should be available", func(t *testing.T) {
+			assert.True(t, secret.IsAvailable(now))
+		})
+	})
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+)
+
+type (
+	// SplitSecret tracks the shares of a secret split with Shamir's secret sharing.
+	// Each share is stored as its own secret; Threshold of them reconstruct the content.
+	// Shares are tracked by their key IDs, so the split never holds what is needed to access them.
+	SplitSecret struct {
+		ID              string
+		ContentType     string
+		Filename        string
+		Threshold       int
+		ShareKeys       []string
+		ExpirationEpoch int64
+	}
+
+	// SplitSecretShare is a share created with a split secret. The owner token manages the share's secret.
+	SplitSecretShare struct {
+		ID         string `json:"id" example:"22b6fff1be15d1fd54b7b8ec6ad22e80e66275195c914c4b0f9652248a498680"`
+		OwnerToken string `json:"owner_token,omitempty" example:"60303ae22b998861bce3b28f33eec1be758a213c86c93c076dbe9f558c11c752"`
+	}
+
+	SplitSecretMetadata struct {
+		ID          string
+		ContentType ContentType
+		Threshold   int
+		ShareCount  int
+		Shares      []SplitSecretShare
+		Expiration  FormattedTime
+	}
+
+	CreateSplitSecretResponse struct {
+		ID          string             `json:"id" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+		ContentType ContentType        `json:"content_type" swaggertype:"string" example:"text"`
+		Threshold   int                `json:"threshold" example:"2"`
+		Shares      []SplitSecretShare `json:"shares"`
+		Expiration  FormattedTime      `json:"expiration" swaggertype:"string" example:"1970-01-01 00:00:00 UTC"`
+	}
+
+	SplitSecretMetadataResponse struct {
+		ID          string        `json:"id" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+		ContentType ContentType   `json:"content_type" swaggertype:"string" example:"text"`
+		Threshold   int           `json:"threshold" example:"2"`
+		ShareCount  int           `json:"share_count" example:"3"`
+		Expiration  FormattedTime `json:"expiration" swaggertype:"string" example:"1970-01-01 00:00:00 UTC"`
+	}
+)
+
+func (split *SplitSecret) Expiration() FormattedTime {
+	return FormattedTime(time.Unix(split.ExpirationEpoch, 0).UTC())
+}
+
+func (split *SplitSecret) Duration() time.Duration {
+	return time.Until(split.Expiration().Time().UTC())
+}
+
+// HasShareKey reports whether the secret stored under the given key ID is one of the split's shares.
+func (split *SplitSecret) HasShareKey(shareKey string) bool {
+	for _, key := range split.ShareKeys {
+		if key == shareKey {
+			return true
+		}
+	}
+	return false
+}
+
+func (split *SplitSecret) Metadata() *SplitSecretMetadata {
+	return &SplitSecretMetadata{
+		ID:          split.ID,
+		ContentType: ContentType(split.ContentType),
+		Threshold:   split.Threshold,
+		ShareCount:  len(split.ShareKeys),
+		Expiration:  split.Expiration(),
+	}
+}
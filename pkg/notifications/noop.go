@@ -0,0 +1,17 @@
+package notifications
+
+import (
+	"cellar/pkg/models"
+	"context"
+)
+
+// NoopNotifier discards all notifications. It is used when no notification channel is enabled.
+type NoopNotifier struct{}
+
+func NewNoopNotifier() *NoopNotifier {
+	return &NoopNotifier{}
+}
+
+func (notifier NoopNotifier) Notify(_ context.Context, _ models.Notification) error {
+	return nil
+}
@@ -0,0 +1,13 @@
+package notifications
+
+import (
+	"cellar/pkg/models"
+	"context"
+)
+
+var Key = "NOTIFIER"
+
+//go:generate mockgen -destination=../mocks/mock_notifier.go -package=mocks . Notifier
+type Notifier interface {
+	Notify(ctx context.Context, notification models.Notification) error
+}
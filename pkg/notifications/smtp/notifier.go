@@ -0,0 +1,199 @@
+package smtp
+
+import (
+	pkgerrors "cellar/pkg/errors"
+	"cellar/pkg/models"
+	"cellar/pkg/settings/notifications"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrQueueFull is returned when a notification cannot be queued because the send queue is at capacity.
+var ErrQueueFull = errors.New("notification queue is full")
+
+// ErrNotifierClosed is returned when a notification is queued after the notifier has been closed.
+var ErrNotifierClosed = errors.New("notifier is closed")
+
+type (
+	Notifier struct {
+		configuration notifications.ISmtpConfiguration
+		templates     map[models.NotificationType]messageTemplate
+		queue         chan envelope
+		done          chan struct{}
+		mutex         sync.RWMutex
+		closed        bool
+		logger        *log.Entry
+	}
+
+	envelope struct {
+		recipient string
+		message   []byte
+	}
+)
+
+// NewNotifier creates an SMTP notifier and starts its background send queue.
+// Notifications are rendered synchronously and delivered asynchronously so request handling is never blocked on the mail server.
+func NewNotifier(configuration notifications.ISmtpConfiguration) (*Notifier, error) {
+	if err := configuration.Validate(); err != nil {
+		return nil, err
+	}
+
+	notifier := &Notifier{
+		configuration: configuration,
+		templates:     newMessageTemplates(),
+		queue:         make(chan envelope, configuration.QueueSize()),
+		done:          make(chan struct{}),
+		logger:        initializeLogger(configuration),
+	}
+	go notifier.run()
+
+	return notifier, nil
+}
+
+func initializeLogger(configuration notifications.ISmtpConfiguration) *log.Entry {
+	logger := log.WithFields(log.Fields{
+		"context":  "notifications",
+		"instance": "smtp",
+		"address":  fmt.Sprintf("%s:%d", configuration.Host(), configuration.Port()),
+	})
+
+	logger.Debug("initializing smtp configuration")
+	if configuration.Username() == "" {
+		logger.Warn("smtp username is empty, sending without authentication")
+	}
+
+	return logger
+}
+
+// Notify renders the notification and places it on the send queue.
+// Returns ErrQueueFull if the queue is at capacity rather than blocking the caller.
+func (notifier *Notifier) Notify(ctx context.Context, notification models.Notification) error {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return err
+	}
+
+	tmpl, ok := notifier.templates[notification.Type]
+	if !ok {
+		return fmt.Errorf("unknown notification type '%s'", notification.Type)
+	}
+
+	text, html, err := tmpl.render(notification)
+	if err != nil {
+		return err
+	}
+
+	message, err := buildMessage(notifier.configuration.From(), notification.Recipient, tmpl.subject, text, html)
+	if err != nil {
+		return err
+	}
+
+	notifier.mutex.RLock()
+	defer notifier.mutex.RUnlock()
+	if notifier.closed {
+		return ErrNotifierClosed
+	}
+
+	select {
+	case notifier.queue <- envelope{recipient: notification.Recipient, message: message}:
+		notifier.logger.WithField("notificationType", notification.Type).Debug("queued notification")
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Close stops accepting notifications and waits for queued notifications to be sent.
+func (notifier *Notifier) Close() {
+	notifier.mutex.Lock()
+	if notifier.closed {
+		notifier.mutex.Unlock()
+		return
+	}
+	notifier.closed = true
+	close(notifier.queue)
+	notifier.mutex.Unlock()
+
+	<-notifier.done
+}
+
+func (notifier *Notifier) run() {
+	defer close(notifier.done)
+
+	for envelope := range notifier.queue {
+		if err := notifier.send(envelope); err != nil {
+			notifier.logger.WithError(err).Error("error sending notification")
+		} else {
+			notifier.logger.Debug("notification sent")
+		}
+	}
+}
+
+// send delivers a message the way smtp.SendMail does, upgrading to TLS when the server offers it,
+// but over a connection with a deadline, so a mail server that stops responding fails the send
+// instead of blocking the queue.
+func (notifier *Notifier) send(envelope envelope) error {
+	host := notifier.configuration.Host()
+	address := net.JoinHostPort(host, strconv.Itoa(notifier.configuration.Port()))
+	timeout := time.Duration(notifier.configuration.TimeoutSeconds()) * time.Second
+
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return err
+	}
+	if err = conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer func() { _ = client.Close() }()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err = client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+
+	if notifier.configuration.Username() != "" {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return errors.New("smtp server does not support authentication")
+		}
+		auth := smtp.PlainAuth("", notifier.configuration.Username(), notifier.configuration.Password(), host)
+		if err = client.Auth(auth); err != nil {
+			return err
+		}
+	}
+
+	if err = client.Mail(notifier.configuration.From()); err != nil {
+		return err
+	}
+	if err = client.Rcpt(envelope.recipient); err != nil {
+		return err
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = writer.Write(envelope.message); err != nil {
+		return err
+	}
+	if err = writer.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
@@ -0,0 +1,231 @@
+package smtp_test
+
+import (
+	"cellar/pkg/mocks"
+	"cellar/pkg/models"
+	"cellar/pkg/notifications/smtp"
+	"cellar/testing/testhelpers"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSmtpNotifier(t *testing.T) {
+	setup := func(t *testing.T, server *testhelpers.SmtpServer, queueSize int) *smtp.Notifier {
+		ctrl := gomock.NewController(t)
+		configuration := mocks.NewMockISmtpConfiguration(ctrl)
+		configuration.EXPECT().Validate().Return(nil).AnyTimes()
+		configuration.EXPECT().Host().Return(server.Host()).AnyTimes()
+		configuration.EXPECT().Port().Return(server.Port()).AnyTimes()
+		configuration.EXPECT().Username().Return("").AnyTimes()
+		configuration.EXPECT().Password().Return("").AnyTimes()
+		configuration.EXPECT().From().Return("cellar@example.com").AnyTimes()
+		configuration.EXPECT().QueueSize().Return(queueSize).AnyTimes()
+		configuration.EXPECT().TimeoutSeconds().Return(1).AnyTimes()
+
+		notifier, err := smtp.NewNotifier(configuration)
+		require.NoError(t, err)
+		t.Cleanup(notifier.Close)
+
+		return notifier
+	}
+
+	t.Run("when notifying that a secret was accessed", func(t *testing.T) {
+		server := testhelpers.NewSmtpServer(t)
+		notifier := setup(t, server, 10)
+
+		err := notifier.Notify(context.Background(), models.Notification{
+			Type:        models.NotificationSecretAccessed,
+			Recipient:   "sender@example.com",
+			Reference:   models.SecretReference("22b6fff1be15d1fd54b7b8ec6ad22e80e66275195c914c4b0f9652248a498680"),
+			AccessCount: 1,
+			AccessLimit: 5,
+			OccurredAt:  time.Now(),
+		})
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool { return len(server.Messages()) == 1 }, 5*time.Second, 10*time.Millisecond)
+		message := server.Messages()[0]
+
+		t.Run("it should send from the configured address", func(t *testing.T) {
+			assert.Equal(t, "cellar@example.com", message.From)
+		})
+
+		t.Run("it should send to the recipient", func(t *testing.T) {
+			assert.Equal(t, []string{"sender@example.com"}, message.To)
+		})
+
+		t.Run("it should include a plain-text part", func(t *testing.T) {
+			assert.Contains(t, message.Data, "Content-Type: text/plain; charset=UTF-8")
+		})
+
+		t.Run("it should include an html part", func(t *testing.T) {
+			assert.Contains(t, message.Data, "Content-Type: text/html; charset=UTF-8")
+		})
+
+		t.Run("it should reference the secret by a shortened id", func(t *testing.T) {
+			assert.Contains(t, message.Data, "22b6fff1")
+			assert.NotContains(t, message.Data, "22b6fff1be15d1fd54b7b8ec6ad22e80e66275195c914c4b0f9652248a498680")
+		})
+
+		t.Run("it should include the access count", func(t *testing.T) {
+			assert.Contains(t, message.Data, "1 of 5 allowed times")
+		})
+	})
+
+	t.Run("when notifying that a secret expired", func(t *testing.T) {
+		server := testhelpers.NewSmtpServer(t)
+		notifier := setup(t, server, 10)
+
+		err := notifier.Notify(context.Background(), models.Notification{
+			Type:       models.NotificationSecretExpired,
+			Recipient:  "sender@example.com",
+			Reference:  models.SecretReference(testhelpers.RandomId(t)),
+			OccurredAt: time.Now(),
+		})
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool { return len(server.Messages()) == 1 }, 5*time.Second, 10*time.Millisecond)
+
+		t.Run("it should use the expiry template", func(t *testing.T) {
+			assert.Contains(t, server.Messages()[0].Data, "without being opened")
+		})
+	})
+
+	t.Run("when sending a verification code", func(t *testing.T) {
+		server := testhelpers.NewSmtpServer(t)
+		notifier := setup(t, server, 10)
+
+		err := notifier.Notify(context.Background(), models.Notification{
+			Type:       models.NotificationVerificationCode,
+			Recipient:  "recipient@example.com",
+			Reference:  models.SecretReference(testhelpers.RandomId(t)),
+			Code:       "042137",
+			OccurredAt: time.Now(),
+			ExpiresAt:  time.Now().Add(10 * time.Minute),
+		})
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool { return len(server.Messages()) == 1 }, 5*time.Second, 10*time.Millisecond)
+		message := server.Messages()[0]
+
+		t.Run("it should send to the recipient", func(t *testing.T) {
+			assert.Equal(t, []string{"recipient@example.com"}, message.To)
+		})
+
+		t.Run("it should include the code", func(t *testing.T) {
+			assert.Contains(t, message.Data, "Your verification code is: 042137")
+		})
+	})
+
+	t.Run("when notification type is unknown", func(t *testing.T) {
+		server := testhelpers.NewSmtpServer(t)
+		notifier := setup(t, server, 10)
+
+		err := notifier.Notify(context.Background(), models.Notification{
+			Type:      "unknown",
+			Recipient: "sender@example.com",
+		})
+
+		t.Run("it should return error", func(t *testing.T) {
+			assert.Error(t, err)
+		})
+	})
+
+	t.Run("when notifier is closed", func(t *testing.T) {
+		server := testhelpers.NewSmtpServer(t)
+		notifier := setup(t, server, 10)
+
+		for i := 0; i < 3; i++ {
+			require.NoError(t, notifier.Notify(context.Background(), models.Notification{
+				Type:       models.NotificationSecretExpired,
+				Recipient:  "sender@example.com",
+				Reference:  models.SecretReference(testhelpers.RandomId(t)),
+				OccurredAt: time.Now(),
+			}))
+		}
+		notifier.Close()
+
+		t.Run("it should deliver queued notifications before returning", func(t *testing.T) {
+			assert.Len(t, server.Messages(), 3)
+		})
+
+		t.Run("it should reject new notifications", func(t *testing.T) {
+			err := notifier.Notify(context.Background(), models.Notification{
+				Type:      models.NotificationSecretExpired,
+				Recipient: "sender@example.com",
+			})
+			assert.ErrorIs(t, err, smtp.ErrNotifierClosed)
+		})
+	})
+
+	t.Run("when the mail server stops responding", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = listener.Close() })
+		go func() {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				t.Cleanup(func() { _ = conn.Close() })
+			}
+		}()
+
+		ctrl := gomock.NewController(t)
+		configuration := mocks.NewMockISmtpConfiguration(ctrl)
+		configuration.EXPECT().Validate().Return(nil).AnyTimes()
+		configuration.EXPECT().Host().Return("127.0.0.1").AnyTimes()
+		configuration.EXPECT().Port().Return(listener.Addr().(*net.TCPAddr).Port).AnyTimes()
+		configuration.EXPECT().Username().Return("").AnyTimes()
+		configuration.EXPECT().From().Return("cellar@example.com").AnyTimes()
+		configuration.EXPECT().QueueSize().Return(10).AnyTimes()
+		configuration.EXPECT().TimeoutSeconds().Return(1).AnyTimes()
+
+		notifier, err := smtp.NewNotifier(configuration)
+		require.NoError(t, err)
+
+		require.NoError(t, notifier.Notify(context.Background(), models.Notification{
+			Type:       models.NotificationSecretExpired,
+			Recipient:  "sender@example.com",
+			Reference:  models.SecretReference(testhelpers.RandomId(t)),
+			OccurredAt: time.Now(),
+		}))
+
+		closed := make(chan struct{})
+		go func() {
+			notifier.Close()
+			close(closed)
+		}()
+
+		t.Run("it should give up on the send after the timeout", func(t *testing.T) {
+			select {
+			case <-closed:
+			case <-time.After(5 * time.Second):
+				t.Fatal("the notifier is still waiting on the mail server")
+			}
+		})
+	})
+
+	t.Run("when context is cancelled", func(t *testing.T) {
+		server := testhelpers.NewSmtpServer(t)
+		notifier := setup(t, server, 10)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		t.Run("it should return context error", func(t *testing.T) {
+			err := notifier.Notify(ctx, models.Notification{
+				Type:      models.NotificationSecretAccessed,
+				Recipient: "sender@example.com",
+			})
+			assert.Error(t, err)
+		})
+	})
+}
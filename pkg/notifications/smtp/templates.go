@@ -0,0 +1,134 @@
+package smtp
+
+import (
+	"bytes"
+	"cellar/pkg/models"
+	"fmt"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+	"time"
+)
+
+type (
+	messageTemplate struct {
+		subject string
+		text    *texttemplate.Template
+		html    *htmltemplate.Template
+	}
+
+	templateData struct {
+		Reference   string
+		AccessCount int
+		AccessLimit int
+		OccurredAt  string
+		Code        string
+		ExpiresAt   string
+	}
+)
+
+const secretAccessedText = `Hello,
+
+Your secret {{.Reference}} was opened on {{.OccurredAt}}.
+{{if .AccessLimit}}It has now been opened {{.AccessCount}} of {{.AccessLimit}} allowed times.{{else}}It has now been opened {{.AccessCount}} times.{{end}}
+
+If you did not expect this secret to be opened, rotate any credentials it contained.
+
+Cellar
+`
+
+const secretAccessedHtml = `<!DOCTYPE html>
+<html>
+<body>
+<p>Hello,</p>
+<p>Your secret <code>{{.Reference}}</code> was opened on {{.OccurredAt}}.</p>
+<p>{{if .AccessLimit}}It has now been opened {{.AccessCount}} of {{.AccessLimit}} allowed times.{{else}}It has now been opened {{.AccessCount}} times.{{end}}</p>
+<p>If you did not expect this secret to be opened, rotate any credentials it contained.</p>
+<p>Cellar</p>
+</body>
+</html>
+`
+
+const secretExpiredText = `Hello,
+
+Your secret {{.Reference}} expired on {{.OccurredAt}} without being opened.
+
+The recipient never viewed its content. You may need to share it again.
+
+Cellar
+`
+
+const secretExpiredHtml = `<!DOCTYPE html>
+<html>
+<body>
+<p>Hello,</p>
+<p>Your secret <code>{{.Reference}}</code> expired on {{.OccurredAt}} without being opened.</p>
+<p>The recipient never viewed its content. You may need to share it again.</p>
+<p>Cellar</p>
+</body>
+</html>
+`
+
+const verificationCodeText = `Hello,
+
+Someone is trying to open the Cellar secret {{.Reference}} that was shared with you.
+
+Your verification code is: {{.Code}}
+
+The code can be used once and expires on {{.ExpiresAt}}.
+If you did not try to open this secret, you can ignore this message.
+
+Cellar
+`
+
+const verificationCodeHtml = `<!DOCTYPE html>
+<html>
+<body>
+<p>Hello,</p>
+<p>Someone is trying to open the Cellar secret <code>{{.Reference}}</code> that was shared with you.</p>
+<p>Your verification code is: <strong>{{.Code}}</strong></p>
+<p>The code can be used once and expires on {{.ExpiresAt}}.<br>If you did not try to open this secret, you can ignore this message.</p>
+<p>Cellar</p>
+</body>
+</html>
+`
+
+func newMessageTemplates() map[models.NotificationType]messageTemplate {
+	return map[models.NotificationType]messageTemplate{
+		models.NotificationSecretAccessed: {
+			subject: "Your Cellar secret was opened",
+			text:    texttemplate.Must(texttemplate.New("accessed.txt").Parse(secretAccessedText)),
+			html:    htmltemplate.Must(htmltemplate.New("accessed.html").Parse(secretAccessedHtml)),
+		},
+		models.NotificationSecretExpired: {
+			subject: "Your Cellar secret expired unread",
+			text:    texttemplate.Must(texttemplate.New("expired.txt").Parse(secretExpiredText)),
+			html:    htmltemplate.Must(htmltemplate.New("expired.html").Parse(secretExpiredHtml)),
+		},
+		models.NotificationVerificationCode: {
+			subject: "Your Cellar verification code",
+			text:    texttemplate.Must(texttemplate.New("verification.txt").Parse(verificationCodeText)),
+			html:    htmltemplate.Must(htmltemplate.New("verification.html").Parse(verificationCodeHtml)),
+		},
+	}
+}
+
+func (tmpl messageTemplate) render(notification models.Notification) (text []byte, html []byte, err error) {
+	data := templateData{
+		Reference:   notification.Reference,
+		AccessCount: notification.AccessCount,
+		AccessLimit: notification.AccessLimit,
+		OccurredAt:  notification.OccurredAt.UTC().Format(time.RFC1123),
+		Code:        notification.Code,
+		ExpiresAt:   notification.ExpiresAt.UTC().Format(time.RFC1123),
+	}
+
+	var textBuffer, htmlBuffer bytes.Buffer
+	if err = tmpl.text.Execute(&textBuffer, data); err != nil {
+		return nil, nil, fmt.Errorf("rendering plain-text template: %w", err)
+	}
+	if err = tmpl.html.Execute(&htmlBuffer, data); err != nil {
+		return nil, nil, fmt.Errorf("rendering html template: %w", err)
+	}
+
+	return textBuffer.Bytes(), htmlBuffer.Bytes(), nil
+}
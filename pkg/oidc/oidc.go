@@ -0,0 +1,262 @@
+// Package oidc verifies the JWTs an OpenID Connect provider issues to users, against the provider's published keys.
+package oidc
+
+import (
+	"cellar/pkg/models"
+	"cellar/pkg/settings"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
+)
+
+// Key holds the verifier of tokens.
+var Key = "OIDC_VERIFIER"
+
+// IdentityKey holds the identity a request was authenticated as, if any.
+var IdentityKey = "OIDC_IDENTITY"
+
+// ErrInvalidToken is returned when a token is malformed, not signed by a known key, or its claims are not valid.
+var ErrInvalidToken = errors.New("invalid token")
+
+const (
+	// leeway allows for clock skew between the issuer and the server when checking exp, nbf and iat.
+	leeway = time.Minute
+	// refreshInterval is the least time between fetches for a key that is not in the cached set,
+	// so tokens naming unknown keys cannot make the server fetch the set on every request.
+	refreshInterval = 30 * time.Second
+	// maxJWKSSize bounds the size of a fetched key set.
+	maxJWKSSize = 1 << 20
+)
+
+var signatureAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.EdDSA,
+}
+
+//go:generate mockgen -destination=../mocks/mock_oidc_verifier.go -package=mocks . Verifier
+type Verifier interface {
+	// Verify checks a token's signature and claims and returns the identity it was issued for.
+	Verify(ctx context.Context, token string) (*models.Identity, error)
+}
+
+// JWKSVerifier verifies tokens against a key set fetched from the issuer, or read from a local file, and cached.
+type JWKSVerifier struct {
+	config settings.IOIDCConfiguration
+	client *http.Client
+	logger *log.Entry
+
+	cached    atomic.Pointer[cachedKeys]
+	refreshes singleflight.Group
+}
+
+// cachedKeys is a loaded key set and when it was loaded.
+type cachedKeys struct {
+	keys      *jose.JSONWebKeySet
+	fetchedAt time.Time
+}
+
+// NewJWKSVerifier returns an error if OIDC is enabled without an issuer and an audience,
+// as tokens issued by any issuer or for any other service would be accepted.
+func NewJWKSVerifier(config settings.IOIDCConfiguration) (*JWKSVerifier, error) {
+	if config.Enabled() {
+		if config.Issuer() == "" {
+			return nil, errors.New("an issuer is required when OIDC is enabled")
+		}
+		if config.Audience() == "" {
+			return nil, errors.New("an audience is required when OIDC is enabled")
+		}
+	}
+
+	return &JWKSVerifier{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second},
+		logger: log.WithField("context", "oidc"),
+	}, nil
+}
+
+func (verifier *JWKSVerifier) Verify(ctx context.Context, raw string) (*models.Identity, error) {
+	token, err := jwt.ParseSigned(raw, signatureAlgorithms)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if len(token.Headers) != 1 {
+		return nil, fmt.Errorf("%w: token must have exactly one signature", ErrInvalidToken)
+	}
+
+	key, err := verifier.key(ctx, token.Headers[0].KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims jwt.Claims
+	var extra map[string]any
+	if err = token.Claims(key, &claims, &extra); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if claims.Expiry == nil {
+		return nil, fmt.Errorf("%w: token has no expiry", ErrInvalidToken)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: token has no subject", ErrInvalidToken)
+	}
+
+	expected := jwt.Expected{
+		Issuer:      verifier.config.Issuer(),
+		AnyAudience: jwt.Audience{verifier.config.Audience()},
+		Time:        time.Now(),
+	}
+	if err = claims.ValidateWithLeeway(expected, leeway); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	tenant, _ := extra[verifier.config.TenantClaim()].(string)
+	return &models.Identity{
+		Subject: claims.Subject,
+		Groups:  groups(extra[verifier.config.GroupsClaim()]),
+		Tenant:  tenant,
+	}, nil
+}
+
+// key returns the signing key with the given ID. A token without a key ID can only be verified
+// if the set holds a single signing key. The set is fetched again once its cache expires,
+// or when a token names a key that is not in it, such as after the issuer rotated its keys.
+func (verifier *JWKSVerifier) key(ctx context.Context, keyId string) (*jose.JSONWebKey, error) {
+	cached := verifier.cached.Load()
+
+	cacheTime := time.Duration(verifier.config.JWKSCacheSeconds()) * time.Second
+	if cached == nil || time.Since(cached.fetchedAt) > cacheTime {
+		refreshed, err := verifier.refresh(ctx)
+		if err != nil && cached == nil {
+			return nil, err
+		}
+		if err == nil {
+			cached = refreshed
+		}
+	}
+
+	key := findKey(cached.keys, keyId)
+	if key == nil && time.Since(cached.fetchedAt) > refreshInterval {
+		if refreshed, err := verifier.refresh(ctx); err == nil {
+			key = findKey(refreshed.keys, keyId)
+		}
+	}
+	if key == nil {
+		return nil, fmt.Errorf("%w: token is signed with an unknown key", ErrInvalidToken)
+	}
+	return key, nil
+}
+
+// refresh loads the key set. If it cannot be loaded, the keys loaded before are kept.
+// Concurrent refreshes share a single load, which is not cancelled with the request that started it,
+// and requests holding keys that are still valid are not held up while it runs.
+func (verifier *JWKSVerifier) refresh(ctx context.Context) (*cachedKeys, error) {
+	refreshed, err, _ := verifier.refreshes.Do("keys", func() (any, error) {
+		keys, err := verifier.load(context.WithoutCancel(ctx))
+		if err != nil {
+			verifier.logger.WithError(err).Error("error loading the OIDC key set")
+			return nil, err
+		}
+
+		cached := &cachedKeys{keys: keys, fetchedAt: time.Now()}
+		verifier.cached.Store(cached)
+		verifier.logger.WithField("keys", len(keys.Keys)).Debug("loaded the OIDC key set")
+		return cached, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return refreshed.(*cachedKeys), nil
+}
+
+func (verifier *JWKSVerifier) load(ctx context.Context) (*jose.JSONWebKeySet, error) {
+	var content []byte
+	var err error
+	if path := verifier.config.JWKSFile(); path != "" {
+		content, err = os.ReadFile(path)
+	} else {
+		content, err = verifier.fetch(ctx, verifier.config.JWKSURL())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var keys jose.JSONWebKeySet
+	if err = json.Unmarshal(content, &keys); err != nil {
+		return nil, fmt.Errorf("error parsing the key set: %w", err)
+	}
+	return &keys, nil
+}
+
+func (verifier *JWKSVerifier) fetch(ctx context.Context, url string) ([]byte, error) {
+	if url == "" {
+		return nil, errors.New("neither a JWKS URL nor a JWKS file is configured")
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := verifier.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching the key set returned status %d", response.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(response.Body, maxJWKSSize))
+}
+
+func findKey(keys *jose.JSONWebKeySet, keyId string) *jose.JSONWebKey {
+	var signing []jose.JSONWebKey
+	for _, key := range keys.Keys {
+		if key.Use == "" || key.Use == "sig" {
+			signing = append(signing, key)
+		}
+	}
+
+	if keyId == "" {
+		if len(signing) == 1 {
+			return &signing[0]
+		}
+		return nil
+	}
+	for i := range signing {
+		if signing[i].KeyID == keyId {
+			return &signing[i]
+		}
+	}
+	return nil
+}
+
+// groups reads a groups claim, given as a list of strings or as a single string.
+func groups(claim any) []string {
+	switch value := claim.(type) {
+	case string:
+		return []string{value}
+	case []any:
+		var groups []string
+		for _, item := range value {
+			if group, ok := item.(string); ok {
+				groups = append(groups, group)
+			}
+		}
+		return groups
+	default:
+		return nil
+	}
+}
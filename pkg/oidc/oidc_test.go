@@ -0,0 +1,281 @@
+package oidc_test
+
+import (
+	"cellar/pkg/mocks"
+	"cellar/pkg/oidc"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const issuer = "https://sso.example.com"
+
+type signingKey struct {
+	private *ecdsa.PrivateKey
+	id      string
+}
+
+func newSigningKey(t *testing.T, id string) signingKey {
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return signingKey{private: private, id: id}
+}
+
+func (key signingKey) public() jose.JSONWebKey {
+	return jose.JSONWebKey{Key: &key.private.PublicKey, KeyID: key.id, Algorithm: string(jose.ES256), Use: "sig"}
+}
+
+func (key signingKey) sign(t *testing.T, claims jwt.Claims, extra map[string]any) string {
+	options := (&jose.SignerOptions{}).WithType("JWT").WithHeader(jose.HeaderKey("kid"), key.id)
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: key.private}, options)
+	require.NoError(t, err)
+	token, err := jwt.Signed(signer).Claims(claims).Claims(extra).Serialize()
+	require.NoError(t, err)
+	return token
+}
+
+func validClaims() jwt.Claims {
+	return jwt.Claims{
+		Issuer:   issuer,
+		Subject:  "alice@example.com",
+		Audience: jwt.Audience{"cellar"},
+		Expiry:   jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		IssuedAt: jwt.NewNumericDate(time.Now()),
+	}
+}
+
+func jwksFile(t *testing.T, keys ...signingKey) string {
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, jwks(t, keys...), 0o600))
+	return path
+}
+
+func jwks(t *testing.T, keys ...signingKey) []byte {
+	set := jose.JSONWebKeySet{}
+	for _, key := range keys {
+		set.Keys = append(set.Keys, key.public())
+	}
+	content, err := json.Marshal(set)
+	require.NoError(t, err)
+	return content
+}
+
+func newConfig(t *testing.T, jwksFile string, jwksURL string) *mocks.MockIOIDCConfiguration {
+	ctrl := gomock.NewController(t)
+	config := mocks.NewMockIOIDCConfiguration(ctrl)
+	config.EXPECT().Enabled().Return(true).AnyTimes()
+	config.EXPECT().Issuer().Return(issuer).AnyTimes()
+	config.EXPECT().Audience().Return("cellar").AnyTimes()
+	config.EXPECT().JWKSFile().Return(jwksFile).AnyTimes()
+	config.EXPECT().JWKSURL().Return(jwksURL).AnyTimes()
+	config.EXPECT().JWKSCacheSeconds().Return(3600).AnyTimes()
+	config.EXPECT().GroupsClaim().Return("groups").AnyTimes()
+	config.EXPECT().TenantClaim().Return("tenant").AnyTimes()
+	return config
+}
+
+func TestVerify(t *testing.T) {
+	ctx := context.Background()
+	key := newSigningKey(t, "k1")
+	sut, err := oidc.NewJWKSVerifier(newConfig(t, jwksFile(t, key), ""))
+	require.NoError(t, err)
+
+	t.Run("when the token is valid", func(t *testing.T) {
+		identity, err := sut.Verify(ctx, key.sign(t, validClaims(), map[string]any{"groups": []string{"staff", "security"}}))
+		require.NoError(t, err)
+
+		t.Run("it should return the subject and groups", func(t *testing.T) {
+			assert.Equal(t, "alice@example.com", identity.Subject)
+			assert.Equal(t, []string{"staff", "security"}, identity.Groups)
+		})
+	})
+
+	t.Run("when the groups claim is a single group", func(t *testing.T) {
+		identity, err := sut.Verify(ctx, key.sign(t, validClaims(), map[string]any{"groups": "staff"}))
+		require.NoError(t, err)
+
+		t.Run("it should return the group", func(t *testing.T) {
+			assert.Equal(t, []string{"staff"}, identity.Groups)
+		})
+	})
+
+	t.Run("when the token names a tenant", func(t *testing.T) {
+		identity, err := sut.Verify(ctx, key.sign(t, validClaims(), map[string]any{"tenant": "finance"}))
+		require.NoError(t, err)
+
+		t.Run("it should return the tenant", func(t *testing.T) {
+			assert.Equal(t, "finance", identity.Tenant)
+		})
+	})
+
+	invalid := map[string]func() string{
+		"is expired": func() string {
+			claims := validClaims()
+			claims.Expiry = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+			return key.sign(t, claims, nil)
+		},
+		"has no expiry": func() string {
+			claims := validClaims()
+			claims.Expiry = nil
+			return key.sign(t, claims, nil)
+		},
+		"is from another issuer": func() string {
+			claims := validClaims()
+			claims.Issuer = "https://evil.example.com"
+			return key.sign(t, claims, nil)
+		},
+		"is for another audience": func() string {
+			claims := validClaims()
+			claims.Audience = jwt.Audience{"another"}
+			return key.sign(t, claims, nil)
+		},
+		"has no subject": func() string {
+			claims := validClaims()
+			claims.Subject = ""
+			return key.sign(t, claims, nil)
+		},
+		"is signed with an unknown key": func() string {
+			return newSigningKey(t, "k2").sign(t, validClaims(), nil)
+		},
+		"is signed with another key under a known key id": func() string {
+			return newSigningKey(t, "k1").sign(t, validClaims(), nil)
+		},
+		"is not a JWT": func() string {
+			return "not a token"
+		},
+	}
+	for name, token := range invalid {
+		t.Run("when the token "+name, func(t *testing.T) {
+			_, err := sut.Verify(ctx, token())
+
+			t.Run("it should reject it", func(t *testing.T) {
+				assert.ErrorIs(t, err, oidc.ErrInvalidToken)
+			})
+		})
+	}
+}
+
+func TestVerifyWithFetchedKeys(t *testing.T) {
+	ctx := context.Background()
+	current := newSigningKey(t, "k1")
+
+	var fetches atomic.Int32
+	served := atomic.Pointer[[]byte]{}
+	content := jwks(t, current)
+	served.Store(&content)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		_, _ = w.Write(*served.Load())
+	}))
+	t.Cleanup(server.Close)
+
+	sut, err := oidc.NewJWKSVerifier(newConfig(t, "", server.URL))
+	require.NoError(t, err)
+
+	t.Run("when several tokens are verified", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			_, err := sut.Verify(ctx, current.sign(t, validClaims(), nil))
+			require.NoError(t, err)
+		}
+
+		t.Run("it should fetch the keys once", func(t *testing.T) {
+			assert.Equal(t, int32(1), fetches.Load())
+		})
+	})
+
+	t.Run("when a token names a key the issuer just added", func(t *testing.T) {
+		rotated := newSigningKey(t, "k2")
+		content := jwks(t, current, rotated)
+		served.Store(&content)
+		_, err := sut.Verify(ctx, rotated.sign(t, validClaims(), nil))
+
+		t.Run("it should not fetch the keys again right away", func(t *testing.T) {
+			assert.ErrorIs(t, err, oidc.ErrInvalidToken)
+			assert.Equal(t, int32(1), fetches.Load())
+		})
+	})
+}
+
+func TestVerifyConcurrently(t *testing.T) {
+	ctx := context.Background()
+	key := newSigningKey(t, "k1")
+
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		time.Sleep(100 * time.Millisecond)
+		_, _ = w.Write(jwks(t, key))
+	}))
+	t.Cleanup(server.Close)
+
+	sut, err := oidc.NewJWKSVerifier(newConfig(t, "", server.URL))
+	require.NoError(t, err)
+
+	t.Run("when tokens are verified while the keys are fetched", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := sut.Verify(ctx, key.sign(t, validClaims(), nil))
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		t.Run("it should fetch the keys once", func(t *testing.T) {
+			assert.Equal(t, int32(1), fetches.Load())
+		})
+	})
+}
+
+func TestNewJWKSVerifier(t *testing.T) {
+	newConfig := func(t *testing.T, enabled bool, issuer string, audience string) *mocks.MockIOIDCConfiguration {
+		ctrl := gomock.NewController(t)
+		config := mocks.NewMockIOIDCConfiguration(ctrl)
+		config.EXPECT().Enabled().Return(enabled).AnyTimes()
+		config.EXPECT().Issuer().Return(issuer).AnyTimes()
+		config.EXPECT().Audience().Return(audience).AnyTimes()
+		return config
+	}
+
+	t.Run("when OIDC is enabled without an issuer", func(t *testing.T) {
+		_, err := oidc.NewJWKSVerifier(newConfig(t, true, "", "cellar"))
+
+		t.Run("it should return error", func(t *testing.T) {
+			assert.Error(t, err)
+		})
+	})
+
+	t.Run("when OIDC is enabled without an audience", func(t *testing.T) {
+		_, err := oidc.NewJWKSVerifier(newConfig(t, true, issuer, ""))
+
+		t.Run("it should return error", func(t *testing.T) {
+			assert.Error(t, err)
+		})
+	})
+
+	t.Run("when OIDC is disabled", func(t *testing.T) {
+		_, err := oidc.NewJWKSVerifier(newConfig(t, false, "", ""))
+
+		t.Run("it should not return error", func(t *testing.T) {
+			assert.NoError(t, err)
+		})
+	})
+}
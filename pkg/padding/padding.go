@@ -0,0 +1,74 @@
+// Package padding rounds secret content up to one of a few bucket sizes before it is encrypted,
+// so the length of the stored ciphertext does not tell how long the secret is.
+// Only the stored size is hidden: the padding is removed before the secret is returned, so responses
+// that carry the content, and their Content-Length, still reveal its size.
+package padding
+
+import (
+	"bytes"
+	"cellar/pkg/plaintext"
+	"encoding/binary"
+	"errors"
+)
+
+// magic marks padded content and the version of the padding format.
+var magic = []byte("cpd1")
+
+// headerSize is the size of the magic followed by the content size and the padded size.
+const headerSize = 4 + 8 + 8
+
+// ErrInvalidPadding is returned when padded content was truncated, extended or otherwise does not match its header.
+var ErrInvalidPadding = errors.New("invalid padding")
+
+// Pad prefixes content with its size and the padded size, and fills it with zeros up to the smallest bucket
+// that holds it. Content larger than every bucket is rounded up to a multiple of the largest one.
+// Buckets must be sorted smallest first. The padded content is allocated with plaintext.Alloc.
+func Pad(content []byte, buckets []int) []byte {
+	size := Size(len(content), buckets)
+
+	padded := plaintext.Alloc(size)[:headerSize]
+	copy(padded, magic)
+	binary.BigEndian.PutUint64(padded[4:], uint64(len(content)))
+	binary.BigEndian.PutUint64(padded[12:], uint64(size))
+	padded = append(padded, content...)
+	return padded[:size]
+}
+
+// Unpad returns the content of padded content. Because both sizes are recorded, and the fill must be all zeros,
+// content that was cut short or had bytes appended is rejected instead of returned partially.
+func Unpad(padded []byte) ([]byte, error) {
+	if len(padded) < headerSize || !bytes.Equal(padded[:4], magic) {
+		return nil, ErrInvalidPadding
+	}
+
+	contentSize := binary.BigEndian.Uint64(padded[4:])
+	size := binary.BigEndian.Uint64(padded[12:])
+	if size != uint64(len(padded)) || contentSize > size-headerSize {
+		return nil, ErrInvalidPadding
+	}
+
+	content := padded[headerSize : headerSize+int(contentSize)]
+	for _, b := range padded[headerSize+int(contentSize):] {
+		if b != 0 {
+			return nil, ErrInvalidPadding
+		}
+	}
+	return content, nil
+}
+
+// Size returns the padded size of content of the given size.
+func Size(contentSize int, buckets []int) int {
+	size := headerSize + contentSize
+	if len(buckets) == 0 {
+		return size
+	}
+
+	for _, bucket := range buckets {
+		if size <= bucket {
+			return bucket
+		}
+	}
+
+	largest := buckets[len(buckets)-1]
+	return (size + largest - 1) / largest * largest
+}
@@ -0,0 +1,92 @@
+package padding
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var buckets = []int{256, 1024, 4096}
+
+func TestPad(t *testing.T) {
+	t.Run("when content is short", func(t *testing.T) {
+		for _, content := range []string{"", "a", "password", "correct horse battery staple"} {
+			t.Run("it should pad it to the smallest bucket", func(t *testing.T) {
+				assert.Len(t, Pad([]byte(content), buckets), 256)
+			})
+		}
+	})
+
+	t.Run("when content does not fit the smallest bucket", func(t *testing.T) {
+		t.Run("it should pad it to the next bucket", func(t *testing.T) {
+			assert.Len(t, Pad(make([]byte, 300), buckets), 1024)
+		})
+	})
+
+	t.Run("when content is larger than every bucket", func(t *testing.T) {
+		t.Run("it should round it up to a multiple of the largest bucket", func(t *testing.T) {
+			assert.Len(t, Pad(make([]byte, 5000), buckets), 8192)
+		})
+	})
+}
+
+func TestUnpad(t *testing.T) {
+	content := []byte("correct horse battery staple")
+	padded := Pad(content, buckets)
+
+	t.Run("when content is padded", func(t *testing.T) {
+		actual, err := Unpad(padded)
+		require.NoError(t, err)
+
+		t.Run("it should return the content", func(t *testing.T) {
+			assert.Equal(t, content, actual)
+		})
+	})
+
+	t.Run("when content ends in zeros", func(t *testing.T) {
+		zeros := []byte{1, 0, 0}
+		actual, err := Unpad(Pad(zeros, buckets))
+		require.NoError(t, err)
+
+		t.Run("it should keep them", func(t *testing.T) {
+			assert.Equal(t, zeros, actual)
+		})
+	})
+
+	t.Run("when padded content is truncated", func(t *testing.T) {
+		for _, size := range []int{0, headerSize - 1, headerSize + 10, len(padded) - 1} {
+			_, err := Unpad(padded[:size])
+
+			t.Run("it should reject it", func(t *testing.T) {
+				assert.ErrorIs(t, err, ErrInvalidPadding)
+			})
+		}
+	})
+
+	t.Run("when padded content is extended", func(t *testing.T) {
+		_, err := Unpad(append(append([]byte{}, padded...), 0))
+
+		t.Run("it should reject it", func(t *testing.T) {
+			assert.ErrorIs(t, err, ErrInvalidPadding)
+		})
+	})
+
+	t.Run("when the fill is changed", func(t *testing.T) {
+		changed := append([]byte{}, padded...)
+		changed[len(changed)-1] = 1
+		_, err := Unpad(changed)
+
+		t.Run("it should reject it", func(t *testing.T) {
+			assert.ErrorIs(t, err, ErrInvalidPadding)
+		})
+	})
+
+	t.Run("when content is not padded", func(t *testing.T) {
+		_, err := Unpad(content)
+
+		t.Run("it should reject it", func(t *testing.T) {
+			assert.ErrorIs(t, err, ErrInvalidPadding)
+		})
+	})
+}
@@ -0,0 +1,13 @@
+//go:build linux
+
+package plaintext
+
+import "syscall"
+
+func lock(region []byte) error {
+	return syscall.Mlock(region)
+}
+
+func unlock(region []byte) error {
+	return syscall.Munlock(region)
+}
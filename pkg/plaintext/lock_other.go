@@ -0,0 +1,15 @@
+//go:build !linux
+
+package plaintext
+
+import "errors"
+
+var errLockingUnsupported = errors.New("locking memory is only supported on linux")
+
+func lock(region []byte) error {
+	return errLockingUnsupported
+}
+
+func unlock(region []byte) error {
+	return nil
+}
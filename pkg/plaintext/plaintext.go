@@ -0,0 +1,115 @@
+// Package plaintext holds decrypted and not yet encrypted secret content in byte slices that are wiped
+// as soon as they are no longer needed, instead of strings the garbage collector leaves in memory.
+// Slices allocated here can also be locked into memory, so they are never written to swap.
+//
+// Content is passed around as plain byte slices. Whoever allocates a slice, or receives one from
+// a function documented to hand it over, wipes it once done with it.
+package plaintext
+
+import (
+	"io"
+	"os"
+	"sync"
+	"unsafe"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	mutex   sync.Mutex
+	locking bool
+	// locked maps the first byte of each locked slice to the page aligned region locked for it,
+	// so Wipe can unlock the region and let the region be collected.
+	locked = make(map[uintptr][]byte)
+)
+
+var pageSize = os.Getpagesize()
+
+// SetLocking sets whether slices are locked into memory as they are allocated.
+// Slices that cannot be locked, because the platform does not support it or the process is over its limit
+// of locked memory, are allocated as usual.
+func SetLocking(enabled bool) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	locking = enabled
+}
+
+// Alloc returns a zeroed slice of the given size, locked into memory if locking is enabled.
+// The caller wipes it once done with it.
+func Alloc(size int) []byte {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if !locking || size == 0 {
+		return make([]byte, size)
+	}
+
+	// lock whole pages of its own, so unlocking it never unlocks memory of another slice
+	region := make([]byte, size+2*pageSize)
+	offset := pageSize - int(uintptr(unsafe.Pointer(&region[0]))%uintptr(pageSize))
+	region = region[offset : offset+(size+pageSize-1)/pageSize*pageSize]
+	if err := lock(region); err != nil {
+		log.WithError(err).WithField("context", "plaintext").Debug("could not lock plaintext into memory")
+		return make([]byte, size)
+	}
+
+	locked[uintptr(unsafe.Pointer(&region[0]))] = region
+	return region[:size:size]
+}
+
+// Clone returns a copy of content allocated with Alloc. The caller wipes it once done with it.
+func Clone(content []byte) []byte {
+	clone := Alloc(len(content))
+	copy(clone, content)
+	return clone
+}
+
+// ReadFull reads exactly size bytes from reader into a slice allocated with Alloc,
+// so the content is never copied into the growing buffers io.ReadAll uses.
+// The caller wipes the slice once done with it. Nothing is returned, and nothing is left behind, on error.
+func ReadFull(reader io.Reader, size int64) ([]byte, error) {
+	content := Alloc(int(size))
+	if _, err := io.ReadFull(reader, content); err != nil {
+		Wipe(content)
+		return nil, err
+	}
+	return content, nil
+}
+
+// Wipe zeroes content up to its capacity, and unlocks it from memory if it was locked.
+// It is safe to wipe a slice more than once, and to wipe slices that were not allocated with Alloc.
+func Wipe(content []byte) {
+	if cap(content) == 0 {
+		return
+	}
+	content = content[:cap(content)]
+	clear(content)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	key := uintptr(unsafe.Pointer(&content[0]))
+	if region, ok := locked[key]; ok {
+		clear(region)
+		if err := unlock(region); err != nil {
+			log.WithError(err).WithField("context", "plaintext").Debug("could not unlock plaintext from memory")
+		}
+		delete(locked, key)
+	}
+}
+
+// Wiped reports whether content is all zeros up to its capacity.
+func Wiped(content []byte) bool {
+	for _, b := range content[:cap(content)] {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// lockedCount returns the number of slices that are still locked into memory.
+func lockedCount() int {
+	mutex.Lock()
+	defer mutex.Unlock()
+	return len(locked)
+}
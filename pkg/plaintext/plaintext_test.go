@@ -0,0 +1,97 @@
+package plaintext
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWipe(t *testing.T) {
+	t.Run("when content is wiped", func(t *testing.T) {
+		content := Clone([]byte("my very secret text"))
+		Wipe(content)
+
+		t.Run("it should zero every byte", func(t *testing.T) {
+			assert.True(t, Wiped(content))
+		})
+	})
+
+	t.Run("when a shortened slice is wiped", func(t *testing.T) {
+		content := Clone([]byte("my very secret text"))
+		Wipe(content[:2])
+
+		t.Run("it should zero the bytes beyond its length", func(t *testing.T) {
+			assert.True(t, Wiped(content))
+		})
+	})
+
+	t.Run("when content is wiped twice or is empty", func(t *testing.T) {
+		content := Clone([]byte("my very secret text"))
+
+		t.Run("it should not panic", func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				Wipe(content)
+				Wipe(content)
+				Wipe(nil)
+				Wipe([]byte{})
+			})
+		})
+	})
+}
+
+func TestReadFull(t *testing.T) {
+	t.Run("when the reader holds the expected size", func(t *testing.T) {
+		content, err := ReadFull(strings.NewReader("my very secret text"), 19)
+		require.NoError(t, err)
+
+		t.Run("it should return the content", func(t *testing.T) {
+			assert.Equal(t, "my very secret text", string(content))
+		})
+
+		t.Run("it should not allocate more than the content", func(t *testing.T) {
+			assert.Equal(t, 19, cap(content))
+		})
+	})
+
+	t.Run("when the reader is shorter than the expected size", func(t *testing.T) {
+		_, err := ReadFull(strings.NewReader("short"), 19)
+
+		t.Run("it should return an error", func(t *testing.T) {
+			assert.Error(t, err)
+		})
+	})
+}
+
+func TestLocking(t *testing.T) {
+	SetLocking(true)
+	t.Cleanup(func() { SetLocking(false) })
+
+	content := Alloc(100)
+	if lockedCount() == 0 {
+		Wipe(content)
+		t.Skip("memory cannot be locked on this platform or under the current limit")
+	}
+	copy(content, bytes.Repeat([]byte("s"), 100))
+
+	t.Run("it should keep the content within its own pages", func(t *testing.T) {
+		assert.Equal(t, 100, len(content))
+		assert.Equal(t, 100, cap(content))
+	})
+
+	t.Run("and the content is wiped", func(t *testing.T) {
+		Wipe(content)
+		runtime.GC()
+
+		t.Run("it should zero every byte", func(t *testing.T) {
+			assert.True(t, Wiped(content))
+		})
+
+		t.Run("it should unlock the content", func(t *testing.T) {
+			assert.Equal(t, 0, lockedCount())
+		})
+	})
+}
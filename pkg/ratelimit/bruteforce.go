@@ -0,0 +1,161 @@
+package ratelimit
+
+import (
+	pkgerrors "cellar/pkg/errors"
+	"cellar/pkg/settings"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	log "github.com/sirupsen/logrus"
+)
+
+// BruteForceKey holds the detector that locks out clients failing many secret lookups, as guessing IDs does.
+var BruteForceKey = "BRUTE_FORCE_DETECTOR"
+
+// lockoutMemory is how long a client's lockouts are remembered for escalating the next one.
+const lockoutMemory = 24 * time.Hour
+
+//go:generate mockgen -destination=../mocks/mock_bruteforce_detector.go -package=mocks . BruteForceDetector
+type BruteForceDetector interface {
+	// Blocked returns the number of seconds the client remains locked out for, or 0 if it is not locked out.
+	Blocked(ctx context.Context, identifier string) (int, error)
+	// RecordNotFound counts a lookup of a secret that does not exist and blocks the client once it reaches the limit.
+	// Returns true if this lookup got the client blocked.
+	RecordNotFound(ctx context.Context, identifier string) (bool, error)
+	// RecordFailure counts a lookup rejected for invalid credentials and locks the client out once it reaches
+	// its own limit, apart from the lookups of secrets that do not exist.
+	// Returns true if this failure got the client locked out.
+	RecordFailure(ctx context.Context, identifier string) (bool, error)
+}
+
+// RedisBruteForceDetector locks clients out for the configured block time. Each lockout within a day lasts twice
+// as long as the one before, up to the configured maximum, whichever limit the client reached.
+type RedisBruteForceDetector struct {
+	client *redis.Client
+	config settings.IHardeningConfiguration
+	logger *log.Entry
+}
+
+func NewRedisBruteForceDetector(client *redis.Client, config settings.IHardeningConfiguration) *RedisBruteForceDetector {
+	return &RedisBruteForceDetector{
+		client: client,
+		config: config,
+		logger: log.WithFields(log.Fields{
+			"context": "brute force detector",
+			"backend": "redis",
+		}),
+	}
+}
+
+func (detector *RedisBruteForceDetector) Blocked(ctx context.Context, identifier string) (int, error) {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return 0, err
+	}
+
+	ttl, err := detector.client.TTL(ctx, detector.blockKey(identifier)).Result()
+	if err != nil {
+		return 0, err
+	}
+	if ttl <= 0 {
+		return 0, nil
+	}
+	return int(ttl.Round(time.Second).Seconds()), nil
+}
+
+func (detector *RedisBruteForceDetector) RecordNotFound(ctx context.Context, identifier string) (bool, error) {
+	return detector.record(ctx, identifier, detector.notFoundKey(identifier),
+		detector.config.NotFoundLimit(), detector.config.NotFoundWindowSeconds())
+}
+
+func (detector *RedisBruteForceDetector) RecordFailure(ctx context.Context, identifier string) (bool, error) {
+	return detector.record(ctx, identifier, detector.failuresKey(identifier),
+		detector.config.FailureLimit(), detector.config.FailureWindowSeconds())
+}
+
+// record counts a failed lookup under the count key and locks the client out once the count reaches the limit
+// within the window.
+func (detector *RedisBruteForceDetector) record(ctx context.Context, identifier string, countKey string, limit int, windowSeconds int) (bool, error) {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return false, err
+	}
+
+	window := time.Duration(windowSeconds) * time.Second
+
+	var count *redis.IntCmd
+	_, err := detector.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		count = pipe.Incr(ctx, countKey)
+		pipe.ExpireNX(ctx, countKey, window)
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if count.Val() < int64(limit) {
+		return false, nil
+	}
+
+	lockouts, err := detector.client.Get(ctx, detector.lockoutsKey(identifier)).Int()
+	if err != nil && err != redis.Nil {
+		return false, err
+	}
+
+	blockSeconds := detector.blockSeconds(lockouts)
+	blocked, err := detector.client.SetNX(ctx, detector.blockKey(identifier), limit, time.Duration(blockSeconds)*time.Second).Result()
+	if err != nil {
+		return false, err
+	}
+
+	_, err = detector.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, countKey)
+		if blocked {
+			pipe.Incr(ctx, detector.lockoutsKey(identifier))
+			pipe.Expire(ctx, detector.lockoutsKey(identifier), lockoutMemory)
+		}
+		return nil
+	})
+	if err != nil {
+		return blocked, err
+	}
+
+	if blocked {
+		detector.logger.WithFields(log.Fields{
+			"identifier":   identifier,
+			"limit":        limit,
+			"lockout":      lockouts + 1,
+			"blockSeconds": blockSeconds,
+		}).Warn("locked out client failing too many secret lookups")
+	}
+	return blocked, nil
+}
+
+// blockSeconds doubles the configured block time for each earlier lockout, up to the configured maximum.
+func (detector *RedisBruteForceDetector) blockSeconds(lockouts int) int {
+	block := detector.config.BlockSeconds()
+	maxBlock := detector.config.MaxBlockSeconds()
+	for i := 0; i < lockouts && block < maxBlock; i++ {
+		block *= 2
+	}
+	if block > maxBlock {
+		return maxBlock
+	}
+	return block
+}
+
+func (detector *RedisBruteForceDetector) notFoundKey(identifier string) string {
+	return fmt.Sprintf("cellar:bruteforce:%s:notfound", identifier)
+}
+
+func (detector *RedisBruteForceDetector) failuresKey(identifier string) string {
+	return fmt.Sprintf("cellar:bruteforce:%s:failures", identifier)
+}
+
+func (detector *RedisBruteForceDetector) blockKey(identifier string) string {
+	return fmt.Sprintf("cellar:bruteforce:%s:blocked", identifier)
+}
+
+func (detector *RedisBruteForceDetector) lockoutsKey(identifier string) string {
+	return fmt.Sprintf("cellar:bruteforce:%s:lockouts", identifier)
+}
@@ -0,0 +1,136 @@
+package ratelimit_test
+
+import (
+	"cellar/pkg/mocks"
+	"cellar/pkg/ratelimit"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRedisBruteForceDetector(t *testing.T) {
+	t.Run("when recording lookups of secrets that do not exist", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		client := setupRedisClient(t)
+		config := mocks.NewMockIHardeningConfiguration(ctrl)
+		config.EXPECT().NotFoundLimit().Return(2).AnyTimes()
+		config.EXPECT().NotFoundWindowSeconds().Return(60).AnyTimes()
+		config.EXPECT().FailureLimit().Return(3).AnyTimes()
+		config.EXPECT().FailureWindowSeconds().Return(60).AnyTimes()
+		config.EXPECT().BlockSeconds().Return(120).AnyTimes()
+		config.EXPECT().MaxBlockSeconds().Return(300).AnyTimes()
+
+		detector := ratelimit.NewRedisBruteForceDetector(client, config)
+		ctx := context.Background()
+		identifier := "test-client-bruteforce-notfound"
+
+		t.Run("and invalid credentials", func(t *testing.T) {
+			blocked, err := detector.RecordFailure(ctx, identifier)
+			require.NoError(t, err)
+			assert.False(t, blocked)
+			blocked, err = detector.RecordNotFound(ctx, identifier)
+			require.NoError(t, err)
+
+			t.Run("it should count them apart", func(t *testing.T) {
+				assert.False(t, blocked)
+			})
+		})
+
+		t.Run("and reaching the limit", func(t *testing.T) {
+			blocked, err := detector.RecordNotFound(ctx, identifier)
+			require.NoError(t, err)
+
+			t.Run("it should report the client got blocked", func(t *testing.T) {
+				assert.True(t, blocked)
+			})
+
+			t.Run("it should block the client for the configured time", func(t *testing.T) {
+				retryAfter, err := detector.Blocked(ctx, identifier)
+				require.NoError(t, err)
+				assert.InDelta(t, 120, retryAfter, 2)
+			})
+		})
+	})
+
+	t.Run("when recording invalid credentials", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		client := setupRedisClient(t)
+		config := mocks.NewMockIHardeningConfiguration(ctrl)
+		config.EXPECT().FailureLimit().Return(3).AnyTimes()
+		config.EXPECT().FailureWindowSeconds().Return(60).AnyTimes()
+		config.EXPECT().BlockSeconds().Return(120).AnyTimes()
+		config.EXPECT().MaxBlockSeconds().Return(300).AnyTimes()
+
+		detector := ratelimit.NewRedisBruteForceDetector(client, config)
+		ctx := context.Background()
+		identifier := "test-client-bruteforce"
+
+		t.Run("and below the limit", func(t *testing.T) {
+			for i := 0; i < 2; i++ {
+				blocked, err := detector.RecordFailure(ctx, identifier)
+				require.NoError(t, err)
+				assert.False(t, blocked)
+			}
+
+			t.Run("it should not block the client", func(t *testing.T) {
+				retryAfter, err := detector.Blocked(ctx, identifier)
+				require.NoError(t, err)
+				assert.Equal(t, 0, retryAfter)
+			})
+		})
+
+		t.Run("and reaching the limit", func(t *testing.T) {
+			blocked, err := detector.RecordFailure(ctx, identifier)
+			require.NoError(t, err)
+
+			t.Run("it should report the client got blocked", func(t *testing.T) {
+				assert.True(t, blocked)
+			})
+
+			t.Run("it should block the client for the configured time", func(t *testing.T) {
+				retryAfter, err := detector.Blocked(ctx, identifier)
+				require.NoError(t, err)
+				assert.InDelta(t, 120, retryAfter, 2)
+			})
+
+			t.Run("it should not block other clients", func(t *testing.T) {
+				retryAfter, err := detector.Blocked(ctx, "test-client-other")
+				require.NoError(t, err)
+				assert.Equal(t, 0, retryAfter)
+			})
+		})
+
+		t.Run("and reaching the limit again after the lockout ends", func(t *testing.T) {
+			require.NoError(t, client.Del(ctx, "cellar:bruteforce:"+identifier+":blocked").Err())
+			for i := 0; i < 3; i++ {
+				_, err := detector.RecordFailure(ctx, identifier)
+				require.NoError(t, err)
+			}
+
+			t.Run("it should lock the client out for twice as long", func(t *testing.T) {
+				retryAfter, err := detector.Blocked(ctx, identifier)
+				require.NoError(t, err)
+				assert.InDelta(t, 240, retryAfter, 2)
+			})
+		})
+
+		t.Run("and reaching the limit once more", func(t *testing.T) {
+			require.NoError(t, client.Del(ctx, "cellar:bruteforce:"+identifier+":blocked").Err())
+			for i := 0; i < 3; i++ {
+				_, err := detector.RecordFailure(ctx, identifier)
+				require.NoError(t, err)
+			}
+
+			t.Run("it should not lock the client out for longer than the maximum", func(t *testing.T) {
+				retryAfter, err := detector.Blocked(ctx, identifier)
+				require.NoError(t, err)
+				assert.InDelta(t, 300, retryAfter, 2)
+			})
+		})
+	})
+}
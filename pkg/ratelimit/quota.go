@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	pkgerrors "cellar/pkg/errors"
+	"cellar/pkg/settings"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// QuotaKey holds the quota that limits how much each SSO user can create.
+var QuotaKey = "USER_QUOTA"
+
+//go:generate mockgen -destination=../mocks/mock_user_quota.go -package=mocks . UserQuota
+type UserQuota interface {
+	// Exhausted returns the number of seconds until the user can create again, or 0 if the user is within the limit.
+	Exhausted(ctx context.Context, subject string) (int, error)
+	// Record counts a creation by the user.
+	Record(ctx context.Context, subject string) error
+}
+
+type RedisUserQuota struct {
+	client *redis.Client
+	config settings.IOIDCConfiguration
+}
+
+func NewRedisUserQuota(client *redis.Client, config settings.IOIDCConfiguration) *RedisUserQuota {
+	return &RedisUserQuota{
+		client: client,
+		config: config,
+	}
+}
+
+func (quota *RedisUserQuota) Exhausted(ctx context.Context, subject string) (int, error) {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return 0, err
+	}
+
+	limit := quota.config.UserCreateLimit()
+	if limit == 0 {
+		return 0, nil
+	}
+
+	key := quota.key(subject)
+	count, err := quota.client.Get(ctx, key).Int()
+	if err != nil && err != redis.Nil {
+		return 0, err
+	}
+	if count < limit {
+		return 0, nil
+	}
+
+	ttl, err := quota.client.TTL(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if ttl <= 0 {
+		return 0, nil
+	}
+	return int(ttl.Round(time.Second).Seconds()), nil
+}
+
+func (quota *RedisUserQuota) Record(ctx context.Context, subject string) error {
+	if err := pkgerrors.CheckContext(ctx); err != nil {
+		return err
+	}
+
+	key := quota.key(subject)
+	window := time.Duration(quota.config.UserCreateWindowSeconds()) * time.Second
+	_, err := quota.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Incr(ctx, key)
+		pipe.ExpireNX(ctx, key, window)
+		return nil
+	})
+	return err
+}
+
+func (quota *RedisUserQuota) key(subject string) string {
+	return fmt.Sprintf("cellar:quota:%s:created", subject)
+}
@@ -0,0 +1,68 @@
+package ratelimit_test
+
+import (
+	"cellar/pkg/mocks"
+	"cellar/pkg/ratelimit"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRedisUserQuota(t *testing.T) {
+	t.Run("when recording creations", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		client := setupRedisClient(t)
+		config := mocks.NewMockIOIDCConfiguration(ctrl)
+		config.EXPECT().UserCreateLimit().Return(2).AnyTimes()
+		config.EXPECT().UserCreateWindowSeconds().Return(60).AnyTimes()
+
+		quota := ratelimit.NewRedisUserQuota(client, config)
+		ctx := context.Background()
+		subject := "alice@example.com"
+
+		t.Run("and below the limit", func(t *testing.T) {
+			require.NoError(t, quota.Record(ctx, subject))
+
+			t.Run("it should let the user create", func(t *testing.T) {
+				retryAfter, err := quota.Exhausted(ctx, subject)
+				require.NoError(t, err)
+				assert.Equal(t, 0, retryAfter)
+			})
+		})
+
+		t.Run("and reaching the limit", func(t *testing.T) {
+			require.NoError(t, quota.Record(ctx, subject))
+
+			t.Run("it should tell the user when the window ends", func(t *testing.T) {
+				retryAfter, err := quota.Exhausted(ctx, subject)
+				require.NoError(t, err)
+				assert.InDelta(t, 60, retryAfter, 2)
+			})
+
+			t.Run("it should not limit other users", func(t *testing.T) {
+				retryAfter, err := quota.Exhausted(ctx, "bob@example.com")
+				require.NoError(t, err)
+				assert.Equal(t, 0, retryAfter)
+			})
+		})
+	})
+
+	t.Run("when users are not limited", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		config := mocks.NewMockIOIDCConfiguration(ctrl)
+		config.EXPECT().UserCreateLimit().Return(0).AnyTimes()
+
+		quota := ratelimit.NewRedisUserQuota(nil, config)
+
+		t.Run("it should let the user create without checking the datastore", func(t *testing.T) {
+			retryAfter, err := quota.Exhausted(context.Background(), "alice@example.com")
+			require.NoError(t, err)
+			assert.Equal(t, 0, retryAfter)
+		})
+	})
+}
@@ -0,0 +1,89 @@
+package recipients
+
+import (
+	"bytes"
+	pkgerrors "cellar/pkg/errors"
+	"cellar/pkg/models"
+	"io"
+	"strings"
+
+	"filippo.io/age"
+	ageArmor "filippo.io/age/armor"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	pgpArmor "github.com/ProtonMail/go-crypto/openpgp/armor"
+)
+
+const openPGPPublicKeyHeader = "-----BEGIN PGP PUBLIC KEY BLOCK-----"
+
+// Seal encrypts the content to an age X25519 recipient or an armored OpenPGP public key.
+// Returns the armored ciphertext and the content type marking it.
+// Keys that cannot be parsed are returned as ValidationError types.
+func Seal(publicKey string, content []byte) (sealed []byte, contentType string, err error) {
+	publicKey = strings.TrimSpace(publicKey)
+
+	switch {
+	case strings.HasPrefix(publicKey, "age1"):
+		sealed, err = sealAge(publicKey, content)
+		return sealed, models.ContentTypeAge, err
+	case strings.HasPrefix(publicKey, openPGPPublicKeyHeader):
+		sealed, err = sealOpenPGP(publicKey, content)
+		return sealed, models.ContentTypeOpenPGP, err
+	default:
+		return nil, "", pkgerrors.NewValidationError("recipient_public_key must be an age X25519 recipient or an armored OpenPGP public key")
+	}
+}
+
+func sealAge(publicKey string, content []byte) ([]byte, error) {
+	recipient, err := age.ParseX25519Recipient(publicKey)
+	if err != nil {
+		return nil, pkgerrors.NewValidationError("recipient_public_key: invalid age recipient")
+	}
+
+	buf := bytes.NewBuffer(nil)
+	armorWriter := ageArmor.NewWriter(buf)
+	writer, err := age.Encrypt(armorWriter, recipient)
+	if err != nil {
+		return nil, err
+	}
+	if err = writeAndClose(writer, content); err != nil {
+		return nil, err
+	}
+	if err = armorWriter.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func sealOpenPGP(publicKey string, content []byte) ([]byte, error) {
+	entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(publicKey))
+	if err != nil || len(entities) == 0 {
+		return nil, pkgerrors.NewValidationError("recipient_public_key: invalid OpenPGP public key")
+	}
+
+	buf := bytes.NewBuffer(nil)
+	armorWriter, err := pgpArmor.Encode(buf, "PGP MESSAGE", nil)
+	if err != nil {
+		return nil, err
+	}
+	writer, err := openpgp.Encrypt(armorWriter, entities[:1], nil, &openpgp.FileHints{IsBinary: true}, nil)
+	if err != nil {
+		return nil, pkgerrors.NewValidationError("recipient_public_key: OpenPGP key cannot encrypt: " + err.Error())
+	}
+	if err = writeAndClose(writer, content); err != nil {
+		return nil, err
+	}
+	if err = armorWriter.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeAndClose(writer io.WriteCloser, content []byte) error {
+	if _, err := writer.Write(content); err != nil {
+		_ = writer.Close()
+		return err
+	}
+	return writer.Close()
+}
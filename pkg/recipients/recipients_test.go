@@ -0,0 +1,96 @@
+package recipients
+
+import (
+	"bytes"
+	pkgerrors "cellar/pkg/errors"
+	"cellar/pkg/models"
+	"io"
+	"testing"
+
+	"filippo.io/age"
+	ageArmor "filippo.io/age/armor"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	pgpArmor "github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var content = []byte("Super Secret Test Content")
+
+func TestSeal(t *testing.T) {
+	t.Run("when the recipient is an age key", func(t *testing.T) {
+		identity, err := age.GenerateX25519Identity()
+		require.NoError(t, err)
+
+		sealed, contentType, err := Seal(identity.Recipient().String(), content)
+		require.NoError(t, err)
+
+		t.Run("it should mark the content as age", func(t *testing.T) {
+			assert.Equal(t, models.ContentTypeAge, contentType)
+		})
+
+		t.Run("it should return armored ciphertext the recipient can decrypt", func(t *testing.T) {
+			assert.True(t, bytes.HasPrefix(sealed, []byte(ageArmor.Header)))
+
+			reader, err := age.Decrypt(ageArmor.NewReader(bytes.NewReader(sealed)), identity)
+			require.NoError(t, err)
+			plaintext, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			assert.Equal(t, content, plaintext)
+		})
+	})
+
+	t.Run("when the recipient is an OpenPGP key", func(t *testing.T) {
+		entity, err := openpgp.NewEntity("Recipient", "", "recipient@example.com", nil)
+		require.NoError(t, err)
+
+		publicKey := bytes.NewBuffer(nil)
+		armorWriter, err := pgpArmor.Encode(publicKey, openpgp.PublicKeyType, nil)
+		require.NoError(t, err)
+		require.NoError(t, entity.Serialize(armorWriter))
+		require.NoError(t, armorWriter.Close())
+
+		sealed, contentType, err := Seal(publicKey.String(), content)
+		require.NoError(t, err)
+
+		t.Run("it should mark the content as OpenPGP", func(t *testing.T) {
+			assert.Equal(t, models.ContentTypeOpenPGP, contentType)
+		})
+
+		t.Run("it should return an armored message the recipient can decrypt", func(t *testing.T) {
+			block, err := pgpArmor.Decode(bytes.NewReader(sealed))
+			require.NoError(t, err)
+			assert.Equal(t, "PGP MESSAGE", block.Type)
+
+			message, err := openpgp.ReadMessage(block.Body, openpgp.EntityList{entity}, nil, nil)
+			require.NoError(t, err)
+			plaintext, err := io.ReadAll(message.UnverifiedBody)
+			require.NoError(t, err)
+			assert.Equal(t, content, plaintext)
+		})
+	})
+
+	t.Run("when the recipient key is not supported", func(t *testing.T) {
+		_, _, err := Seal("ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAA", content)
+
+		t.Run("it should return validation error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsValidationError(err), "expected validation error")
+		})
+	})
+
+	t.Run("when the age recipient is malformed", func(t *testing.T) {
+		_, _, err := Seal("age1notarecipient", content)
+
+		t.Run("it should return validation error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsValidationError(err), "expected validation error")
+		})
+	})
+
+	t.Run("when the OpenPGP key is malformed", func(t *testing.T) {
+		_, _, err := Seal(openPGPPublicKeyHeader+"\n\nnot a key\n-----END PGP PUBLIC KEY BLOCK-----", content)
+
+		t.Run("it should return validation error", func(t *testing.T) {
+			assert.True(t, pkgerrors.IsValidationError(err), "expected validation error")
+		})
+	})
+}
@@ -1,6 +1,9 @@
 package settings
 
 import (
+	"sort"
+	"strconv"
+
 	"github.com/spf13/viper"
 )
 
@@ -12,6 +15,11 @@ type IAppConfiguration interface {
 	MaxFileSizeMB() int
 	MaxAccessCount() int
 	MaxExpirationSeconds() int
+	MaxKVFields() int
+	MaxKVKeyLength() int
+	AllowedCIDRs() []string
+	PaddingBuckets() []int
+	LockMemory() bool
 }
 
 const (
@@ -22,6 +30,12 @@ const (
 	appMaxFileSizeMBKey        = appKey + "max_file_size_mb"
 	appMaxAccessCountKey       = appKey + "max_access_count"
 	appMaxExpirationSecondsKey = appKey + "max_expiration_seconds"
+	appMaxKVFieldsKey          = appKey + "max_kv_fields"
+	appMaxKVKeyLengthKey       = appKey + "max_kv_key_length"
+	appAllowedCIDRsKey         = appKey + "allowed_cidrs"
+	appPaddingEnabledKey       = appKey + "padding_enabled"
+	appPaddingBucketsKey       = appKey + "padding_buckets"
+	appLockMemoryKey           = appKey + "lock_memory"
 )
 
 var version string
@@ -40,6 +54,11 @@ func NewAppConfiguration() *AppConfiguration {
 	viper.SetDefault(appMaxFileSizeMBKey, 8)
 	viper.SetDefault(appMaxAccessCountKey, 100)
 	viper.SetDefault(appMaxExpirationSecondsKey, 604800)
+	viper.SetDefault(appMaxKVFieldsKey, 50)
+	viper.SetDefault(appMaxKVKeyLengthKey, 64)
+	viper.SetDefault(appPaddingEnabledKey, false)
+	viper.SetDefault(appPaddingBucketsKey, "1024,4096,16384,65536,262144,1048576")
+	viper.SetDefault(appLockMemoryKey, false)
 	return &AppConfiguration{}
 }
 
@@ -78,3 +97,49 @@ func (app AppConfiguration) MaxExpirationSeconds() int {
 	}
 	return value
 }
+
+func (app AppConfiguration) MaxKVFields() int {
+	value := viper.GetInt(appMaxKVFieldsKey)
+	if value < 1 {
+		return 1
+	}
+	return value
+}
+
+func (app AppConfiguration) MaxKVKeyLength() int {
+	value := viper.GetInt(appMaxKVKeyLengthKey)
+	if value < 1 {
+		return 1
+	}
+	return value
+}
+
+// AllowedCIDRs returns the default allowlist for secrets created without their own.
+// Ranges can be separated by commas or whitespace. An empty list allows every client.
+func (app AppConfiguration) AllowedCIDRs() []string {
+	return getList(appAllowedCIDRsKey)
+}
+
+// PaddingBuckets returns the sizes, in bytes and smallest first, that secret content is padded up to before it is encrypted.
+// Sizes can be separated by commas or whitespace, and sizes that are not positive numbers are ignored.
+// Padding hides the size of stored secrets only, not of the responses that return their content.
+// Returns nil when padding is disabled.
+func (app AppConfiguration) PaddingBuckets() []int {
+	if !viper.GetBool(appPaddingEnabledKey) {
+		return nil
+	}
+
+	var buckets []int
+	for _, value := range getList(appPaddingBucketsKey) {
+		if bucket, err := strconv.Atoi(value); err == nil && bucket > 0 {
+			buckets = append(buckets, bucket)
+		}
+	}
+	sort.Ints(buckets)
+	return buckets
+}
+
+// LockMemory returns whether buffers holding plaintext secret content are locked into memory, so they are never swapped to disk.
+func (app AppConfiguration) LockMemory() bool {
+	return viper.GetBool(appLockMemoryKey)
+}
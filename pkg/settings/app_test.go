@@ -154,6 +154,203 @@ func TestAppConfiguration(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("when testing MaxKVFields", func(t *testing.T) {
+		testCases := []struct {
+			name          string
+			setValue      *int
+			expectedValue int
+			reason        string
+		}{
+			{
+				name:          "not set",
+				setValue:      nil,
+				expectedValue: 50,
+				reason:        "default value of 50",
+			},
+			{
+				name:          "set to valid value",
+				setValue:      intPtr(20),
+				expectedValue: 20,
+				reason:        "configured value",
+			},
+			{
+				name:          "set to zero",
+				setValue:      intPtr(0),
+				expectedValue: 1,
+				reason:        "1 as minimum value",
+			},
+		}
+
+		for _, tc := range testCases {
+			t.Run("and "+tc.name, func(t *testing.T) {
+				viper.Reset()
+				if tc.setValue != nil {
+					viper.Set("app.max_kv_fields", *tc.setValue)
+				}
+				app := NewAppConfiguration()
+
+				t.Run("it should return "+tc.reason, func(t *testing.T) {
+					result := app.MaxKVFields()
+					assert.Equal(t, tc.expectedValue, result)
+				})
+			})
+		}
+	})
+
+	t.Run("when testing MaxKVKeyLength", func(t *testing.T) {
+		testCases := []struct {
+			name          string
+			setValue      *int
+			expectedValue int
+			reason        string
+		}{
+			{
+				name:          "not set",
+				setValue:      nil,
+				expectedValue: 64,
+				reason:        "default value of 64",
+			},
+			{
+				name:          "set to valid value",
+				setValue:      intPtr(128),
+				expectedValue: 128,
+				reason:        "configured value",
+			},
+			{
+				name:          "set to zero",
+				setValue:      intPtr(0),
+				expectedValue: 1,
+				reason:        "1 as minimum value",
+			},
+		}
+
+		for _, tc := range testCases {
+			t.Run("and "+tc.name, func(t *testing.T) {
+				viper.Reset()
+				if tc.setValue != nil {
+					viper.Set("app.max_kv_key_length", *tc.setValue)
+				}
+				app := NewAppConfiguration()
+
+				t.Run("it should return "+tc.reason, func(t *testing.T) {
+					result := app.MaxKVKeyLength()
+					assert.Equal(t, tc.expectedValue, result)
+				})
+			})
+		}
+	})
+
+	t.Run("when testing AllowedCIDRs", func(t *testing.T) {
+		testCases := []struct {
+			name          string
+			setValue      any
+			expectedValue []string
+			reason        string
+		}{
+			{
+				name:          "not set",
+				setValue:      nil,
+				expectedValue: nil,
+				reason:        "no ranges, allowing every client",
+			},
+			{
+				name:          "set to a comma separated string",
+				setValue:      "10.0.0.0/8, 192.168.0.0/16",
+				expectedValue: []string{"10.0.0.0/8", "192.168.0.0/16"},
+				reason:        "each range",
+			},
+			{
+				name:          "set to a list",
+				setValue:      []string{"10.0.0.0/8", "2001:db8::/32"},
+				expectedValue: []string{"10.0.0.0/8", "2001:db8::/32"},
+				reason:        "each range",
+			},
+		}
+
+		for _, tc := range testCases {
+			t.Run("and "+tc.name, func(t *testing.T) {
+				viper.Reset()
+				if tc.setValue != nil {
+					viper.Set("app.allowed_cidrs", tc.setValue)
+				}
+				app := NewAppConfiguration()
+
+				t.Run("it should return "+tc.reason, func(t *testing.T) {
+					result := app.AllowedCIDRs()
+					assert.Equal(t, tc.expectedValue, result)
+				})
+			})
+		}
+	})
+
+	t.Run("when testing PaddingBuckets", func(t *testing.T) {
+		testCases := []struct {
+			name          string
+			enabled       bool
+			setValue      any
+			expectedValue []int
+			reason        string
+		}{
+			{
+				name:          "padding is disabled",
+				enabled:       false,
+				setValue:      "256,1024",
+				expectedValue: nil,
+				reason:        "no buckets",
+			},
+			{
+				name:          "padding is enabled with the default buckets",
+				enabled:       true,
+				setValue:      nil,
+				expectedValue: []int{1024, 4096, 16384, 65536, 262144, 1048576},
+				reason:        "the default buckets",
+			},
+			{
+				name:          "padding is enabled with unsorted and invalid buckets",
+				enabled:       true,
+				setValue:      "4096, 256 none -1",
+				expectedValue: []int{256, 4096},
+				reason:        "the valid buckets, smallest first",
+			},
+		}
+
+		for _, tc := range testCases {
+			t.Run("and "+tc.name, func(t *testing.T) {
+				viper.Reset()
+				viper.Set("app.padding_enabled", tc.enabled)
+				if tc.setValue != nil {
+					viper.Set("app.padding_buckets", tc.setValue)
+				}
+				app := NewAppConfiguration()
+
+				t.Run("it should return "+tc.reason, func(t *testing.T) {
+					assert.Equal(t, tc.expectedValue, app.PaddingBuckets())
+				})
+			})
+		}
+	})
+
+	t.Run("when testing LockMemory", func(t *testing.T) {
+		t.Run("and it is not set", func(t *testing.T) {
+			viper.Reset()
+			app := NewAppConfiguration()
+
+			t.Run("it should not lock memory", func(t *testing.T) {
+				assert.False(t, app.LockMemory())
+			})
+		})
+
+		t.Run("and it is enabled", func(t *testing.T) {
+			viper.Reset()
+			viper.Set("app.lock_memory", "true")
+			app := NewAppConfiguration()
+
+			t.Run("it should lock memory", func(t *testing.T) {
+				assert.True(t, app.LockMemory())
+			})
+		})
+	})
 }
 
 func intPtr(i int) *int {
@@ -0,0 +1,41 @@
+package settings
+
+import (
+	"github.com/spf13/viper"
+)
+
+const (
+	authKey                = "auth."
+	authEnabledKey         = authKey + "enabled"
+	authAnonymousScopesKey = authKey + "anonymous_scopes"
+)
+
+//go:generate mockgen -destination=../mocks/mock_auth_configuration.go -package=mocks cellar/pkg/settings IAuthConfiguration
+type IAuthConfiguration interface {
+	Enabled() bool
+	AnonymousScopes() []string
+	OIDC() IOIDCConfiguration
+}
+
+type AuthConfiguration struct{}
+
+func NewAuthConfiguration() *AuthConfiguration {
+	viper.SetDefault(authEnabledKey, false)
+	viper.SetDefault(authAnonymousScopesKey, "read-config,delete-any")
+	return &AuthConfiguration{}
+}
+
+// Enabled reports whether routes that require a scope are restricted to API keys granted it.
+func (auth AuthConfiguration) Enabled() bool {
+	return viper.GetBool(authEnabledKey)
+}
+
+// AnonymousScopes returns the scopes granted to requests without an API key.
+// Scopes can be separated by commas or whitespace.
+func (auth AuthConfiguration) AnonymousScopes() []string {
+	return getList(authAnonymousScopesKey)
+}
+
+func (auth AuthConfiguration) OIDC() IOIDCConfiguration {
+	return NewOIDCConfiguration()
+}
@@ -0,0 +1,38 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthConfiguration(t *testing.T) {
+	t.Run("when nothing is set", func(t *testing.T) {
+		viper.Reset()
+		auth := NewAuthConfiguration()
+
+		t.Run("it should not require API keys", func(t *testing.T) {
+			assert.False(t, auth.Enabled())
+		})
+
+		t.Run("it should let anonymous requests read the config and delete secrets", func(t *testing.T) {
+			assert.Equal(t, []string{"read-config", "delete-any"}, auth.AnonymousScopes())
+		})
+	})
+
+	t.Run("when anonymous requests are granted no scopes", func(t *testing.T) {
+		viper.Reset()
+		viper.Set("auth.enabled", true)
+		viper.Set("auth.anonymous_scopes", "")
+		auth := NewAuthConfiguration()
+
+		t.Run("it should require API keys", func(t *testing.T) {
+			assert.True(t, auth.Enabled())
+		})
+
+		t.Run("it should grant no scopes", func(t *testing.T) {
+			assert.Empty(t, auth.AnonymousScopes())
+		})
+	})
+}
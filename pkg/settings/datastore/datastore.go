@@ -1,19 +1,60 @@
 package datastore
 
+import (
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
 type IDatastoreConfiguration interface {
 	Redis() IRedisConfiguration
+	IDPepper() string
+	IntegrityKeys() []string
+	IntegrityAllowUntagged() bool
 }
 
 const (
 	datastoreKey = "datastore."
+
+	datastoreIDPepperKey               = datastoreKey + "id_pepper"
+	datastoreIntegrityKeysKey          = datastoreKey + "integrity_keys"
+	datastoreIntegrityAllowUntaggedKey = datastoreKey + "integrity_allow_untagged"
 )
 
 type DatastoreConfiguration struct{}
 
 func NewDatastoreConfiguration() *DatastoreConfiguration {
+	viper.SetDefault(datastoreIDPepperKey, "")
+	viper.SetDefault(datastoreIntegrityKeysKey, "")
+	viper.SetDefault(datastoreIntegrityAllowUntaggedKey, false)
 	return &DatastoreConfiguration{}
 }
 
 func (d *DatastoreConfiguration) Redis() IRedisConfiguration {
 	return NewRedisConfiguration()
 }
+
+// IDPepper is the server-side secret that the key IDs records are stored under are derived with.
+// It is required, and changing it makes every stored record unreachable.
+func (d *DatastoreConfiguration) IDPepper() string {
+	return viper.GetString(datastoreIDPepperKey)
+}
+
+// IntegrityKeys are the keys stored secrets are tagged with, each given as "<key id>:<secret>" and separated by commas.
+// The first key tags secrets, the others only verify secrets tagged before it was rotated in.
+func (d *DatastoreConfiguration) IntegrityKeys() []string {
+	var keys []string
+	for _, value := range viper.GetStringSlice(datastoreIntegrityKeysKey) {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				keys = append(keys, item)
+			}
+		}
+	}
+	return keys
+}
+
+// IntegrityAllowUntagged reports whether secrets without an integrity tag, written before keys were configured, can be read.
+func (d *DatastoreConfiguration) IntegrityAllowUntagged() bool {
+	return viper.GetBool(datastoreIntegrityAllowUntaggedKey)
+}
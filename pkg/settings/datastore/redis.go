@@ -9,6 +9,8 @@ type IRedisConfiguration interface {
 	Port() int
 	Password() string
 	DB() int
+	ExpiryEventsEnabled() bool
+	ExpirySweepIntervalSeconds() int
 }
 
 const (
@@ -17,6 +19,9 @@ const (
 	redisPortKey     = redisKey + "port"
 	redisPasswordKey = redisKey + "password"
 	redisDBKey       = redisKey + "db"
+
+	redisExpiryEventsEnabledKey        = redisKey + "expiry_events_enabled"
+	redisExpirySweepIntervalSecondsKey = redisKey + "expiry_sweep_interval_seconds"
 )
 
 type RedisConfiguration struct{}
@@ -26,6 +31,8 @@ func NewRedisConfiguration() *RedisConfiguration {
 	viper.SetDefault(redisPortKey, 6379)
 	viper.SetDefault(redisPasswordKey, "")
 	viper.SetDefault(redisDBKey, 0)
+	viper.SetDefault(redisExpiryEventsEnabledKey, true)
+	viper.SetDefault(redisExpirySweepIntervalSecondsKey, 300)
 	return &RedisConfiguration{}
 }
 
@@ -44,3 +51,15 @@ func (rds RedisConfiguration) Password() string {
 func (rds RedisConfiguration) DB() int {
 	return viper.GetInt(redisDBKey)
 }
+
+func (rds RedisConfiguration) ExpiryEventsEnabled() bool {
+	return viper.GetBool(redisExpiryEventsEnabledKey)
+}
+
+func (rds RedisConfiguration) ExpirySweepIntervalSeconds() int {
+	value := viper.GetInt(redisExpirySweepIntervalSecondsKey)
+	if value < 10 {
+		return 10
+	}
+	return value
+}
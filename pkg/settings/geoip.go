@@ -0,0 +1,58 @@
+package settings
+
+import (
+	"github.com/spf13/viper"
+)
+
+const (
+	geoIPKey                      = "geoip."
+	geoIPDatabasePathKey          = geoIPKey + "database_path"
+	geoIPReloadIntervalSecondsKey = geoIPKey + "reload_interval_seconds"
+	geoIPAllowedCountriesKey      = geoIPKey + "allowed_countries"
+	geoIPDeniedCountriesKey       = geoIPKey + "denied_countries"
+)
+
+//go:generate mockgen -destination=../mocks/mock_geoip_configuration.go -package=mocks cellar/pkg/settings IGeoIPConfiguration
+type IGeoIPConfiguration interface {
+	Enabled() bool
+	DatabasePath() string
+	ReloadIntervalSeconds() int
+	AllowedCountries() []string
+	DeniedCountries() []string
+}
+
+type GeoIPConfiguration struct{}
+
+func NewGeoIPConfiguration() *GeoIPConfiguration {
+	viper.SetDefault(geoIPDatabasePathKey, "")
+	viper.SetDefault(geoIPReloadIntervalSecondsKey, 300)
+	return &GeoIPConfiguration{}
+}
+
+// Enabled reports whether a GeoIP database is configured.
+func (geoIP GeoIPConfiguration) Enabled() bool {
+	return geoIP.DatabasePath() != ""
+}
+
+func (geoIP GeoIPConfiguration) DatabasePath() string {
+	return viper.GetString(geoIPDatabasePathKey)
+}
+
+// ReloadIntervalSeconds is how often the database file is checked for changes. 0 disables reloading.
+func (geoIP GeoIPConfiguration) ReloadIntervalSeconds() int {
+	value := viper.GetInt(geoIPReloadIntervalSecondsKey)
+	if value < 0 {
+		return 0
+	}
+	return value
+}
+
+// AllowedCountries returns the countries every secret can be accessed from. An empty list allows every country.
+func (geoIP GeoIPConfiguration) AllowedCountries() []string {
+	return getList(geoIPAllowedCountriesKey)
+}
+
+// DeniedCountries returns the countries no secret can be accessed from.
+func (geoIP GeoIPConfiguration) DeniedCountries() []string {
+	return getList(geoIPDeniedCountriesKey)
+}
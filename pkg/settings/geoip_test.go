@@ -0,0 +1,50 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeoIPConfiguration(t *testing.T) {
+	t.Run("when nothing is set", func(t *testing.T) {
+		viper.Reset()
+		geoIP := NewGeoIPConfiguration()
+
+		t.Run("it should be disabled", func(t *testing.T) {
+			assert.False(t, geoIP.Enabled())
+		})
+
+		t.Run("it should check for a new database every 5 minutes", func(t *testing.T) {
+			assert.Equal(t, 300, geoIP.ReloadIntervalSeconds())
+		})
+
+		t.Run("it should not restrict any country", func(t *testing.T) {
+			assert.Empty(t, geoIP.AllowedCountries())
+			assert.Empty(t, geoIP.DeniedCountries())
+		})
+	})
+
+	t.Run("when a database and country lists are set", func(t *testing.T) {
+		viper.Reset()
+		viper.Set("geoip.database_path", "/var/lib/cellar/GeoLite2-Country.mmdb")
+		viper.Set("geoip.reload_interval_seconds", -1)
+		viper.Set("geoip.allowed_countries", "DE, FR")
+		viper.Set("geoip.denied_countries", "US")
+		geoIP := NewGeoIPConfiguration()
+
+		t.Run("it should be enabled", func(t *testing.T) {
+			assert.True(t, geoIP.Enabled())
+		})
+
+		t.Run("it should return 0 as minimum reload interval", func(t *testing.T) {
+			assert.Equal(t, 0, geoIP.ReloadIntervalSeconds())
+		})
+
+		t.Run("it should return each country", func(t *testing.T) {
+			assert.Equal(t, []string{"DE", "FR"}, geoIP.AllowedCountries())
+			assert.Equal(t, []string{"US"}, geoIP.DeniedCountries())
+		})
+	})
+}
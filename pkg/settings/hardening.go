@@ -0,0 +1,122 @@
+package settings
+
+import (
+	"github.com/spf13/viper"
+)
+
+const (
+	hardeningKey                            = "hardening."
+	hardeningUniformResponsesEnabledKey     = hardeningKey + "uniform_responses_enabled"
+	hardeningUniformResponseMillisecondsKey = hardeningKey + "uniform_response_milliseconds"
+	hardeningBruteForceEnabledKey           = hardeningKey + "brute_force_enabled"
+	hardeningNotFoundLimitKey               = hardeningKey + "not_found_limit"
+	hardeningNotFoundWindowSecondsKey       = hardeningKey + "not_found_window_seconds"
+	hardeningFailureLimitKey                = hardeningKey + "failure_limit"
+	hardeningFailureWindowSecondsKey        = hardeningKey + "failure_window_seconds"
+	hardeningBlockSecondsKey                = hardeningKey + "block_seconds"
+	hardeningMaxBlockSecondsKey             = hardeningKey + "max_block_seconds"
+)
+
+//go:generate mockgen -destination=../mocks/mock_hardening_configuration.go -package=mocks cellar/pkg/settings IHardeningConfiguration
+type IHardeningConfiguration interface {
+	UniformResponsesEnabled() bool
+	UniformResponseMilliseconds() int
+	BruteForceEnabled() bool
+	NotFoundLimit() int
+	NotFoundWindowSeconds() int
+	FailureLimit() int
+	FailureWindowSeconds() int
+	BlockSeconds() int
+	MaxBlockSeconds() int
+}
+
+type HardeningConfiguration struct{}
+
+func NewHardeningConfiguration() *HardeningConfiguration {
+	viper.SetDefault(hardeningUniformResponsesEnabledKey, false)
+	viper.SetDefault(hardeningUniformResponseMillisecondsKey, 500)
+	viper.SetDefault(hardeningBruteForceEnabledKey, false)
+	viper.SetDefault(hardeningNotFoundLimitKey, 20)
+	viper.SetDefault(hardeningNotFoundWindowSecondsKey, 300)
+	viper.SetDefault(hardeningFailureLimitKey, 20)
+	viper.SetDefault(hardeningFailureWindowSecondsKey, 300)
+	viper.SetDefault(hardeningBlockSecondsKey, 900)
+	viper.SetDefault(hardeningMaxBlockSecondsKey, 86400)
+	return &HardeningConfiguration{}
+}
+
+// UniformResponsesEnabled reports whether lookups of secrets that do not exist are made indistinguishable
+// in timing from lookups of secrets that do.
+func (hardening HardeningConfiguration) UniformResponsesEnabled() bool {
+	return viper.GetBool(hardeningUniformResponsesEnabledKey)
+}
+
+// UniformResponseMilliseconds is the least time a response takes when uniform responses are enabled.
+// It should be above the time the slowest lookups of existing secrets take.
+func (hardening HardeningConfiguration) UniformResponseMilliseconds() int {
+	value := viper.GetInt(hardeningUniformResponseMillisecondsKey)
+	if value < 0 {
+		return 0
+	}
+	return value
+}
+
+// BruteForceEnabled reports whether clients that look up too many secrets that do not exist,
+// or give too many invalid credentials, are locked out.
+func (hardening HardeningConfiguration) BruteForceEnabled() bool {
+	return viper.GetBool(hardeningBruteForceEnabledKey)
+}
+
+// NotFoundLimit is the number of lookups of secrets that do not exist after which a client is blocked.
+func (hardening HardeningConfiguration) NotFoundLimit() int {
+	value := viper.GetInt(hardeningNotFoundLimitKey)
+	if value < 1 {
+		return 1
+	}
+	return value
+}
+
+func (hardening HardeningConfiguration) NotFoundWindowSeconds() int {
+	value := viper.GetInt(hardeningNotFoundWindowSecondsKey)
+	if value < 1 {
+		return 1
+	}
+	return value
+}
+
+// FailureLimit is the number of lookups rejected for invalid credentials after which a client is locked out.
+// They are counted apart from the lookups of secrets that do not exist.
+func (hardening HardeningConfiguration) FailureLimit() int {
+	value := viper.GetInt(hardeningFailureLimitKey)
+	if value < 1 {
+		return 1
+	}
+	return value
+}
+
+func (hardening HardeningConfiguration) FailureWindowSeconds() int {
+	value := viper.GetInt(hardeningFailureWindowSecondsKey)
+	if value < 1 {
+		return 1
+	}
+	return value
+}
+
+// BlockSeconds is how long a client is locked out for the first time it reaches the not found or failure limit.
+// Each further lockout doubles it, up to MaxBlockSeconds.
+func (hardening HardeningConfiguration) BlockSeconds() int {
+	value := viper.GetInt(hardeningBlockSecondsKey)
+	if value < 1 {
+		return 1
+	}
+	return value
+}
+
+// MaxBlockSeconds is the longest a client is locked out for, however often it reaches a limit.
+func (hardening HardeningConfiguration) MaxBlockSeconds() int {
+	value := viper.GetInt(hardeningMaxBlockSecondsKey)
+	if block := hardening.BlockSeconds(); value < block {
+		return block
+	}
+	return value
+}
@@ -0,0 +1,62 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHardeningConfiguration(t *testing.T) {
+	t.Run("when nothing is set", func(t *testing.T) {
+		viper.Reset()
+		hardening := NewHardeningConfiguration()
+
+		t.Run("it should disable uniform responses and brute force detection", func(t *testing.T) {
+			assert.False(t, hardening.UniformResponsesEnabled())
+			assert.False(t, hardening.BruteForceEnabled())
+		})
+
+		t.Run("it should pad responses to half a second", func(t *testing.T) {
+			assert.Equal(t, 500, hardening.UniformResponseMilliseconds())
+		})
+
+		t.Run("it should block for 15 minutes after 20 lookups of missing secrets in 5 minutes", func(t *testing.T) {
+			assert.Equal(t, 20, hardening.NotFoundLimit())
+			assert.Equal(t, 300, hardening.NotFoundWindowSeconds())
+			assert.Equal(t, 900, hardening.BlockSeconds())
+		})
+
+		t.Run("it should lock out for 15 minutes after 20 invalid credentials in 5 minutes", func(t *testing.T) {
+			assert.Equal(t, 20, hardening.FailureLimit())
+			assert.Equal(t, 300, hardening.FailureWindowSeconds())
+			assert.Equal(t, 900, hardening.BlockSeconds())
+		})
+
+		t.Run("it should escalate lockouts up to a day", func(t *testing.T) {
+			assert.Equal(t, 86400, hardening.MaxBlockSeconds())
+		})
+	})
+
+	t.Run("when values are out of range", func(t *testing.T) {
+		viper.Reset()
+		viper.Set("hardening.uniform_response_milliseconds", -1)
+		viper.Set("hardening.not_found_limit", 0)
+		viper.Set("hardening.not_found_window_seconds", 0)
+		viper.Set("hardening.failure_limit", 0)
+		viper.Set("hardening.failure_window_seconds", 0)
+		viper.Set("hardening.block_seconds", 0)
+		viper.Set("hardening.max_block_seconds", 0)
+		hardening := NewHardeningConfiguration()
+
+		t.Run("it should clamp them", func(t *testing.T) {
+			assert.Equal(t, 0, hardening.UniformResponseMilliseconds())
+			assert.Equal(t, 1, hardening.NotFoundLimit())
+			assert.Equal(t, 1, hardening.NotFoundWindowSeconds())
+			assert.Equal(t, 1, hardening.FailureLimit())
+			assert.Equal(t, 1, hardening.FailureWindowSeconds())
+			assert.Equal(t, 1, hardening.BlockSeconds())
+			assert.Equal(t, 1, hardening.MaxBlockSeconds())
+		})
+	})
+}
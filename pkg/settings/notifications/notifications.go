@@ -0,0 +1,19 @@
+package notifications
+
+type INotificationsConfiguration interface {
+	Smtp() ISmtpConfiguration
+}
+
+const (
+	notificationsKey = "notifications."
+)
+
+type NotificationsConfiguration struct{}
+
+func NewNotificationsConfiguration() *NotificationsConfiguration {
+	return &NotificationsConfiguration{}
+}
+
+func (n *NotificationsConfiguration) Smtp() ISmtpConfiguration {
+	return NewSmtpConfiguration()
+}
@@ -0,0 +1,102 @@
+package notifications
+
+import (
+	"errors"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	smtpKey          = notificationsKey + "smtp."
+	smtpEnabledKey   = smtpKey + "enabled"
+	smtpHostKey      = smtpKey + "host"
+	smtpPortKey      = smtpKey + "port"
+	smtpUsernameKey  = smtpKey + "username"
+	smtpPasswordKey  = smtpKey + "password"
+	smtpFromKey      = smtpKey + "from"
+	smtpQueueSizeKey = smtpKey + "queue_size"
+	smtpTimeoutKey   = smtpKey + "timeout_seconds"
+)
+
+//go:generate mockgen -destination=../../mocks/mock_smtp_configuration.go -package=mocks cellar/pkg/settings/notifications ISmtpConfiguration
+type ISmtpConfiguration interface {
+	Enabled() bool
+	Host() string
+	Port() int
+	Username() string
+	Password() string
+	From() string
+	QueueSize() int
+	TimeoutSeconds() int
+	Validate() error
+}
+
+type SmtpConfiguration struct{}
+
+func NewSmtpConfiguration() *SmtpConfiguration {
+	viper.SetDefault(smtpEnabledKey, false)
+	viper.SetDefault(smtpHostKey, "localhost")
+	viper.SetDefault(smtpPortKey, 25)
+	viper.SetDefault(smtpUsernameKey, "")
+	viper.SetDefault(smtpPasswordKey, "")
+	viper.SetDefault(smtpFromKey, "")
+	viper.SetDefault(smtpQueueSizeKey, 100)
+	viper.SetDefault(smtpTimeoutKey, 30)
+	return &SmtpConfiguration{}
+}
+
+func (smtp SmtpConfiguration) Enabled() bool {
+	return viper.GetBool(smtpEnabledKey)
+}
+
+func (smtp SmtpConfiguration) Host() string {
+	return viper.GetString(smtpHostKey)
+}
+
+func (smtp SmtpConfiguration) Port() int {
+	return viper.GetInt(smtpPortKey)
+}
+
+func (smtp SmtpConfiguration) Username() string {
+	return viper.GetString(smtpUsernameKey)
+}
+
+func (smtp SmtpConfiguration) Password() string {
+	return viper.GetString(smtpPasswordKey)
+}
+
+func (smtp SmtpConfiguration) From() string {
+	return viper.GetString(smtpFromKey)
+}
+
+func (smtp SmtpConfiguration) QueueSize() int {
+	value := viper.GetInt(smtpQueueSizeKey)
+	if value < 1 {
+		return 1
+	}
+	return value
+}
+
+// TimeoutSeconds bounds how long sending a single notification may take, so a mail server that stops
+// responding cannot hold up the notifications queued behind it.
+func (smtp SmtpConfiguration) TimeoutSeconds() int {
+	value := viper.GetInt(smtpTimeoutKey)
+	if value < 1 {
+		return 1
+	}
+	return value
+}
+
+func (smtp SmtpConfiguration) Validate() error {
+	if smtp.Host() == "" {
+		return errors.New("SMTP host not set")
+	}
+	if smtp.Port() <= 0 {
+		return errors.New("SMTP port not set")
+	}
+	if smtp.From() == "" {
+		return errors.New("SMTP from address not set")
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package settings
+
+import (
+	"github.com/spf13/viper"
+)
+
+const (
+	oidcKey                        = authKey + "oidc."
+	oidcEnabledKey                 = oidcKey + "enabled"
+	oidcIssuerKey                  = oidcKey + "issuer"
+	oidcAudienceKey                = oidcKey + "audience"
+	oidcJWKSURLKey                 = oidcKey + "jwks_url"
+	oidcJWKSFileKey                = oidcKey + "jwks_file"
+	oidcJWKSCacheSecondsKey        = oidcKey + "jwks_cache_seconds"
+	oidcGroupsClaimKey             = oidcKey + "groups_claim"
+	oidcTenantClaimKey             = oidcKey + "tenant_claim"
+	oidcAllowedGroupsKey           = oidcKey + "allowed_groups"
+	oidcScopesKey                  = oidcKey + "scopes"
+	oidcUserCreateLimitKey         = oidcKey + "user_create_limit"
+	oidcUserCreateWindowSecondsKey = oidcKey + "user_create_window_seconds"
+)
+
+//go:generate mockgen -destination=../mocks/mock_oidc_configuration.go -package=mocks cellar/pkg/settings IOIDCConfiguration
+type IOIDCConfiguration interface {
+	Enabled() bool
+	Issuer() string
+	Audience() string
+	JWKSURL() string
+	JWKSFile() string
+	JWKSCacheSeconds() int
+	GroupsClaim() string
+	TenantClaim() string
+	AllowedGroups() []string
+	Scopes() []string
+	UserCreateLimit() int
+	UserCreateWindowSeconds() int
+}
+
+type OIDCConfiguration struct{}
+
+func NewOIDCConfiguration() *OIDCConfiguration {
+	viper.SetDefault(oidcEnabledKey, false)
+	viper.SetDefault(oidcIssuerKey, "")
+	viper.SetDefault(oidcAudienceKey, "")
+	viper.SetDefault(oidcJWKSURLKey, "")
+	viper.SetDefault(oidcJWKSFileKey, "")
+	viper.SetDefault(oidcJWKSCacheSecondsKey, 3600)
+	viper.SetDefault(oidcGroupsClaimKey, "groups")
+	viper.SetDefault(oidcTenantClaimKey, "tenant")
+	viper.SetDefault(oidcAllowedGroupsKey, "")
+	viper.SetDefault(oidcScopesKey, "create")
+	viper.SetDefault(oidcUserCreateLimitKey, 0)
+	viper.SetDefault(oidcUserCreateWindowSecondsKey, 86400)
+	return &OIDCConfiguration{}
+}
+
+// Enabled reports whether bearer tokens that are not API keys are verified as JWTs from the configured issuer.
+func (oidc OIDCConfiguration) Enabled() bool {
+	return viper.GetBool(oidcEnabledKey)
+}
+
+// Issuer is the value the iss claim of every token must have. It is required when OIDC is enabled.
+func (oidc OIDCConfiguration) Issuer() string {
+	return viper.GetString(oidcIssuerKey)
+}
+
+// Audience is the value the aud claim of every token must include. It is required when OIDC is enabled.
+func (oidc OIDCConfiguration) Audience() string {
+	return viper.GetString(oidcAudienceKey)
+}
+
+// JWKSURL is where the keys tokens are signed with are fetched from.
+func (oidc OIDCConfiguration) JWKSURL() string {
+	return viper.GetString(oidcJWKSURLKey)
+}
+
+// JWKSFile is a local file the keys are read from instead of the JWKS URL, such as for testing without the issuer.
+func (oidc OIDCConfiguration) JWKSFile() string {
+	return viper.GetString(oidcJWKSFileKey)
+}
+
+// JWKSCacheSeconds is how long fetched keys are used before they are fetched again.
+func (oidc OIDCConfiguration) JWKSCacheSeconds() int {
+	value := viper.GetInt(oidcJWKSCacheSecondsKey)
+	if value < 60 {
+		return 60
+	}
+	return value
+}
+
+// GroupsClaim is the claim that lists the groups of the user.
+func (oidc OIDCConfiguration) GroupsClaim() string {
+	return viper.GetString(oidcGroupsClaimKey)
+}
+
+// TenantClaim is the claim that names the tenant of the user.
+func (oidc OIDCConfiguration) TenantClaim() string {
+	return viper.GetString(oidcTenantClaimKey)
+}
+
+// AllowedGroups returns the groups a user must be in one of. An empty list allows every user of the issuer.
+// Groups can be separated by commas or whitespace.
+func (oidc OIDCConfiguration) AllowedGroups() []string {
+	return getList(oidcAllowedGroupsKey)
+}
+
+// Scopes returns the scopes granted to users authenticated with a token.
+func (oidc OIDCConfiguration) Scopes() []string {
+	return getList(oidcScopesKey)
+}
+
+// UserCreateLimit is how many secrets, splits and requests each user can create per window. Zero does not limit users.
+func (oidc OIDCConfiguration) UserCreateLimit() int {
+	value := viper.GetInt(oidcUserCreateLimitKey)
+	if value < 0 {
+		return 0
+	}
+	return value
+}
+
+// UserCreateWindowSeconds is the window the creations of each user are counted in.
+func (oidc OIDCConfiguration) UserCreateWindowSeconds() int {
+	value := viper.GetInt(oidcUserCreateWindowSecondsKey)
+	if value < 60 {
+		return 60
+	}
+	return value
+}
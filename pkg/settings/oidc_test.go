@@ -0,0 +1,59 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOIDCConfiguration(t *testing.T) {
+	t.Run("when nothing is set", func(t *testing.T) {
+		viper.Reset()
+		oidc := NewOIDCConfiguration()
+
+		t.Run("it should not verify tokens", func(t *testing.T) {
+			assert.False(t, oidc.Enabled())
+		})
+
+		t.Run("it should cache keys for an hour", func(t *testing.T) {
+			assert.Equal(t, 3600, oidc.JWKSCacheSeconds())
+		})
+
+		t.Run("it should read groups from the groups claim and allow every group", func(t *testing.T) {
+			assert.Equal(t, "groups", oidc.GroupsClaim())
+			assert.Equal(t, "tenant", oidc.TenantClaim())
+			assert.Empty(t, oidc.AllowedGroups())
+		})
+
+		t.Run("it should let users create without a limit", func(t *testing.T) {
+			assert.Equal(t, []string{"create"}, oidc.Scopes())
+			assert.Equal(t, 0, oidc.UserCreateLimit())
+			assert.Equal(t, 86400, oidc.UserCreateWindowSeconds())
+		})
+	})
+
+	t.Run("when values are out of range", func(t *testing.T) {
+		viper.Reset()
+		viper.Set("auth.oidc.jwks_cache_seconds", 0)
+		viper.Set("auth.oidc.user_create_limit", -1)
+		viper.Set("auth.oidc.user_create_window_seconds", 0)
+		oidc := NewOIDCConfiguration()
+
+		t.Run("it should clamp them", func(t *testing.T) {
+			assert.Equal(t, 60, oidc.JWKSCacheSeconds())
+			assert.Equal(t, 0, oidc.UserCreateLimit())
+			assert.Equal(t, 60, oidc.UserCreateWindowSeconds())
+		})
+	})
+
+	t.Run("when allowed groups are set", func(t *testing.T) {
+		viper.Reset()
+		viper.Set("auth.oidc.allowed_groups", "staff, security")
+		oidc := NewOIDCConfiguration()
+
+		t.Run("it should return them", func(t *testing.T) {
+			assert.Equal(t, []string{"staff", "security"}, oidc.AllowedGroups())
+		})
+	})
+}
@@ -3,6 +3,7 @@ package settings
 import (
 	"cellar/pkg/settings/cryptography"
 	"cellar/pkg/settings/datastore"
+	"cellar/pkg/settings/notifications"
 	"strings"
 
 	"github.com/spf13/viper"
@@ -16,26 +17,41 @@ type IConfiguration interface {
 	Datastore() datastore.IDatastoreConfiguration
 	Encryption() cryptography.IEncryptionConfiguration
 	Logging() ILoggingConfiguration
+	Notifications() notifications.INotificationsConfiguration
 	RateLimit() IRateLimitConfiguration
+	GeoIP() IGeoIPConfiguration
+	Hardening() IHardeningConfiguration
+	Auth() IAuthConfiguration
+	Tenants() ITenantsConfiguration
 }
 
 type Configuration struct {
-	app        IAppConfiguration
-	datastore  datastore.IDatastoreConfiguration
-	encryption cryptography.IEncryptionConfiguration
-	logging    ILoggingConfiguration
-	rateLimit  IRateLimitConfiguration
+	app           IAppConfiguration
+	datastore     datastore.IDatastoreConfiguration
+	encryption    cryptography.IEncryptionConfiguration
+	logging       ILoggingConfiguration
+	notifications notifications.INotificationsConfiguration
+	rateLimit     IRateLimitConfiguration
+	geoIP         IGeoIPConfiguration
+	hardening     IHardeningConfiguration
+	auth          IAuthConfiguration
+	tenants       ITenantsConfiguration
 }
 
 func NewConfiguration() *Configuration {
 	viper.AutomaticEnv()
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	return &Configuration{
-		app:        NewAppConfiguration(),
-		datastore:  datastore.NewDatastoreConfiguration(),
-		encryption: cryptography.NewEncryptionConfiguration(),
-		logging:    NewLoggingConfiguration(),
-		rateLimit:  NewRateLimitConfiguration(),
+		app:           NewAppConfiguration(),
+		datastore:     datastore.NewDatastoreConfiguration(),
+		encryption:    cryptography.NewEncryptionConfiguration(),
+		logging:       NewLoggingConfiguration(),
+		notifications: notifications.NewNotificationsConfiguration(),
+		rateLimit:     NewRateLimitConfiguration(),
+		geoIP:         NewGeoIPConfiguration(),
+		hardening:     NewHardeningConfiguration(),
+		auth:          NewAuthConfiguration(),
+		tenants:       NewTenantsConfiguration(),
 	}
 }
 
@@ -51,4 +67,29 @@ func (config Configuration) Encryption() cryptography.IEncryptionConfiguration {
 
 func (config Configuration) Logging() ILoggingConfiguration { return config.logging }
 
+func (config Configuration) Notifications() notifications.INotificationsConfiguration {
+	return config.notifications
+}
+
 func (config Configuration) RateLimit() IRateLimitConfiguration { return config.rateLimit }
+
+func (config Configuration) GeoIP() IGeoIPConfiguration { return config.geoIP }
+
+func (config Configuration) Hardening() IHardeningConfiguration { return config.hardening }
+
+func (config Configuration) Auth() IAuthConfiguration { return config.auth }
+
+func (config Configuration) Tenants() ITenantsConfiguration { return config.tenants }
+
+// getList returns the values of a list setting, which can be separated by commas or whitespace.
+func getList(key string) []string {
+	var values []string
+	for _, value := range viper.GetStringSlice(key) {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,33 @@
+package settings
+
+import (
+	"github.com/spf13/viper"
+)
+
+const (
+	tenantsKey     = "tenants."
+	tenantsFileKey = tenantsKey + "file"
+)
+
+//go:generate mockgen -destination=../mocks/mock_tenants_configuration.go -package=mocks cellar/pkg/settings ITenantsConfiguration
+type ITenantsConfiguration interface {
+	Enabled() bool
+	File() string
+}
+
+type TenantsConfiguration struct{}
+
+func NewTenantsConfiguration() *TenantsConfiguration {
+	viper.SetDefault(tenantsFileKey, "")
+	return &TenantsConfiguration{}
+}
+
+// Enabled reports whether a tenants file is configured.
+func (tenants TenantsConfiguration) Enabled() bool {
+	return tenants.File() != ""
+}
+
+// File is the JSON file that defines the tenants, their limits, keys and rate limits.
+func (tenants TenantsConfiguration) File() string {
+	return viper.GetString(tenantsFileKey)
+}
@@ -0,0 +1,30 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenantsConfiguration(t *testing.T) {
+	t.Run("when nothing is set", func(t *testing.T) {
+		viper.Reset()
+		tenants := NewTenantsConfiguration()
+
+		t.Run("it should be disabled", func(t *testing.T) {
+			assert.False(t, tenants.Enabled())
+		})
+	})
+
+	t.Run("when a tenants file is set", func(t *testing.T) {
+		viper.Reset()
+		viper.Set("tenants.file", "/etc/cellar/tenants.json")
+		tenants := NewTenantsConfiguration()
+
+		t.Run("it should be enabled", func(t *testing.T) {
+			assert.True(t, tenants.Enabled())
+			assert.Equal(t, "/etc/cellar/tenants.json", tenants.File())
+		})
+	})
+}
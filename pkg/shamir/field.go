@@ -0,0 +1,38 @@
+package shamir
+
+// Arithmetic in GF(2^8) with the AES reduction polynomial x^8 + x^4 + x^3 + x + 1.
+// Multiplication and inversion avoid lookup tables and data dependent branches so
+// their timing does not depend on the secret.
+
+func add(a, b byte) byte {
+	return a ^ b
+}
+
+func mul(a, b byte) byte {
+	var result byte
+	for i := 0; i < 8; i++ {
+		// mask is 0xff when the low bit of b is set and 0x00 otherwise.
+		mask := -(b & 1)
+		result ^= a & mask
+
+		carry := -(a >> 7)
+		a = (a << 1) ^ (0x1b & carry)
+		b >>= 1
+	}
+	return result
+}
+
+// inverse returns the multiplicative inverse of a, which is a^254 as a^255 = 1 for every non-zero a.
+// The inverse of zero is zero.
+func inverse(a byte) byte {
+	result := a
+	for i := 0; i < 6; i++ {
+		result = mul(result, result)
+		result = mul(result, a)
+	}
+	return mul(result, result)
+}
+
+func div(a, b byte) byte {
+	return mul(a, inverse(b))
+}
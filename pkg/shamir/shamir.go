@@ -0,0 +1,114 @@
+// Package shamir splits a secret into shares with Shamir's secret sharing over GF(2^8),
+// so that any threshold of the shares reconstructs the secret and fewer reveal nothing about it.
+package shamir
+
+import (
+	"cellar/pkg/plaintext"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// MaxShares is the largest number of shares, one per non-zero element of the field.
+const MaxShares = 255
+
+// Split divides the secret into the given number of shares, any threshold of which reconstruct it.
+// Each share holds one byte per secret byte followed by its x coordinate.
+func Split(secret []byte, shares int, threshold int) ([][]byte, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("secret cannot be empty")
+	}
+	if threshold < 2 || threshold > shares {
+		return nil, fmt.Errorf("threshold must be between 2 and %d", shares)
+	}
+	if shares > MaxShares {
+		return nil, fmt.Errorf("shares cannot exceed %d", MaxShares)
+	}
+
+	result := make([][]byte, shares)
+	for i := range result {
+		result[i] = make([]byte, len(secret)+1)
+		result[i][len(secret)] = byte(i + 1)
+	}
+
+	// Each secret byte is the constant term of its own random polynomial of degree threshold-1.
+	coefficients := make([]byte, threshold)
+	for i, value := range secret {
+		if _, err := rand.Read(coefficients[1:]); err != nil {
+			return nil, err
+		}
+		coefficients[0] = value
+
+		for _, share := range result {
+			share[i] = evaluate(coefficients, share[len(secret)])
+		}
+	}
+
+	return result, nil
+}
+
+// Combine reconstructs the secret from shares produced by Split.
+// Returns an error if the shares are malformed or duplicated; too few shares silently yield the wrong secret.
+// The secret is allocated with plaintext.Alloc.
+func Combine(shares [][]byte) ([]byte, error) {
+	if len(shares) < 2 {
+		return nil, errors.New("at least two shares are required")
+	}
+
+	length := len(shares[0])
+	if length < 2 {
+		return nil, errors.New("shares are too short")
+	}
+
+	xs := make([]byte, len(shares))
+	seen := make(map[byte]bool, len(shares))
+	for i, share := range shares {
+		if len(share) != length {
+			return nil, errors.New("shares must all be the same length")
+		}
+		x := share[length-1]
+		if x == 0 || seen[x] {
+			return nil, errors.New("shares must have distinct non-zero coordinates")
+		}
+		seen[x] = true
+		xs[i] = x
+	}
+
+	secret := plaintext.Alloc(length - 1)
+	ys := make([]byte, len(shares))
+	defer plaintext.Wipe(ys)
+	for i := range secret {
+		for j, share := range shares {
+			ys[j] = share[i]
+		}
+		secret[i] = interpolateAtZero(xs, ys)
+	}
+
+	return secret, nil
+}
+
+// evaluate returns the value of the polynomial with the given coefficients at x, using Horner's method.
+func evaluate(coefficients []byte, x byte) byte {
+	var result byte
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		result = add(mul(result, x), coefficients[i])
+	}
+	return result
+}
+
+// interpolateAtZero returns the value at zero of the polynomial through the given points, by Lagrange interpolation.
+func interpolateAtZero(xs []byte, ys []byte) byte {
+	var result byte
+	for i := range xs {
+		basis := byte(1)
+		for j := range xs {
+			if i == j {
+				continue
+			}
+			// In GF(2^8) subtraction is addition, so 0 - x_j = x_j.
+			basis = mul(basis, div(xs[j], add(xs[i], xs[j])))
+		}
+		result = add(result, mul(ys[i], basis))
+	}
+	return result
+}
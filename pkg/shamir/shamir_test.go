@@ -0,0 +1,81 @@
+package shamir
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestField(t *testing.T) {
+	t.Run("when inverting every non-zero element", func(t *testing.T) {
+		t.Run("it should multiply back to one", func(t *testing.T) {
+			for a := 1; a < 256; a++ {
+				assert.Equal(t, byte(1), mul(byte(a), inverse(byte(a))), "element %d", a)
+			}
+		})
+	})
+
+	t.Run("when multiplying by a known product", func(t *testing.T) {
+		t.Run("it should reduce by the AES polynomial", func(t *testing.T) {
+			assert.Equal(t, byte(0xc1), mul(0x57, 0x83))
+		})
+	})
+}
+
+func TestSplit(t *testing.T) {
+	secret := []byte("break glass credentials")
+
+	t.Run("when splitting a secret", func(t *testing.T) {
+		shares, err := Split(secret, 5, 3)
+		require.NoError(t, err)
+
+		t.Run("it should return the requested number of shares", func(t *testing.T) {
+			assert.Len(t, shares, 5)
+			for _, share := range shares {
+				assert.Len(t, share, len(secret)+1)
+			}
+		})
+
+		t.Run("it should reconstruct the secret from any threshold of shares", func(t *testing.T) {
+			for _, subset := range [][]int{{0, 1, 2}, {0, 2, 4}, {4, 3, 1}, {0, 1, 2, 3, 4}} {
+				selected := make([][]byte, 0, len(subset))
+				for _, i := range subset {
+					selected = append(selected, shares[i])
+				}
+
+				combined, err := Combine(selected)
+				require.NoError(t, err)
+				assert.Equal(t, secret, combined, "shares %v", subset)
+			}
+		})
+
+		t.Run("it should not reconstruct the secret from fewer shares", func(t *testing.T) {
+			combined, err := Combine(shares[:2])
+			require.NoError(t, err)
+			assert.NotEqual(t, secret, combined)
+		})
+
+		t.Run("it should reject duplicated shares", func(t *testing.T) {
+			_, err := Combine([][]byte{shares[0], shares[0], shares[1]})
+			assert.Error(t, err)
+		})
+	})
+
+	t.Run("when the threshold is invalid", func(t *testing.T) {
+		t.Run("it should return an error", func(t *testing.T) {
+			_, err := Split(secret, 3, 1)
+			assert.Error(t, err)
+
+			_, err = Split(secret, 3, 4)
+			assert.Error(t, err)
+		})
+	})
+
+	t.Run("when there are too many shares", func(t *testing.T) {
+		t.Run("it should return an error", func(t *testing.T) {
+			_, err := Split(secret, MaxShares+1, 2)
+			assert.Error(t, err)
+		})
+	})
+}
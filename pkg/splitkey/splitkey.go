@@ -0,0 +1,93 @@
+// Package splitkey encrypts secret content with a per-secret key that is handed to the creator and never stored,
+// so the server cannot decrypt the content on its own.
+package splitkey
+
+import (
+	"cellar/pkg/plaintext"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+)
+
+const keySize = 32
+
+// checkContext separates the key check from any other use of the key.
+const checkContext = "cellar split key check\x00"
+
+// ErrInvalidKey is returned when a key is malformed or does not decrypt the content.
+var ErrInvalidKey = errors.New("invalid split key")
+
+// NewKey generates a random key, encoded as unpadded URL-safe base64 so it can be carried in a link fragment.
+func NewKey() (string, error) {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(key), nil
+}
+
+// Check returns the value stored in place of the key, used to reject a wrong key before the content is decrypted.
+func Check(key string) string {
+	sum := sha256.Sum256([]byte(checkContext + key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Matches compares a presented key against a stored check in constant time.
+func Matches(check string, key string) bool {
+	if check == "" || key == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(Check(key)), []byte(check)) == 1
+}
+
+// Seal encrypts the content with AES-256-GCM under the key. The random nonce is prepended to the ciphertext.
+func Seal(key string, content []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(content)+aead.Overhead())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, content, nil), nil
+}
+
+// Open decrypts content sealed with Seal into a slice allocated with plaintext.Alloc. A wrong key returns ErrInvalidKey.
+func Open(key string, sealed []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrInvalidKey
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	content := plaintext.Alloc(len(ciphertext))
+	opened, err := aead.Open(content[:0], nonce, ciphertext, nil)
+	if err != nil {
+		plaintext.Wipe(content)
+		return nil, ErrInvalidKey
+	}
+	return opened, nil
+}
+
+func newAEAD(key string) (cipher.AEAD, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(key)
+	if err != nil || len(raw) != keySize {
+		return nil, ErrInvalidKey
+	}
+
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,69 @@
+package splitkey
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealAndOpen(t *testing.T) {
+	key, err := NewKey()
+	require.NoError(t, err)
+
+	sealed, err := Seal(key, []byte("my very secret text"))
+	require.NoError(t, err)
+
+	t.Run("when opening with the same key", func(t *testing.T) {
+		content, err := Open(key, sealed)
+
+		t.Run("it should return the content", func(t *testing.T) {
+			require.NoError(t, err)
+			assert.Equal(t, []byte("my very secret text"), content)
+		})
+	})
+
+	t.Run("when opening with another key", func(t *testing.T) {
+		otherKey, err := NewKey()
+		require.NoError(t, err)
+
+		_, err = Open(otherKey, sealed)
+
+		t.Run("it should return ErrInvalidKey", func(t *testing.T) {
+			assert.ErrorIs(t, err, ErrInvalidKey)
+		})
+	})
+
+	t.Run("when opening with a malformed key", func(t *testing.T) {
+		_, err := Open("not a key", sealed)
+
+		t.Run("it should return ErrInvalidKey", func(t *testing.T) {
+			assert.ErrorIs(t, err, ErrInvalidKey)
+		})
+	})
+}
+
+func TestMatches(t *testing.T) {
+	key, err := NewKey()
+	require.NoError(t, err)
+	check := Check(key)
+
+	t.Run("it should match the key it was made from", func(t *testing.T) {
+		assert.True(t, Matches(check, key))
+	})
+
+	t.Run("it should not match another key", func(t *testing.T) {
+		otherKey, err := NewKey()
+		require.NoError(t, err)
+		assert.False(t, Matches(check, otherKey))
+	})
+
+	t.Run("it should not match an empty key or check", func(t *testing.T) {
+		assert.False(t, Matches(check, ""))
+		assert.False(t, Matches("", key))
+	})
+
+	t.Run("it should not reveal the key", func(t *testing.T) {
+		assert.NotContains(t, check, key)
+	})
+}
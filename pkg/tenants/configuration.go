@@ -0,0 +1,111 @@
+package tenants
+
+import (
+	"cellar/pkg/settings"
+	"cellar/pkg/settings/cryptography"
+)
+
+type (
+	// Configuration is the configuration of the server as seen by a tenant. The limits, encryption key
+	// and rate limits the tenant sets replace those of the server; everything else is the server's.
+	Configuration struct {
+		settings.IConfiguration
+		tenant *Tenant
+	}
+
+	appConfiguration struct {
+		settings.IAppConfiguration
+		tenant *Tenant
+	}
+
+	rateLimitConfiguration struct {
+		settings.IRateLimitConfiguration
+		limits RateLimits
+	}
+
+	encryptionConfiguration struct {
+		cryptography.IEncryptionConfiguration
+		tenant *Tenant
+	}
+
+	vaultConfiguration struct {
+		cryptography.IVaultConfiguration
+		transitKey string
+	}
+
+	awsConfiguration struct {
+		cryptography.IAwsConfiguration
+		kmsKeyId string
+	}
+)
+
+func NewConfiguration(server settings.IConfiguration, tenant *Tenant) *Configuration {
+	return &Configuration{IConfiguration: server, tenant: tenant}
+}
+
+func (config Configuration) App() settings.IAppConfiguration {
+	return appConfiguration{IAppConfiguration: config.IConfiguration.App(), tenant: config.tenant}
+}
+
+func (config Configuration) RateLimit() settings.IRateLimitConfiguration {
+	return rateLimitConfiguration{IRateLimitConfiguration: config.IConfiguration.RateLimit(), limits: config.tenant.RateLimit}
+}
+
+func (config Configuration) Encryption() cryptography.IEncryptionConfiguration {
+	return encryptionConfiguration{IEncryptionConfiguration: config.IConfiguration.Encryption(), tenant: config.tenant}
+}
+
+func (app appConfiguration) MaxFileSizeMB() int {
+	return override(app.tenant.MaxFileSizeMB, app.IAppConfiguration.MaxFileSizeMB)
+}
+
+func (app appConfiguration) MaxAccessCount() int {
+	return override(app.tenant.MaxAccessCount, app.IAppConfiguration.MaxAccessCount)
+}
+
+func (app appConfiguration) MaxExpirationSeconds() int {
+	return override(app.tenant.MaxExpirationSeconds, app.IAppConfiguration.MaxExpirationSeconds)
+}
+
+func (rlc rateLimitConfiguration) Tier1RequestsPerWindow() int {
+	return override(rlc.limits.Tier1RequestsPerWindow, rlc.IRateLimitConfiguration.Tier1RequestsPerWindow)
+}
+
+func (rlc rateLimitConfiguration) Tier2RequestsPerWindow() int {
+	return override(rlc.limits.Tier2RequestsPerWindow, rlc.IRateLimitConfiguration.Tier2RequestsPerWindow)
+}
+
+func (rlc rateLimitConfiguration) Tier3RequestsPerWindow() int {
+	return override(rlc.limits.Tier3RequestsPerWindow, rlc.IRateLimitConfiguration.Tier3RequestsPerWindow)
+}
+
+func (e encryptionConfiguration) Vault() cryptography.IVaultConfiguration {
+	vault := e.IEncryptionConfiguration.Vault()
+	if e.tenant.VaultTransitKey == "" {
+		return vault
+	}
+	return vaultConfiguration{IVaultConfiguration: vault, transitKey: e.tenant.VaultTransitKey}
+}
+
+func (e encryptionConfiguration) Aws() cryptography.IAwsConfiguration {
+	aws := e.IEncryptionConfiguration.Aws()
+	if e.tenant.AwsKmsKeyId == "" {
+		return aws
+	}
+	return awsConfiguration{IAwsConfiguration: aws, kmsKeyId: e.tenant.AwsKmsKeyId}
+}
+
+func (vault vaultConfiguration) EncryptionTokenName() string {
+	return vault.transitKey
+}
+
+func (aws awsConfiguration) KmsKeyId() string {
+	return aws.kmsKeyId
+}
+
+func override(value *int, server func() int) int {
+	if value != nil {
+		return *value
+	}
+	return server()
+}
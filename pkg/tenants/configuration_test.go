@@ -0,0 +1,58 @@
+package tenants_test
+
+import (
+	"cellar/pkg/settings"
+	"cellar/pkg/tenants"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfiguration(t *testing.T) {
+	viper.Reset()
+	viper.Set("cryptography.vault.encryption_token_name", "cellar")
+	viper.Set("cryptography.aws.kms_key_id", "alias/cellar")
+	server := settings.NewConfiguration()
+
+	t.Run("when the tenant sets its own limits and keys", func(t *testing.T) {
+		maxAccessCount, maxFileSizeMB, tier1 := 5, 1, 10
+		sut := tenants.NewConfiguration(server, &tenants.Tenant{
+			ID:              "finance",
+			MaxAccessCount:  &maxAccessCount,
+			MaxFileSizeMB:   &maxFileSizeMB,
+			VaultTransitKey: "finance",
+			AwsKmsKeyId:     "alias/finance",
+			RateLimit:       tenants.RateLimits{Tier1RequestsPerWindow: &tier1},
+		})
+
+		t.Run("it should use the limits of the tenant", func(t *testing.T) {
+			assert.Equal(t, 5, sut.App().MaxAccessCount())
+			assert.Equal(t, 1, sut.App().MaxFileSizeMB())
+			assert.Equal(t, 10, sut.RateLimit().Tier1RequestsPerWindow())
+		})
+
+		t.Run("it should use the keys of the tenant", func(t *testing.T) {
+			assert.Equal(t, "finance", sut.Encryption().Vault().EncryptionTokenName())
+			assert.Equal(t, "alias/finance", sut.Encryption().Aws().KmsKeyId())
+		})
+
+		t.Run("it should use the server's settings the tenant does not set", func(t *testing.T) {
+			assert.Equal(t, server.App().MaxExpirationSeconds(), sut.App().MaxExpirationSeconds())
+			assert.Equal(t, server.RateLimit().Tier2RequestsPerWindow(), sut.RateLimit().Tier2RequestsPerWindow())
+			assert.Equal(t, server.RateLimit().WindowSeconds(), sut.RateLimit().WindowSeconds())
+			assert.Equal(t, server.App().MaxKVFields(), sut.App().MaxKVFields())
+		})
+	})
+
+	t.Run("when the tenant sets nothing", func(t *testing.T) {
+		sut := tenants.NewConfiguration(server, &tenants.Tenant{ID: "sales"})
+
+		t.Run("it should use the settings of the server", func(t *testing.T) {
+			assert.Equal(t, server.App().MaxAccessCount(), sut.App().MaxAccessCount())
+			assert.Equal(t, server.RateLimit().Tier1RequestsPerWindow(), sut.RateLimit().Tier1RequestsPerWindow())
+			assert.Equal(t, "cellar", sut.Encryption().Vault().EncryptionTokenName())
+			assert.Equal(t, "alias/cellar", sut.Encryption().Aws().KmsKeyId())
+		})
+	})
+}
@@ -0,0 +1,51 @@
+package tenants
+
+import (
+	"cellar/pkg/cryptography"
+	"cellar/pkg/datastore"
+	"cellar/pkg/ratelimit"
+	"cellar/pkg/settings"
+)
+
+type (
+	// Namespace holds the configuration and clients of a tenant,
+	// which replace those shared by the server for the requests of the tenant.
+	Namespace struct {
+		Tenant        *Tenant
+		Configuration settings.IConfiguration
+		Encryption    cryptography.Encryption
+		DataStore     datastore.DataStore
+		RateLimiter   ratelimit.RateLimiter
+	}
+
+	// Namespaces finds the namespace of a tenant by its ID or by one of its hosts.
+	Namespaces struct {
+		byId   map[string]*Namespace
+		byHost map[string]*Namespace
+	}
+)
+
+func NewNamespaces(namespaces []*Namespace) *Namespaces {
+	result := &Namespaces{
+		byId:   map[string]*Namespace{},
+		byHost: map[string]*Namespace{},
+	}
+	for _, namespace := range namespaces {
+		result.byId[namespace.Tenant.ID] = namespace
+		for _, host := range namespace.Tenant.Hosts {
+			result.byHost[host] = namespace
+		}
+	}
+	return result
+}
+
+// ByID returns the namespace of the tenant with the ID, or nil if there is no such tenant.
+func (namespaces *Namespaces) ByID(id string) *Namespace {
+	return namespaces.byId[id]
+}
+
+// ByHost returns the namespace of the tenant served on the host, which may include a port,
+// or nil if the host belongs to no tenant.
+func (namespaces *Namespaces) ByHost(host string) *Namespace {
+	return namespaces.byHost[normalizeHost(host)]
+}
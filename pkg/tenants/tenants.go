@@ -0,0 +1,145 @@
+package tenants
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Key holds the namespaces of every tenant, and ResolvedKey the tenant a request was resolved to.
+const (
+	Key         = "TENANTS"
+	ResolvedKey = "TENANT"
+)
+
+var idPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+type (
+	// Tenant is a business unit served by the same server, with its own namespace in the datastore,
+	// and its own limits, encryption key and rate limits. Limits that are not set are those of the server.
+	Tenant struct {
+		ID                   string     `json:"id"`
+		Hosts                []string   `json:"hosts"`
+		KeyPrefix            string     `json:"key_prefix"`
+		MaxFileSizeMB        *int       `json:"max_file_size_mb"`
+		MaxAccessCount       *int       `json:"max_access_count"`
+		MaxExpirationSeconds *int       `json:"max_expiration_seconds"`
+		VaultTransitKey      string     `json:"vault_transit_key"`
+		AwsKmsKeyId          string     `json:"aws_kms_key_id"`
+		RateLimit            RateLimits `json:"rate_limit"`
+	}
+
+	// RateLimits are the requests a client of the tenant can make per window in each tier.
+	RateLimits struct {
+		Tier1RequestsPerWindow *int `json:"tier1_requests_per_window"`
+		Tier2RequestsPerWindow *int `json:"tier2_requests_per_window"`
+		Tier3RequestsPerWindow *int `json:"tier3_requests_per_window"`
+	}
+
+	file struct {
+		Tenants []Tenant `json:"tenants"`
+	}
+)
+
+// Load reads the tenants defined in a JSON file.
+func Load(path string) ([]Tenant, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse reads the tenants defined in JSON, and checks that each one is valid and that no two of them
+// share an ID, a host or a key prefix. Tenants without a key prefix are given "tenants:<id>:".
+func Parse(data []byte) ([]Tenant, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var parsed file
+	if err := decoder.Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("invalid tenants file: %w", err)
+	}
+
+	ids := map[string]bool{}
+	hosts := map[string]bool{}
+	prefixes := map[string]bool{}
+	for i := range parsed.Tenants {
+		tenant := &parsed.Tenants[i]
+		if tenant.KeyPrefix == "" {
+			tenant.KeyPrefix = "tenants:" + tenant.ID + ":"
+		}
+		for j, host := range tenant.Hosts {
+			tenant.Hosts[j] = normalizeHost(host)
+		}
+
+		if err := tenant.validate(); err != nil {
+			return nil, fmt.Errorf("tenant %q: %w", tenant.ID, err)
+		}
+
+		if ids[tenant.ID] {
+			return nil, fmt.Errorf("tenant %q is defined more than once", tenant.ID)
+		}
+		ids[tenant.ID] = true
+		for _, host := range tenant.Hosts {
+			if hosts[host] {
+				return nil, fmt.Errorf("tenant %q: host %q belongs to another tenant", tenant.ID, host)
+			}
+			hosts[host] = true
+		}
+		if prefixes[tenant.KeyPrefix] {
+			return nil, fmt.Errorf("tenant %q: key prefix %q belongs to another tenant", tenant.ID, tenant.KeyPrefix)
+		}
+		prefixes[tenant.KeyPrefix] = true
+	}
+
+	return parsed.Tenants, nil
+}
+
+func (tenant *Tenant) validate() error {
+	if !idPattern.MatchString(tenant.ID) {
+		return errors.New("the ID must be lowercase letters, digits, dashes and underscores")
+	}
+	if !strings.HasSuffix(tenant.KeyPrefix, ":") {
+		return errors.New("the key prefix must end with a colon")
+	}
+	for _, host := range tenant.Hosts {
+		if host == "" {
+			return errors.New("hosts must not be empty")
+		}
+	}
+
+	minimums := []struct {
+		name  string
+		value *int
+		min   int
+	}{
+		{name: "max_file_size_mb", value: tenant.MaxFileSizeMB, min: 0},
+		{name: "max_access_count", value: tenant.MaxAccessCount, min: 1},
+		{name: "max_expiration_seconds", value: tenant.MaxExpirationSeconds, min: 900},
+		{name: "tier1_requests_per_window", value: tenant.RateLimit.Tier1RequestsPerWindow, min: 1},
+		{name: "tier2_requests_per_window", value: tenant.RateLimit.Tier2RequestsPerWindow, min: 1},
+		{name: "tier3_requests_per_window", value: tenant.RateLimit.Tier3RequestsPerWindow, min: 1},
+	}
+	for _, minimum := range minimums {
+		if minimum.value != nil && *minimum.value < minimum.min {
+			return fmt.Errorf("%s must be at least %d", minimum.name, minimum.min)
+		}
+	}
+
+	return nil
+}
+
+// normalizeHost returns a host name without its port, in lowercase.
+func normalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		host = name
+	}
+	return strings.Trim(host, "[]")
+}
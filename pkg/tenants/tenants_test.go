@@ -0,0 +1,97 @@
+package tenants_test
+
+import (
+	"cellar/pkg/tenants"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("when the tenants are valid", func(t *testing.T) {
+		actual, err := tenants.Parse([]byte(`{"tenants": [
+			{"id": "finance", "hosts": ["Finance.Cellar.Example.com:443"], "max_access_count": 10, "vault_transit_key": "finance"},
+			{"id": "sales", "key_prefix": "sales:"}
+		]}`))
+		require.NoError(t, err)
+		require.Len(t, actual, 2)
+
+		t.Run("it should keep the settings of each tenant", func(t *testing.T) {
+			assert.Equal(t, "finance", actual[0].ID)
+			assert.Equal(t, 10, *actual[0].MaxAccessCount)
+			assert.Equal(t, "finance", actual[0].VaultTransitKey)
+			assert.Nil(t, actual[0].MaxFileSizeMB)
+		})
+
+		t.Run("it should store hosts without their port in lowercase", func(t *testing.T) {
+			assert.Equal(t, []string{"finance.cellar.example.com"}, actual[0].Hosts)
+		})
+
+		t.Run("it should give tenants without a key prefix one named after their ID", func(t *testing.T) {
+			assert.Equal(t, "tenants:finance:", actual[0].KeyPrefix)
+			assert.Equal(t, "sales:", actual[1].KeyPrefix)
+		})
+	})
+
+	invalid := map[string]string{
+		"the JSON is malformed":            `{"tenants": [`,
+		"a setting is unknown":             `{"tenants": [{"id": "finance", "max_size": 1}]}`,
+		"a tenant has no ID":               `{"tenants": [{"hosts": ["finance.example.com"]}]}`,
+		"an ID has a colon":                `{"tenants": [{"id": "finance:eu"}]}`,
+		"an ID is defined twice":           `{"tenants": [{"id": "finance"}, {"id": "finance", "key_prefix": "other:"}]}`,
+		"a host belongs to two tenants":    `{"tenants": [{"id": "finance", "hosts": ["a.example.com"]}, {"id": "sales", "hosts": ["A.example.com"]}]}`,
+		"a key prefix belongs to two":      `{"tenants": [{"id": "finance", "key_prefix": "shared:"}, {"id": "sales", "key_prefix": "shared:"}]}`,
+		"a key prefix does not end in ':'": `{"tenants": [{"id": "finance", "key_prefix": "finance"}]}`,
+		"a limit is below its minimum":     `{"tenants": [{"id": "finance", "max_expiration_seconds": 60}]}`,
+		"a rate limit is below 1":          `{"tenants": [{"id": "finance", "rate_limit": {"tier1_requests_per_window": 0}}]}`,
+	}
+	for name, data := range invalid {
+		t.Run("when "+name, func(t *testing.T) {
+			t.Run("it should return an error", func(t *testing.T) {
+				_, err := tenants.Parse([]byte(data))
+				assert.Error(t, err)
+			})
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("when the file exists", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "tenants.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"tenants": [{"id": "finance"}]}`), 0o600))
+
+		actual, err := tenants.Load(path)
+		require.NoError(t, err)
+
+		t.Run("it should return the tenants", func(t *testing.T) {
+			require.Len(t, actual, 1)
+			assert.Equal(t, "finance", actual[0].ID)
+		})
+	})
+
+	t.Run("when the file does not exist", func(t *testing.T) {
+		t.Run("it should return an error", func(t *testing.T) {
+			_, err := tenants.Load(filepath.Join(t.TempDir(), "missing.json"))
+			assert.Error(t, err)
+		})
+	})
+}
+
+func TestNamespaces(t *testing.T) {
+	finance := &tenants.Namespace{Tenant: &tenants.Tenant{ID: "finance", Hosts: []string{"finance.example.com"}}}
+	sut := tenants.NewNamespaces([]*tenants.Namespace{finance})
+
+	t.Run("it should find a namespace by the ID of its tenant", func(t *testing.T) {
+		assert.Same(t, finance, sut.ByID("finance"))
+		assert.Nil(t, sut.ByID("sales"))
+	})
+
+	t.Run("it should find a namespace by a host of its tenant, with or without a port", func(t *testing.T) {
+		assert.Same(t, finance, sut.ByHost("finance.example.com"))
+		assert.Same(t, finance, sut.ByHost("FINANCE.example.com:8080"))
+		assert.Nil(t, sut.ByHost("sales.example.com"))
+	})
+}
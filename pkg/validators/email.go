@@ -0,0 +1,21 @@
+package validators
+
+import (
+	"net/mail"
+	"strings"
+)
+
+// IsValidEmail reports whether address is a bare email address such as "user@example.com".
+// Display names, address lists and addresses longer than 254 characters are rejected.
+func IsValidEmail(address string) bool {
+	if address == "" || len(address) > 254 || strings.ContainsAny(address, "\r\n") {
+		return false
+	}
+
+	parsed, err := mail.ParseAddress(address)
+	if err != nil {
+		return false
+	}
+
+	return parsed.Address == address
+}
@@ -0,0 +1,46 @@
+package validators
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsValidEmail(t *testing.T) {
+	t.Run("when address is a bare email address", func(t *testing.T) {
+		t.Run("it should return true", func(t *testing.T) {
+			assert.True(t, IsValidEmail("sender@example.com"))
+		})
+	})
+
+	t.Run("when address is empty", func(t *testing.T) {
+		t.Run("it should return false", func(t *testing.T) {
+			assert.False(t, IsValidEmail(""))
+		})
+	})
+
+	t.Run("when address has a display name", func(t *testing.T) {
+		t.Run("it should return false", func(t *testing.T) {
+			assert.False(t, IsValidEmail("Sender <sender@example.com>"))
+		})
+	})
+
+	t.Run("when address contains a line break", func(t *testing.T) {
+		t.Run("it should return false", func(t *testing.T) {
+			assert.False(t, IsValidEmail("sender@example.com\r\nBcc: other@example.com"))
+		})
+	})
+
+	t.Run("when address is missing a domain", func(t *testing.T) {
+		t.Run("it should return false", func(t *testing.T) {
+			assert.False(t, IsValidEmail("sender"))
+		})
+	})
+
+	t.Run("when address is too long", func(t *testing.T) {
+		t.Run("it should return false", func(t *testing.T) {
+			assert.False(t, IsValidEmail(strings.Repeat("a", 250)+"@example.com"))
+		})
+	})
+}
@@ -49,4 +49,12 @@ func TestWhenGettingConfig(t *testing.T) {
 	t.Run("it should return maxExpirationSeconds greater than zero", func(t *testing.T) {
 		assert.Greater(t, configResp.Limits.MaxExpirationSeconds, 0)
 	})
+
+	t.Run("it should return maxKvFields greater than zero", func(t *testing.T) {
+		assert.Greater(t, configResp.Limits.MaxKVFields, 0)
+	})
+
+	t.Run("it should return maxKvKeyLength greater than zero", func(t *testing.T) {
+		assert.Greater(t, configResp.Limits.MaxKVKeyLength, 0)
+	})
 }
@@ -0,0 +1,107 @@
+//go:build acceptance
+// +build acceptance
+
+package requests
+
+import (
+	"cellar/pkg/models"
+	"cellar/testing/testhelpers"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhenFulfillingSecretRequest(t *testing.T) {
+	cfg := testhelpers.GetConfiguration()
+	content := "Vendor Credentials"
+
+	createResp := testhelpers.PostFormData(t, cfg.App().ClientAddress()+"/v2/requests", map[string]string{
+		"content_type":     models.ContentTypeText,
+		"max_size_bytes":   "1024",
+		"access_limit":     "1",
+		"expiration_epoch": strconv.FormatInt(testhelpers.EpochFromNow(time.Hour), 10),
+	}, nil)
+	defer createResp.Body.Close()
+	require.Equal(t, http.StatusCreated, createResp.StatusCode)
+
+	var created models.CreateSecretRequestResponse
+	require.NoError(t, json.NewDecoder(createResp.Body).Decode(&created))
+
+	t.Run("it should return an owner token", func(t *testing.T) {
+		assert.NotEmpty(t, created.OwnerToken)
+	})
+
+	submitPath := fmt.Sprintf("%s/v2/requests/%s/submit", cfg.App().ClientAddress(), created.ID)
+	submitResp := testhelpers.PostFormData(t, submitPath, map[string]string{"content": content}, nil)
+	defer submitResp.Body.Close()
+
+	t.Run("it should accept the submission", func(t *testing.T) {
+		assert.Equal(t, http.StatusCreated, submitResp.StatusCode)
+	})
+
+	t.Run("it should reject a second submission", func(t *testing.T) {
+		resp := testhelpers.PostFormData(t, submitPath, map[string]string{"content": content}, nil)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	})
+
+	getRequest := func(t *testing.T, ownerToken string) models.SecretRequestMetadataResponse {
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v2/requests/%s", cfg.App().ClientAddress(), created.ID), nil)
+		require.NoError(t, err)
+		if ownerToken != "" {
+			req.Header.Set("X-Owner-Token", ownerToken)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var metadata models.SecretRequestMetadataResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&metadata))
+		return metadata
+	}
+
+	t.Run("it should not reveal the secret ID without the owner token", func(t *testing.T) {
+		metadata := getRequest(t, "")
+		assert.True(t, metadata.Fulfilled)
+		assert.Empty(t, metadata.SecretID)
+	})
+
+	metadata := getRequest(t, created.OwnerToken)
+	require.NotEmpty(t, metadata.SecretID)
+
+	accessPath := fmt.Sprintf("%s/v2/secrets/%s/access", cfg.App().ClientAddress(), metadata.SecretID)
+
+	t.Run("it should forbid access without the owner token", func(t *testing.T) {
+		resp, err := http.Post(accessPath, "application/json", nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("it should return the content to the owner", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, accessPath, nil)
+		require.NoError(t, err)
+		req.Header.Set("X-Owner-Token", created.OwnerToken)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		var actual models.SecretContentResponse
+		require.NoError(t, json.Unmarshal(body, &actual))
+		assert.Equal(t, content, actual.Content)
+	})
+}
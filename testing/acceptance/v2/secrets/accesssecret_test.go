@@ -10,7 +10,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -119,3 +121,40 @@ func TestWhenAccessingSecretWithAccessLimitOfOne(t *testing.T) {
 		assert.Equal(t, content, actual.Content)
 	})
 }
+
+func TestWhenAccessingSecretContentBeforeItIsAvailable(t *testing.T) {
+	cfg := testhelpers.GetConfiguration()
+	createResp := testhelpers.PostFormData(t, cfg.App().ClientAddress()+"/v2/secrets", map[string]string{
+		"content":              "Super Secret Test Content",
+		"available_from_epoch": strconv.FormatInt(testhelpers.EpochFromNow(time.Hour), 10),
+		"expiration_epoch":     strconv.FormatInt(testhelpers.EpochFromNow(2*time.Hour), 10),
+	}, nil)
+	defer createResp.Body.Close()
+	require.Equal(t, http.StatusCreated, createResp.StatusCode)
+
+	var secret models.SecretMetadataResponseV2
+	require.NoError(t, json.NewDecoder(createResp.Body).Decode(&secret))
+
+	path := fmt.Sprintf("%s/v2/secrets/%s/access", cfg.App().ClientAddress(), secret.ID)
+	resp, err := http.Post(path, "application/json", nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	t.Run("it should return forbidden status", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("it should return retry after header", func(t *testing.T) {
+		assert.NotEmpty(t, resp.Header.Get("Retry-After"))
+	})
+
+	t.Run("it should not consume an access", func(t *testing.T) {
+		metadataResp, err := http.Get(fmt.Sprintf("%s/v2/secrets/%s", cfg.App().ClientAddress(), secret.ID))
+		require.NoError(t, err)
+		defer metadataResp.Body.Close()
+
+		var metadata models.SecretMetadataResponseV2
+		require.NoError(t, json.NewDecoder(metadataResp.Body).Decode(&metadata))
+		assert.Equal(t, 0, metadata.AccessCount)
+	})
+}
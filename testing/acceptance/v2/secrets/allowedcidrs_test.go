@@ -0,0 +1,87 @@
+//go:build acceptance
+// +build acceptance
+
+package secrets
+
+import (
+	"cellar/pkg/models"
+	"cellar/testing/testhelpers"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhenAccessingSecretWithAllowedCIDRs(t *testing.T) {
+	cfg := testhelpers.GetConfiguration()
+
+	createSecret := func(allowedCIDRs string) models.SecretMetadataResponseV2 {
+		resp := testhelpers.PostFormData(t, cfg.App().ClientAddress()+"/v2/secrets", map[string]string{
+			"content":          "Super Secret Test Content",
+			"allowed_cidrs":    allowedCIDRs,
+			"expiration_epoch": strconv.FormatInt(testhelpers.EpochFromNow(time.Hour), 10),
+		}, nil)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var secret models.SecretMetadataResponseV2
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&secret))
+		return secret
+	}
+
+	t.Run("and the client is outside the allowlist", func(t *testing.T) {
+		secret := createSecret("192.0.2.0/24, 198.51.100.7")
+
+		t.Run("it should return the normalized allowlist", func(t *testing.T) {
+			assert.Equal(t, []string{"192.0.2.0/24", "198.51.100.7/32"}, secret.AllowedCIDRs)
+		})
+
+		resp, err := http.Post(fmt.Sprintf("%s/v2/secrets/%s/access", cfg.App().ClientAddress(), secret.ID), "application/json", nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		t.Run("it should return 403 Forbidden", func(t *testing.T) {
+			assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		})
+
+		t.Run("it should not consume an access", func(t *testing.T) {
+			metadataResp, err := http.Get(fmt.Sprintf("%s/v2/secrets/%s", cfg.App().ClientAddress(), secret.ID))
+			require.NoError(t, err)
+			defer metadataResp.Body.Close()
+
+			var metadata models.SecretMetadataResponseV2
+			require.NoError(t, json.NewDecoder(metadataResp.Body).Decode(&metadata))
+			assert.Equal(t, 0, metadata.AccessCount)
+		})
+	})
+
+	t.Run("and the client is within the allowlist", func(t *testing.T) {
+		secret := createSecret("0.0.0.0/0,::/0")
+
+		resp, err := http.Post(fmt.Sprintf("%s/v2/secrets/%s/access", cfg.App().ClientAddress(), secret.ID), "application/json", nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		t.Run("it should return the content", func(t *testing.T) {
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		})
+	})
+
+	t.Run("and a range is invalid", func(t *testing.T) {
+		resp := testhelpers.PostFormData(t, cfg.App().ClientAddress()+"/v2/secrets", map[string]string{
+			"content":          "Super Secret Test Content",
+			"allowed_cidrs":    "10.0.0.0/33",
+			"expiration_epoch": strconv.FormatInt(testhelpers.EpochFromNow(time.Hour), 10),
+		}, nil)
+		defer resp.Body.Close()
+
+		t.Run("it should return 400 Bad Request", func(t *testing.T) {
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		})
+	})
+}
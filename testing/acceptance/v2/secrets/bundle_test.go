@@ -0,0 +1,92 @@
+//go:build acceptance
+// +build acceptance
+
+package secrets
+
+import (
+	"archive/zip"
+	"bytes"
+	"cellar/pkg/models"
+	"cellar/testing/testhelpers"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhenAccessingSecretBundle(t *testing.T) {
+	cfg := testhelpers.GetConfiguration()
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for _, file := range []struct{ name, content string }{
+		{name: "cert.pem", content: "certificate"},
+		{name: "key.pem", content: "private key"},
+	} {
+		part, err := writer.CreateFormFile("files", file.name)
+		require.NoError(t, err)
+		_, err = part.Write([]byte(file.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.WriteField("note", "rotate by friday"))
+	require.NoError(t, writer.WriteField("access_limit", "2"))
+	require.NoError(t, writer.WriteField("expiration_epoch", strconv.FormatInt(testhelpers.EpochFromNow(time.Hour), 10)))
+	require.NoError(t, writer.Close())
+
+	createResp, err := http.Post(cfg.App().ClientAddress()+"/v2/secrets", writer.FormDataContentType(), body)
+	require.NoError(t, err)
+	defer createResp.Body.Close()
+	require.Equal(t, http.StatusCreated, createResp.StatusCode)
+
+	var secret models.SecretMetadataResponseV2
+	require.NoError(t, json.NewDecoder(createResp.Body).Decode(&secret))
+
+	t.Run("it should index the bundle items", func(t *testing.T) {
+		assert.Equal(t, models.ContentType(models.ContentTypeBundle), secret.ContentType)
+		assert.Equal(t, []models.BundleItem{
+			{Name: "cert.pem", Size: 11},
+			{Name: "key.pem", Size: 11},
+			{Name: "note.txt", Size: 16},
+		}, secret.Items)
+	})
+
+	t.Run("it should return a single item", func(t *testing.T) {
+		resp, err := http.Post(fmt.Sprintf("%s/v2/secrets/%s/items/1/access", cfg.App().ClientAddress(), secret.ID), "application/json", nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		content, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "private key", string(content))
+		assert.Contains(t, resp.Header.Get("Content-Disposition"), `filename="key.pem"`)
+	})
+
+	t.Run("it should return the whole bundle as a zip", func(t *testing.T) {
+		resp, err := http.Post(fmt.Sprintf("%s/v2/secrets/%s/access", cfg.App().ClientAddress(), secret.ID), "application/json", nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/zip", resp.Header.Get("Content-Type"))
+
+		archive, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+		require.NoError(t, err)
+		assert.Len(t, reader.File, 3)
+	})
+
+	t.Run("it should burn the bundle once its access limit is reached", func(t *testing.T) {
+		resp, err := http.Post(fmt.Sprintf("%s/v2/secrets/%s/items/0/access", cfg.App().ClientAddress(), secret.ID), "application/json", nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
@@ -0,0 +1,78 @@
+//go:build acceptance
+// +build acceptance
+
+package secrets
+
+import (
+	"cellar/pkg/models"
+	"cellar/testing/testhelpers"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhenAccessingSecretWithCountryRestrictions(t *testing.T) {
+	cfg := testhelpers.GetConfiguration()
+
+	createSecret := func(field string, countries string) models.SecretMetadataResponseV2 {
+		resp := testhelpers.PostFormData(t, cfg.App().ClientAddress()+"/v2/secrets", map[string]string{
+			"content":          "Super Secret Test Content",
+			field:              countries,
+			"expiration_epoch": strconv.FormatInt(testhelpers.EpochFromNow(time.Hour), 10),
+		}, nil)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var secret models.SecretMetadataResponseV2
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&secret))
+		return secret
+	}
+
+	// The test environment has no GeoIP database, so the country of the client is unknown.
+	t.Run("and the secret only allows some countries", func(t *testing.T) {
+		secret := createSecret("allowed_countries", "de, fr")
+
+		t.Run("it should return the normalized country codes", func(t *testing.T) {
+			assert.Equal(t, []string{"DE", "FR"}, secret.AllowedCountries)
+		})
+
+		resp, err := http.Post(fmt.Sprintf("%s/v2/secrets/%s/access", cfg.App().ClientAddress(), secret.ID), "application/json", nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		t.Run("it should return 403 Forbidden for a client of unknown country", func(t *testing.T) {
+			assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		})
+	})
+
+	t.Run("and the secret denies some countries", func(t *testing.T) {
+		secret := createSecret("denied_countries", "DE")
+
+		resp, err := http.Post(fmt.Sprintf("%s/v2/secrets/%s/access", cfg.App().ClientAddress(), secret.ID), "application/json", nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		t.Run("it should return the content", func(t *testing.T) {
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		})
+	})
+
+	t.Run("and a country code is invalid", func(t *testing.T) {
+		resp := testhelpers.PostFormData(t, cfg.App().ClientAddress()+"/v2/secrets", map[string]string{
+			"content":           "Super Secret Test Content",
+			"allowed_countries": "Germany",
+			"expiration_epoch":  strconv.FormatInt(testhelpers.EpochFromNow(time.Hour), 10),
+		}, nil)
+		defer resp.Body.Close()
+
+		t.Run("it should return 400 Bad Request", func(t *testing.T) {
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		})
+	})
+}
@@ -0,0 +1,83 @@
+//go:build acceptance
+// +build acceptance
+
+package secrets
+
+import (
+	"cellar/pkg/models"
+	"cellar/testing/testhelpers"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhenGeneratingSecret(t *testing.T) {
+	cfg := testhelpers.GetConfiguration()
+	generatePath := cfg.App().ClientAddress() + "/v2/secrets/generate"
+	expirationEpoch := strconv.FormatInt(testhelpers.EpochFromNow(time.Hour), 10)
+
+	t.Run("and the kind is a password", func(t *testing.T) {
+		resp := testhelpers.PostFormData(t, generatePath, map[string]string{
+			"kind":              models.GeneratedSecretKindPassword,
+			"length":            "20",
+			"character_classes": "lowercase,digits",
+			"expiration_epoch":  expirationEpoch,
+		}, nil)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var secret models.SecretMetadataResponseV2
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&secret))
+
+		t.Run("it should return the secret link without the password", func(t *testing.T) {
+			assert.NotEmpty(t, secret.ID)
+			assert.Equal(t, models.ContentType(models.ContentTypeText), secret.ContentType)
+		})
+
+		t.Run("it should store a password matching the policy", func(t *testing.T) {
+			accessResp, err := http.Post(fmt.Sprintf("%s/v2/secrets/%s/access", cfg.App().ClientAddress(), secret.ID), "application/json", nil)
+			require.NoError(t, err)
+			defer accessResp.Body.Close()
+
+			var content models.SecretContentResponse
+			require.NoError(t, json.NewDecoder(accessResp.Body).Decode(&content))
+			assert.Regexp(t, `^[a-z0-9]{20}$`, content.Content)
+		})
+	})
+
+	t.Run("and the kind is an SSH key pair", func(t *testing.T) {
+		resp := testhelpers.PostFormData(t, generatePath, map[string]string{
+			"kind":             models.GeneratedSecretKindSSH,
+			"expiration_epoch": expirationEpoch,
+		}, nil)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var secret models.SecretMetadataResponseV2
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&secret))
+
+		t.Run("it should store a bundle of the key pair", func(t *testing.T) {
+			require.Len(t, secret.Items, 2)
+			assert.True(t, strings.HasSuffix(secret.Items[1].Name, ".pub"))
+		})
+	})
+
+	t.Run("and the kind is unknown", func(t *testing.T) {
+		resp := testhelpers.PostFormData(t, generatePath, map[string]string{
+			"kind":             "pgp",
+			"expiration_epoch": expirationEpoch,
+		}, nil)
+		defer resp.Body.Close()
+
+		t.Run("it should return bad request", func(t *testing.T) {
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		})
+	})
+}
@@ -0,0 +1,54 @@
+//go:build acceptance
+// +build acceptance
+
+package secrets
+
+import (
+	"bytes"
+	"cellar/pkg/models"
+	"cellar/testing/testhelpers"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhenAccessingKVSecret(t *testing.T) {
+	cfg := testhelpers.GetConfiguration()
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	require.NoError(t, writer.WriteField("kv", `{"username":"admin","password":"hunter2"}`))
+	require.NoError(t, writer.WriteField("expiration_epoch", strconv.FormatInt(testhelpers.EpochFromNow(time.Hour), 10)))
+	require.NoError(t, writer.Close())
+
+	createResp, err := http.Post(cfg.App().ClientAddress()+"/v2/secrets", writer.FormDataContentType(), body)
+	require.NoError(t, err)
+	defer createResp.Body.Close()
+	require.Equal(t, http.StatusCreated, createResp.StatusCode)
+
+	var secret models.SecretMetadataResponseV2
+	require.NoError(t, json.NewDecoder(createResp.Body).Decode(&secret))
+
+	t.Run("it should be stored as a kv secret", func(t *testing.T) {
+		assert.Equal(t, models.ContentType(models.ContentTypeKV), secret.ContentType)
+	})
+
+	t.Run("it should return the fields as a JSON object", func(t *testing.T) {
+		resp, err := http.Post(fmt.Sprintf("%s/v2/secrets/%s/access", cfg.App().ClientAddress(), secret.ID), "application/json", nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var content models.SecretKVContentResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&content))
+		assert.Equal(t, secret.ID, content.ID)
+		assert.Equal(t, map[string]string{"username": "admin", "password": "hunter2"}, content.Content)
+	})
+}
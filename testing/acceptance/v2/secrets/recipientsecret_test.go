@@ -0,0 +1,65 @@
+//go:build acceptance
+// +build acceptance
+
+package secrets
+
+import (
+	"bytes"
+	"cellar/pkg/models"
+	"cellar/testing/testhelpers"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	ageArmor "filippo.io/age/armor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhenAccessingSecretSealedToRecipient(t *testing.T) {
+	cfg := testhelpers.GetConfiguration()
+	content := "Super Secret Test Content"
+
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	createResp := testhelpers.PostFormData(t, cfg.App().ClientAddress()+"/v2/secrets", map[string]string{
+		"content":              content,
+		"recipient_public_key": identity.Recipient().String(),
+		"expiration_epoch":     strconv.FormatInt(testhelpers.EpochFromNow(time.Hour), 10),
+	}, nil)
+	defer createResp.Body.Close()
+	require.Equal(t, http.StatusCreated, createResp.StatusCode)
+
+	var secret models.SecretMetadataResponseV2
+	require.NoError(t, json.NewDecoder(createResp.Body).Decode(&secret))
+
+	t.Run("it should mark the secret as sealed", func(t *testing.T) {
+		assert.Equal(t, models.ContentType(models.ContentTypeAge), secret.ContentType)
+	})
+
+	resp, err := http.Post(fmt.Sprintf("%s/v2/secrets/%s/access", cfg.App().ClientAddress(), secret.ID), "application/json", nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	sealed, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	t.Run("it should mark the response as age ciphertext", func(t *testing.T) {
+		assert.Equal(t, "application/x-age-encrypted", resp.Header.Get("Content-Type"))
+	})
+
+	t.Run("it should return ciphertext only the recipient can decrypt", func(t *testing.T) {
+		reader, err := age.Decrypt(ageArmor.NewReader(bytes.NewReader(sealed)), identity)
+		require.NoError(t, err)
+		plaintext, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, content, string(plaintext))
+	})
+}
@@ -0,0 +1,74 @@
+//go:build acceptance
+// +build acceptance
+
+package secrets
+
+import (
+	"cellar/pkg/models"
+	"cellar/testing/testhelpers"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhenRequestingAVerificationCode(t *testing.T) {
+	cfg := testhelpers.GetConfiguration()
+
+	t.Run("and the secret does not require recipient verification", func(t *testing.T) {
+		resp := testhelpers.PostFormData(t, cfg.App().ClientAddress()+"/v2/secrets", map[string]string{
+			"content":          "Super Secret Test Content",
+			"expiration_epoch": strconv.FormatInt(testhelpers.EpochFromNow(time.Hour), 10),
+		}, nil)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var secret models.SecretMetadataResponseV2
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&secret))
+
+		t.Run("it should not report recipient verification", func(t *testing.T) {
+			assert.False(t, secret.RecipientVerification)
+		})
+
+		verificationResp, err := http.Post(fmt.Sprintf("%s/v2/secrets/%s/verification", cfg.App().ClientAddress(), secret.ID), "application/json", nil)
+		require.NoError(t, err)
+		defer verificationResp.Body.Close()
+
+		t.Run("it should return 400 Bad Request", func(t *testing.T) {
+			assert.Equal(t, http.StatusBadRequest, verificationResp.StatusCode)
+		})
+	})
+
+	t.Run("and the secret does not exist", func(t *testing.T) {
+		resp, err := http.Post(fmt.Sprintf("%s/v2/secrets/%s/verification", cfg.App().ClientAddress(), testhelpers.RandomId(t)), "application/json", nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		t.Run("it should return 404 Not Found", func(t *testing.T) {
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		})
+	})
+}
+
+func TestWhenCreatingSecretWithRecipientEmail(t *testing.T) {
+	cfg := testhelpers.GetConfiguration()
+
+	// The test environment does not enable email notifications, so codes could never be delivered.
+	t.Run("and email notifications are not enabled", func(t *testing.T) {
+		resp := testhelpers.PostFormData(t, cfg.App().ClientAddress()+"/v2/secrets", map[string]string{
+			"content":          "Super Secret Test Content",
+			"recipient_email":  "recipient@example.com",
+			"expiration_epoch": strconv.FormatInt(testhelpers.EpochFromNow(time.Hour), 10),
+		}, nil)
+		defer resp.Body.Close()
+
+		t.Run("it should return 400 Bad Request", func(t *testing.T) {
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		})
+	})
+}
@@ -0,0 +1,73 @@
+//go:build acceptance
+// +build acceptance
+
+package secrets
+
+import (
+	"cellar/pkg/models"
+	"cellar/testing/testhelpers"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhenAccessingSplitKeySecret(t *testing.T) {
+	cfg := testhelpers.GetConfiguration()
+	content := "Super Secret Test Content"
+
+	createResp := testhelpers.PostFormData(t, cfg.App().ClientAddress()+"/v2/secrets", map[string]string{
+		"content":          content,
+		"split_key":        "true",
+		"access_limit":     "1",
+		"expiration_epoch": strconv.FormatInt(testhelpers.EpochFromNow(time.Hour), 10),
+	}, nil)
+	defer createResp.Body.Close()
+	require.Equal(t, http.StatusCreated, createResp.StatusCode)
+
+	var secret models.SecretMetadataResponseV2
+	require.NoError(t, json.NewDecoder(createResp.Body).Decode(&secret))
+
+	t.Run("it should return the key to the creator", func(t *testing.T) {
+		assert.True(t, secret.SplitKey)
+		assert.NotEmpty(t, secret.Key)
+	})
+
+	access := func(key string) *http.Response {
+		request, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/v2/secrets/%s/access", cfg.App().ClientAddress(), secret.ID), nil)
+		require.NoError(t, err)
+		if key != "" {
+			request.Header.Set("X-Secret-Key", key)
+		}
+		resp, err := http.DefaultClient.Do(request)
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("it should reject access without the key", func(t *testing.T) {
+		resp := access("")
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("it should reject access with another key", func(t *testing.T) {
+		resp := access(testhelpers.RandomId(t))
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("it should return the content with the key", func(t *testing.T) {
+		resp := access(secret.Key)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var response models.SecretContentResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		assert.Equal(t, content, response.Content)
+	})
+}
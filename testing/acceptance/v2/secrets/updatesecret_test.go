@@ -0,0 +1,100 @@
+//go:build acceptance
+// +build acceptance
+
+package secrets
+
+import (
+	"bytes"
+	"cellar/pkg/models"
+	"cellar/testing/testhelpers"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func patchSecret(t *testing.T, uri string, ownerToken string, formData map[string]string) *http.Response {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for key, val := range formData {
+		require.NoError(t, writer.WriteField(key, val))
+	}
+	require.NoError(t, writer.Close())
+
+	request, err := http.NewRequest(http.MethodPatch, uri, body)
+	require.NoError(t, err)
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+	if ownerToken != "" {
+		request.Header.Set("X-Owner-Token", ownerToken)
+	}
+
+	resp, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	return resp
+}
+
+func TestWhenUpdatingSecret(t *testing.T) {
+	cfg := testhelpers.GetConfiguration()
+	secret := testhelpers.CreateSecretV2(t, cfg, models.ContentTypeText, "Super Secret Test Content", 10)
+	require.NotEmpty(t, secret.OwnerToken)
+
+	path := fmt.Sprintf("%s/v2/secrets/%s", cfg.App().ClientAddress(), secret.ID)
+	expectedExpiration := time.Now().Add(2 * time.Hour).UTC()
+
+	t.Run("and owner token is missing", func(t *testing.T) {
+		resp := patchSecret(t, path, "", map[string]string{"access_limit": "20"})
+		defer resp.Body.Close()
+
+		t.Run("it should return forbidden status", func(t *testing.T) {
+			assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		})
+	})
+
+	t.Run("and owner token matches", func(t *testing.T) {
+		resp := patchSecret(t, path, secret.OwnerToken, map[string]string{
+			"access_limit":     "20",
+			"expiration_epoch": strconv.FormatInt(expectedExpiration.Unix(), 10),
+		})
+		defer resp.Body.Close()
+
+		t.Run("it should return ok status", func(t *testing.T) {
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		})
+
+		var actual models.SecretMetadataResponseV2
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&actual))
+
+		t.Run("it should return new access limit", func(t *testing.T) {
+			assert.Equal(t, 20, actual.AccessLimit)
+		})
+
+		t.Run("it should return new expiration", func(t *testing.T) {
+			assert.Equal(t, expectedExpiration.Format("2006-01-02 15:04:05 UTC"), actual.Expiration.Format())
+		})
+	})
+
+	t.Run("and reading the history", func(t *testing.T) {
+		request, err := http.NewRequest(http.MethodGet, path+"/history", nil)
+		require.NoError(t, err)
+		request.Header.Set("X-Owner-Token", secret.OwnerToken)
+
+		resp, err := http.DefaultClient.Do(request)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var actual models.SecretHistoryResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&actual))
+
+		t.Run("it should record the modification", func(t *testing.T) {
+			require.Len(t, actual.Events, 1)
+			assert.Equal(t, models.SecretHistoryEventType(models.SecretHistoryModified), actual.Events[0].Type)
+		})
+	})
+}
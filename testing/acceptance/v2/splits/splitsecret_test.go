@@ -0,0 +1,73 @@
+//go:build acceptance
+// +build acceptance
+
+package splits
+
+import (
+	"cellar/pkg/models"
+	"cellar/testing/testhelpers"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhenCombiningSplitSecret(t *testing.T) {
+	cfg := testhelpers.GetConfiguration()
+	content := "Break Glass Credentials"
+
+	createResp := testhelpers.PostFormData(t, cfg.App().ClientAddress()+"/v2/splits", map[string]string{
+		"content":          content,
+		"shares":           "3",
+		"threshold":        "2",
+		"access_limit":     "1",
+		"expiration_epoch": strconv.FormatInt(testhelpers.EpochFromNow(time.Hour), 10),
+	}, nil)
+	defer createResp.Body.Close()
+	require.Equal(t, http.StatusCreated, createResp.StatusCode)
+
+	var created models.CreateSplitSecretResponse
+	require.NoError(t, json.NewDecoder(createResp.Body).Decode(&created))
+
+	t.Run("it should return a link for every share", func(t *testing.T) {
+		require.Len(t, created.Shares, 3)
+		assert.Equal(t, 2, created.Threshold)
+	})
+
+	combine := func(t *testing.T, shareIds ...string) *http.Response {
+		form := url.Values{"share_ids": shareIds}
+		resp, err := http.Post(fmt.Sprintf("%s/v2/splits/%s/combine", cfg.App().ClientAddress(), created.ID),
+			"application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("it should reject fewer shares than the threshold", func(t *testing.T) {
+		resp := combine(t, created.Shares[0].ID)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("it should reconstruct the content from the threshold of shares", func(t *testing.T) {
+		resp := combine(t, created.Shares[0].ID, created.Shares[2].ID)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var actual models.SecretContentResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&actual))
+		assert.Equal(t, content, actual.Content)
+	})
+
+	t.Run("it should not combine again once the shares are used up", func(t *testing.T) {
+		resp := combine(t, created.Shares[0].ID, created.Shares[2].ID)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
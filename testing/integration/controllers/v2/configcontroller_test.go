@@ -7,6 +7,7 @@ import (
 	"cellar/pkg/controllers/v2"
 	"cellar/pkg/models"
 	"cellar/pkg/settings"
+	"cellar/pkg/tenants"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -14,6 +15,7 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -61,5 +63,51 @@ func TestGetConfig(t *testing.T) {
 		t.Run("it should return maxExpirationSeconds", func(t *testing.T) {
 			assert.Equal(t, cfg.App().MaxExpirationSeconds(), response.Limits.MaxExpirationSeconds)
 		})
+
+		t.Run("it should return maxKvFields", func(t *testing.T) {
+			assert.Equal(t, cfg.App().MaxKVFields(), response.Limits.MaxKVFields)
+		})
+
+		t.Run("it should return maxKvKeyLength", func(t *testing.T) {
+			assert.Equal(t, cfg.App().MaxKVKeyLength(), response.Limits.MaxKVKeyLength)
+		})
+
+		t.Run("it should not name a tenant", func(t *testing.T) {
+			assert.Empty(t, response.Tenant)
+		})
+	})
+
+	t.Run("when the caller was resolved to a tenant", func(t *testing.T) {
+		viper.Set("tenants.file", "/etc/cellar/tenants.json")
+		t.Cleanup(func() { viper.Set("tenants.file", "") })
+
+		maxAccessCount := 5
+		tenant := &tenants.Tenant{ID: "finance", MaxAccessCount: &maxAccessCount}
+		cfg := tenants.NewConfiguration(settings.NewConfiguration(), tenant)
+
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Set(settings.Key, cfg)
+			c.Set(tenants.ResolvedKey, tenant)
+			c.Next()
+		})
+		router.GET("/v2/config", v2.GetConfig)
+
+		req, _ := http.NewRequest("GET", "/v2/config", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var response models.ConfigResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+		t.Run("it should return the tenant and its limits", func(t *testing.T) {
+			assert.Equal(t, "finance", response.Tenant)
+			assert.Equal(t, 5, response.Limits.MaxAccessCount)
+		})
+
+		t.Run("it should keep shared caches from serving the response to other callers", func(t *testing.T) {
+			assert.Equal(t, "private, max-age=86400", w.Header().Get("Cache-Control"))
+			assert.Equal(t, "Authorization", w.Header().Get("Vary"))
+		})
 	})
 }
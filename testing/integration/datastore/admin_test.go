@@ -0,0 +1,248 @@
+//go:build integration
+// +build integration
+
+package datastore
+
+import (
+	"cellar/pkg/commands"
+	"cellar/pkg/datastore/redis"
+	"cellar/pkg/mocks"
+	"cellar/pkg/models"
+	"cellar/pkg/settings"
+	"cellar/testing/testhelpers"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestWhenAdministeringDataStore(t *testing.T) {
+	ctx := context.Background()
+	cfg := settings.NewConfiguration()
+	shared := redis.NewDataStore(cfg.Datastore().Redis(), testhelpers.GetKeyIDs(t))
+	sut := shared.WithKeyPrefix("tenants:" + testhelpers.RandomId(t)[:8] + ":")
+	other := shared.WithKeyPrefix("tenants:" + testhelpers.RandomId(t)[:8] + ":")
+
+	write := func(t *testing.T, dataStore *redis.DataStore, creator string) models.Secret {
+		secret := models.Secret{
+			ID:              testhelpers.RandomId(t),
+			CipherText:      testhelpers.RandomId(t),
+			ContentType:     models.ContentTypeText,
+			AccessLimit:     5,
+			ExpirationEpoch: testhelpers.EpochFromNow(time.Minute),
+			Creator:         creator,
+			CreatedEpoch:    time.Now().Unix(),
+		}
+		require.NoError(t, dataStore.WriteSecret(ctx, secret))
+		return secret
+	}
+
+	first := write(t, sut, "alice")
+	second := write(t, sut, "")
+	outside := write(t, other, "alice")
+
+	t.Cleanup(func() {
+		_, _ = sut.DeleteSecret(ctx, first.ID)
+		_, _ = sut.DeleteSecret(ctx, second.ID)
+		_, _ = other.DeleteSecret(ctx, outside.ID)
+		_ = shared.SetReadOnly(ctx, false)
+		_ = shared.Close()
+	})
+
+	t.Run("when scanning secrets", func(t *testing.T) {
+		visited := map[string]models.SecretRecord{}
+		err := sut.ScanSecrets(ctx, func(record models.SecretRecord) error {
+			visited[record.KeyID] = record
+			return nil
+		})
+		require.NoError(t, err)
+
+		t.Run("it should visit every secret of the namespace", func(t *testing.T) {
+			assert.Len(t, visited, 2)
+			assert.Contains(t, visited, sut.KeyID(first.ID))
+			assert.Contains(t, visited, sut.KeyID(second.ID))
+		})
+
+		t.Run("it should not visit secrets of other namespaces", func(t *testing.T) {
+			assert.NotContains(t, visited, sut.KeyID(outside.ID))
+		})
+
+		t.Run("it should read the creator and creation time", func(t *testing.T) {
+			record := visited[sut.KeyID(first.ID)]
+			assert.Equal(t, "alice", record.Creator)
+			assert.Equal(t, first.CreatedEpoch, record.CreatedEpoch)
+		})
+
+		t.Run("it should read the size of the content", func(t *testing.T) {
+			assert.Equal(t, int64(len(first.CipherText)), visited[sut.KeyID(first.ID)].Size)
+		})
+	})
+
+	t.Run("when burning a secret by key id", func(t *testing.T) {
+		found, err := sut.BurnSecret(ctx, sut.KeyID(first.ID))
+		require.NoError(t, err)
+
+		t.Run("it should report the secret was found", func(t *testing.T) {
+			assert.True(t, found)
+		})
+
+		t.Run("it should delete the secret", func(t *testing.T) {
+			assert.Nil(t, sut.ReadSecret(ctx, first.ID))
+		})
+
+		t.Run("it should not find the secret in another namespace", func(t *testing.T) {
+			found, err := sut.BurnSecret(ctx, sut.KeyID(outside.ID))
+			require.NoError(t, err)
+			assert.False(t, found)
+			assert.NotNil(t, other.ReadSecret(ctx, outside.ID))
+		})
+	})
+
+	t.Run("when setting read-only mode", func(t *testing.T) {
+		require.NoError(t, sut.SetReadOnly(ctx, true))
+
+		t.Run("it should apply to every namespace", func(t *testing.T) {
+			readOnly, err := other.ReadOnly(ctx)
+			require.NoError(t, err)
+			assert.True(t, readOnly)
+		})
+
+		t.Run("it should be cleared", func(t *testing.T) {
+			require.NoError(t, sut.SetReadOnly(ctx, false))
+			readOnly, err := shared.ReadOnly(ctx)
+			require.NoError(t, err)
+			assert.False(t, readOnly)
+		})
+	})
+}
+
+func TestWhenPurgingSubmittedSecrets(t *testing.T) {
+	ctx := context.Background()
+	cfg := settings.NewConfiguration()
+	shared := redis.NewDataStore(cfg.Datastore().Redis(), testhelpers.GetKeyIDs(t))
+	sut := shared.WithKeyPrefix("tenants:" + testhelpers.RandomId(t)[:8] + ":")
+	t.Cleanup(func() { _ = shared.Close() })
+
+	ctrl := gomock.NewController(t)
+	appConfig := mocks.NewMockIAppConfiguration(ctrl)
+	appConfig.EXPECT().PaddingBuckets().Return(nil).AnyTimes()
+	encryption := mocks.NewMockEncryption(ctrl)
+	encryption.EXPECT().Encrypt(gomock.Any(), gomock.Any()).Return(testhelpers.RandomId(t), nil).AnyTimes()
+
+	submit := func(t *testing.T) string {
+		request := models.SecretRequest{
+			ID:              testhelpers.RandomId(t),
+			OwnerTokenHash:  testhelpers.RandomId(t),
+			MaxSizeBytes:    1024,
+			AccessLimit:     1,
+			ExpirationEpoch: testhelpers.EpochFromNow(time.Minute),
+			Creator:         "carol",
+		}
+		require.NoError(t, sut.WriteSecretRequest(ctx, request))
+
+		_, err := commands.SubmitSecretRequest(ctx, appConfig, sut, encryption, request.ID, models.Secret{
+			Content:     []byte("vendor credentials"),
+			ContentType: models.ContentTypeText,
+		})
+		require.NoError(t, err)
+
+		submitted := sut.ReadSecretRequest(ctx, request.ID)
+		require.NotNil(t, submitted)
+		t.Cleanup(func() { _, _ = sut.DeleteSecret(ctx, submitted.SecretID) })
+		return submitted.SecretID
+	}
+
+	purge := func(t *testing.T, filter models.PurgeFilter) int64 {
+		purged, err := commands.PurgeSecrets(ctx, sut, models.AdminActor{Credential: "api-key:k1"}, filter)
+		require.NoError(t, err)
+		return purged
+	}
+
+	t.Run("by creation time", func(t *testing.T) {
+		secretId := submit(t)
+		purged := purge(t, models.PurgeFilter{CreatedBeforeEpoch: time.Now().Add(time.Minute).Unix()})
+
+		t.Run("it should burn the submitted secret", func(t *testing.T) {
+			assert.Equal(t, int64(1), purged)
+			assert.Nil(t, sut.ReadSecret(ctx, secretId))
+		})
+	})
+
+	t.Run("by creator", func(t *testing.T) {
+		secretId := submit(t)
+		purged := purge(t, models.PurgeFilter{Creator: "carol"})
+
+		t.Run("it should burn the secret submitted to the creator's request", func(t *testing.T) {
+			assert.Equal(t, int64(1), purged)
+			assert.Nil(t, sut.ReadSecret(ctx, secretId))
+		})
+	})
+}
+
+func TestWhenPurgingSplitSecretShares(t *testing.T) {
+	ctx := context.Background()
+	cfg := settings.NewConfiguration()
+	shared := redis.NewDataStore(cfg.Datastore().Redis(), testhelpers.GetKeyIDs(t))
+	sut := shared.WithKeyPrefix("tenants:" + testhelpers.RandomId(t)[:8] + ":")
+	t.Cleanup(func() { _ = shared.Close() })
+
+	ctrl := gomock.NewController(t)
+	appConfig := mocks.NewMockIAppConfiguration(ctrl)
+	appConfig.EXPECT().MaxAccessCount().Return(100).AnyTimes()
+	appConfig.EXPECT().MaxExpirationSeconds().Return(604800).AnyTimes()
+	appConfig.EXPECT().AllowedCIDRs().Return(nil).AnyTimes()
+	appConfig.EXPECT().PaddingBuckets().Return(nil).AnyTimes()
+	encryption := mocks.NewMockEncryption(ctrl)
+	encryption.EXPECT().Encrypt(gomock.Any(), gomock.Any()).Return(testhelpers.RandomId(t), nil).AnyTimes()
+
+	split := func(t *testing.T) []string {
+		metadata, err := commands.CreateSplitSecret(ctx, appConfig, sut, encryption, models.Secret{
+			Content:         []byte("break glass credentials"),
+			ContentType:     models.ContentTypeText,
+			AccessLimit:     1,
+			ExpirationEpoch: testhelpers.EpochFromNow(time.Minute),
+			Creator:         "dave",
+		}, 3, 2)
+		require.NoError(t, err)
+
+		shareIds := make([]string, 0, len(metadata.Shares))
+		for _, share := range metadata.Shares {
+			shareIds = append(shareIds, share.ID)
+			t.Cleanup(func() { _, _ = sut.DeleteSecret(ctx, share.ID) })
+		}
+		return shareIds
+	}
+
+	purge := func(t *testing.T, filter models.PurgeFilter) int64 {
+		purged, err := commands.PurgeSecrets(ctx, sut, models.AdminActor{Credential: "api-key:k1"}, filter)
+		require.NoError(t, err)
+		return purged
+	}
+
+	t.Run("by creation time", func(t *testing.T) {
+		shareIds := split(t)
+		purged := purge(t, models.PurgeFilter{CreatedBeforeEpoch: time.Now().Add(time.Minute).Unix()})
+
+		t.Run("it should burn every share", func(t *testing.T) {
+			assert.Equal(t, int64(3), purged)
+			for _, shareId := range shareIds {
+				assert.Nil(t, sut.ReadSecret(ctx, shareId))
+			}
+		})
+	})
+
+	t.Run("by creator", func(t *testing.T) {
+		shareIds := split(t)
+		purged := purge(t, models.PurgeFilter{Creator: "dave"})
+
+		t.Run("it should burn every share of the creator", func(t *testing.T) {
+			assert.Equal(t, int64(3), purged)
+			for _, shareId := range shareIds {
+				assert.Nil(t, sut.ReadSecret(ctx, shareId))
+			}
+		})
+	})
+}
@@ -0,0 +1,118 @@
+//go:build integration
+// +build integration
+
+package datastore
+
+import (
+	"cellar/pkg/datastore/redis"
+	"cellar/pkg/models"
+	"cellar/pkg/settings"
+	"cellar/testing/testhelpers"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingHandler struct {
+	mutex  sync.Mutex
+	events []models.LifecycleEvent
+}
+
+func (handler *recordingHandler) Handle(_ context.Context, event models.LifecycleEvent) {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+	handler.events = append(handler.events, event)
+}
+
+func (handler *recordingHandler) expiredKeyIds() []string {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	var keyIds []string
+	for _, event := range handler.events {
+		if event.Type == models.LifecycleSecretExpired {
+			keyIds = append(keyIds, event.KeyID)
+		}
+	}
+	return keyIds
+}
+
+func writeShortLivedSecret(t *testing.T, sut *redis.DataStore) models.Secret {
+	secret := models.Secret{
+		ID:              testhelpers.RandomId(t),
+		CipherText:      testhelpers.RandomId(t),
+		ContentType:     models.ContentTypeText,
+		ExpirationEpoch: time.Now().Add(time.Second).Unix(),
+	}
+	require.NoError(t, sut.WriteSecret(context.Background(), secret))
+	return secret
+}
+
+func TestWhenListeningForExpiryEvents(t *testing.T) {
+	cfg := settings.NewConfiguration()
+	sut := redis.NewDataStore(cfg.Datastore().Redis(), testhelpers.GetKeyIDs(t))
+	t.Cleanup(func() { _ = sut.Close() })
+
+	handler := &recordingHandler{}
+	listener := redis.NewExpiryListener(sut, cfg.Datastore().Redis(), handler)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() { _ = listener.Run(ctx) }()
+
+	// allow the subscription to be established before the secret expires
+	time.Sleep(500 * time.Millisecond)
+	secret := writeShortLivedSecret(t, sut)
+
+	t.Run("it should emit an expired event for the secret", func(t *testing.T) {
+		assert.Eventually(t, func() bool {
+			for _, keyId := range handler.expiredKeyIds() {
+				if keyId == sut.KeyID(secret.ID) {
+					return true
+				}
+			}
+			return false
+		}, 10*time.Second, 100*time.Millisecond)
+	})
+}
+
+func TestWhenSweepingExpiredSecrets(t *testing.T) {
+	cfg := settings.NewConfiguration()
+	sut := redis.NewDataStore(cfg.Datastore().Redis(), testhelpers.GetKeyIDs(t))
+	t.Cleanup(func() { _ = sut.Close() })
+
+	handler := &recordingHandler{}
+	listener := redis.NewExpiryListener(sut, cfg.Datastore().Redis(), handler)
+
+	expiredSecret := writeShortLivedSecret(t, sut)
+	deletedSecret := writeShortLivedSecret(t, sut)
+	_, err := sut.DeleteSecret(context.Background(), deletedSecret.ID)
+	require.NoError(t, err)
+
+	time.Sleep(2 * time.Second)
+
+	_, err = listener.Sweep(context.Background())
+	require.NoError(t, err)
+
+	t.Run("it should emit an expired event for the expired secret", func(t *testing.T) {
+		assert.Contains(t, handler.expiredKeyIds(), sut.KeyID(expiredSecret.ID))
+	})
+
+	t.Run("it should not emit an expired event for the deleted secret", func(t *testing.T) {
+		assert.NotContains(t, handler.expiredKeyIds(), sut.KeyID(deletedSecret.ID))
+	})
+
+	t.Run("and sweeping again", func(t *testing.T) {
+		before := len(handler.expiredKeyIds())
+		_, err := listener.Sweep(context.Background())
+		require.NoError(t, err)
+
+		t.Run("it should not emit the expiry a second time", func(t *testing.T) {
+			assert.Equal(t, before, len(handler.expiredKeyIds()))
+		})
+	})
+}
@@ -0,0 +1,265 @@
+//go:build integration
+// +build integration
+
+package datastore
+
+import (
+	"cellar/pkg/datastore/redis"
+	"cellar/pkg/integrity"
+	"cellar/pkg/models"
+	"cellar/pkg/settings"
+	"cellar/testing/testhelpers"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhenReadingTaggedSecrets(t *testing.T) {
+	ctx := context.Background()
+	cfg := settings.NewConfiguration()
+	redisClient := testhelpers.GetRedisClient(cfg.Datastore().Redis())
+	sut := redis.NewDataStore(cfg.Datastore().Redis(), testhelpers.GetKeyIDs(t))
+
+	require.NoError(t, integrity.SetKeys([]string{"k1:secret"}))
+	t.Cleanup(func() {
+		_ = integrity.SetKeys(nil)
+		_ = redisClient.Close()
+	})
+
+	writeSecret := func(t *testing.T) (models.Secret, *redis.RedisKey) {
+		secret := models.Secret{
+			ID:              testhelpers.RandomId(t),
+			CipherText:      testhelpers.RandomId(t),
+			ContentType:     models.ContentTypeText,
+			AccessLimit:     2,
+			ExpirationEpoch: testhelpers.EpochFromNow(time.Minute),
+			OwnerTokenHash:  testhelpers.RandomId(t),
+			OwnerOnly:       true,
+			CreatedEpoch:    time.Now().Unix(),
+		}
+		keys := redis.NewRedisKeySet(testhelpers.GetKeyIDs(t), secret.ID)
+		t.Cleanup(func() { _ = redisClient.Del(ctx, keys.AllKeys()...).Err() })
+		require.NoError(t, sut.WriteSecret(ctx, secret))
+		return secret, keys
+	}
+
+	t.Run("when the secret is unchanged", func(t *testing.T) {
+		secret, _ := writeSecret(t)
+
+		t.Run("it should read the secret", func(t *testing.T) {
+			assert.NotNil(t, sut.ReadSecret(ctx, secret.ID))
+		})
+	})
+
+	t.Run("when the access limit is raised in the datastore", func(t *testing.T) {
+		secret, keys := writeSecret(t)
+		require.NoError(t, redisClient.Set(ctx, keys.AccessLimit(), "100", time.Minute).Err())
+
+		t.Run("it should reject the secret", func(t *testing.T) {
+			assert.Nil(t, sut.ReadSecret(ctx, secret.ID))
+		})
+
+		t.Run("it should refuse to update the secret", func(t *testing.T) {
+			_, err := sut.UpdateSecret(ctx, secret.ID, testhelpers.EpochFromNow(time.Minute), 1)
+			assert.ErrorIs(t, err, integrity.ErrMismatch)
+		})
+	})
+
+	t.Run("when the expiration is extended in the datastore", func(t *testing.T) {
+		secret, keys := writeSecret(t)
+		require.NoError(t, redisClient.Set(ctx, keys.ExpirationEpoch(), testhelpers.EpochFromNow(time.Hour), time.Minute).Err())
+
+		t.Run("it should reject the secret", func(t *testing.T) {
+			assert.Nil(t, sut.ReadSecret(ctx, secret.ID))
+		})
+	})
+
+	t.Run("when the secret is accessed", func(t *testing.T) {
+		secret, _ := writeSecret(t)
+		_, err := sut.IncreaseAccessCount(ctx, secret.ID)
+		require.NoError(t, err)
+
+		t.Run("it should read the secret with its new access count", func(t *testing.T) {
+			actual := sut.ReadSecret(ctx, secret.ID)
+			require.NotNil(t, actual)
+			assert.Equal(t, 1, actual.AccessCount)
+		})
+	})
+
+	t.Run("when the access count is lowered in the datastore", func(t *testing.T) {
+		secret, keys := writeSecret(t)
+		_, err := sut.IncreaseAccessCount(ctx, secret.ID)
+		require.NoError(t, err)
+		require.NoError(t, redisClient.Set(ctx, keys.Access(), "0", time.Minute).Err())
+
+		t.Run("it should reject the secret", func(t *testing.T) {
+			assert.Nil(t, sut.ReadSecret(ctx, secret.ID))
+		})
+
+		t.Run("it should refuse to count the access", func(t *testing.T) {
+			_, err := sut.IncreaseAccessCount(ctx, secret.ID)
+			assert.ErrorIs(t, err, integrity.ErrMismatch)
+		})
+	})
+
+	t.Run("when the owner-only restriction is removed in the datastore", func(t *testing.T) {
+		secret, keys := writeSecret(t)
+		require.NoError(t, redisClient.Del(ctx, keys.OwnerOnly()).Err())
+
+		t.Run("it should reject the secret", func(t *testing.T) {
+			assert.Nil(t, sut.ReadSecret(ctx, secret.ID))
+		})
+	})
+
+	t.Run("when a notification email is added in the datastore", func(t *testing.T) {
+		secret, keys := writeSecret(t)
+		require.NoError(t, redisClient.Set(ctx, keys.NotifyEmail(), "attacker@example.com", time.Minute).Err())
+
+		t.Run("it should reject the secret", func(t *testing.T) {
+			assert.Nil(t, sut.ReadSecret(ctx, secret.ID))
+		})
+	})
+
+	t.Run("when the creation time is changed in the datastore", func(t *testing.T) {
+		secret, keys := writeSecret(t)
+		require.NoError(t, redisClient.Set(ctx, keys.Created(), testhelpers.EpochFromNow(time.Hour), time.Minute).Err())
+
+		t.Run("it should reject the secret", func(t *testing.T) {
+			assert.Nil(t, sut.ReadSecret(ctx, secret.ID))
+		})
+	})
+
+	t.Run("when the tag is removed in the datastore", func(t *testing.T) {
+		secret, keys := writeSecret(t)
+		require.NoError(t, redisClient.Del(ctx, keys.Integrity()).Err())
+
+		t.Run("it should reject the secret", func(t *testing.T) {
+			assert.Nil(t, sut.ReadSecret(ctx, secret.ID))
+		})
+	})
+
+	t.Run("when the secret is updated", func(t *testing.T) {
+		secret, _ := writeSecret(t)
+		updated, err := sut.UpdateSecret(ctx, secret.ID, testhelpers.EpochFromNow(2*time.Minute), 3)
+		require.NoError(t, err)
+		require.True(t, updated)
+
+		t.Run("it should read the updated secret", func(t *testing.T) {
+			actual := sut.ReadSecret(ctx, secret.ID)
+			require.NotNil(t, actual)
+			assert.Equal(t, 3, actual.AccessLimit)
+		})
+	})
+
+	t.Run("when the secret is updated concurrently", func(t *testing.T) {
+		secret, _ := writeSecret(t)
+
+		var wg sync.WaitGroup
+		for limit := 2; limit < 7; limit++ {
+			wg.Add(1)
+			go func(limit int) {
+				defer wg.Done()
+				_, err := sut.UpdateSecret(ctx, secret.ID, testhelpers.EpochFromNow(2*time.Minute), limit)
+				assert.NoError(t, err)
+			}(limit)
+		}
+		wg.Wait()
+
+		t.Run("it should leave a tag that covers the stored values", func(t *testing.T) {
+			assert.NotNil(t, sut.ReadSecret(ctx, secret.ID))
+		})
+	})
+
+	t.Run("when the secret was written before keys were configured and untagged secrets are allowed", func(t *testing.T) {
+		writeUntagged := func(t *testing.T) (models.Secret, *redis.RedisKey) {
+			require.NoError(t, integrity.SetKeys(nil))
+			secret, keys := writeSecret(t)
+			require.NoError(t, integrity.SetKeys([]string{"k1:secret"}))
+			integrity.SetAllowUntagged(true)
+			t.Cleanup(func() { integrity.SetAllowUntagged(false) })
+			return secret, keys
+		}
+
+		assertTagged := func(t *testing.T, keys *redis.RedisKey, expirationEpoch int64) {
+			t.Run("it should tag the secret", func(t *testing.T) {
+				tag, err := redisClient.Get(ctx, keys.Integrity()).Result()
+				require.NoError(t, err)
+				assert.Regexp(t, `^k1:`, tag)
+			})
+
+			t.Run("it should expire the tag along with the secret", func(t *testing.T) {
+				ttl, err := redisClient.TTL(ctx, keys.Integrity()).Result()
+				require.NoError(t, err)
+				assert.InDelta(t, time.Until(time.Unix(expirationEpoch, 0)).Seconds(), ttl.Seconds(), 2)
+			})
+		}
+
+		t.Run("and it is accessed", func(t *testing.T) {
+			secret, keys := writeUntagged(t)
+			accessCount, err := sut.IncreaseAccessCount(ctx, secret.ID)
+
+			t.Run("it should count the access", func(t *testing.T) {
+				require.NoError(t, err)
+				assert.Equal(t, int64(1), accessCount)
+			})
+
+			assertTagged(t, keys, secret.ExpirationEpoch)
+
+			t.Run("it should read the secret once untagged secrets are no longer allowed", func(t *testing.T) {
+				integrity.SetAllowUntagged(false)
+				actual := sut.ReadSecret(ctx, secret.ID)
+				require.NotNil(t, actual)
+				assert.Equal(t, 1, actual.AccessCount)
+			})
+		})
+
+		t.Run("and it is updated", func(t *testing.T) {
+			secret, keys := writeUntagged(t)
+			expirationEpoch := testhelpers.EpochFromNow(2 * time.Minute)
+			updated, err := sut.UpdateSecret(ctx, secret.ID, expirationEpoch, 3)
+
+			t.Run("it should report the secret was updated", func(t *testing.T) {
+				require.NoError(t, err)
+				assert.True(t, updated)
+			})
+
+			assertTagged(t, keys, expirationEpoch)
+		})
+
+		t.Run("and its view window is started", func(t *testing.T) {
+			secret, keys := writeUntagged(t)
+			expirationEpoch := testhelpers.EpochFromNow(30 * time.Second)
+
+			t.Run("it should not return error", func(t *testing.T) {
+				assert.NoError(t, sut.StartViewWindow(ctx, secret.ID, expirationEpoch))
+			})
+
+			assertTagged(t, keys, expirationEpoch)
+		})
+	})
+
+	t.Run("when the key is rotated", func(t *testing.T) {
+		secret, keys := writeSecret(t)
+		require.NoError(t, integrity.SetKeys([]string{"k2:another secret", "k1:secret"}))
+		t.Cleanup(func() { _ = integrity.SetKeys([]string{"k1:secret"}) })
+
+		t.Run("it should read secrets tagged with the old key", func(t *testing.T) {
+			assert.NotNil(t, sut.ReadSecret(ctx, secret.ID))
+		})
+
+		t.Run("and the secret is updated", func(t *testing.T) {
+			_, err := sut.UpdateSecret(ctx, secret.ID, secret.ExpirationEpoch, 2)
+			require.NoError(t, err)
+
+			t.Run("it should tag the secret with the new key", func(t *testing.T) {
+				tag, err := redisClient.Get(ctx, keys.Integrity()).Result()
+				require.NoError(t, err)
+				assert.Regexp(t, `^k2:`, tag)
+			})
+		})
+	})
+}
@@ -0,0 +1,190 @@
+//go:build integration
+// +build integration
+
+package datastore
+
+import (
+	"cellar/pkg/datastore/redis"
+	"cellar/pkg/models"
+	"cellar/pkg/settings"
+	"cellar/testing/testhelpers"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhenWritingSecretUnderItsKeyId(t *testing.T) {
+	ctx := context.Background()
+	cfg := settings.NewConfiguration()
+	redisClient := testhelpers.GetRedisClient(cfg.Datastore().Redis())
+	sut := redis.NewDataStore(cfg.Datastore().Redis(), testhelpers.GetKeyIDs(t))
+
+	secret := models.Secret{
+		ID:              testhelpers.RandomId(t),
+		CipherText:      testhelpers.RandomId(t),
+		ContentType:     models.ContentTypeText,
+		NotifyEmail:     "sender@example.com",
+		AccessLimit:     1,
+		ExpirationEpoch: testhelpers.EpochFromNow(time.Minute),
+	}
+
+	keys := redis.NewRedisKeySet(testhelpers.GetKeyIDs(t), secret.ID)
+	t.Cleanup(func() {
+		_ = redisClient.Del(ctx, keys.AllKeys()...).Err()
+		_ = redisClient.Close()
+	})
+
+	require.NoError(t, sut.WriteSecret(ctx, secret))
+
+	t.Run("it should not store the secret id in any key name", func(t *testing.T) {
+		found, _, err := redisClient.Scan(ctx, 0, fmt.Sprintf("*%s*", secret.ID), 1000).Result()
+		require.NoError(t, err)
+		assert.Empty(t, found)
+	})
+
+	t.Run("it should index the expiration by key id", func(t *testing.T) {
+		_, err := redisClient.ZScore(ctx, "cellar:secrets:expirations", sut.KeyID(secret.ID)).Result()
+		assert.NoError(t, err)
+	})
+
+	t.Run("it should not store the secret id in the expiry notification", func(t *testing.T) {
+		val, err := redisClient.Get(ctx, keys.ExpiryNotification()).Result()
+		require.NoError(t, err)
+		assert.NotContains(t, val, secret.ID)
+	})
+
+	t.Run("and taking the expiry notification by key id", func(t *testing.T) {
+		notice, err := sut.TakeExpiryNotification(ctx, sut.KeyID(secret.ID))
+		require.NoError(t, err)
+
+		t.Run("it should return the recipient and a reference to the secret", func(t *testing.T) {
+			assert.Equal(t, secret.NotifyEmail, notice.Recipient)
+			assert.Equal(t, models.SecretReference(secret.ID), notice.Reference)
+		})
+	})
+}
+
+func TestWhenMigratingKeys(t *testing.T) {
+	ctx := context.Background()
+	cfg := settings.NewConfiguration()
+	redisClient := testhelpers.GetRedisClient(cfg.Datastore().Redis())
+	sut := redis.NewDataStore(cfg.Datastore().Redis(), testhelpers.GetKeyIDs(t))
+
+	secret := models.Secret{
+		ID:              testhelpers.RandomId(t),
+		CipherText:      testhelpers.RandomId(t),
+		ContentType:     models.ContentTypeText,
+		NotifyEmail:     "sender@example.com",
+		AccessLimit:     5,
+		ExpirationEpoch: testhelpers.EpochFromNow(time.Minute),
+	}
+	shareId := testhelpers.RandomId(t)
+	split := models.SplitSecret{
+		ID:              testhelpers.RandomId(t),
+		ContentType:     models.ContentTypeText,
+		Threshold:       2,
+		ExpirationEpoch: secret.ExpirationEpoch,
+	}
+
+	// write the records the way they were stored before key ids
+	legacySecret := func(tail string) string { return fmt.Sprintf("secrets:%s:%s", secret.ID, tail) }
+	legacySplit := func(tail string) string { return fmt.Sprintf("splits:%s:%s", split.ID, tail) }
+	for key, value := range map[string]interface{}{
+		legacySecret("access"):             0,
+		legacySecret("accesslimit"):        strconv.Itoa(secret.AccessLimit),
+		legacySecret("contenttype"):        secret.ContentType,
+		legacySecret("content"):            secret.CipherText,
+		legacySecret("expirationepoch"):    secret.ExpirationEpoch,
+		legacySecret("notifyemail"):        secret.NotifyEmail,
+		legacySecret("expirynotification"): secret.NotifyEmail,
+		legacySplit("contenttype"):         split.ContentType,
+		legacySplit("threshold"):           strconv.Itoa(split.Threshold),
+		legacySplit("shares"):              fmt.Sprintf(`["%s"]`, shareId),
+		legacySplit("expirationepoch"):     split.ExpirationEpoch,
+	} {
+		require.NoError(t, redisClient.Set(ctx, key, value, time.Minute).Err())
+	}
+	require.NoError(t, redisClient.ZAdd(ctx, "cellar:secrets:expirations", goredis.Z{
+		Score:  float64(secret.ExpirationEpoch),
+		Member: secret.ID,
+	}).Err())
+
+	keys := redis.NewRedisKeySet(testhelpers.GetKeyIDs(t), secret.ID)
+	splitKeys := redis.NewRedisSplitKeySet(testhelpers.GetKeyIDs(t), split.ID)
+	t.Cleanup(func() {
+		_ = redisClient.Del(ctx, keys.AllKeys()...).Err()
+		_ = redisClient.Del(ctx, splitKeys.AllKeys()...).Err()
+		_ = redisClient.ZRem(ctx, "cellar:secrets:expirations", secret.ID, sut.KeyID(secret.ID)).Err()
+		_ = redisClient.Close()
+	})
+
+	migrated, err := sut.MigrateKeys(ctx)
+	require.NoError(t, err)
+
+	t.Run("it should migrate the records", func(t *testing.T) {
+		assert.GreaterOrEqual(t, migrated, 2)
+	})
+
+	t.Run("it should find the secret by its id", func(t *testing.T) {
+		actual := sut.ReadSecret(ctx, secret.ID)
+		require.NotNil(t, actual)
+		assert.Equal(t, secret.CipherText, actual.CipherText)
+		assert.Equal(t, secret.AccessLimit, actual.AccessLimit)
+	})
+
+	t.Run("it should keep the time to live of the keys", func(t *testing.T) {
+		val, err := redisClient.TTL(ctx, keys.Content()).Result()
+		require.NoError(t, err)
+		assert.Greater(t, val, time.Duration(0))
+	})
+
+	t.Run("it should remove the keys named after the secret id", func(t *testing.T) {
+		val, err := redisClient.Exists(ctx, legacySecret("content"), legacySplit("shares")).Result()
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), val)
+	})
+
+	t.Run("it should index the expiration by key id", func(t *testing.T) {
+		score, err := redisClient.ZScore(ctx, "cellar:secrets:expirations", sut.KeyID(secret.ID)).Result()
+		require.NoError(t, err)
+		assert.Equal(t, float64(secret.ExpirationEpoch), score)
+
+		_, err = redisClient.ZScore(ctx, "cellar:secrets:expirations", secret.ID).Result()
+		assert.ErrorIs(t, err, goredis.Nil)
+	})
+
+	t.Run("it should reference the secret in the expiry notification", func(t *testing.T) {
+		val, err := redisClient.Get(ctx, keys.ExpiryNotification()).Bytes()
+		require.NoError(t, err)
+
+		var notice models.ExpiryNotice
+		require.NoError(t, json.Unmarshal(val, &notice))
+		assert.Equal(t, secret.NotifyEmail, notice.Recipient)
+		assert.Equal(t, models.SecretReference(secret.ID), notice.Reference)
+	})
+
+	t.Run("it should track the shares of the split secret by key id", func(t *testing.T) {
+		actual := sut.ReadSplitSecret(ctx, split.ID)
+		require.NotNil(t, actual)
+		assert.True(t, actual.HasShareKey(sut.KeyID(shareId)))
+		assert.Equal(t, []string{sut.KeyID(shareId)}, actual.ShareKeys)
+	})
+
+	t.Run("and migrating again", func(t *testing.T) {
+		_, err := sut.MigrateKeys(ctx)
+		require.NoError(t, err)
+
+		t.Run("it should leave the migrated records alone", func(t *testing.T) {
+			actual := sut.ReadSecret(ctx, secret.ID)
+			require.NotNil(t, actual)
+			assert.Equal(t, secret.CipherText, actual.CipherText)
+		})
+	})
+}
@@ -0,0 +1,65 @@
+//go:build integration
+// +build integration
+
+package datastore
+
+import (
+	"cellar/pkg/datastore/redis"
+	"cellar/pkg/models"
+	"cellar/pkg/settings"
+	"cellar/testing/testhelpers"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhenUsingKeyPrefix(t *testing.T) {
+	ctx := context.Background()
+	cfg := settings.NewConfiguration()
+	redisClient := testhelpers.GetRedisClient(cfg.Datastore().Redis())
+	shared := redis.NewDataStore(cfg.Datastore().Redis(), testhelpers.GetKeyIDs(t))
+	sut := shared.WithKeyPrefix("tenants:finance:")
+
+	secret := models.Secret{
+		ID:              testhelpers.RandomId(t),
+		CipherText:      testhelpers.RandomId(t),
+		ContentType:     models.ContentTypeText,
+		AccessLimit:     5,
+		ExpirationEpoch: testhelpers.EpochFromNow(time.Minute),
+	}
+	require.NoError(t, sut.WriteSecret(ctx, secret))
+
+	contentKey := fmt.Sprintf("tenants:finance:secrets:%s:content", sut.KeyID(secret.ID))
+	t.Cleanup(func() {
+		keys, _ := redisClient.Keys(ctx, fmt.Sprintf("tenants:finance:secrets:%s:*", sut.KeyID(secret.ID))).Result()
+		if len(keys) > 0 {
+			_ = redisClient.Del(ctx, keys...).Err()
+		}
+		_ = redisClient.Close()
+		_ = shared.Close()
+	})
+
+	t.Run("it should store the secret under the prefix", func(t *testing.T) {
+		exists, err := redisClient.Exists(ctx, contentKey).Result()
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), exists)
+	})
+
+	t.Run("it should read the secret through a datastore with the prefix", func(t *testing.T) {
+		actual := sut.ReadSecret(ctx, secret.ID)
+		require.NotNil(t, actual)
+		assert.Equal(t, secret.CipherText, actual.CipherText)
+	})
+
+	t.Run("it should not find the secret through a datastore without the prefix", func(t *testing.T) {
+		assert.Nil(t, shared.ReadSecret(ctx, secret.ID))
+	})
+
+	t.Run("it should not find the secret through a datastore with another prefix", func(t *testing.T) {
+		assert.Nil(t, shared.WithKeyPrefix("tenants:sales:").ReadSecret(ctx, secret.ID))
+	})
+}
@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -22,7 +23,7 @@ import (
 func TestWhenGettingHealth(t *testing.T) {
 	ctx := context.Background()
 	cfg := settings.NewConfiguration()
-	sut := redis.NewDataStore(cfg.Datastore().Redis())
+	sut := redis.NewDataStore(cfg.Datastore().Redis(), testhelpers.GetKeyIDs(t))
 	actual := sut.Health(ctx)
 
 	t.Run("it should return redis name", func(t *testing.T) {
@@ -42,7 +43,7 @@ func TestWhenWritingSecret(t *testing.T) {
 	ctx := context.Background()
 	cfg := settings.NewConfiguration()
 	redisClient := testhelpers.GetRedisClient(cfg.Datastore().Redis())
-	sut := redis.NewDataStore(cfg.Datastore().Redis())
+	sut := redis.NewDataStore(cfg.Datastore().Redis(), testhelpers.GetKeyIDs(t))
 
 	secret := models.Secret{
 		ID:              testhelpers.RandomId(t),
@@ -70,7 +71,7 @@ func TestWhenWritingSecret(t *testing.T) {
 			testSecret.ID = testhelpers.RandomId(t)
 			testSecret.Filename = tc.filename
 
-			keys := redis.NewRedisKeySet(testSecret.ID)
+			keys := redis.NewRedisKeySet(testhelpers.GetKeyIDs(t), testSecret.ID)
 			err := sut.WriteSecret(ctx, testSecret)
 
 			t.Cleanup(func() {
@@ -174,7 +175,7 @@ func TestWhenReadingSecret(t *testing.T) {
 	ctx := context.Background()
 	cfg := settings.NewConfiguration()
 	redisClient := testhelpers.GetRedisClient(cfg.Datastore().Redis())
-	sut := redis.NewDataStore(cfg.Datastore().Redis())
+	sut := redis.NewDataStore(cfg.Datastore().Redis(), testhelpers.GetKeyIDs(t))
 
 	expected := models.Secret{
 		ID:              testhelpers.RandomId(t),
@@ -205,7 +206,7 @@ func TestWhenReadingSecret(t *testing.T) {
 			if tc.setupOldStyle {
 				// Test backward compatibility: manually create old-style secret without filename key
 				id := testhelpers.RandomId(t)
-				keys = redis.NewRedisKeySet(id)
+				keys = redis.NewRedisKeySet(testhelpers.GetKeyIDs(t), id)
 
 				_ = redisClient.Set(ctx, keys.ContentType(), models.ContentTypeFile, time.Minute).Err()
 				_ = redisClient.Set(ctx, keys.Content(), expected.CipherText, time.Minute).Err()
@@ -220,7 +221,7 @@ func TestWhenReadingSecret(t *testing.T) {
 				secret = expected
 				secret.ID = testhelpers.RandomId(t)
 				secret.Filename = tc.filename
-				keys = redis.NewRedisKeySet(secret.ID)
+				keys = redis.NewRedisKeySet(testhelpers.GetKeyIDs(t), secret.ID)
 				require.NoError(t, sut.WriteSecret(ctx, secret))
 			}
 
@@ -259,11 +260,309 @@ func TestWhenReadingSecret(t *testing.T) {
 	}
 }
 
+func TestWhenReadingBundleSecret(t *testing.T) {
+	ctx := context.Background()
+	cfg := settings.NewConfiguration()
+	redisClient := testhelpers.GetRedisClient(cfg.Datastore().Redis())
+	sut := redis.NewDataStore(cfg.Datastore().Redis(), testhelpers.GetKeyIDs(t))
+
+	secret := models.Secret{
+		ID:          testhelpers.RandomId(t),
+		CipherText:  testhelpers.RandomId(t),
+		ContentType: models.ContentTypeBundle,
+		BundleItems: []models.BundleItem{
+			{Name: "cert.pem", Size: 1024},
+			{Name: "note.txt", Size: 16},
+		},
+		ExpirationEpoch: testhelpers.EpochFromNow(time.Minute),
+	}
+	keys := redis.NewRedisKeySet(testhelpers.GetKeyIDs(t), secret.ID)
+
+	t.Cleanup(func() {
+		_ = redisClient.Del(ctx, keys.AllKeys()...).Err()
+		_ = redisClient.Close()
+	})
+
+	require.NoError(t, sut.WriteSecret(ctx, secret))
+	actual := sut.ReadSecret(ctx, secret.ID)
+	require.NotNil(t, actual)
+
+	t.Run("it should return the bundle items in order", func(t *testing.T) {
+		assert.Equal(t, secret.BundleItems, actual.BundleItems)
+	})
+
+	t.Run("it should set the bundle items to expire with the secret", func(t *testing.T) {
+		ttl, err := redisClient.TTL(ctx, keys.BundleItems()).Result()
+		require.NoError(t, err)
+		assert.Greater(t, ttl, time.Duration(0))
+	})
+}
+
+func TestWhenReadingSplitKeySecret(t *testing.T) {
+	ctx := context.Background()
+	cfg := settings.NewConfiguration()
+	redisClient := testhelpers.GetRedisClient(cfg.Datastore().Redis())
+	sut := redis.NewDataStore(cfg.Datastore().Redis(), testhelpers.GetKeyIDs(t))
+
+	secret := models.Secret{
+		ID:              testhelpers.RandomId(t),
+		CipherText:      testhelpers.RandomId(t),
+		ContentType:     models.ContentTypeText,
+		SplitKey:        true,
+		SplitKeyCheck:   testhelpers.RandomId(t),
+		ExpirationEpoch: testhelpers.EpochFromNow(time.Minute),
+	}
+	keys := redis.NewRedisKeySet(testhelpers.GetKeyIDs(t), secret.ID)
+
+	t.Cleanup(func() {
+		_ = redisClient.Del(ctx, keys.AllKeys()...).Err()
+		_ = redisClient.Close()
+	})
+
+	require.NoError(t, sut.WriteSecret(ctx, secret))
+	actual := sut.ReadSecret(ctx, secret.ID)
+	require.NotNil(t, actual)
+
+	t.Run("it should return the split key check", func(t *testing.T) {
+		assert.True(t, actual.SplitKey)
+		assert.Equal(t, secret.SplitKeyCheck, actual.SplitKeyCheck)
+	})
+
+	t.Run("it should set the split key check to expire with the secret", func(t *testing.T) {
+		ttl, err := redisClient.TTL(ctx, keys.SplitKeyCheck()).Result()
+		require.NoError(t, err)
+		assert.Greater(t, ttl, time.Duration(0))
+	})
+}
+
+func TestWhenReadingSecretWithAllowedCIDRs(t *testing.T) {
+	ctx := context.Background()
+	cfg := settings.NewConfiguration()
+	redisClient := testhelpers.GetRedisClient(cfg.Datastore().Redis())
+	sut := redis.NewDataStore(cfg.Datastore().Redis(), testhelpers.GetKeyIDs(t))
+
+	secret := models.Secret{
+		ID:              testhelpers.RandomId(t),
+		CipherText:      testhelpers.RandomId(t),
+		ContentType:     models.ContentTypeText,
+		AllowedCIDRs:    []string{"10.0.0.0/8", "2001:db8::/32"},
+		ExpirationEpoch: testhelpers.EpochFromNow(time.Minute),
+	}
+	keys := redis.NewRedisKeySet(testhelpers.GetKeyIDs(t), secret.ID)
+
+	t.Cleanup(func() {
+		_ = redisClient.Del(ctx, keys.AllKeys()...).Err()
+		_ = redisClient.Close()
+	})
+
+	require.NoError(t, sut.WriteSecret(ctx, secret))
+	actual := sut.ReadSecret(ctx, secret.ID)
+	require.NotNil(t, actual)
+
+	t.Run("it should return the allowed CIDRs", func(t *testing.T) {
+		assert.Equal(t, secret.AllowedCIDRs, actual.AllowedCIDRs)
+	})
+
+	t.Run("it should set the allowed CIDRs to expire with the secret", func(t *testing.T) {
+		ttl, err := redisClient.TTL(ctx, keys.AllowedCIDRs()).Result()
+		require.NoError(t, err)
+		assert.Greater(t, ttl, time.Duration(0))
+	})
+}
+
+func TestWhenReadingSecretWithCountryRestrictions(t *testing.T) {
+	ctx := context.Background()
+	cfg := settings.NewConfiguration()
+	redisClient := testhelpers.GetRedisClient(cfg.Datastore().Redis())
+	sut := redis.NewDataStore(cfg.Datastore().Redis(), testhelpers.GetKeyIDs(t))
+
+	secret := models.Secret{
+		ID:               testhelpers.RandomId(t),
+		CipherText:       testhelpers.RandomId(t),
+		ContentType:      models.ContentTypeText,
+		AllowedCountries: []string{"DE", "FR"},
+		DeniedCountries:  []string{"US"},
+		ExpirationEpoch:  testhelpers.EpochFromNow(time.Minute),
+	}
+	keys := redis.NewRedisKeySet(testhelpers.GetKeyIDs(t), secret.ID)
+
+	t.Cleanup(func() {
+		_ = redisClient.Del(ctx, keys.AllKeys()...).Err()
+		_ = redisClient.Close()
+	})
+
+	require.NoError(t, sut.WriteSecret(ctx, secret))
+	actual := sut.ReadSecret(ctx, secret.ID)
+	require.NotNil(t, actual)
+
+	t.Run("it should return the country restrictions", func(t *testing.T) {
+		assert.Equal(t, secret.AllowedCountries, actual.AllowedCountries)
+		assert.Equal(t, secret.DeniedCountries, actual.DeniedCountries)
+	})
+
+	t.Run("it should set the country restrictions to expire with the secret", func(t *testing.T) {
+		for _, key := range []string{keys.AllowedCountries(), keys.DeniedCountries()} {
+			ttl, err := redisClient.TTL(ctx, key).Result()
+			require.NoError(t, err)
+			assert.Greater(t, ttl, time.Duration(0))
+		}
+	})
+}
+
+func TestWhenWritingAPaddedSecret(t *testing.T) {
+	ctx := context.Background()
+	cfg := settings.NewConfiguration()
+	redisClient := testhelpers.GetRedisClient(cfg.Datastore().Redis())
+	sut := redis.NewDataStore(cfg.Datastore().Redis(), testhelpers.GetKeyIDs(t))
+
+	secret := models.Secret{
+		ID:              testhelpers.RandomId(t),
+		CipherText:      testhelpers.RandomId(t),
+		ContentType:     models.ContentTypeText,
+		Padded:          true,
+		ExpirationEpoch: testhelpers.EpochFromNow(time.Hour),
+	}
+	keys := redis.NewRedisKeySet(testhelpers.GetKeyIDs(t), secret.ID)
+
+	t.Cleanup(func() {
+		_ = redisClient.Del(ctx, keys.AllKeys()...).Err()
+		_ = redisClient.Close()
+	})
+
+	require.NoError(t, sut.WriteSecret(ctx, secret))
+
+	t.Run("it should return the secret as padded", func(t *testing.T) {
+		actual := sut.ReadSecret(ctx, secret.ID)
+		require.NotNil(t, actual)
+		assert.True(t, actual.Padded)
+	})
+}
+
+func TestWhenUsingAVerificationCode(t *testing.T) {
+	ctx := context.Background()
+	cfg := settings.NewConfiguration()
+	redisClient := testhelpers.GetRedisClient(cfg.Datastore().Redis())
+	sut := redis.NewDataStore(cfg.Datastore().Redis(), testhelpers.GetKeyIDs(t))
+
+	secret := models.Secret{
+		ID:              testhelpers.RandomId(t),
+		CipherText:      testhelpers.RandomId(t),
+		ContentType:     models.ContentTypeText,
+		RecipientEmail:  "recipient@example.com",
+		ExpirationEpoch: testhelpers.EpochFromNow(time.Hour),
+	}
+	keys := redis.NewRedisKeySet(testhelpers.GetKeyIDs(t), secret.ID)
+
+	t.Cleanup(func() {
+		_ = redisClient.Del(ctx, keys.AllKeys()...).Err()
+		_ = redisClient.Close()
+	})
+
+	require.NoError(t, sut.WriteSecret(ctx, secret))
+
+	t.Run("it should return the recipient email with the secret", func(t *testing.T) {
+		actual := sut.ReadSecret(ctx, secret.ID)
+		require.NotNil(t, actual)
+		assert.Equal(t, secret.RecipientEmail, actual.RecipientEmail)
+	})
+
+	t.Run("and a code is written", func(t *testing.T) {
+		written, err := sut.WriteVerificationCode(ctx, secret.ID, "code-hash", time.Minute)
+		require.NoError(t, err)
+
+		t.Run("it should report the code was written", func(t *testing.T) {
+			assert.True(t, written)
+		})
+
+		t.Run("it should return the code and count each attempt", func(t *testing.T) {
+			codeHash, attempts, err := sut.ReadVerificationCode(ctx, secret.ID, time.Hour)
+			require.NoError(t, err)
+			assert.Equal(t, "code-hash", codeHash)
+			assert.Equal(t, int64(1), attempts)
+
+			_, attempts, err = sut.ReadVerificationCode(ctx, secret.ID, time.Hour)
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), attempts)
+		})
+
+		t.Run("it should expire the code before the secret", func(t *testing.T) {
+			ttl, err := redisClient.TTL(ctx, keys.VerificationCode()).Result()
+			require.NoError(t, err)
+			assert.Greater(t, ttl, time.Duration(0))
+			assert.LessOrEqual(t, ttl, time.Minute)
+		})
+
+		t.Run("it should expire the attempts after the attempt window", func(t *testing.T) {
+			ttl, err := redisClient.TTL(ctx, keys.VerificationAttempts()).Result()
+			require.NoError(t, err)
+			assert.Greater(t, ttl, time.Minute)
+			assert.LessOrEqual(t, ttl, time.Hour)
+		})
+
+		t.Run("it should not replace the pending code", func(t *testing.T) {
+			written, err := sut.WriteVerificationCode(ctx, secret.ID, "new-code-hash", time.Minute)
+			require.NoError(t, err)
+			assert.False(t, written)
+
+			codeHash, _, err := sut.ReadVerificationCode(ctx, secret.ID, time.Hour)
+			require.NoError(t, err)
+			assert.Equal(t, "code-hash", codeHash)
+		})
+
+		t.Run("it should keep counting the attempts when a new code is written", func(t *testing.T) {
+			require.NoError(t, redisClient.Del(ctx, keys.VerificationCode()).Err())
+			written, err := sut.WriteVerificationCode(ctx, secret.ID, "new-code-hash", time.Minute)
+			require.NoError(t, err)
+			require.True(t, written)
+
+			codeHash, attempts, err := sut.ReadVerificationCode(ctx, secret.ID, time.Hour)
+			require.NoError(t, err)
+			assert.Equal(t, "new-code-hash", codeHash)
+			assert.Equal(t, int64(4), attempts)
+		})
+	})
+
+	t.Run("and the code is consumed", func(t *testing.T) {
+		t.Run("it should not consume a different code", func(t *testing.T) {
+			consumed, err := sut.ConsumeVerificationCode(ctx, secret.ID, "code-hash")
+			require.NoError(t, err)
+			assert.False(t, consumed)
+		})
+
+		t.Run("it should consume the pending code only once", func(t *testing.T) {
+			var wg sync.WaitGroup
+			var mu sync.Mutex
+			consumedTimes := 0
+			for i := 0; i < 5; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					consumed, err := sut.ConsumeVerificationCode(ctx, secret.ID, "new-code-hash")
+					assert.NoError(t, err)
+					if consumed {
+						mu.Lock()
+						consumedTimes++
+						mu.Unlock()
+					}
+				}()
+			}
+			wg.Wait()
+			assert.Equal(t, 1, consumedTimes)
+		})
+
+		t.Run("it should no longer return the code", func(t *testing.T) {
+			codeHash, _, err := sut.ReadVerificationCode(ctx, secret.ID, time.Hour)
+			require.NoError(t, err)
+			assert.Empty(t, codeHash)
+		})
+	})
+}
+
 func TestWenDeletingSecret(t *testing.T) {
 	ctx := context.Background()
 	cfg := settings.NewConfiguration()
 	redisClient := testhelpers.GetRedisClient(cfg.Datastore().Redis())
-	sut := redis.NewDataStore(cfg.Datastore().Redis())
+	sut := redis.NewDataStore(cfg.Datastore().Redis(), testhelpers.GetKeyIDs(t))
 
 	secret := models.Secret{
 		ID:              testhelpers.RandomId(t),
@@ -273,7 +572,7 @@ func TestWenDeletingSecret(t *testing.T) {
 		ExpirationEpoch: testhelpers.EpochFromNow(time.Minute),
 	}
 
-	keys := redis.NewRedisKeySet(secret.ID)
+	keys := redis.NewRedisKeySet(testhelpers.GetKeyIDs(t), secret.ID)
 
 	require.NoError(t, sut.WriteSecret(ctx, secret))
 
@@ -327,7 +626,7 @@ func TestWhenIncreasingSecretAccess(t *testing.T) {
 	ctx := context.Background()
 	cfg := settings.NewConfiguration()
 	redisClient := testhelpers.GetRedisClient(cfg.Datastore().Redis())
-	sut := redis.NewDataStore(cfg.Datastore().Redis())
+	sut := redis.NewDataStore(cfg.Datastore().Redis(), testhelpers.GetKeyIDs(t))
 
 	secret := models.Secret{
 		ID:              testhelpers.RandomId(t),
@@ -337,7 +636,7 @@ func TestWhenIncreasingSecretAccess(t *testing.T) {
 		ExpirationEpoch: testhelpers.EpochFromNow(time.Minute),
 	}
 
-	keys := redis.NewRedisKeySet(secret.ID)
+	keys := redis.NewRedisKeySet(testhelpers.GetKeyIDs(t), secret.ID)
 
 	require.NoError(t, sut.WriteSecret(ctx, secret))
 
@@ -368,3 +667,168 @@ func TestWhenIncreasingSecretAccess(t *testing.T) {
 		assert.Equal(t, secret.AccessLimit, val)
 	})
 }
+
+func TestWhenStartingSecretViewWindow(t *testing.T) {
+	ctx := context.Background()
+	cfg := settings.NewConfiguration()
+	redisClient := testhelpers.GetRedisClient(cfg.Datastore().Redis())
+	sut := redis.NewDataStore(cfg.Datastore().Redis(), testhelpers.GetKeyIDs(t))
+
+	secret := models.Secret{
+		ID:                testhelpers.RandomId(t),
+		CipherText:        testhelpers.RandomId(t),
+		ContentType:       models.ContentTypeText,
+		AccessLimit:       50,
+		ExpirationEpoch:   testhelpers.EpochFromNow(time.Hour),
+		ViewWindowSeconds: 60,
+	}
+
+	keys := redis.NewRedisKeySet(testhelpers.GetKeyIDs(t), secret.ID)
+
+	require.NoError(t, sut.WriteSecret(ctx, secret))
+
+	t.Cleanup(func() {
+		_ = redisClient.Del(ctx, keys.AllKeys()...).Err()
+		_ = redisClient.Close()
+	})
+
+	expirationEpoch := testhelpers.EpochFromNow(time.Minute)
+	err := sut.StartViewWindow(ctx, secret.ID, expirationEpoch)
+
+	t.Run("it should not return error", func(t *testing.T) {
+		assert.NoError(t, err)
+	})
+
+	t.Run("it should update the stored expiration", func(t *testing.T) {
+		val, err := redisClient.Get(ctx, keys.ExpirationEpoch()).Int64()
+		require.NoError(t, err)
+		assert.Equal(t, expirationEpoch, val)
+	})
+
+	t.Run("it should shorten the TTL of every key", func(t *testing.T) {
+		for _, key := range []string{keys.Content(), keys.ContentType(), keys.Access(), keys.AccessLimit(), keys.ExpirationEpoch(), keys.ViewWindow()} {
+			val, err := redisClient.TTL(ctx, key).Result()
+			require.NoError(t, err)
+			assert.LessOrEqual(t, val, time.Minute, key)
+			assert.Greater(t, val, time.Duration(0), key)
+		}
+	})
+
+	t.Run("it should read the view window", func(t *testing.T) {
+		actual := sut.ReadSecret(ctx, secret.ID)
+		require.NotNil(t, actual)
+		assert.Equal(t, secret.ViewWindowSeconds, actual.ViewWindowSeconds)
+	})
+}
+
+func TestWhenUpdatingSecret(t *testing.T) {
+	ctx := context.Background()
+	cfg := settings.NewConfiguration()
+	redisClient := testhelpers.GetRedisClient(cfg.Datastore().Redis())
+	sut := redis.NewDataStore(cfg.Datastore().Redis(), testhelpers.GetKeyIDs(t))
+
+	secret := models.Secret{
+		ID:              testhelpers.RandomId(t),
+		CipherText:      testhelpers.RandomId(t),
+		ContentType:     models.ContentTypeText,
+		AccessLimit:     5,
+		ExpirationEpoch: testhelpers.EpochFromNow(time.Minute),
+	}
+
+	keys := redis.NewRedisKeySet(testhelpers.GetKeyIDs(t), secret.ID)
+
+	require.NoError(t, sut.WriteSecret(ctx, secret))
+
+	t.Cleanup(func() {
+		_ = redisClient.Del(ctx, keys.AllKeys()...).Err()
+		_ = redisClient.Close()
+	})
+
+	expirationEpoch := testhelpers.EpochFromNow(time.Hour)
+	found, err := sut.UpdateSecret(ctx, secret.ID, expirationEpoch, 10)
+
+	t.Run("it should not return error", func(t *testing.T) {
+		assert.NoError(t, err)
+	})
+
+	t.Run("it should find the secret", func(t *testing.T) {
+		assert.True(t, found)
+	})
+
+	t.Run("it should update the stored expiration and access limit", func(t *testing.T) {
+		actual := sut.ReadSecret(ctx, secret.ID)
+		require.NotNil(t, actual)
+		assert.Equal(t, expirationEpoch, actual.ExpirationEpoch)
+		assert.Equal(t, 10, actual.AccessLimit)
+	})
+
+	t.Run("it should extend the TTL of every key", func(t *testing.T) {
+		for _, key := range []string{keys.Content(), keys.ContentType(), keys.Access(), keys.AccessLimit(), keys.ExpirationEpoch()} {
+			val, err := redisClient.TTL(ctx, key).Result()
+			require.NoError(t, err)
+			assert.Greater(t, val, 59*time.Minute, key)
+		}
+	})
+
+	t.Run("and the secret does not exist", func(t *testing.T) {
+		found, err := sut.UpdateSecret(ctx, testhelpers.RandomId(t), expirationEpoch, 10)
+
+		t.Run("it should not return error", func(t *testing.T) {
+			assert.NoError(t, err)
+		})
+
+		t.Run("it should not find the secret", func(t *testing.T) {
+			assert.False(t, found)
+		})
+	})
+}
+
+func TestWhenAppendingSecretHistory(t *testing.T) {
+	ctx := context.Background()
+	cfg := settings.NewConfiguration()
+	redisClient := testhelpers.GetRedisClient(cfg.Datastore().Redis())
+	sut := redis.NewDataStore(cfg.Datastore().Redis(), testhelpers.GetKeyIDs(t))
+
+	secret := models.Secret{
+		ID:              testhelpers.RandomId(t),
+		CipherText:      testhelpers.RandomId(t),
+		ContentType:     models.ContentTypeText,
+		ExpirationEpoch: testhelpers.EpochFromNow(time.Minute),
+	}
+
+	keys := redis.NewRedisKeySet(testhelpers.GetKeyIDs(t), secret.ID)
+
+	require.NoError(t, sut.WriteSecret(ctx, secret))
+
+	t.Cleanup(func() {
+		_ = redisClient.Del(ctx, keys.AllKeys()...).Err()
+		_ = redisClient.Close()
+	})
+
+	accessLimit := 3
+	expected := []models.SecretHistoryEvent{
+		{Type: models.SecretHistoryAccessed, OccurredAt: time.Now().UTC().Truncate(time.Second), AccessCount: 1},
+		{Type: models.SecretHistoryModified, OccurredAt: time.Now().UTC().Truncate(time.Second), AccessLimit: &accessLimit, ExpirationEpoch: secret.ExpirationEpoch},
+	}
+	for _, event := range expected {
+		require.NoError(t, sut.AppendSecretHistory(ctx, secret.ID, event))
+	}
+
+	actual, err := sut.ReadSecretHistory(ctx, secret.ID)
+	require.NoError(t, err)
+
+	t.Run("it should return the events in order", func(t *testing.T) {
+		require.Len(t, actual, len(expected))
+		for i := range expected {
+			assert.Equal(t, expected[i].Type, actual[i].Type)
+			assert.True(t, expected[i].OccurredAt.Equal(actual[i].OccurredAt))
+		}
+	})
+
+	t.Run("it should set TTL on the history", func(t *testing.T) {
+		val, err := redisClient.TTL(ctx, keys.History()).Result()
+		require.NoError(t, err)
+		assert.LessOrEqual(t, val, time.Minute)
+		assert.Greater(t, val, time.Duration(0))
+	})
+}
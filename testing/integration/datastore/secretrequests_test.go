@@ -0,0 +1,101 @@
+//go:build integration
+// +build integration
+
+package datastore
+
+import (
+	"cellar/pkg/datastore/redis"
+	"cellar/pkg/models"
+	"cellar/pkg/settings"
+	"cellar/testing/testhelpers"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhenReadingWrittenSecretRequest(t *testing.T) {
+	ctx := context.Background()
+	cfg := settings.NewConfiguration()
+	redisClient := testhelpers.GetRedisClient(cfg.Datastore().Redis())
+	sut := redis.NewDataStore(cfg.Datastore().Redis(), testhelpers.GetKeyIDs(t))
+
+	request := models.SecretRequest{
+		ID:              testhelpers.RandomId(t),
+		OwnerTokenHash:  testhelpers.RandomId(t),
+		ContentType:     models.ContentTypeFile,
+		MaxSizeBytes:    1024,
+		AccessLimit:     1,
+		ExpirationEpoch: testhelpers.EpochFromNow(time.Minute),
+		Creator:         "alice",
+	}
+
+	keys := redis.NewRedisRequestKeySet(testhelpers.GetKeyIDs(t), request.ID)
+	t.Cleanup(func() {
+		_ = redisClient.Del(ctx, keys.AllKeys()...).Err()
+		_ = redisClient.Close()
+	})
+
+	require.NoError(t, sut.WriteSecretRequest(ctx, request))
+	actual := sut.ReadSecretRequest(ctx, request.ID)
+	require.NotNil(t, actual)
+
+	t.Run("it should return the written request", func(t *testing.T) {
+		assert.Equal(t, request, *actual)
+	})
+
+	t.Run("it should set TTL on the owner token", func(t *testing.T) {
+		val, err := redisClient.TTL(ctx, keys.OwnerToken()).Result()
+		require.NoError(t, err)
+		assert.LessOrEqual(t, time.Now().Add(val).UTC().Sub(request.Expiration().Time()), time.Second)
+	})
+}
+
+func TestWhenFulfillingSecretRequest(t *testing.T) {
+	ctx := context.Background()
+	cfg := settings.NewConfiguration()
+	redisClient := testhelpers.GetRedisClient(cfg.Datastore().Redis())
+	sut := redis.NewDataStore(cfg.Datastore().Redis(), testhelpers.GetKeyIDs(t))
+
+	request := models.SecretRequest{
+		ID:              testhelpers.RandomId(t),
+		OwnerTokenHash:  testhelpers.RandomId(t),
+		MaxSizeBytes:    1024,
+		ExpirationEpoch: testhelpers.EpochFromNow(time.Minute),
+	}
+
+	keys := redis.NewRedisRequestKeySet(testhelpers.GetKeyIDs(t), request.ID)
+	t.Cleanup(func() {
+		_ = redisClient.Del(ctx, keys.AllKeys()...).Err()
+		_ = redisClient.Close()
+	})
+
+	require.NoError(t, sut.WriteSecretRequest(ctx, request))
+
+	firstSecretId := testhelpers.RandomId(t)
+	fulfilled, err := sut.FulfillSecretRequest(ctx, request, firstSecretId)
+	require.NoError(t, err)
+
+	t.Run("it should fulfill the request", func(t *testing.T) {
+		assert.True(t, fulfilled)
+	})
+
+	t.Run("it should store the secret ID", func(t *testing.T) {
+		assert.Equal(t, firstSecretId, sut.ReadSecretRequest(ctx, request.ID).SecretID)
+	})
+
+	t.Run("and fulfilling it again", func(t *testing.T) {
+		fulfilled, err := sut.FulfillSecretRequest(ctx, request, testhelpers.RandomId(t))
+		require.NoError(t, err)
+
+		t.Run("it should not fulfill the request", func(t *testing.T) {
+			assert.False(t, fulfilled)
+		})
+
+		t.Run("it should keep the first secret ID", func(t *testing.T) {
+			assert.Equal(t, firstSecretId, sut.ReadSecretRequest(ctx, request.ID).SecretID)
+		})
+	})
+}
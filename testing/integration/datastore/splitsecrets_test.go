@@ -0,0 +1,53 @@
+//go:build integration
+// +build integration
+
+package datastore
+
+import (
+	"cellar/pkg/datastore/redis"
+	"cellar/pkg/models"
+	"cellar/pkg/settings"
+	"cellar/testing/testhelpers"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhenReadingWrittenSplitSecret(t *testing.T) {
+	ctx := context.Background()
+	cfg := settings.NewConfiguration()
+	redisClient := testhelpers.GetRedisClient(cfg.Datastore().Redis())
+	sut := redis.NewDataStore(cfg.Datastore().Redis(), testhelpers.GetKeyIDs(t))
+
+	split := models.SplitSecret{
+		ID:              testhelpers.RandomId(t),
+		ContentType:     models.ContentTypeFile,
+		Filename:        "recovery-codes.txt",
+		Threshold:       2,
+		ShareKeys:       []string{sut.KeyID(testhelpers.RandomId(t)), sut.KeyID(testhelpers.RandomId(t)), sut.KeyID(testhelpers.RandomId(t))},
+		ExpirationEpoch: testhelpers.EpochFromNow(time.Minute),
+	}
+
+	keys := redis.NewRedisSplitKeySet(testhelpers.GetKeyIDs(t), split.ID)
+	t.Cleanup(func() {
+		_ = redisClient.Del(ctx, keys.AllKeys()...).Err()
+		_ = redisClient.Close()
+	})
+
+	require.NoError(t, sut.WriteSplitSecret(ctx, split))
+	actual := sut.ReadSplitSecret(ctx, split.ID)
+	require.NotNil(t, actual)
+
+	t.Run("it should return the written split secret", func(t *testing.T) {
+		assert.Equal(t, split, *actual)
+	})
+
+	t.Run("it should set TTL on the shares", func(t *testing.T) {
+		val, err := redisClient.TTL(ctx, keys.Shares()).Result()
+		require.NoError(t, err)
+		assert.LessOrEqual(t, time.Now().Add(val).UTC().Sub(split.Expiration().Time()), time.Second)
+	})
+}
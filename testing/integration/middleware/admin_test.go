@@ -0,0 +1,127 @@
+//go:build integration
+
+package middleware
+
+import (
+	"cellar/pkg/apikeys"
+	"cellar/pkg/datastore"
+	"cellar/pkg/middleware"
+	"cellar/pkg/mocks"
+	"cellar/pkg/models"
+	"cellar/pkg/settings"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRequireAdminMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	setupRouter := func(t *testing.T, enabled bool) *gin.Engine {
+		ctrl := gomock.NewController(t)
+		auth := mocks.NewMockIAuthConfiguration(ctrl)
+		auth.EXPECT().Enabled().Return(enabled).AnyTimes()
+		auth.EXPECT().AnonymousScopes().Return([]string{string(models.ScopeAdmin)}).AnyTimes()
+		mockConfig := mocks.NewMockIConfiguration(ctrl)
+		mockConfig.EXPECT().Auth().Return(auth).AnyTimes()
+
+		router := gin.New()
+		router.Use(middleware.ErrorHandler())
+		router.Use(func(c *gin.Context) {
+			c.Set(settings.Key, mockConfig)
+			if scope := c.GetHeader("X-Test-Key-Scope"); scope != "" {
+				c.Set(apikeys.AuthenticatedKey, &models.APIKey{ID: "k1", Scopes: []models.Scope{models.Scope(scope)}})
+			}
+			c.Next()
+		})
+		router.GET("/admin", middleware.RequireAdmin(), func(c *gin.Context) { c.Status(http.StatusOK) })
+		return router
+	}
+
+	request := func(router *gin.Engine, scope string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/admin", nil)
+		if scope != "" {
+			req.Header.Set("X-Test-Key-Scope", scope)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("when API keys are disabled", func(t *testing.T) {
+		router := setupRouter(t, false)
+
+		t.Run("it should reject every request", func(t *testing.T) {
+			assert.Equal(t, http.StatusForbidden, request(router, "").Code)
+		})
+	})
+
+	t.Run("when API keys are enabled", func(t *testing.T) {
+		router := setupRouter(t, true)
+
+		t.Run("it should reject anonymous requests even if anonymous requests are granted the admin scope", func(t *testing.T) {
+			assert.Equal(t, http.StatusUnauthorized, request(router, "").Code)
+		})
+
+		t.Run("it should reject keys without the admin scope", func(t *testing.T) {
+			assert.Equal(t, http.StatusForbidden, request(router, string(models.ScopeCreate)).Code)
+		})
+
+		t.Run("it should accept keys with the admin scope", func(t *testing.T) {
+			assert.Equal(t, http.StatusOK, request(router, string(models.ScopeAdmin)).Code)
+		})
+	})
+}
+
+func TestWritableMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	setupRouter := func(dataStore datastore.DataStore) *gin.Engine {
+		router := gin.New()
+		router.Use(middleware.ErrorHandler())
+		router.Use(func(c *gin.Context) {
+			c.Set(datastore.Key, dataStore)
+			c.Next()
+		})
+		router.POST("/secrets", middleware.Writable(), func(c *gin.Context) { c.Status(http.StatusCreated) })
+		return router
+	}
+
+	request := func(router *gin.Engine) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("POST", "/secrets", nil))
+		return w
+	}
+
+	t.Run("when the server is in read-only mode", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		dataStore := mocks.NewMockAdminDataStore(ctrl)
+		dataStore.EXPECT().ReadOnly(gomock.Any()).Return(true, nil)
+
+		t.Run("it should reject the request as unavailable", func(t *testing.T) {
+			assert.Equal(t, http.StatusServiceUnavailable, request(setupRouter(dataStore)).Code)
+		})
+	})
+
+	t.Run("when the server is not in read-only mode", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		dataStore := mocks.NewMockAdminDataStore(ctrl)
+		dataStore.EXPECT().ReadOnly(gomock.Any()).Return(false, nil)
+
+		t.Run("it should pass the request", func(t *testing.T) {
+			assert.Equal(t, http.StatusCreated, request(setupRouter(dataStore)).Code)
+		})
+	})
+
+	t.Run("when the datastore cannot be put in read-only mode", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		t.Run("it should pass the request", func(t *testing.T) {
+			assert.Equal(t, http.StatusCreated, request(setupRouter(mocks.NewMockDataStore(ctrl))).Code)
+		})
+	})
+}
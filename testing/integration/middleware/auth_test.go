@@ -0,0 +1,169 @@
+//go:build integration
+
+package middleware
+
+import (
+	"cellar/pkg/apikeys"
+	"cellar/pkg/middleware"
+	"cellar/pkg/mocks"
+	"cellar/pkg/models"
+	"cellar/pkg/settings"
+	"cellar/testing/testhelpers"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	cfg := settings.NewConfiguration()
+	client := testhelpers.GetRedisClient(cfg.Datastore().Redis())
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Fatalf("Redis must be available for integration tests: %v", err)
+	}
+	t.Cleanup(func() {
+		client.FlushDB(ctx)
+		_ = client.Close()
+	})
+
+	store := apikeys.NewRedisStore(client)
+	writeKey := func(t *testing.T, scopes ...models.Scope) string {
+		key, token, err := apikeys.Generate("test", scopes)
+		require.NoError(t, err)
+		require.NoError(t, store.Write(ctx, key))
+		return token
+	}
+
+	setupRouter := func(t *testing.T, enabled bool, anonymousScopes ...string) *gin.Engine {
+		ctrl := gomock.NewController(t)
+		auth := mocks.NewMockIAuthConfiguration(ctrl)
+		auth.EXPECT().Enabled().Return(enabled).AnyTimes()
+		auth.EXPECT().AnonymousScopes().Return(anonymousScopes).AnyTimes()
+		mockConfig := mocks.NewMockIConfiguration(ctrl)
+		mockConfig.EXPECT().Auth().Return(auth).AnyTimes()
+
+		router := gin.New()
+		router.Use(middleware.ErrorHandler())
+		router.Use(func(c *gin.Context) {
+			c.Set(settings.Key, mockConfig)
+			c.Set(apikeys.Key, store)
+			c.Next()
+		})
+		router.Use(middleware.Authenticate())
+
+		handler := func(c *gin.Context) { c.Status(http.StatusOK) }
+		router.POST("/secrets", middleware.RequireScope(models.ScopeCreate), handler)
+		router.GET("/config", middleware.RequireScope(models.ScopeReadConfig), handler)
+		router.POST("/secrets/:id/access", handler)
+		return router
+	}
+
+	request := func(router *gin.Engine, method string, path string, authorization string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("when API keys are disabled", func(t *testing.T) {
+		router := setupRouter(t, false)
+
+		t.Run("it should allow anonymous requests to every route", func(t *testing.T) {
+			assert.Equal(t, http.StatusOK, request(router, "POST", "/secrets", "").Code)
+			assert.Equal(t, http.StatusOK, request(router, "GET", "/config", "").Code)
+		})
+
+		t.Run("it should ignore the Authorization header", func(t *testing.T) {
+			assert.Equal(t, http.StatusOK, request(router, "POST", "/secrets", "Bearer cellar_invalid").Code)
+		})
+	})
+
+	t.Run("when API keys are enabled", func(t *testing.T) {
+		router := setupRouter(t, true, "read-config")
+
+		t.Run("and the request has no key", func(t *testing.T) {
+			w := request(router, "POST", "/secrets", "")
+
+			t.Run("it should reject it from routes that need a scope", func(t *testing.T) {
+				assert.Equal(t, http.StatusUnauthorized, w.Code)
+				assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+			})
+
+			t.Run("it should allow it to routes that need an anonymous scope", func(t *testing.T) {
+				assert.Equal(t, http.StatusOK, request(router, "GET", "/config", "").Code)
+			})
+
+			t.Run("it should allow it to routes that need no scope", func(t *testing.T) {
+				assert.Equal(t, http.StatusOK, request(router, "POST", "/secrets/id/access", "").Code)
+			})
+		})
+
+		t.Run("and the key is granted the scope", func(t *testing.T) {
+			token := writeKey(t, models.ScopeCreate)
+
+			t.Run("it should allow the request", func(t *testing.T) {
+				assert.Equal(t, http.StatusOK, request(router, "POST", "/secrets", "Bearer "+token).Code)
+			})
+		})
+
+		t.Run("and the key is granted the admin scope", func(t *testing.T) {
+			token := writeKey(t, models.ScopeAdmin)
+
+			t.Run("it should allow the request", func(t *testing.T) {
+				assert.Equal(t, http.StatusOK, request(router, "POST", "/secrets", "Bearer "+token).Code)
+			})
+		})
+
+		t.Run("and the key is not granted the scope", func(t *testing.T) {
+			token := writeKey(t, models.ScopeReadConfig)
+
+			t.Run("it should forbid the request", func(t *testing.T) {
+				assert.Equal(t, http.StatusForbidden, request(router, "POST", "/secrets", "Bearer "+token).Code)
+			})
+		})
+
+		t.Run("and the key is invalid", func(t *testing.T) {
+			token := writeKey(t, models.ScopeCreate)
+			invalid := token[:len(token)-4] + "0000"
+			if invalid == token {
+				invalid = token[:len(token)-4] + "1111"
+			}
+
+			t.Run("it should reject the request, even to routes that need no scope", func(t *testing.T) {
+				assert.Equal(t, http.StatusUnauthorized, request(router, "POST", "/secrets", "Bearer "+invalid).Code)
+				assert.Equal(t, http.StatusUnauthorized, request(router, "POST", "/secrets/id/access", "Bearer "+invalid).Code)
+			})
+		})
+
+		t.Run("and the key was revoked", func(t *testing.T) {
+			key, token, err := apikeys.Generate("revoked", []models.Scope{models.ScopeCreate})
+			require.NoError(t, err)
+			require.NoError(t, store.Write(ctx, key))
+			_, err = store.Delete(ctx, key.ID)
+			require.NoError(t, err)
+
+			t.Run("it should reject the request", func(t *testing.T) {
+				assert.Equal(t, http.StatusUnauthorized, request(router, "POST", "/secrets", "Bearer "+token).Code)
+			})
+		})
+
+		t.Run("and the Authorization header is not a bearer token", func(t *testing.T) {
+			token := writeKey(t, models.ScopeCreate)
+
+			t.Run("it should reject the request", func(t *testing.T) {
+				assert.Equal(t, http.StatusUnauthorized, request(router, "POST", "/secrets", "Basic "+token).Code)
+			})
+		})
+	})
+}
@@ -0,0 +1,277 @@
+//go:build integration
+
+package middleware
+
+import (
+	"cellar/pkg/cryptography"
+	"cellar/pkg/datastore"
+	pkgerrors "cellar/pkg/errors"
+	"cellar/pkg/middleware"
+	"cellar/pkg/mocks"
+	"cellar/pkg/ratelimit"
+	"cellar/pkg/settings"
+	"cellar/testing/testhelpers"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestBruteForceProtectionMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	router, client := setupHardeningRouter(t, ctrl, func(config *mocks.MockIHardeningConfiguration) {
+		config.EXPECT().BruteForceEnabled().Return(true).AnyTimes()
+		config.EXPECT().UniformResponsesEnabled().Return(false).AnyTimes()
+		config.EXPECT().NotFoundLimit().Return(3).AnyTimes()
+		config.EXPECT().NotFoundWindowSeconds().Return(60).AnyTimes()
+		config.EXPECT().FailureLimit().Return(3).AnyTimes()
+		config.EXPECT().FailureWindowSeconds().Return(60).AnyTimes()
+		config.EXPECT().BlockSeconds().Return(120).AnyTimes()
+		config.EXPECT().MaxBlockSeconds().Return(600).AnyTimes()
+	}, nil, nil)
+
+	request := func(clientIP string, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", path, nil)
+		req.RemoteAddr = clientIP
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("when a client finds the secrets it looks up", func(t *testing.T) {
+		clientIP := getUniqueIP()
+		for i := 0; i < 5; i++ {
+			require.Equal(t, http.StatusOK, request(clientIP, "/secrets/found").Code)
+		}
+
+		t.Run("it should not block the client", func(t *testing.T) {
+			assert.Equal(t, http.StatusOK, request(clientIP, "/secrets/found").Code)
+		})
+	})
+
+	t.Run("when a client looks up too many secrets that do not exist", func(t *testing.T) {
+		clientIP := getUniqueIP()
+		for i := 0; i < 3; i++ {
+			require.Equal(t, http.StatusNotFound, request(clientIP, "/secrets/missing").Code)
+		}
+
+		w := request(clientIP, "/secrets/found")
+
+		t.Run("it should block the client", func(t *testing.T) {
+			assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		})
+
+		t.Run("it should tell the client when to retry", func(t *testing.T) {
+			assert.NotEmpty(t, w.Header().Get("Retry-After"))
+		})
+
+		t.Run("it should not block other clients", func(t *testing.T) {
+			assert.Equal(t, http.StatusOK, request(getUniqueIP(), "/secrets/found").Code)
+		})
+	})
+
+	t.Run("when a client gives too many invalid credentials", func(t *testing.T) {
+		clientIP := getUniqueIP()
+		for i := 0; i < 3; i++ {
+			require.Equal(t, http.StatusForbidden, request(clientIP, "/secrets/invalid").Code)
+		}
+
+		t.Run("it should block the client", func(t *testing.T) {
+			assert.Equal(t, http.StatusTooManyRequests, request(clientIP, "/secrets/found").Code)
+		})
+	})
+
+	t.Run("when a client is refused for reasons other than its credentials", func(t *testing.T) {
+		clientIP := getUniqueIP()
+		for i := 0; i < 3; i++ {
+			require.Equal(t, http.StatusForbidden, request(clientIP, "/secrets/forbidden").Code)
+		}
+
+		t.Run("it should not block the client", func(t *testing.T) {
+			assert.Equal(t, http.StatusOK, request(clientIP, "/secrets/found").Code)
+		})
+	})
+
+	t.Run("when a client is blocked again after its lockout ends", func(t *testing.T) {
+		clientIP := getUniqueIP()
+		for i := 0; i < 3; i++ {
+			require.Equal(t, http.StatusNotFound, request(clientIP, "/secrets/missing").Code)
+		}
+		require.NoError(t, client.Del(context.Background(), "cellar:bruteforce:"+clientIP+":blocked").Err())
+		for i := 0; i < 3; i++ {
+			require.Equal(t, http.StatusNotFound, request(clientIP, "/secrets/missing").Code)
+		}
+
+		w := request(clientIP, "/secrets/found")
+
+		t.Run("it should block the client for twice as long", func(t *testing.T) {
+			require.Equal(t, http.StatusTooManyRequests, w.Code)
+			retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+			require.NoError(t, err)
+			assert.InDelta(t, 240, retryAfter, 2)
+		})
+	})
+}
+
+func TestUniformResponseMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const uniformResponse = 200 * time.Millisecond
+
+	ctrl := gomock.NewController(t)
+	encryption := mocks.NewMockEncryption(ctrl)
+	dataStore := mocks.NewMockDataStore(ctrl)
+	router, _ := setupHardeningRouter(t, ctrl, func(config *mocks.MockIHardeningConfiguration) {
+		config.EXPECT().BruteForceEnabled().Return(false).AnyTimes()
+		config.EXPECT().UniformResponsesEnabled().Return(true).AnyTimes()
+		config.EXPECT().UniformResponseMilliseconds().Return(int(uniformResponse.Milliseconds())).AnyTimes()
+	}, encryption, dataStore)
+
+	request := func(path string) (*sentAtRecorder, time.Duration) {
+		w := &sentAtRecorder{ResponseRecorder: httptest.NewRecorder()}
+		req := httptest.NewRequest("GET", path, nil)
+		req.RemoteAddr = getUniqueIP()
+		start := time.Now()
+		router.ServeHTTP(w, req)
+		w.sentAfter = w.sentAt.Sub(start)
+		return w, time.Since(start)
+	}
+
+	t.Run("when the secret does not exist", func(t *testing.T) {
+		dataStore.EXPECT().
+			ReadSecret(gomock.Any(), gomock.Any()).
+			Return(nil).
+			Times(2)
+		encryption.EXPECT().
+			Encrypt(gomock.Any(), gomock.Any()).
+			Return("vault:v1:decoy", nil).
+			Times(1)
+		encryption.EXPECT().
+			Decrypt(gomock.Any(), "vault:v1:decoy").
+			Return([]byte("decoy"), nil).
+			Times(2)
+
+		w, elapsed := request("/secrets/missing/access")
+		require.Equal(t, http.StatusNotFound, w.Code)
+
+		t.Run("it should take at least the uniform response time", func(t *testing.T) {
+			assert.GreaterOrEqual(t, elapsed, uniformResponse)
+		})
+
+		t.Run("it should read the datastore and decrypt the same decoy content every time", func(t *testing.T) {
+			w, _ := request("/secrets/missing/access")
+			assert.Equal(t, http.StatusNotFound, w.Code)
+		})
+	})
+
+	t.Run("when the secret exists", func(t *testing.T) {
+		w, elapsed := request("/secrets/found/access")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		t.Run("it should take the same time without a decoy access", func(t *testing.T) {
+			assert.GreaterOrEqual(t, elapsed, uniformResponse)
+			assert.Less(t, elapsed, 2*uniformResponse)
+		})
+
+		t.Run("it should not send any of the response before the uniform response time", func(t *testing.T) {
+			assert.GreaterOrEqual(t, w.sentAfter, uniformResponse)
+		})
+
+		t.Run("it should send the whole response", func(t *testing.T) {
+			assert.JSONEq(t, `{"message":"success"}`, w.Body.String())
+		})
+	})
+
+	t.Run("when the request fails", func(t *testing.T) {
+		w, _ := request("/secrets/forbidden")
+
+		t.Run("it should send the error response", func(t *testing.T) {
+			assert.Equal(t, http.StatusForbidden, w.Code)
+			assert.NotEmpty(t, w.Body.String())
+		})
+	})
+}
+
+// sentAtRecorder records when the response started being sent.
+type sentAtRecorder struct {
+	*httptest.ResponseRecorder
+	sentAt    time.Time
+	sentAfter time.Duration
+}
+
+func (w *sentAtRecorder) WriteHeader(code int) {
+	if w.sentAt.IsZero() {
+		w.sentAt = time.Now()
+	}
+	w.ResponseRecorder.WriteHeader(code)
+}
+
+func (w *sentAtRecorder) Write(data []byte) (int, error) {
+	if w.sentAt.IsZero() {
+		w.sentAt = time.Now()
+	}
+	return w.ResponseRecorder.Write(data)
+}
+
+func setupHardeningRouter(t *testing.T, ctrl *gomock.Controller, configure func(*mocks.MockIHardeningConfiguration), encryption cryptography.Encryption, dataStore datastore.DataStore) (*gin.Engine, *redis.Client) {
+	cfg := settings.NewConfiguration()
+	client := testhelpers.GetRedisClient(cfg.Datastore().Redis())
+
+	ctx := context.Background()
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Fatalf("Redis must be available for integration tests: %v", err)
+	}
+	t.Cleanup(func() {
+		client.FlushDB(ctx)
+		_ = client.Close()
+	})
+
+	hardening := mocks.NewMockIHardeningConfiguration(ctrl)
+	configure(hardening)
+
+	mockConfig := mocks.NewMockIConfiguration(ctrl)
+	mockConfig.EXPECT().Hardening().Return(hardening).AnyTimes()
+
+	detector := ratelimit.NewRedisBruteForceDetector(client, hardening)
+
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+	router.Use(func(c *gin.Context) {
+		c.Set(settings.Key, mockConfig)
+		c.Set(ratelimit.BruteForceKey, detector)
+		if encryption != nil {
+			c.Set(cryptography.Key, encryption)
+		}
+		if dataStore != nil {
+			c.Set(datastore.Key, dataStore)
+		}
+		c.Next()
+	})
+
+	handler := func(c *gin.Context) {
+		switch c.Param("id") {
+		case "found":
+			c.JSON(http.StatusOK, gin.H{"message": "success"})
+		case "invalid":
+			_ = c.Error(pkgerrors.NewInvalidCredentialError("invalid owner token"))
+		case "forbidden":
+			_ = c.Error(pkgerrors.NewForbiddenError("client address not allowed"))
+		default:
+			c.Status(http.StatusNotFound)
+		}
+	}
+	router.GET("/secrets/:id", middleware.BruteForceProtection(), middleware.UniformResponse(), handler)
+	router.GET("/secrets/:id/access", middleware.BruteForceProtection(), middleware.UniformAccessResponse(), handler)
+
+	return router, client
+}
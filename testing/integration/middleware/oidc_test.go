@@ -0,0 +1,169 @@
+//go:build integration
+
+package middleware
+
+import (
+	"cellar/pkg/apikeys"
+	"cellar/pkg/middleware"
+	"cellar/pkg/mocks"
+	"cellar/pkg/models"
+	"cellar/pkg/oidc"
+	"cellar/pkg/ratelimit"
+	"cellar/pkg/settings"
+	"cellar/testing/testhelpers"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestOIDCMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	cfg := settings.NewConfiguration()
+	client := testhelpers.GetRedisClient(cfg.Datastore().Redis())
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Fatalf("Redis must be available for integration tests: %v", err)
+	}
+	t.Cleanup(func() {
+		client.FlushDB(ctx)
+		_ = client.Close()
+	})
+
+	identities := map[string]*models.Identity{
+		"alice-token": {Subject: "alice", Groups: []string{"engineering"}},
+		"bob-token":   {Subject: "bob", Groups: []string{"sales"}},
+	}
+
+	setupRouter := func(t *testing.T, allowedGroups []string, scopes []string, limit int) *gin.Engine {
+		ctrl := gomock.NewController(t)
+		oidcConfig := mocks.NewMockIOIDCConfiguration(ctrl)
+		oidcConfig.EXPECT().Enabled().Return(true).AnyTimes()
+		oidcConfig.EXPECT().AllowedGroups().Return(allowedGroups).AnyTimes()
+		oidcConfig.EXPECT().Scopes().Return(scopes).AnyTimes()
+		oidcConfig.EXPECT().UserCreateLimit().Return(limit).AnyTimes()
+		oidcConfig.EXPECT().UserCreateWindowSeconds().Return(3600).AnyTimes()
+		auth := mocks.NewMockIAuthConfiguration(ctrl)
+		auth.EXPECT().Enabled().Return(true).AnyTimes()
+		auth.EXPECT().AnonymousScopes().Return([]string{}).AnyTimes()
+		auth.EXPECT().OIDC().Return(oidcConfig).AnyTimes()
+		mockConfig := mocks.NewMockIConfiguration(ctrl)
+		mockConfig.EXPECT().Auth().Return(auth).AnyTimes()
+
+		verifier := mocks.NewMockVerifier(ctrl)
+		verifier.EXPECT().Verify(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, token string) (*models.Identity, error) {
+				if identity, ok := identities[token]; ok {
+					return identity, nil
+				}
+				return nil, fmt.Errorf("%w: unknown token", oidc.ErrInvalidToken)
+			},
+		).AnyTimes()
+
+		client.FlushDB(ctx)
+		quota := ratelimit.NewRedisUserQuota(client, oidcConfig)
+
+		router := gin.New()
+		router.Use(middleware.ErrorHandler())
+		router.Use(func(c *gin.Context) {
+			c.Set(settings.Key, mockConfig)
+			c.Set(apikeys.Key, apikeys.NewRedisStore(client))
+			c.Set(oidc.Key, verifier)
+			c.Set(ratelimit.QuotaKey, quota)
+			c.Next()
+		})
+		router.Use(middleware.Authenticate())
+
+		router.POST("/secrets", middleware.RequireScope(models.ScopeCreate), middleware.UserCreateLimit(), func(c *gin.Context) {
+			c.Status(http.StatusCreated)
+		})
+		router.POST("/failing", middleware.RequireScope(models.ScopeCreate), middleware.UserCreateLimit(), func(c *gin.Context) {
+			c.Status(http.StatusBadRequest)
+		})
+		router.DELETE("/secrets/:id", middleware.RequireScope(models.ScopeDeleteAny), func(c *gin.Context) {
+			c.Status(http.StatusNoContent)
+		})
+		return router
+	}
+
+	request := func(router *gin.Engine, method string, path string, token string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("when the token is valid", func(t *testing.T) {
+		router := setupRouter(t, nil, []string{"create"}, 0)
+
+		t.Run("it should allow routes that need a granted scope", func(t *testing.T) {
+			assert.Equal(t, http.StatusCreated, request(router, "POST", "/secrets", "alice-token").Code)
+		})
+
+		t.Run("it should forbid routes that need a scope that is not granted", func(t *testing.T) {
+			assert.Equal(t, http.StatusForbidden, request(router, "DELETE", "/secrets/id", "alice-token").Code)
+		})
+	})
+
+	t.Run("when the token is invalid", func(t *testing.T) {
+		router := setupRouter(t, nil, []string{"create"}, 0)
+
+		t.Run("it should reject the request", func(t *testing.T) {
+			w := request(router, "POST", "/secrets", "forged-token")
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+		})
+	})
+
+	t.Run("when allowed groups are configured", func(t *testing.T) {
+		router := setupRouter(t, []string{"engineering"}, []string{"create"}, 0)
+
+		t.Run("it should allow users in an allowed group", func(t *testing.T) {
+			assert.Equal(t, http.StatusCreated, request(router, "POST", "/secrets", "alice-token").Code)
+		})
+
+		t.Run("it should forbid users outside the allowed groups", func(t *testing.T) {
+			assert.Equal(t, http.StatusForbidden, request(router, "POST", "/secrets", "bob-token").Code)
+		})
+	})
+
+	t.Run("when the SSO users are granted the admin scope", func(t *testing.T) {
+		router := setupRouter(t, nil, []string{"admin"}, 0)
+
+		t.Run("it should allow every route", func(t *testing.T) {
+			assert.Equal(t, http.StatusNoContent, request(router, "DELETE", "/secrets/id", "alice-token").Code)
+		})
+	})
+
+	t.Run("when a user creation limit is configured", func(t *testing.T) {
+		router := setupRouter(t, nil, []string{"create"}, 2)
+
+		t.Run("it should not count failed creations", func(t *testing.T) {
+			for i := 0; i < 3; i++ {
+				assert.Equal(t, http.StatusBadRequest, request(router, "POST", "/failing", "alice-token").Code)
+			}
+		})
+
+		t.Run("it should limit the creations of each user", func(t *testing.T) {
+			assert.Equal(t, http.StatusCreated, request(router, "POST", "/secrets", "alice-token").Code)
+			assert.Equal(t, http.StatusCreated, request(router, "POST", "/secrets", "alice-token").Code)
+
+			w := request(router, "POST", "/secrets", "alice-token")
+			assert.Equal(t, http.StatusTooManyRequests, w.Code)
+			assert.NotEmpty(t, w.Header().Get("Retry-After"))
+		})
+
+		t.Run("it should count the creations of other users separately", func(t *testing.T) {
+			assert.Equal(t, http.StatusCreated, request(router, "POST", "/secrets", "bob-token").Code)
+		})
+	})
+}
@@ -0,0 +1,144 @@
+//go:build integration
+
+package middleware
+
+import (
+	"cellar/pkg/apikeys"
+	"cellar/pkg/datastore"
+	"cellar/pkg/middleware"
+	"cellar/pkg/mocks"
+	"cellar/pkg/models"
+	"cellar/pkg/oidc"
+	"cellar/pkg/settings"
+	"cellar/pkg/tenants"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestResolveTenantMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	setupRouter := func(t *testing.T, enabled bool) *gin.Engine {
+		ctrl := gomock.NewController(t)
+		tenantsConfig := mocks.NewMockITenantsConfiguration(ctrl)
+		tenantsConfig.EXPECT().Enabled().Return(enabled).AnyTimes()
+		mockConfig := mocks.NewMockIConfiguration(ctrl)
+		mockConfig.EXPECT().Tenants().Return(tenantsConfig).AnyTimes()
+
+		dataStores := map[string]datastore.DataStore{
+			"server":  mocks.NewMockDataStore(ctrl),
+			"finance": mocks.NewMockDataStore(ctrl),
+			"sales":   mocks.NewMockDataStore(ctrl),
+		}
+		namespace := func(id string, hosts ...string) *tenants.Namespace {
+			tenant := &tenants.Tenant{ID: id, Hosts: hosts}
+			return &tenants.Namespace{
+				Tenant:        tenant,
+				Configuration: mockConfig,
+				Encryption:    mocks.NewMockEncryption(ctrl),
+				DataStore:     dataStores[id],
+				RateLimiter:   mocks.NewMockRateLimiter(ctrl),
+			}
+		}
+		namespaces := tenants.NewNamespaces([]*tenants.Namespace{
+			namespace("finance", "finance.example.com"),
+			namespace("sales", "sales.example.com"),
+		})
+
+		router := gin.New()
+		router.Use(middleware.ErrorHandler())
+		router.Use(func(c *gin.Context) {
+			c.Set(settings.Key, mockConfig)
+			c.Set(datastore.Key, dataStores["server"])
+			c.Set(tenants.Key, namespaces)
+			if tenant := c.GetHeader("X-Test-Key-Tenant"); tenant != "" {
+				c.Set(apikeys.AuthenticatedKey, &models.APIKey{Tenant: tenant})
+			}
+			if tenant := c.GetHeader("X-Test-Token-Tenant"); tenant != "" {
+				c.Set(oidc.IdentityKey, &models.Identity{Subject: "alice", Tenant: tenant})
+			}
+			c.Next()
+		})
+		router.Use(middleware.ResolveTenant())
+		router.GET("/", func(c *gin.Context) {
+			tenant := "server"
+			if resolved, ok := c.Get(tenants.ResolvedKey); ok {
+				tenant = resolved.(*tenants.Tenant).ID
+			}
+			assert.Same(t, dataStores[tenant], c.MustGet(datastore.Key))
+			c.String(http.StatusOK, tenant)
+		})
+		return router
+	}
+
+	request := func(router *gin.Engine, host string, headers map[string]string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Host = host
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("when tenants are disabled", func(t *testing.T) {
+		router := setupRouter(t, false)
+
+		t.Run("it should use the server's namespace on every host", func(t *testing.T) {
+			w := request(router, "finance.example.com", nil)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "server", w.Body.String())
+		})
+	})
+
+	t.Run("when tenants are enabled", func(t *testing.T) {
+		router := setupRouter(t, true)
+
+		t.Run("and the request has no credentials", func(t *testing.T) {
+			t.Run("it should resolve the tenant from the host", func(t *testing.T) {
+				assert.Equal(t, "finance", request(router, "finance.example.com:8080", nil).Body.String())
+			})
+
+			t.Run("it should use the server's namespace on hosts of no tenant", func(t *testing.T) {
+				assert.Equal(t, "server", request(router, "cellar.example.com", nil).Body.String())
+			})
+		})
+
+		t.Run("and the API key is bound to a tenant", func(t *testing.T) {
+			t.Run("it should resolve the tenant of the key on hosts of no tenant", func(t *testing.T) {
+				w := request(router, "cellar.example.com", map[string]string{"X-Test-Key-Tenant": "sales"})
+				assert.Equal(t, "sales", w.Body.String())
+			})
+
+			t.Run("it should accept the key on the host of its tenant", func(t *testing.T) {
+				w := request(router, "sales.example.com", map[string]string{"X-Test-Key-Tenant": "sales"})
+				assert.Equal(t, "sales", w.Body.String())
+			})
+
+			t.Run("it should reject the key on the host of another tenant", func(t *testing.T) {
+				w := request(router, "finance.example.com", map[string]string{"X-Test-Key-Tenant": "sales"})
+				assert.Equal(t, http.StatusForbidden, w.Code)
+			})
+		})
+
+		t.Run("and the token names a tenant", func(t *testing.T) {
+			t.Run("it should resolve the tenant of the token", func(t *testing.T) {
+				w := request(router, "cellar.example.com", map[string]string{"X-Test-Token-Tenant": "finance"})
+				assert.Equal(t, "finance", w.Body.String())
+			})
+		})
+
+		t.Run("and the credentials name an unknown tenant", func(t *testing.T) {
+			t.Run("it should reject the request", func(t *testing.T) {
+				w := request(router, "cellar.example.com", map[string]string{"X-Test-Token-Tenant": "marketing"})
+				assert.Equal(t, http.StatusForbidden, w.Code)
+			})
+		})
+	})
+}
@@ -2,6 +2,7 @@ package testhelpers
 
 import (
 	"bytes"
+	"cellar/pkg/keyid"
 	"cellar/pkg/models"
 	"cellar/pkg/settings"
 	"cellar/pkg/settings/datastore"
@@ -40,6 +41,14 @@ func GetRedisClient(cfg datastore.IRedisConfiguration) *redis.Client {
 		DB:       cfg.DB(),
 	})
 }
+
+// GetKeyIDs returns a hasher that derives key IDs under a pepper only used by tests.
+func GetKeyIDs(tb testing.TB) *keyid.Hasher {
+	keyIds, err := keyid.NewHasher("cellar-test-pepper")
+	require.NoError(tb, err)
+	return keyIds
+}
+
 func CreateSecretV1(t *testing.T, cfg settings.IConfiguration, content string, accessLimit int) models.SecretMetadataResponse {
 	secret := map[string]interface{}{
 		"access_limit":     accessLimit,
@@ -0,0 +1,141 @@
+package testhelpers
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	// SmtpServer is a minimal in-process SMTP stand-in that accepts every message it receives.
+	// It implements just enough of RFC 5321 for net/smtp clients to deliver mail without authentication or TLS.
+	SmtpServer struct {
+		listener net.Listener
+		mutex    sync.Mutex
+		messages []SmtpMessage
+	}
+
+	SmtpMessage struct {
+		From string
+		To   []string
+		Data string
+	}
+)
+
+// NewSmtpServer starts an SMTP stand-in on a random local port. It is stopped when the test completes.
+func NewSmtpServer(tb testing.TB) *SmtpServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(tb, err)
+
+	server := &SmtpServer{listener: listener}
+	go server.serve()
+
+	tb.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	return server
+}
+
+func (server *SmtpServer) Host() string {
+	return server.listener.Addr().(*net.TCPAddr).IP.String()
+}
+
+func (server *SmtpServer) Port() int {
+	return server.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (server *SmtpServer) Address() string {
+	return net.JoinHostPort(server.Host(), strconv.Itoa(server.Port()))
+}
+
+// Messages returns a copy of all messages received so far.
+func (server *SmtpServer) Messages() []SmtpMessage {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	return append([]SmtpMessage(nil), server.messages...)
+}
+
+func (server *SmtpServer) serve() {
+	for {
+		conn, err := server.listener.Accept()
+		if err != nil {
+			return
+		}
+		go server.handle(conn)
+	}
+}
+
+func (server *SmtpServer) handle(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	reader := bufio.NewReader(conn)
+	reply := func(line string) bool {
+		_, err := conn.Write([]byte(line + "\r\n"))
+		return err == nil
+	}
+
+	if !reply("220 localhost ESMTP cellar-test") {
+		return
+	}
+
+	var current SmtpMessage
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(line)
+
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			current = SmtpMessage{From: extractAddress(line[len("MAIL FROM:"):])}
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			current.To = append(current.To, extractAddress(line[len("RCPT TO:"):]))
+			reply("250 OK")
+		case command == "DATA":
+			if !reply("354 End data with <CR><LF>.<CR><LF>") {
+				return
+			}
+			var data strings.Builder
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" || dataLine == ".\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(dataLine, "."))
+			}
+			current.Data = data.String()
+			server.mutex.Lock()
+			server.messages = append(server.messages, current)
+			server.mutex.Unlock()
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func extractAddress(value string) string {
+	value = strings.TrimSpace(value)
+	if index := strings.Index(value, " "); index >= 0 {
+		value = value[:index]
+	}
+	return strings.Trim(value, "<>")
+}